@@ -1,94 +1,493 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apiserver"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
 	"github.com/sri2103/resource-quota-enforcer/pkg/client"
+	rqeconfig "github.com/sri2103/resource-quota-enforcer/pkg/config"
 	"github.com/sri2103/resource-quota-enforcer/pkg/controller"
+	"github.com/sri2103/resource-quota-enforcer/pkg/dashboard"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
 	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	policyinformers "github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions"
 	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
 	"github.com/sri2103/resource-quota-enforcer/pkg/health"
 	"github.com/sri2103/resource-quota-enforcer/pkg/informers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	"github.com/sri2103/resource-quota-enforcer/pkg/manager"
 	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyreport"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+	"github.com/sri2103/resource-quota-enforcer/pkg/promusage"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+	"github.com/sri2103/resource-quota-enforcer/pkg/version"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 func main() {
+	contexts := flag.String("contexts", "", "comma-separated contexts of -kubeconfig naming the clusters to enforce policies in from this one process; each is named after its context in metrics, logs and audit events (empty enforces in the cluster of -kubeconfig only)")
+	grpcAddr := flag.String("grpc-addr", "", "address to serve the gRPC API on, e.g. :9090 (disabled when empty)")
+	metricsAddr := flag.String("metrics-addr", ":8080", "address to serve /metrics, /healthz and /readyz on")
+	enablePprof := flag.Bool("enable-pprof", false, "serve net/http/pprof under /debug/pprof/ on the metrics address")
+	stallTimeout := flag.Duration("worker-stall-timeout", controller.DefaultStallTimeout, "fail /healthz when queued work has not been processed for this long and no worker is free")
+	enableDebug := flag.Bool("enable-debug-endpoints", false, "serve /debug/cache and /debug/config on the metrics address, authorized through the API server")
+	dashboardAddr := flag.String("dashboard-addr", "", "address to serve the read-only web dashboard of usage and enforcement actions on, e.g. :8090; it has no authentication (disabled when empty)")
+	var auditCfg audit.Config
+	auditCfg.AddFlags(flag.CommandLine)
+	var traceCfg tracing.Config
+	traceCfg.AddFlags(flag.CommandLine)
+	var logOpts logging.Options
+	logOpts.AddFlags(flag.CommandLine)
+	var rateLimits client.RateLimits
+	rateLimits.AddFlags(flag.CommandLine)
+	var impersonate v1beta1.Impersonation
+	flag.StringVar(&impersonate.User, "as", "", "user to delete pods and suspend jobs as, for policies without spec.impersonate; the service account needs the impersonate verb on it (empty acts as the service account)")
+	flag.Func("as-group", "group to impersonate along with -as; repeat or separate with commas for several", func(groups string) error {
+		impersonate.Groups = append(impersonate.Groups, strings.Split(groups, ",")...)
+		return nil
+	})
+	workers := flag.Int("workers", 5, "number of goroutines reconciling namespaces concurrently")
+	informerResync := flag.Duration("informer-resync", informers.DefaultResync, "how often the pod, namespace and policy informers replay their cache (0 disables)")
+	var enforcement handlers.EnforcementMode
+	flag.Var(&enforcement, "enforcement", "active deletes pods to enforce policies; dry-run only reports the pods it would delete in events, metrics and the audit log")
+	var exempt handlers.ExemptNamespaces
+	exempt.AddFlags(flag.CommandLine)
+	var configOpts rqeconfig.Options
+	configOpts.AddFlags(flag.CommandLine)
+	features.DefaultGate.AddFlags(flag.CommandLine)
+	maxIterations := flag.Int("enforce-max-iterations", handlers.DefaultMaxIterations, "maximum pods deleted per policy in one sync")
+	deletionsPerMinute := flag.Int("max-deletions-per-minute", 0, "most pods enforcement deletes per namespace per minute; further violations are reported and left for a later sync (0 disables)")
+	deletionTimeout := flag.Duration("pod-deletion-timeout", handlers.DefaultDeletionTimeout, "how long to wait for a deleted pod to disappear; if it is still terminating then, the rest of the violation is left to the next sync")
+	trackUsage := flag.Bool("track-usage", true, "keep running usage totals from pod events instead of listing pods on every sync")
+	enableDRA := flag.Bool("enable-dra", false, "count devices claimed through DRA resourceClaims against spec.maxExtendedResources")
+	prometheusURL := flag.String("prometheus-url", "", "base URL of the Prometheus that measures usage for policies with spec.usageSource=prometheus, e.g. http://prometheus.monitoring.svc:9090")
+	clusterCapacity := flag.Bool("cluster-capacity", true, "watch nodes to resolve spec.clusterShare of policies against the cluster's allocatable resources")
+	policyReports := flag.Bool("policy-reports", false, "write wgpolicyk8s.io PolicyReports of evicted pods and a ClusterPolicyReport of every policy's state")
+	policyReportInterval := flag.Duration("policy-report-interval", time.Minute, "how often the ClusterPolicyReport is brought up to date with -policy-reports")
+	defaultPolicyFile := flag.String("default-policy", "", "ResourceQuotaPolicy manifest enforced in namespaces without a policy of their own, unless annotated quota.platform/default-action=allow")
+	var sourceCfg policysource.Config
+	sourceCfg.AddFlags(flag.CommandLine)
+	var maintenanceCfg maintenance.Config
+	maintenanceCfg.AddFlags(flag.CommandLine)
+	var exclude handlers.Exclusions
+	exclude.AddFlags(flag.CommandLine)
+	ctrlOpts := controller.DefaultOptions
+	ctrlOpts.AddFlags(flag.CommandLine)
+	var leaderCfg manager.Config
+	leaderCfg.AddFlags(flag.CommandLine)
+
 	// set up clients
 	config, err := client.PrepareConfig()
-	if err != nil {
-		log.Fatalf("error loading config: %v", err)
+	exitOnErr(err, "Error loading config")
+	var configFile *rqeconfig.File
+	if configOpts.Path != "" {
+		configFile = rqeconfig.New(flag.CommandLine, configOpts.Path, rqeconfig.SectionController)
+		exitOnErr(configFile.Load(), "Error loading config file")
+	}
+	exitOnErr(logging.Setup(logOpts), "Error setting up logging")
+	if *workers < 1 {
+		exitOnErr(fmt.Errorf("--workers must be at least 1, got %d", *workers), "Invalid flags")
+	}
+	if impersonate.User == "" && len(impersonate.Groups) > 0 {
+		exitOnErr(fmt.Errorf("-as-group needs -as"), "Invalid flags")
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "rqe-controller", traceCfg)
+	exitOnErr(err, "Error setting up tracing")
+	defer shutdownTracing(context.Background())
+
+	auditor, err := audit.NewFromConfig(auditCfg)
+	exitOnErr(err, "Error opening audit log")
+	if *policyReports && auditor == nil {
+		auditor = audit.New(nil)
+	}
+	var recent *dashboard.Recent
+	if *dashboardAddr != "" {
+		if auditor == nil {
+			auditor = audit.New(nil)
+		}
+		recent = dashboard.NewRecent(dashboard.DefaultRecent)
+		auditor.AddExporter(recent, auditCfg.Batch)
+	}
+	defer auditor.Close()
+
+	s := &settings{
+		informerResync:     *informerResync,
+		maxIterations:      *maxIterations,
+		deletionsPerMinute: *deletionsPerMinute,
+		deletionTimeout:    *deletionTimeout,
+		enforcement:        &enforcement,
+		exempt:             &exempt,
+		trackUsage:         *trackUsage,
+		enableDRA:          *enableDRA,
+		prometheusURL:      *prometheusURL,
+		clusterCapacity:    *clusterCapacity,
+		policyReports:      *policyReports,
+		stallTimeout:       *stallTimeout,
+		sourceCfg:          sourceCfg,
+		maintenanceCfg:     maintenanceCfg,
+		exclude:            exclude,
+		ctrlOpts:           ctrlOpts,
+		auditor:            auditor,
+		auditBatch:         auditCfg.Batch,
+		rateLimits:         rateLimits,
+	}
+	if impersonate.User != "" {
+		s.impersonate = &impersonate
+	}
+	if *defaultPolicyFile != "" {
+		s.defaultPolicy, err = policyfile.Load(*defaultPolicyFile)
+		exitOnErr(err, "Error loading default policy")
+	}
+
+	var clusters []*cluster
+	if *contexts == "" {
+		c, err := s.newCluster("", config)
+		exitOnErr(err, "Error setting up controller")
+		clusters = append(clusters, c)
+	} else {
+		kubeconfig := flag.Lookup("kubeconfig").Value.String()
+		for _, name := range strings.Split(*contexts, ",") {
+			config, err := client.ContextConfig(kubeconfig, name)
+			exitOnErr(err, "Error loading config of context "+name)
+			c, err := s.newCluster(name, config)
+			exitOnErr(err, "Error setting up controller for context "+name)
+			clusters = append(clusters, c)
+		}
+	}
+	// the gRPC API and the debug endpoints serve the first cluster
+	primary := clusters[0]
+
+	// the Lease, with -leader-elect, is held in the cluster of -kubeconfig
+	mgrConfig := rest.CopyConfig(config)
+	rateLimits.Apply(mgrConfig)
+	client.SetUserAgent(mgrConfig, client.ComponentController)
+	mgr, err := manager.New(mgrConfig, leaderCfg)
+	exitOnErr(err, "Error setting up manager")
+	add := func(r manager.Runnable) { exitOnErr(mgr.Add(r), "Error setting up manager") }
+
+	metrics.InitMetrics()
+	if maintenanceCfg.ConfigMap != "" {
+		metrics.InitMaintenanceMetrics()
+	}
+	if configFile != nil {
+		metrics.InitConfigMetrics()
+		// the clusters share both, so a change applies to all of them
+		configFile.Live("enforcement", nil)
+		configFile.Live("exempt-namespaces", func() {
+			for _, c := range clusters {
+				c.ctrl.Resync()
+			}
+		})
+		add(manager.Always(func(ctx context.Context) error {
+			configFile.Run(ctx, configOpts.Interval)
+			return nil
+		}))
 	}
+
+	add(manager.Server(healthAndMetricsServer(*metricsAddr, *enablePprof, *enableDebug, clusters, func() bool { return manager.Standby(mgr) })))
+
+	if *grpcAddr != "" {
+		add(manager.Always(func(ctx context.Context) error {
+			return apiserver.Serve(ctx, *grpcAddr, apiserver.NewServer(primary.clientset, primary.crClient))
+		}))
+	}
+
+	for _, c := range clusters {
+		// policy sources, node capacity and maintenance mode are only read, so every replica
+		// watches them and one that takes over the Lease has them synced already
+		if c.ctrl.Source != nil {
+			add(manager.Always(func(ctx context.Context) error {
+				c.ctrl.Source.Run(ctx)
+				return nil
+			}))
+		}
+		if c.ctrl.Capacity != nil {
+			add(manager.Always(func(ctx context.Context) error {
+				c.ctrl.Capacity.Run(ctx)
+				return nil
+			}))
+		}
+		if c.maintenance != nil {
+			add(manager.Always(func(ctx context.Context) error {
+				c.maintenance.Run(ctx)
+				return nil
+			}))
+		}
+		if c.reporter != nil && !c.ctrl.WithoutCRD {
+			add(manager.Leader(func(ctx context.Context) error {
+				c.reporter.Run(ctx, *policyReportInterval)
+				return nil
+			}))
+		}
+		add(manager.Leader(func(ctx context.Context) error {
+			c.ctrl.Run(ctx, *workers)
+			if ctx.Err() == nil {
+				// Run only returns early when its caches never synced; exiting lets a restart,
+				// or another replica, try again
+				return fmt.Errorf("controller caches never synced")
+			}
+			return nil
+		}))
+	}
+	if *dashboardAddr != "" {
+		h := &dashboard.Handler{
+			Title:  "Resource Quota Enforcer",
+			Usage:  func() []dashboard.Namespace { return dashboardUsage(clusters) },
+			Recent: recent,
+		}
+		add(manager.Always(func(ctx context.Context) error {
+			return dashboard.ListenAndServe(ctx, *dashboardAddr, h)
+		}))
+	}
+
+	logging.L().Info("Resource Quota Enforcer controller started", "version", version.Get(), "enforcement", enforcement.String(), "featureGates", features.DefaultGate.String(), "clusters", len(clusters), "leaderElect", leaderCfg.LeaderElect)
+	// cancelled on SIGINT/SIGTERM, which stops the controller and any API call in flight. Start
+	// blocks until every runnable has returned, so the deferred audit flush and trace export run
+	// after the last sync. Losing the Lease ends it with an error, and the process exits so no
+	// sync runs without it.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := mgr.Start(ctx); err != nil {
+		logging.L().Error(err, "Controller stopped")
+		auditor.Close()
+		shutdownTracing(context.Background())
+		os.Exit(1)
+	}
+}
+
+// settings are the flags every cluster's controller is built from.
+type settings struct {
+	informerResync     time.Duration
+	maxIterations      int
+	deletionsPerMinute int
+	deletionTimeout    time.Duration
+	enforcement        *handlers.EnforcementMode
+	exempt             *handlers.ExemptNamespaces
+	trackUsage         bool
+	enableDRA          bool
+	prometheusURL      string
+	clusterCapacity    bool
+	policyReports      bool
+	stallTimeout       time.Duration
+	sourceCfg          policysource.Config
+	maintenanceCfg     maintenance.Config
+	exclude            handlers.Exclusions
+	ctrlOpts           controller.Options
+	defaultPolicy      *v1beta1.ResourceQuotaPolicy
+	auditor            *audit.Logger
+	auditBatch         audit.BatchOptions
+	rateLimits         client.RateLimits
+	impersonate        *v1beta1.Impersonation
+}
+
+// cluster is the controller of one cluster and the clients it was built with.
+type cluster struct {
+	name      string
+	clientset kubernetes.Interface
+	crClient  platformv1alpha1.Interface
+	ctrl      *controller.Controller
+	reporter  *policyreport.ClusterReporter
+	// maintenance is nil without -maintenance-configmap
+	maintenance *maintenance.Mode
+}
+
+// newCluster builds the controller enforcing policies in the cluster config points at. name is
+// empty unless the controller manages several clusters.
+func (s *settings) newCluster(name string, config *rest.Config) (*cluster, error) {
+	s.rateLimits.Apply(config)
+	client.SetUserAgent(config, client.ComponentController)
+	tracing.WrapConfig(config)
 	clientset, err := client.GetKubernetesClient(config)
 	if err != nil {
-		log.Fatalf("Error building client: %v", err)
+		return nil, fmt.Errorf("build client: %w", err)
 	}
 
 	// custom resource client
 	CRclient, err := platformv1alpha1.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating dynamic client: %v", err)
+		return nil, fmt.Errorf("build policy client: %w", err)
+	}
+	dynamicClient, err := client.DynamicClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("build dynamic client: %w", err)
 	}
 
 	// factories and informers
-	factory := informers.NewNamespaceInformer(clientset)
+	factory := informers.NewNamespaceInformer(clientset, s.informerResync)
 	podInformer := factory.Core().V1().Pods().Informer()
 	nsInformer := factory.Core().V1().Namespaces().Informer()
+	policyFactory := policyinformers.NewSharedInformerFactory(CRclient, s.informerResync)
+	policyInformer := policyFactory.Platform().V1beta1().ResourceQuotaPolicies()
+
+	c := &cluster{name: name, clientset: clientset, crClient: CRclient}
+	if s.policyReports {
+		s.auditor.AddExporter(&policyreport.Exporter{Client: dynamicClient, Cluster: name}, s.auditBatch)
+		c.reporter = &policyreport.ClusterReporter{
+			Client:    dynamicClient,
+			Policies:  policyInformer.Lister(),
+			HasSynced: policyInformer.Informer().HasSynced,
+		}
+	}
 
 	// enforcers to handle pod setups
 	enforcer := &handlers.PodEnforcer{
 		Client:      clientset,
-		PolicyCache: make(map[string]handlers.Policy),
+		PolicyCache: policycache.NewStore[handlers.Policy](),
+		Audit:       s.auditor,
+		Cluster:     name,
+		Exclude:     s.exclude,
+
+		Limiter:         handlers.NewDeletionLimiter(s.deletionsPerMinute),
+		MaxIterations:   s.maxIterations,
+		DeletionTimeout: s.deletionTimeout,
+		Mode:            s.enforcement,
+		Impersonator:    handlers.NewImpersonator(config, s.impersonate),
+	}
+	if s.trackUsage {
+		enforcer.Usage = handlers.NewUsageTracker(podInformer.GetIndexer())
+	}
+	if s.enableDRA {
+		enforcer.Devices = &handlers.DeviceResolver{Client: clientset}
+	}
+	if s.prometheusURL != "" {
+		enforcer.Prometheus = &promusage.Client{URL: s.prometheusURL}
+	}
+	enforcer.VPA = &handlers.VPAResolver{Client: clientset, Dynamic: dynamicClient}
+	if c.maintenance, err = maintenance.NewFromConfig(s.maintenanceCfg, clientset, s.informerResync); err != nil {
+		return nil, fmt.Errorf("set up maintenance mode: %w", err)
+	}
+	if c.maintenance != nil {
+		c.maintenance.Cluster = name
+		enforcer.Maintenance = c.maintenance
 	}
 
-	// start channels to block the main go routine
-	stopCh := make(chan struct{})
+	opts := s.ctrlOpts
+	opts.Cluster = name
 	scheme := runtime.NewScheme()
-	ctrl := controller.NewController(clientset, CRclient, podInformer, nsInformer, enforcer, scheme)
-
-	// end signals
-	sigterm := make(chan os.Signal, 1)
-	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
-	metrics.InitMetrics()
-
-	// run the controller and
-	go ctrl.Run(stopCh, 5)
-
-	go startHealthAndMetrics()
-
-	log.Println("Resource Quota Enforcer controller started 🚀")
-	<-sigterm
-	close(stopCh)
+	ctrl := controller.NewController(clientset, CRclient, podInformer, nsInformer, policyInformer, enforcer, scheme, opts)
+	ctrl.StallTimeout = s.stallTimeout
+	ctrl.DefaultPolicy = s.defaultPolicy
+	ctrl.Exempt = s.exempt
+	// QuotaExemptions are optional, so a cluster without their CRD is enforced without them
+	if installed, err := client.ExemptionCRDInstalled(clientset.Discovery()); err != nil {
+		return nil, fmt.Errorf("look up the QuotaExemption CRD: %w", err)
+	} else if installed {
+		ctrl.Exemptions = policyFactory.Platform().V1beta1().QuotaExemptions()
+	}
+	if s.clusterCapacity {
+		if ctrl.Capacity, err = capacity.NewTracker(clientset, s.informerResync); err != nil {
+			return nil, fmt.Errorf("watch nodes: %w", err)
+		}
+	}
+	if ctrl.Source, err = policysource.NewFromConfig(s.sourceCfg, clientset, s.informerResync); err != nil {
+		return nil, fmt.Errorf("set up policy sources: %w", err)
+	}
+	if ctrl.Source != nil {
+		// policies from files and ConfigMaps let the controller run before the CRD is installed
+		installed, err := client.PolicyCRDInstalled(clientset.Discovery())
+		if err != nil {
+			return nil, fmt.Errorf("look up the ResourceQuotaPolicy CRD: %w", err)
+		}
+		ctrl.WithoutCRD = !installed
+	}
+	c.ctrl = ctrl
+	return c, nil
 }
 
-func startHealthAndMetrics() {
+// startHealthAndMetrics serves health, metrics and the optional pprof and debug endpoints.
+func healthAndMetricsServer(addr string, enablePprof, enableDebug bool, clusters []*cluster, standby func() bool) (*http.Server, func() error) {
 	mux := http.NewServeMux()
 
 	// Health endpoints
-	mux.HandleFunc("/healthz", health.HealthzHandler)
-	mux.HandleFunc("/readyz", health.ReadyzHandler)
+	checks := make([]health.NamedCheck, len(clusters))
+	for i, c := range clusters {
+		checks[i] = health.NamedCheck{Name: "workers", Check: c.ctrl.Check}
+		if c.name != "" {
+			checks[i].Name = "workers-" + c.name
+		}
+	}
+	mux.HandleFunc("/healthz", health.ChecksHandler(checks...))
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		// a replica waiting for the Lease has nothing to sync; rollouts wait for it to be ready
+		if standby() {
+			w.Write([]byte("ready (standby)"))
+			return
+		}
+		health.ReadyzHandler(w, r)
+	})
 
 	// Prometheus metrics
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", metrics.Handler())
+
+	if enablePprof {
+		health.RegisterPprof(mux)
+		logging.L().Info("pprof endpoints enabled under /debug/pprof/")
+	}
+	if enableDebug {
+		primary := clusters[0]
+		health.RegisterDebug(mux, primary.clientset, func(ns string) any { return primary.ctrl.CachedPolicies(ns) })
+		logging.L().Info("Debug endpoints enabled", "paths", []string{"/debug/cache", "/debug/config"})
+	}
 
 	srv := &http.Server{
-		Addr:         ":8080",
+		Addr:         addr,
 		Handler:      mux,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
-	log.Println("📈 Metrics & health endpoints started on :8080")
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Error running metrics server: %v", err)
+	logging.L().Info("Metrics & health endpoints started", "addr", addr)
+	return srv, srv.ListenAndServe
+}
+
+// dashboardUsage lists the usage of every namespace the controller enforces a policy in, named
+// cluster/namespace when it manages several clusters.
+func dashboardUsage(clusters []*cluster) []dashboard.Namespace {
+	var out []dashboard.Namespace
+	for _, c := range clusters {
+		for ns, u := range c.ctrl.Usage() {
+			if c.name != "" {
+				ns = c.name + "/" + ns
+			}
+			out = append(out, dashboard.Namespace{
+				Name:      ns,
+				Policy:    u.Policy,
+				Violation: u.Violation,
+				Synced:    u.Synced,
+				Resources: []dashboard.Resource{
+					dashboard.NewResource("pods", *resource.NewQuantity(int64(u.Pods), resource.DecimalSI), *resource.NewQuantity(int64(u.MaxPods), resource.DecimalSI)),
+					dashboard.NewResource("cpu", u.CPU, u.MaxCPU),
+					dashboard.NewResource("memory", u.Memory, u.MaxMemory),
+				},
+			})
+		}
 	}
+	return out
 }
 
-func StartMetrics() {
+// exitOnErr logs err and exits when it is non-nil.
+func exitOnErr(err error, msg string) {
+	if err != nil {
+		logging.L().Error(err, msg)
+		os.Exit(1)
+	}
 }
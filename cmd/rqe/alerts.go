@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/alerting"
+)
+
+func runAlerts(args []string) error {
+	if len(args) == 0 || args[0] != "generate" {
+		return fmt.Errorf("usage: rqe alerts generate [flags]")
+	}
+	fs := flag.NewFlagSet("alerts generate", flag.ExitOnError)
+	out := fs.String("o", "", "write the manifest to this file instead of stdout")
+	name := fs.String("name", "resource-quota-enforcer", "name of the PrometheusRule")
+	namespace := fs.String("n", "", "namespace of the PrometheusRule")
+	labels := fs.String("labels", "", "comma-separated key=value labels of the PrometheusRule, e.g. the ones your Prometheus' ruleSelector matches")
+	rulesOnly := fs.Bool("rules-only", false, "print a plain Prometheus rule file instead of a PrometheusRule")
+	var opts alerting.Options
+	fs.Float64Var(&opts.QuotaThreshold, "quota-threshold", 0.9, "utilization, as a fraction of the limit, above which a namespace is near its quota")
+	fs.Float64Var(&opts.ErrorRatio, "error-ratio", 0.05, "fraction of admission requests ending in an error above which the webhook alerts")
+	fs.DurationVar(&opts.CertExpiry, "cert-expiry", 7*24*time.Hour, "alert once the webhook's serving certificate expires within this duration")
+	fs.Parse(args[1:])
+
+	opts.Name, opts.Namespace = *name, *namespace
+	if *labels != "" {
+		opts.Labels = make(map[string]string)
+		for _, kv := range strings.Split(*labels, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid label %q, want key=value", kv)
+			}
+			opts.Labels[k] = v
+		}
+	}
+
+	var manifest any = alerting.PrometheusRule(opts)
+	if *rulesOnly {
+		manifest = map[string]any{"groups": alerting.Groups(opts)}
+	}
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/bench"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	concurrency := fs.Int("c", 16, "requests in flight at once")
+	requests := fs.Int("requests", 2000, "requests to send in all")
+	namespace := fs.String("n", "bench", "namespace of the pods admitted")
+	pods := fs.Int("pods", 100, "pods already running in the namespace, for the in-process webhook")
+	file := fs.String("f", "", "ResourceQuotaPolicy manifest for the in-process webhook (default: limits every request fits)")
+	url := fs.String("url", "", "pod admission endpoint of a running webhook, e.g. https://localhost:8443/validate, instead of the in-process one")
+	insecure := fs.Bool("insecure-skip-verify", false, "don't verify the certificate of -url")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts := bench.Options{Concurrency: *concurrency, Requests: *requests, Namespace: *namespace}
+
+	var target bench.Target
+	if *url != "" {
+		client := &http.Client{Timeout: 30 * time.Second, Transport: &http.Transport{
+			MaxIdleConnsPerHost: *concurrency,
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: *insecure},
+		}}
+		target = bench.URL(client, *url)
+	} else {
+		// the webhook logs every denial
+		if err := logging.Setup(logging.Options{Level: "error", Format: "text"}); err != nil {
+			return err
+		}
+		policy := benchPolicy(*pods)
+		if *file != "" {
+			var err error
+			if policy, err = policyfile.Load(*file); err != nil {
+				return err
+			}
+		}
+		target = bench.Handler(bench.NewServer(*namespace, *pods, policy).HandleValidatePods)
+		opts.MeasureAllocs = true
+	}
+
+	result, err := bench.Run(ctx, target, opts)
+	if err != nil {
+		return err
+	}
+	if err := printBench(os.Stdout, result, *output); err != nil {
+		return err
+	}
+	if result.Errors > 0 {
+		return fmt.Errorf("%d requests failed, the first with: %s", result.Errors, result.FirstError)
+	}
+	return nil
+}
+
+// benchPolicy has room for every pod bench.Review creates, so the evaluation runs to the end
+// instead of stopping at the first limit exceeded.
+func benchPolicy(pods int) *v1beta1.ResourceQuotaPolicy {
+	cpu := resource.MustParse(fmt.Sprintf("%dm", 100*(pods+1)))
+	memory := resource.MustParse(fmt.Sprintf("%dMi", 128*(pods+1)))
+	return &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policyfile.DefaultName},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: int32(pods + 1), MaxCPU: &cpu, MaxMemory: &memory},
+	}
+}
+
+func printBench(w io.Writer, r bench.Result, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "requests\t%d (%d allowed, %d denied, %d failed)\n", r.Requests, r.Allowed, r.Denied, r.Errors)
+		fmt.Fprintf(tw, "elapsed\t%s (%.0f/s)\n", r.Elapsed.Round(time.Millisecond), r.Throughput())
+		fmt.Fprintf(tw, "p50\t%s\n", r.P50)
+		fmt.Fprintf(tw, "p95\t%s\n", r.P95)
+		fmt.Fprintf(tw, "p99\t%s\n", r.P99)
+		fmt.Fprintf(tw, "max\t%s\n", r.Max)
+		if r.AllocsPerRequest > 0 {
+			fmt.Fprintf(tw, "allocs/op\t%.0f\n", r.AllocsPerRequest)
+			fmt.Fprintf(tw, "bytes/op\t%.0f\n", r.BytesPerRequest)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+	"github.com/sri2103/resource-quota-enforcer/pkg/translate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	to := fs.String("to", "", "policy engine to convert to: kyverno or gatekeeper")
+	file := fs.String("f", "", "ResourceQuotaPolicy manifest to convert instead of the cluster's policies")
+	namespace := fs.String("n", "", "only convert the policies of this namespace (default: all namespaces)")
+	enforce := fs.Bool("enforce", false, "deny pods over a limit instead of only reporting them (Kyverno Enforce, Gatekeeper deny)")
+	fs.Parse(args)
+
+	if *to != "kyverno" && *to != "gatekeeper" {
+		return fmt.Errorf("-to must be kyverno or gatekeeper, got %q", *to)
+	}
+	var policies []v1beta1.ResourceQuotaPolicy
+	if *file != "" {
+		policy, err := policyfile.Load(*file)
+		if err != nil {
+			return err
+		}
+		if *namespace != "" {
+			policy.Namespace = *namespace
+		}
+		if policy.Namespace == "" {
+			policy.Namespace = metav1.NamespaceDefault
+		}
+		policies = append(policies, *policy)
+	} else {
+		c, err := newClients(*kubeconfig)
+		if err != nil {
+			return err
+		}
+		list, err := c.policies.PlatformV1beta1().ResourceQuotaPolicies(*namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list policies: %w", err)
+		}
+		policies = list.Items
+	}
+	return printConverted(os.Stdout, policies, *to, *enforce, time.Now())
+}
+
+// printConverted writes the policies converted for engine as a multi-document YAML manifest.
+// Fields without a translation are listed in a comment above each policy's object.
+func printConverted(w io.Writer, policies []v1beta1.ResourceQuotaPolicy, engine string, enforce bool, now time.Time) error {
+	var docs []string
+	if engine == "gatekeeper" {
+		template, err := yaml.Marshal(translate.GatekeeperTemplate())
+		if err != nil {
+			return err
+		}
+		docs = append(docs, "# Gatekeeper's Config must sync Pods (group \"\", version v1) for the constraints to see usage.\n"+string(template))
+	}
+	for i := range policies {
+		policy := &policies[i]
+		obj := translate.Kyverno(policy, now, enforce)
+		if engine == "gatekeeper" {
+			obj = translate.GatekeeperConstraint(policy, now, enforce)
+		}
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		doc := string(out)
+		if fields := translate.Unsupported(policy); len(fields) > 0 {
+			doc = fmt.Sprintf("# %s/%s: not translated: %s\n%s", policy.Namespace, policy.Name, strings.Join(fields, ", "), doc)
+		}
+		docs = append(docs, doc)
+	}
+	_, err := io.WriteString(w, strings.Join(docs, "---\n"))
+	return err
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/grafana"
+)
+
+func runDashboards(args []string) error {
+	if len(args) == 0 || args[0] != "generate" {
+		return fmt.Errorf("usage: rqe dashboards generate [flags]")
+	}
+	fs := flag.NewFlagSet("dashboards generate", flag.ExitOnError)
+	out := fs.String("o", "", "write the dashboard to this file instead of stdout")
+	title := fs.String("title", "", "dashboard title (default \"Resource Quota Enforcer\")")
+	uid := fs.String("uid", grafana.DefaultUID, "dashboard uid; importing a dashboard with the same uid replaces it")
+	datasource := fs.String("datasource", "", "uid of the Prometheus data source selected by default")
+	fs.Parse(args[1:])
+
+	data, err := json.MarshalIndent(grafana.Dashboard(grafana.Options{
+		Title:      *title,
+		UID:        *uid,
+		Datasource: *datasource,
+	}), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
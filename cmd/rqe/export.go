@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+)
+
+// droppedAnnotations are set by clients, conversion and the policy sources rather than by
+// whoever wrote the policy, and are left out of exported manifests.
+var droppedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	v1alpha1.HubSpecAnnotation,
+	policysource.AnnotationOrigin,
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	namespace := fs.String("n", "", "export the policies of this namespace")
+	all := fs.Bool("all-namespaces", false, "export the policies of every namespace")
+	withDefaults := fs.Bool("with-defaults", false, "keep limits the API server defaulted, instead of leaving them to be defaulted again on apply")
+	dir := fs.String("dir", "", "write each policy to <dir>/<namespace>/<name>.yaml instead of stdout")
+	fs.Parse(args)
+
+	if (*namespace == "") == !*all {
+		return fmt.Errorf("pass either -n or -all-namespaces")
+	}
+	c, err := newClients(*kubeconfig)
+	if err != nil {
+		return err
+	}
+	list, err := c.policies.PlatformV1beta1().ResourceQuotaPolicies(*namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list policies: %w", err)
+	}
+	policies := list.Items
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Namespace != policies[j].Namespace {
+			return policies[i].Namespace < policies[j].Namespace
+		}
+		return policies[i].Name < policies[j].Name
+	})
+
+	if *dir == "" {
+		return printExported(os.Stdout, policies, *withDefaults)
+	}
+	for i := range policies {
+		p := &policies[i]
+		out, err := exportPolicy(p, *withDefaults)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(*dir, p.Namespace, p.Name+".yaml")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, path)
+	}
+	return nil
+}
+
+// printExported writes policies as a multi-document YAML manifest.
+func printExported(w io.Writer, policies []v1beta1.ResourceQuotaPolicy, withDefaults bool) error {
+	docs := make([]string, 0, len(policies))
+	for i := range policies {
+		out, err := exportPolicy(&policies[i], withDefaults)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(out))
+	}
+	_, err := io.WriteString(w, strings.Join(docs, "---\n"))
+	return err
+}
+
+// exportPolicy renders policy as a manifest to apply again: its name, namespace, labels,
+// annotations and spec, without status or server-set metadata. Spec fields holding the values
+// the API server defaults them to are left out unless withDefaults is set.
+func exportPolicy(policy *v1beta1.ResourceQuotaPolicy, withDefaults bool) ([]byte, error) {
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policy.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if !withDefaults {
+		var defaults v1beta1.ResourceQuotaPolicySpec
+		v1beta1.SetDefaults_ResourceQuotaPolicySpec(&defaults)
+		defaulted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&defaults)
+		if err != nil {
+			return nil, err
+		}
+		for field, value := range defaulted {
+			if equality.Semantic.DeepEqual(spec[field], value) {
+				delete(spec, field)
+			}
+		}
+	}
+
+	metadata := map[string]any{"name": policy.Name, "namespace": policy.Namespace}
+	if len(policy.Labels) > 0 {
+		metadata["labels"] = policy.Labels
+	}
+	annotations := make(map[string]string)
+	for k, v := range policy.Annotations {
+		annotations[k] = v
+	}
+	for _, k := range droppedAnnotations {
+		delete(annotations, k)
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	return yaml.Marshal(map[string]any{
+		"apiVersion": v1beta1.SchemeGroupVersion.String(),
+		"kind":       "ResourceQuotaPolicy",
+		"metadata":   metadata,
+		"spec":       spec,
+	})
+}
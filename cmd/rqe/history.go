@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// historyRow is one recorded snapshot, measured against the limits in force at the time.
+type historyRow struct {
+	Time      time.Time     `json:"time"`
+	Namespace string        `json:"namespace"`
+	Policy    string        `json:"policy"`
+	Pods      resourceUsage `json:"pods"`
+	CPU       resourceUsage `json:"cpu"`
+	Memory    resourceUsage `json:"memory"`
+}
+
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	namespace := fs.String("n", "", "only show this namespace (default: all namespaces)")
+	window := fs.Duration("window", 24*time.Hour, "how far back to look, up to 168h")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	c, err := newClients(*kubeconfig)
+	if err != nil {
+		return err
+	}
+	list, err := c.policies.PlatformV1beta1().ResourceQuotaPolicies(*namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list policies: %w", err)
+	}
+
+	since := time.Now().Add(-*window)
+	var rows []historyRow
+	for _, item := range list.Items {
+		for _, snap := range item.Status.UsageSince(since) {
+			rows = append(rows, historyRowFor(&item, snap))
+		}
+	}
+	return printHistoryRows(os.Stdout, rows, *output)
+}
+
+func historyRowFor(item *v1beta1.ResourceQuotaPolicy, snap v1beta1.UsageSnapshot) historyRow {
+	spec, _ := item.Spec.EffectiveAt(snap.Time.Time)
+	policy := handlers.ParsePolicy(&spec)
+	return historyRow{
+		Time:      snap.Time.Time,
+		Namespace: item.Namespace,
+		Policy:    item.Name,
+		Pods: resourceUsage{
+			Used:        fmt.Sprint(snap.Pods),
+			Limit:       fmt.Sprint(policy.MaxPods),
+			Utilization: podUtilization(int(snap.Pods), policy.MaxPods),
+		},
+		CPU: resourceUsage{
+			Used:        snap.CPU.String(),
+			Limit:       policy.MaxCPU.String(),
+			Utilization: handlers.Utilization(snap.CPU, policy.MaxCPU),
+		},
+		Memory: resourceUsage{
+			Used:        snap.Memory.String(),
+			Limit:       policy.MaxMemory.String(),
+			Utilization: handlers.Utilization(snap.Memory, policy.MaxMemory),
+		},
+	}
+}
+
+func printHistoryRows(w io.Writer, rows []historyRow, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tNAMESPACE\tPOLICY\tPODS\tCPU\tMEMORY")
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Time.Local().Format("01-02 15:04"), r.Namespace, r.Policy, r.Pods, r.CPU, r.Memory)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
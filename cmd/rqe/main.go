@@ -0,0 +1,97 @@
+// Command rqe inspects and exercises resource quota policies from the command line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/client"
+	versioned "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+)
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"usage":      {"show quota consumption per namespace", runUsage},
+	"history":    {"show recorded usage over the last day or week", runHistory},
+	"simulate":   {"check whether a manifest would be admitted", runSimulate},
+	"replay":     {"re-run recorded admissions against a policy set and report changed decisions", runReplay},
+	"bench":      {"measure admission latency and allocations at a target concurrency", runBench},
+	"top":        {"live view of quota usage", runTop},
+	"convert":    {"render policies as Kyverno or Gatekeeper policies", runConvert},
+	"export":     {"dump policies as clean manifests for a GitOps repository", runExport},
+	"snapshot":   {"dump pods, requests, policies and usage for offline simulation or a support ticket", runSnapshot},
+	"dashboards": {"generate Grafana dashboards for the exported metrics", runDashboards},
+	"alerts":     {"generate Prometheus alerting rules for the exported metrics", runAlerts},
+}
+
+func main() {
+	// library packages log operational detail that isn't useful on a terminal
+	log.SetOutput(io.Discard)
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "rqe: unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "rqe %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: rqe <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", n, commands[n].summary)
+	}
+}
+
+// clients bundles the API clients used by subcommands.
+type clients struct {
+	kube     kubernetes.Interface
+	policies versioned.Interface
+}
+
+// kubeconfigFlag registers the --kubeconfig flag on fs.
+func kubeconfigFlag(fs *flag.FlagSet) *string {
+	return fs.String("kubeconfig", client.DefaultKubeconfig(), "(optional) kubeconfig file")
+}
+
+func newClients(kubeconfig string) (*clients, error) {
+	cfg, err := client.BuildConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	client.SetUserAgent(cfg, client.ComponentCLI)
+	kube, err := client.GetKubernetesClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create core client: %w", err)
+	}
+	policies, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create policy client: %w", err)
+	}
+	return &clients{kube: kube, policies: policies}, nil
+}
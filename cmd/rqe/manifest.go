@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// workload is a pod template together with how many pods it creates.
+type workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Replicas  int
+	Pod       corev1.Pod
+}
+
+// readWorkloads decodes every document in a YAML or JSON manifest ("-" for stdin) into the pods it
+// would create. Objects that don't create pods are skipped.
+func readWorkloads(path string) ([]workload, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var out []workload
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, gvk, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decode manifest: %w", err)
+		}
+		if w, ok := workloadFor(obj); ok {
+			w.Kind = gvk.Kind
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func workloadFor(obj interface{}) (workload, bool) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return workload{Name: o.Name, Namespace: o.Namespace, Replicas: 1, Pod: *o}, true
+	case *appsv1.Deployment:
+		return fromTemplate(o.ObjectMeta, o.Spec.Replicas, o.Spec.Template), true
+	case *appsv1.ReplicaSet:
+		return fromTemplate(o.ObjectMeta, o.Spec.Replicas, o.Spec.Template), true
+	case *appsv1.StatefulSet:
+		return fromTemplate(o.ObjectMeta, o.Spec.Replicas, o.Spec.Template), true
+	case *batchv1.Job:
+		return fromTemplate(o.ObjectMeta, o.Spec.Parallelism, o.Spec.Template), true
+	case *batchv1.CronJob:
+		return fromTemplate(o.ObjectMeta, o.Spec.JobTemplate.Spec.Parallelism, o.Spec.JobTemplate.Spec.Template), true
+	default:
+		return workload{}, false
+	}
+}
+
+func fromTemplate(meta metav1.ObjectMeta, replicas *int32, tmpl corev1.PodTemplateSpec) workload {
+	n := 1
+	if replicas != nil {
+		n = int(*replicas)
+	}
+	pod := corev1.Pod{ObjectMeta: *tmpl.ObjectMeta.DeepCopy(), Spec: *tmpl.Spec.DeepCopy()}
+	pod.Namespace = meta.Namespace
+	return workload{Name: meta.Name, Namespace: meta.Namespace, Replicas: n, Pod: pod}
+}
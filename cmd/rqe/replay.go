@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+	"github.com/sri2103/resource-quota-enforcer/pkg/replay"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var errChanged = errors.New("replayed decisions differ from the recorded ones")
+
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	auditFile := fs.String("audit-file", "", "recorded admissions: JSON lines of AdmissionReviews, or the API server's audit log (- for stdin)")
+	policies := fs.String("f", "", "ResourceQuotaPolicy manifests to replay against: a YAML or JSON file, or a directory of them")
+	merge := fs.String("policy-merge", string(v1beta1.MergeHighestPriorityOnly), "how the policies of a namespace with several combine, as the webhook's -policy-merge")
+	defaultAction := fs.String("default-action", webhook.DefaultActionAllow, "what happens to new pods in namespaces without a policy, as the webhook's -default-action")
+	output := fs.String("o", "table", "output format: table or json")
+	all := fs.Bool("all", false, "list every replayed admission, not only changed decisions")
+	fs.Parse(args)
+
+	if *auditFile == "" || *policies == "" {
+		return fmt.Errorf("-audit-file and -f are required")
+	}
+	// the webhook logs every denial it replays
+	if err := logging.Setup(logging.Options{Level: "error", Format: "text"}); err != nil {
+		return err
+	}
+	rp := &replay.Replayer{}
+	var err error
+	if rp.Merge, err = v1beta1.ParseMergeStrategy(*merge); err != nil {
+		return err
+	}
+	if rp.DefaultAction, err = webhook.ParseDefaultAction(*defaultAction); err != nil {
+		return err
+	}
+	if rp.Policies, err = readPolicies(*policies); err != nil {
+		return err
+	}
+	records, err := readRecords(*auditFile)
+	if err != nil {
+		return err
+	}
+
+	results, err := rp.Run(context.Background(), records)
+	if err != nil {
+		return err
+	}
+	changed := 0
+	shown := results[:0:0]
+	for _, r := range results {
+		if r.Changed() {
+			changed++
+		}
+		if *all || r.Changed() {
+			shown = append(shown, r)
+		}
+	}
+	if err := printReplay(os.Stdout, shown, *output); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d admissions replayed, %d decisions changed\n", len(results), changed)
+	if changed > 0 {
+		return errChanged
+	}
+	return nil
+}
+
+func readRecords(path string) ([]replay.Record, error) {
+	if path == "-" {
+		return replay.Read(bufio.NewReader(os.Stdin))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return replay.Read(bufio.NewReader(f))
+}
+
+// readPolicies reads every ResourceQuotaPolicy in path, a manifest of one or more documents or a
+// directory of .yaml, .yml and .json manifests. Each must name its namespace.
+func readPolicies(path string) ([]*v1beta1.ResourceQuotaPolicy, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+	}
+
+	var out []*v1beta1.ResourceQuotaPolicy
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+			policy, err := policyfile.Parse(doc, file)
+			if err != nil {
+				return nil, err
+			}
+			if policy.Name == "" {
+				policy.Name = policyfile.DefaultName
+			}
+			if policy.Namespace == "" {
+				return nil, fmt.Errorf("%s: policy %s has no metadata.namespace", file, policy.Name)
+			}
+			out = append(out, policy)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no ResourceQuotaPolicy in %s", path)
+	}
+	return out, nil
+}
+
+func printReplay(w io.Writer, results []replay.Result, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tPOD\tOPERATION\tRECORDED\tREPLAYED\tPOLICY\tREASON")
+		for _, r := range results {
+			recorded := r.Recorded
+			if recorded == "" {
+				recorded = "-"
+			}
+			replayed := r.Decision
+			if replayed == "" {
+				replayed = r.Replayed
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Namespace, r.Pod, r.Operation, recorded, replayed, r.Policy, r.Reason)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
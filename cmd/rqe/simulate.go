@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/snapshot"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// simulationResult is the admission outcome for one workload.
+type simulationResult struct {
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+	Replicas  int    `json:"replicas"`
+	Admitted  int    `json:"admitted"`
+	Allowed   bool   `json:"allowed"`
+	Policy    string `json:"policy,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// headroom is what a policy has left once every admitted pod is counted.
+type headroom struct {
+	Namespace string `json:"namespace"`
+	Policy    string `json:"policy"`
+	Pods      int    `json:"pods"`
+	CPU       string `json:"cpu"`
+	Memory    string `json:"memory"`
+}
+
+type simulation struct {
+	Results  []simulationResult `json:"results"`
+	Headroom []headroom         `json:"headroom"`
+	// Enforcement is what the controller would do in each namespace of a snapshot.
+	Enforcement []snapshot.Enforcement `json:"enforcement,omitempty"`
+}
+
+var (
+	errDenied      = errors.New("admission would be denied")
+	errWouldDelete = errors.New("the controller would delete pods")
+)
+
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	file := fs.String("f", "", "manifest to simulate (YAML or JSON, - for stdin)")
+	namespace := fs.String("n", "", "target namespace (default: the manifest's namespace, or default)")
+	snapshotFile := fs.String("snapshot", "", "simulate against a dump of the cluster instead of the cluster itself (YAML or JSON, - for stdin), and report the pods the controller would delete")
+	policies := fs.String("policies", "", "with -snapshot, ResourceQuotaPolicy manifests replacing the snapshot's policies of the namespaces they name: a file, or a directory of them")
+	merge := fs.String("policy-merge", string(v1beta1.MergeHighestPriorityOnly), "with -snapshot, how the policies of a namespace with several combine, as the controller's -policy-merge")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	if *file == "" && *snapshotFile == "" {
+		return fmt.Errorf("-f or -snapshot is required")
+	}
+	var workloads []workload
+	if *file != "" {
+		var err error
+		if workloads, err = readWorkloads(*file); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	var c *clients
+	var enforcement []snapshot.Enforcement
+	if *snapshotFile != "" {
+		strategy, err := v1beta1.ParseMergeStrategy(*merge)
+		if err != nil {
+			return err
+		}
+		snap, err := readSnapshot(*snapshotFile)
+		if err != nil {
+			return err
+		}
+		if *policies != "" {
+			replacements, err := readPolicies(*policies)
+			if err != nil {
+				return err
+			}
+			snap.ReplacePolicies(replacements)
+		}
+		// the enforcer logs every pod it would delete
+		if err := logging.Setup(logging.Options{Level: "error", Format: "text"}); err != nil {
+			return err
+		}
+		if enforcement, err = snapshot.Enforce(ctx, snap, snapshot.Options{Merge: strategy}); err != nil {
+			return err
+		}
+		c = &clients{}
+		c.kube, c.policies = snap.Clients()
+	} else {
+		if *policies != "" {
+			return fmt.Errorf("-policies needs -snapshot")
+		}
+		var err error
+		if c, err = newClients(*kubeconfig); err != nil {
+			return err
+		}
+	}
+
+	sim, err := simulate(ctx, c, workloads, *namespace)
+	if err != nil {
+		return err
+	}
+	sim.Enforcement = enforcement
+	if err := printSimulation(os.Stdout, sim, *output); err != nil {
+		return err
+	}
+	for _, r := range sim.Results {
+		if !r.Allowed {
+			return errDenied
+		}
+	}
+	for _, e := range sim.Enforcement {
+		if len(e.WouldDelete) > 0 {
+			return errWouldDelete
+		}
+	}
+	return nil
+}
+
+func readSnapshot(path string) (*snapshot.Snapshot, error) {
+	if path == "-" {
+		return snapshot.Read(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return snapshot.Read(f)
+}
+
+// simulate admits the workloads' pods one by one against a copy of each namespace's pods, so
+// later replicas see the usage of earlier ones. The cluster is only read.
+func simulate(ctx context.Context, c *clients, workloads []workload, namespace string) (*simulation, error) {
+	byNamespace := map[string][]workload{}
+	var order []string
+	for _, w := range workloads {
+		ns := namespace
+		if ns == "" {
+			ns = w.Namespace
+		}
+		if ns == "" {
+			ns = metav1.NamespaceDefault
+		}
+		if _, ok := byNamespace[ns]; !ok {
+			order = append(order, ns)
+		}
+		byNamespace[ns] = append(byNamespace[ns], w)
+	}
+
+	allocatable, err := capacity.List(ctx, c.kube)
+	if err != nil {
+		return nil, err
+	}
+	sim := &simulation{}
+	now := time.Now()
+	for _, ns := range order {
+		policies, err := c.policies.PlatformV1beta1().ResourceQuotaPolicies(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list policies in %s: %w", ns, err)
+		}
+		var objs []runtime.Object
+		err = handlers.EachPod(ctx, c.kube, ns, func(pod *corev1.Pod) error {
+			objs = append(objs, pod)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list pods in %s: %w", ns, err)
+		}
+		sandbox := fake.NewSimpleClientset(objs...)
+		server := &webhook.WebhookServer{Clientset: sandbox}
+
+		for _, w := range byNamespace[ns] {
+			res := simulationResult{Namespace: ns, Workload: w.Kind + "/" + w.Name, Replicas: w.Replicas, Allowed: true}
+			for i := 0; i < w.Replicas && res.Allowed; i++ {
+				pod := w.Pod.DeepCopy()
+				pod.Namespace = ns
+				pod.Name = fmt.Sprintf("%s-simulated-%d", w.Name, i)
+				for _, p := range policies.Items {
+					spec, _ := p.Spec.EffectiveAt(now)
+					spec.ResolveClusterShare(allocatable)
+					allowed, reason, err := server.EvaluatePod(ctx, pod, ns, &spec)
+					if err != nil {
+						return nil, fmt.Errorf("evaluate %s against %s/%s: %w", res.Workload, ns, p.Name, err)
+					}
+					if !allowed {
+						res.Allowed, res.Policy, res.Reason = false, p.Name, reason
+						break
+					}
+				}
+				if res.Allowed {
+					if _, err := sandbox.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+						return nil, err
+					}
+					res.Admitted++
+				}
+			}
+			sim.Results = append(sim.Results, res)
+		}
+
+		enforcer := &handlers.PodEnforcer{Client: sandbox}
+		for _, p := range policies.Items {
+			sim.Headroom = append(sim.Headroom, policyHeadroom(ctx, enforcer, ns, &p, now, allocatable))
+		}
+	}
+	return sim, nil
+}
+
+func policyHeadroom(ctx context.Context, enforcer *handlers.PodEnforcer, ns string, p *v1beta1.ResourceQuotaPolicy, now time.Time, allocatable corev1.ResourceList) headroom {
+	spec, _ := p.Spec.EffectiveAt(now)
+	spec.ResolveClusterShare(allocatable)
+	policy := handlers.ParsePolicy(&spec)
+	h := headroom{Namespace: ns, Policy: p.Name}
+	usage, err := enforcer.ComputeUsage(ctx, ns, policy)
+	if err != nil {
+		return h
+	}
+	cpu := policy.MaxCPU.DeepCopy()
+	cpu.Sub(resource.MustParse(usage.CurrentCPU))
+	mem := policy.MaxMemory.DeepCopy()
+	mem.Sub(resource.MustParse(usage.CurrentMemory))
+	h.Pods = policy.MaxPods - usage.CurrentPods
+	h.CPU = cpu.String()
+	h.Memory = mem.String()
+	return h
+}
+
+func printSimulation(w io.Writer, sim *simulation, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sim)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		// a snapshot simulated without workloads only has enforcement to show
+		if len(sim.Results) > 0 || sim.Enforcement == nil {
+			fmt.Fprintln(tw, "NAMESPACE\tWORKLOAD\tADMITTED\tDECISION\tPOLICY\tREASON")
+			for _, r := range sim.Results {
+				decision := "allowed"
+				if !r.Allowed {
+					decision = "denied"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\t%s\t%s\n", r.Namespace, r.Workload, r.Admitted, r.Replicas, decision, r.Policy, r.Reason)
+			}
+			fmt.Fprintln(tw)
+			fmt.Fprintln(tw, "NAMESPACE\tPOLICY\tPODS LEFT\tCPU LEFT\tMEMORY LEFT")
+			for _, h := range sim.Headroom {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", h.Namespace, h.Policy, h.Pods, h.CPU, h.Memory)
+			}
+		}
+		if sim.Enforcement != nil {
+			if len(sim.Results) > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintln(tw, "NAMESPACE\tPOLICY\tPODS\tCPU\tMEMORY\tWOULD DELETE\tREASON")
+			for _, e := range sim.Enforcement {
+				wouldDelete := strings.Join(e.WouldDelete, ",")
+				if wouldDelete == "" {
+					wouldDelete = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", e.Namespace, e.Policy, e.Pods, e.CPU, e.Memory, wouldDelete, e.Message)
+			}
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/snapshot"
+	"sigs.k8s.io/yaml"
+)
+
+func runSnapshot(args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	namespace := fs.String("n", "", "snapshot this namespace")
+	all := fs.Bool("all-namespaces", false, "snapshot every namespace")
+	merge := fs.String("policy-merge", string(v1beta1.MergeHighestPriorityOnly), "how the policies of a namespace with several combine in the usage summary, as the controller's -policy-merge")
+	output := fs.String("o", "yaml", "output format: yaml or json")
+	file := fs.String("out", "", "write the snapshot to this file instead of stdout")
+	fs.Parse(args)
+
+	if (*namespace == "") == !*all {
+		return fmt.Errorf("pass either -n or -all-namespaces")
+	}
+	if *output != "yaml" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	strategy, err := v1beta1.ParseMergeStrategy(*merge)
+	if err != nil {
+		return err
+	}
+	c, err := newClients(*kubeconfig)
+	if err != nil {
+		return err
+	}
+	// the enforcer logs every pod it would delete while the usage is summed up
+	if err := logging.Setup(logging.Options{Level: "error", Format: "text"}); err != nil {
+		return err
+	}
+	snap, err := snapshot.Take(context.Background(), c.kube, c.policies, *namespace, snapshot.Options{Merge: strategy})
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeSnapshot(w, snap, *output); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d namespaces, %d pods, %d policies\n", len(snap.Namespaces), len(snap.Pods), len(snap.Policies))
+	return nil
+}
+
+func writeSnapshot(w io.Writer, snap *snapshot.Snapshot, output string) error {
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	}
+	out, err := yaml.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runTop(args []string) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	namespace := fs.String("n", "", "only show this namespace (default: all namespaces)")
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	fs.Parse(args)
+
+	c, err := newClients(*kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		rows, err := collectUsage(ctx, c, *namespace)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// clear the screen and move the cursor home before redrawing
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("rqe top - %s (every %s, Ctrl-C to quit)\n\n", time.Now().Format(time.TimeOnly), *interval)
+		if err != nil {
+			fmt.Fprintf(os.Stdout, "error: %v\n", err)
+		} else if err := printUsageRows(os.Stdout, rows, "table"); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// usageRow is one policy's limits and consumption.
+type usageRow struct {
+	Namespace string        `json:"namespace"`
+	Policy    string        `json:"policy"`
+	Pods      resourceUsage `json:"pods"`
+	CPU       resourceUsage `json:"cpu"`
+	Memory    resourceUsage `json:"memory"`
+	Violation bool          `json:"violation"`
+	Message   string        `json:"message,omitempty"`
+}
+
+type resourceUsage struct {
+	Used        string  `json:"used"`
+	Limit       string  `json:"limit"`
+	Utilization float64 `json:"utilization"`
+}
+
+func runUsage(args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	kubeconfig := kubeconfigFlag(fs)
+	namespace := fs.String("n", "", "only show this namespace (default: all namespaces)")
+	output := fs.String("o", "table", "output format: table or json")
+	fs.Parse(args)
+
+	c, err := newClients(*kubeconfig)
+	if err != nil {
+		return err
+	}
+	rows, err := collectUsage(context.Background(), c, *namespace)
+	if err != nil {
+		return err
+	}
+	return printUsageRows(os.Stdout, rows, *output)
+}
+
+// collectUsage computes usage for every policy in namespace ("" for all namespaces) the same
+// way the controller does.
+func collectUsage(ctx context.Context, c *clients, namespace string) ([]usageRow, error) {
+	list, err := c.policies.PlatformV1beta1().ResourceQuotaPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+
+	allocatable, err := capacity.List(ctx, c.kube)
+	if err != nil {
+		return nil, err
+	}
+
+	enforcer := &handlers.PodEnforcer{Client: c.kube}
+	now := time.Now()
+	rows := make([]usageRow, 0, len(list.Items))
+	for _, item := range list.Items {
+		spec, _ := item.Spec.EffectiveAt(now)
+		spec.ResolveClusterShare(allocatable)
+		policy := handlers.ParsePolicy(&spec)
+		res, err := enforcer.ComputeUsage(ctx, item.Namespace, policy)
+		if err != nil {
+			return nil, fmt.Errorf("compute usage for %s: %w", item.Namespace, err)
+		}
+		cpu, _ := resource.ParseQuantity(res.CurrentCPU)
+		mem, _ := resource.ParseQuantity(res.CurrentMemory)
+		rows = append(rows, usageRow{
+			Namespace: item.Namespace,
+			Policy:    item.Name,
+			Pods: resourceUsage{
+				Used:        fmt.Sprint(res.CurrentPods),
+				Limit:       fmt.Sprint(policy.MaxPods),
+				Utilization: podUtilization(res.CurrentPods, policy.MaxPods),
+			},
+			CPU: resourceUsage{
+				Used:        cpu.String(),
+				Limit:       policy.MaxCPU.String(),
+				Utilization: handlers.Utilization(cpu, policy.MaxCPU),
+			},
+			Memory: resourceUsage{
+				Used:        mem.String(),
+				Limit:       policy.MaxMemory.String(),
+				Utilization: handlers.Utilization(mem, policy.MaxMemory),
+			},
+			Violation: res.Violation,
+			Message:   res.Message,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return rows[i].Policy < rows[j].Policy
+	})
+	return rows, nil
+}
+
+func podUtilization(used, limit int) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used) / float64(limit)
+}
+
+func printUsageRows(w io.Writer, rows []usageRow, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tPOLICY\tPODS\tCPU\tMEMORY\tVIOLATION")
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%v\n", r.Namespace, r.Policy, r.Pods, r.CPU, r.Memory, r.Violation)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
+
+// String renders "used/limit (pct%)" for table output.
+func (u resourceUsage) String() string {
+	return fmt.Sprintf("%s/%s (%.0f%%)", u.Used, u.Limit, u.Utilization*100)
+}
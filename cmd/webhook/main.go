@@ -1,104 +1,352 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
 	"github.com/sri2103/resource-quota-enforcer/pkg/client"
+	rqeconfig "github.com/sri2103/resource-quota-enforcer/pkg/config"
+	"github.com/sri2103/resource-quota-enforcer/pkg/dashboard"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
 	clientset "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	policyinformers "github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/health"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	"github.com/sri2103/resource-quota-enforcer/pkg/manager"
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyreport"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
 	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
 )
 
 func main() {
 	var tlsCertFile string
 	var tlsKeyFile string
+	var tlsOpts webhook.TLSOptions
 	var listenAddr string
 	var resync time.Duration
+	var certExpiryWindow time.Duration
+	var metricsAddr string
+	var enablePprof bool
+	var enableDebug bool
+	var dashboardAddr string
+	var enableDRA bool
+	var clusterCapacity bool
+	var policyReports bool
+	var admissionTimeout time.Duration
+	var denyOnTimeout bool
+	var memoTTL time.Duration
+	var maxInFlight int
+	var maxQueued int
+	var maxQueueWait time.Duration
+	var overloadPolicy string
+	var defaultAction string
+	var defaultPolicyFile string
+	var policyMerge string
+	var auditCfg audit.Config
+	var sourceCfg policysource.Config
+	var maintenanceCfg maintenance.Config
+	var traceCfg tracing.Config
+	var logOpts logging.Options
+	var exclude handlers.Exclusions
+	var rateLimits client.RateLimits
+	var exempt handlers.ExemptNamespaces
+	var configOpts rqeconfig.Options
 
 	flag.StringVar(&tlsCertFile, "tls-cert-file", "./certs/server.crt", "Path to TLS certificate")
 	flag.StringVar(&tlsKeyFile, "tls-key-file", "./certs/server.key", "Path to TLS private key")
 	flag.StringVar(&listenAddr, "listen", ":8443", "Webhook server listen address")
 	flag.DurationVar(&resync, "resync", 30*time.Second, "Informer resync period")
+	flag.DurationVar(&certExpiryWindow, "cert-expiry-window", 24*time.Hour, "Report not ready once the serving certificate expires within this window")
+	flag.BoolVar(&enablePprof, "enable-pprof", false, "Serve net/http/pprof under /debug/pprof/ next to /metrics")
+	flag.BoolVar(&enableDebug, "enable-debug-endpoints", false, "Serve /debug/cache and /debug/config on the webhook listener, authorized through the API server")
+	flag.StringVar(&dashboardAddr, "dashboard-addr", "", "Plain HTTP address to serve the read-only web dashboard of recent denials on, e.g. :8090; it has no authentication (disabled when empty)")
+	flag.DurationVar(&admissionTimeout, "admission-timeout", webhook.DefaultTimeout, "The webhook's timeoutSeconds; admissions are answered slightly before it unless the API server sends its own timeout")
+	flag.BoolVar(&denyOnTimeout, "deny-on-timeout", false, "Deny pods whose quota check runs out of time instead of allowing them with a warning")
+	flag.DurationVar(&memoTTL, "admission-memo-ttl", 0, "Reuse the denial of a pod for identical pods of the same controller, for up to this long while no pod of the namespace changes; watches the metadata of every pod (disabled when 0)")
+	flag.IntVar(&maxInFlight, "max-in-flight", 0, "Most admissions evaluated at once; beyond that and -max-queued, admissions are answered according to -overload-policy without being evaluated (unlimited when 0)")
+	flag.IntVar(&maxQueued, "max-queued", 100, "Most admissions waiting for one of -max-in-flight to finish")
+	flag.DurationVar(&maxQueueWait, "max-queue-wait", time.Second, "How long a queued admission waits at most, never more than half the admission timeout")
+	flag.StringVar(&overloadPolicy, "overload-policy", webhook.OverloadAllow, "What happens to admissions shed while the webhook is overloaded: allow with a warning, or deny so the client retries")
+	flag.StringVar(&defaultAction, "default-action", webhook.DefaultActionAllow, "What happens to new pods in namespaces without a ResourceQuotaPolicy: allow, or deny unless the namespace is annotated quota.platform/default-action=allow")
+	flag.StringVar(&defaultPolicyFile, "default-policy", "", "ResourceQuotaPolicy manifest applied to namespaces without a policy of their own, unless annotated quota.platform/default-action=allow")
+	flag.StringVar(&policyMerge, "policy-merge", string(platformv1beta1.MergeHighestPriorityOnly), "How the policies of a namespace with several combine: highestPriorityOnly, strictestWins or sumAllowances; must match the controller")
+	flag.BoolVar(&policyReports, "policy-reports", false, "Add denied pods to a wgpolicyk8s.io PolicyReport in their namespace")
+	flag.BoolVar(&enableDRA, "enable-dra", false, "Count devices claimed through DRA resourceClaims against spec.maxExtendedResources")
+	flag.BoolVar(&clusterCapacity, "cluster-capacity", true, "Watch nodes to resolve spec.clusterShare of policies against the cluster's allocatable resources")
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8081", "Plain HTTP address to serve /metrics on; empty serves it on the webhook listener instead")
+	tlsOpts.AddFlags(flag.CommandLine)
+	auditCfg.AddFlags(flag.CommandLine)
+	sourceCfg.AddFlags(flag.CommandLine)
+	maintenanceCfg.AddFlags(flag.CommandLine)
+	traceCfg.AddFlags(flag.CommandLine)
+	logOpts.AddFlags(flag.CommandLine)
+	exclude.AddFlags(flag.CommandLine)
+	rateLimits.AddFlags(flag.CommandLine)
+	exempt.AddFlags(flag.CommandLine)
+	configOpts.AddFlags(flag.CommandLine)
+	features.DefaultGate.AddFlags(flag.CommandLine)
 	flag.Parse()
+	var configFile *rqeconfig.File
+	if configOpts.Path != "" {
+		configFile = rqeconfig.New(flag.CommandLine, configOpts.Path, rqeconfig.SectionWebhook)
+		exitOnErr(configFile.Load(), "Failed to load config file")
+	}
+	exitOnErr(logging.Setup(logOpts), "Failed to set up logging")
+	logger := logging.L().WithName("main")
 
 	cfg, err := client.PrepareConfig()
-	if err != nil {
-		log.Fatalf("[Main] ❌ Failed to build kubeconfig: %v", err)
-	}
+	exitOnErr(err, "Failed to build kubeconfig")
+	rateLimits.Apply(cfg)
+	client.SetUserAgent(cfg, client.ComponentWebhook)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "rqe-webhook", traceCfg)
+	exitOnErr(err, "Failed to set up tracing")
+	defer shutdownTracing(context.Background())
+	tracing.WrapConfig(cfg)
 
 	cs, err := client.GetKubernetesClient(cfg)
-	if err != nil {
-		log.Fatalf("[Main] ❌ Failed to create core clientset: %v", err)
-	}
+	exitOnErr(err, "Failed to create core clientset")
 
 	typedClient, err := clientset.NewForConfig(cfg)
-	if err != nil {
-		log.Fatalf("[Main] ❌ Failed to create typed clientset: %v", err)
-	}
+	exitOnErr(err, "Failed to create typed clientset")
+
+	// every replica admits, so the webhook's servers run without leader election
+	mgr, err := manager.New(cfg, manager.Config{})
+	exitOnErr(err, "Failed to set up manager")
+	add := func(r manager.Runnable) { exitOnErr(mgr.Add(r), "Failed to set up manager") }
 
 	webhook.InitMetrics()
+	if configFile != nil {
+		metrics.InitConfigMetrics()
+		configFile.Live("exempt-namespaces", nil)
+		configCtx, stopConfig := context.WithCancel(context.Background())
+		defer stopConfig()
+		go configFile.Run(configCtx, configOpts.Interval)
+	}
 
 	// Create informer-based cache
-	policyCache := webhook.NewTypedPolicyCache(typedClient, resync)
+	policyCache := policycache.NewInformer(typedClient, resync)
+	policyCache.Merge, err = platformv1beta1.ParseMergeStrategy(policyMerge)
+	exitOnErr(err, "Invalid -policy-merge")
+	policyCache.Source, err = policysource.NewFromConfig(sourceCfg, cs, resync)
+	exitOnErr(err, "Failed to set up policy sources")
+	if clusterCapacity {
+		policyCache.Capacity, err = capacity.NewTracker(cs, resync)
+		exitOnErr(err, "Failed to watch nodes")
+	}
+	if policyCache.Source != nil {
+		installed, err := client.PolicyCRDInstalled(cs.Discovery())
+		exitOnErr(err, "Failed to look up the ResourceQuotaPolicy CRD")
+		policyCache.WithoutCRD = !installed
+	}
 
 	// Start informer factory
 	stopCh := make(chan struct{})
+	if policyCache.Source != nil {
+		sourceCtx, stopSource := context.WithCancel(context.Background())
+		defer stopSource()
+		go policyCache.Source.Run(sourceCtx)
+	}
+	if policyCache.Capacity != nil {
+		capacityCtx, stopCapacity := context.WithCancel(context.Background())
+		defer stopCapacity()
+		go policyCache.Capacity.Run(capacityCtx)
+	}
+	maintenanceMode, err := maintenance.NewFromConfig(maintenanceCfg, cs, resync)
+	exitOnErr(err, "Failed to set up maintenance mode")
+	if maintenanceMode != nil {
+		metrics.InitMaintenanceMetrics()
+		maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+		defer stopMaintenance()
+		go maintenanceMode.Run(maintenanceCtx)
+	}
 	go policyCache.Run(stopCh)
 
+	// QuotaExemptions are optional, so a cluster without their CRD is admitted without them
+	exemptionsInstalled, err := client.ExemptionCRDInstalled(cs.Discovery())
+	exitOnErr(err, "Failed to look up the QuotaExemption CRD")
+	exemptionFactory := policyinformers.NewSharedInformerFactory(typedClient, resync)
+	if exemptionsInstalled {
+		exemptionFactory.Platform().V1beta1().QuotaExemptions().Informer()
+		exemptionFactory.Start(stopCh)
+	}
+
 	// Wait for cache sync
 	if err := policyCache.WaitForReady(30 * time.Second); err != nil {
-		log.Printf("[Main] ⚠️ Policy cache not ready in time: %v (continuing; cache misses possible)", err)
+		logger.Error(err, "Policy cache not ready in time, continuing; cache misses possible")
 	} else {
-		log.Println("[Main] ✅ Policy cache ready")
+		logger.Info("Policy cache ready")
+	}
+	if exemptionsInstalled {
+		syncCtx, cancelSync := context.WithTimeout(context.Background(), 30*time.Second)
+		for _, synced := range exemptionFactory.WaitForCacheSync(syncCtx.Done()) {
+			if !synced {
+				logger.Error(nil, "QuotaExemption cache not ready in time, continuing; exempt pods may be counted")
+			}
+		}
+		cancelSync()
 	}
 
 	// Create webhook server
 	server := webhook.NewWebhookServerWithInformer(cs, policyCache)
+	server.Capacity = policyCache.Capacity
+	server.Exclude = exclude
+	server.Exempt = &exempt
+	server.Maintenance = maintenanceMode
+	if exemptionsInstalled {
+		server.Exemptions = exemptionFactory.Platform().V1beta1().QuotaExemptions().Lister()
+	}
+	if memoTTL > 0 {
+		metadataClient, err := client.MetadataClient(cfg)
+		exitOnErr(err, "Failed to create metadata client")
+		server.Memo, err = webhook.NewMemo(metadataClient, resync, memoTTL)
+		exitOnErr(err, "Failed to set up admission memo")
+		memoCtx, stopMemo := context.WithCancel(context.Background())
+		defer stopMemo()
+		go server.Memo.Run(memoCtx)
+	}
+	server.Timeout = admissionTimeout
+	server.DenyOnTimeout = denyOnTimeout
+	server.DefaultAction, err = webhook.ParseDefaultAction(defaultAction)
+	exitOnErr(err, "Invalid -default-action")
+	server.OverloadPolicy, err = webhook.ParseOverloadPolicy(overloadPolicy)
+	exitOnErr(err, "Invalid -overload-policy")
+	if maxInFlight > 0 {
+		server.Limiter = webhook.NewLimiter(maxInFlight, maxQueued, maxQueueWait)
+	}
+	if defaultPolicyFile != "" {
+		server.DefaultPolicy, err = policyfile.Load(defaultPolicyFile)
+		exitOnErr(err, "Failed to load default policy")
+	}
+	if enableDRA {
+		server.Devices = &handlers.DeviceResolver{Client: cs}
+	}
+	server.Audit, err = audit.NewFromConfig(auditCfg)
+	exitOnErr(err, "Failed to open audit log")
+	if policyReports {
+		dynamicClient, err := client.DynamicClient(cfg)
+		exitOnErr(err, "Failed to create dynamic client")
+		if server.Audit == nil {
+			server.Audit = audit.New(nil)
+		}
+		server.Audit.AddExporter(policyreport.NewExporter(dynamicClient), auditCfg.Batch)
+	}
+	if dashboardAddr != "" {
+		if server.Audit == nil {
+			server.Audit = audit.New(nil)
+		}
+		recent := dashboard.NewRecent(dashboard.DefaultRecent)
+		server.Audit.AddExporter(recent, auditCfg.Batch)
+		h := &dashboard.Handler{Title: "Resource Quota Enforcer webhook", Recent: recent}
+		add(manager.Always(func(ctx context.Context) error {
+			return dashboard.ListenAndServe(ctx, dashboardAddr, h)
+		}))
+	}
+	defer server.Audit.Close()
 
 	// Routes
 	mux := http.NewServeMux()
-	mux.HandleFunc("/validate", server.HandleValidatePods)
-	mux.HandleFunc("/mutate", server.InvalidateHandler)
+	mux.HandleFunc("/validate", server.WarnDuringMaintenance(server.LimitConcurrency(server.HandleValidatePods)))
+	// flushing the cache is an admin action, authorized through the API server like the debug
+	// endpoints
+	mux.Handle("/invalidate", health.RequireAPIServerAuth(cs, http.HandlerFunc(server.InvalidateHandler)))
+	mux.HandleFunc("/mutate-pod", server.HandleMutatePod)
+	mux.HandleFunc("/convert", server.HandleConvert)
+	mux.HandleFunc("/validate-policy", server.HandleValidatePolicy)
+	mux.HandleFunc("/validate-scale", server.WarnDuringMaintenance(server.LimitConcurrency(server.HandleValidateScale)))
+	mux.HandleFunc("/validate-workload", server.WarnDuringMaintenance(server.LimitConcurrency(server.HandleValidateWorkload)))
+	mux.HandleFunc("/validate-batch", server.WarnDuringMaintenance(server.LimitConcurrency(server.HandleValidateBatch)))
+	mux.HandleFunc("/validate-service", server.WarnDuringMaintenance(server.LimitConcurrency(server.HandleValidateService)))
+	mux.HandleFunc("/mutate-policy", server.HandleMutatePolicy)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
-	mux.Handle("/metrics", webhook.MetricsHandler())
 
 	// TLS setup
 	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
-	if err != nil {
-		log.Fatalf("[Main] ❌ Failed to load cert/key: %v", err)
+	exitOnErr(err, "Failed to load cert/key")
+	leaf := cert.Leaf
+	if leaf == nil {
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		exitOnErr(err, "Failed to parse serving certificate")
 	}
+	webhook.SetServingCert(leaf)
 	tlsCfg := &tls.Config{
 		Certificates: []tls.Certificate{cert},
-		MinVersion:   tls.VersionTLS12,
+	}
+	exitOnErr(tlsOpts.Apply(tlsCfg), "Invalid TLS options")
+
+	// only route admissions here once this replica can actually decide them
+	mux.HandleFunc("/readyz", health.ChecksHandler(
+		health.NamedCheck{Name: "policy-cache", Check: policyCache.Check},
+		health.NamedCheck{Name: "serving-cert", Check: health.CertExpiryCheck(leaf, certExpiryWindow)},
+		health.NamedCheck{Name: "apiserver", Check: health.APIServerCheck(cs)},
+	))
+	if enableDebug {
+		// kept on the TLS listener so bearer tokens are never sent in the clear
+		health.RegisterDebug(mux, cs, func(ns string) any { return policyCache.Dump(ns) })
+	}
+	if metricsAddr == "" {
+		mux.Handle("/metrics", webhook.MetricsHandler())
+		if enablePprof {
+			health.RegisterPprof(mux)
+		}
+	} else {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", webhook.MetricsHandler())
+		if enablePprof {
+			health.RegisterPprof(metricsMux)
+		}
+		metricsSrv := &http.Server{Addr: metricsAddr, Handler: metricsMux}
+		logger.Info("Serving metrics", "addr", metricsAddr)
+		add(manager.Server(metricsSrv, metricsSrv.ListenAndServe))
 	}
 
+	handler := http.Handler(mux)
+	if tlsOpts.ClientCAFile != "" {
+		// probes and scrapes come without a client certificate; the debug and invalidation
+		// endpoints authorize their callers through the API server. pprof doesn't, so it keeps
+		// requiring a certificate.
+		handler = webhook.RequireClientCert(mux, "/healthz", "/readyz", "/metrics", "/debug/cache", "/debug/config", "/invalidate")
+	}
 	srv := &http.Server{
 		Addr:      listenAddr,
-		Handler:   mux,
+		Handler:   handler,
 		TLSConfig: tlsCfg,
 	}
 
-	// Graceful shutdown
-	sigCh := make(chan os.Signal, 2)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		log.Printf("[Main] 🚀 Starting webhook server on %s", listenAddr)
-		if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("[Main] ❌ Webhook server failed: %v", err)
-		}
-	}()
+	logger.Info("Starting webhook server", "addr", listenAddr, "featureGates", features.DefaultGate.String())
+	add(manager.Server(srv, func() error { return srv.ListenAndServeTLS("", "") }))
 
-	<-sigCh
-	log.Println("[Main] 📴 Shutting down webhook server")
+	// Graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	err = mgr.Start(ctx)
+	logger.Info("Shutting down webhook server")
 	close(stopCh)
-	_ = srv.Close()
+	exitOnErr(err, "Webhook server failed")
+}
+
+// exitOnErr logs err and exits when it is non-nil.
+func exitOnErr(err error, msg string) {
+	if err != nil {
+		logging.L().WithName("main").Error(err, msg)
+		os.Exit(1)
+	}
 }
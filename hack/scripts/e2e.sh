@@ -0,0 +1,38 @@
+#!/usr/bin/env bash
+# Runs the end-to-end suite in test/e2e against a kind cluster: builds the controller and webhook
+# images, loads them into the cluster and runs the tests, which deploy both.
+#
+#   KIND_CLUSTER   name of the kind cluster, created unless it exists (default rqe-e2e)
+#   KEEP_CLUSTER   leave the cluster running afterwards when set to 1
+set -euo pipefail
+
+cd "$(dirname "${BASH_SOURCE[0]}")/../.."
+
+KIND_CLUSTER=${KIND_CLUSTER:-rqe-e2e}
+CONTROLLER_IMAGE=rqe-controller:e2e
+WEBHOOK_IMAGE=rqe-webhook:e2e
+
+KUBECONFIG_FILE=$(mktemp)
+created=0
+cleanup() {
+  rm -f "${KUBECONFIG_FILE}"
+  if [[ "${created}" == 1 && "${KEEP_CLUSTER:-0}" != 1 ]]; then
+    kind delete cluster --name "${KIND_CLUSTER}"
+  fi
+}
+trap cleanup EXIT
+
+if ! kind get clusters | grep -qx "${KIND_CLUSTER}"; then
+  created=1
+  kind create cluster --name "${KIND_CLUSTER}" --wait 2m
+fi
+kind get kubeconfig --name "${KIND_CLUSTER}" > "${KUBECONFIG_FILE}"
+
+docker build -f controller.Dockerfile -t "${CONTROLLER_IMAGE}" .
+docker build -f controller.Dockerfile --build-arg CMD=./cmd/webhook -t "${WEBHOOK_IMAGE}" .
+kind load docker-image --name "${KIND_CLUSTER}" "${CONTROLLER_IMAGE}" "${WEBHOOK_IMAGE}"
+
+RQE_E2E_KUBECONFIG="${KUBECONFIG_FILE}" \
+RQE_E2E_CONTROLLER_IMAGE="${CONTROLLER_IMAGE}" \
+RQE_E2E_WEBHOOK_IMAGE="${WEBHOOK_IMAGE}" \
+  go test -tags e2e -count 1 -timeout 15m -v ./test/e2e/ "$@"
@@ -0,0 +1,19 @@
+#!/usr/bin/env bash
+set -o errexit
+set -o nounset
+set -o pipefail
+
+# Regenerates the gRPC API stubs. Needs protoc plus:
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.8
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+
+SCRIPT_ROOT=$(dirname "${BASH_SOURCE[0]}")/../..
+PROTO_DIR=${SCRIPT_ROOT}/pkg/apiserver/quotapb
+
+protoc \
+  --proto_path="${PROTO_DIR}" \
+  --go_out="${PROTO_DIR}" --go_opt=paths=source_relative \
+  --go-grpc_out="${PROTO_DIR}" --go-grpc_opt=paths=source_relative \
+  "${PROTO_DIR}/quota.proto"
+
+echo "✅ Proto generation complete"
@@ -0,0 +1,148 @@
+// Package alerting generates Prometheus alerting rules for the metrics of the controller and the
+// webhook. Expressions are built from the metric names in pkg/metrics and pkg/webhook, so a
+// renamed metric changes the rules with it.
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+// Options customizes the generated rules.
+type Options struct {
+	// Name of the PrometheusRule; empty uses "resource-quota-enforcer".
+	Name string
+	// Namespace of the PrometheusRule; empty leaves it to kubectl.
+	Namespace string
+	// Labels are set on the PrometheusRule, e.g. the ones the Prometheus Operator's
+	// ruleSelector matches.
+	Labels map[string]string
+
+	// QuotaThreshold is the utilization, as a fraction of the limit, above which a namespace
+	// is near its quota; zero uses 0.9.
+	QuotaThreshold float64
+	// ErrorRatio is the fraction of admission requests failing with an error above which the
+	// webhook alerts; zero uses 0.05.
+	ErrorRatio float64
+	// CertExpiry is how long before the serving certificate expires the webhook alerts; zero
+	// uses 7 days.
+	CertExpiry time.Duration
+}
+
+// Rule is a Prometheus alerting rule.
+type Rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Group is a named group of rules evaluated together.
+type Group struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Groups returns the alerting rules: quota near or over its limit, reconcile failures, admission
+// errors, serving certificate expiry and config files that fail to reload.
+func Groups(opts Options) []Group {
+	if opts.QuotaThreshold == 0 {
+		opts.QuotaThreshold = 0.9
+	}
+	if opts.ErrorRatio == 0 {
+		opts.ErrorRatio = 0.05
+	}
+	if opts.CertExpiry == 0 {
+		opts.CertExpiry = 7 * 24 * time.Hour
+	}
+	rule := func(alert, expr, forDuration, severity, summary, description string) Rule {
+		return Rule{
+			Alert:  alert,
+			Expr:   expr,
+			For:    forDuration,
+			Labels: map[string]string{"severity": severity},
+			Annotations: map[string]string{
+				"summary":     summary,
+				"description": description,
+			},
+		}
+	}
+	return []Group{
+		{Name: "resource-quota-enforcer.quota", Rules: []Rule{
+			rule("ResourceQuotaNearLimit",
+				fmt.Sprintf("max by (namespace, resource) (%s) > %g", metrics.NameQuotaUtilization, opts.QuotaThreshold),
+				"15m", "warning",
+				"Namespace {{ $labels.namespace }} is near its {{ $labels.resource }} quota",
+				fmt.Sprintf("{{ $labels.namespace }} uses {{ $value | humanizePercentage }} of its {{ $labels.resource }} limit, above %g%%; new pods will soon be denied.", opts.QuotaThreshold*100)),
+			rule("ResourceQuotaExceeded",
+				fmt.Sprintf("max by (namespace, resource) (%s) > 1", metrics.NameQuotaUtilization),
+				"5m", "critical",
+				"Namespace {{ $labels.namespace }} is over its {{ $labels.resource }} quota",
+				"{{ $labels.namespace }} uses {{ $value | humanizePercentage }} of its {{ $labels.resource }} limit; pods are denied or evicted depending on the policy."),
+		}},
+		{Name: "resource-quota-enforcer.controller", Rules: []Rule{
+			rule("ResourceQuotaReconcileFailing",
+				fmt.Sprintf("sum by (namespace) (rate(%s[10m])) > 0", metrics.NameReconcileErrors),
+				"15m", "warning",
+				"Reconciling namespace {{ $labels.namespace }} keeps failing",
+				"The controller has failed to sync {{ $labels.namespace }} for 15 minutes; its usage and enforcement are stale. Check the controller logs."),
+		}},
+		{Name: "resource-quota-enforcer.webhook", Rules: []Rule{
+			rule("ResourceQuotaWebhookErrors",
+				fmt.Sprintf(`sum(rate(%[1]s_count{result="error"}[5m])) / sum(rate(%[1]s_count[5m])) > %g`, webhook.NameAdmissionDuration, opts.ErrorRatio),
+				"10m", "critical",
+				"The quota webhook fails to decide admission requests",
+				fmt.Sprintf("{{ $value | humanizePercentage }} of admission requests end in an error, above %g%%; those pods are admitted without a quota check.", opts.ErrorRatio*100)),
+			rule("ResourceQuotaWebhookCertExpiring",
+				fmt.Sprintf("%s - time() < %d", webhook.NameServingCertExpiry, int64(opts.CertExpiry.Seconds())),
+				"", "warning",
+				"The quota webhook's serving certificate expires soon",
+				"The certificate of {{ $labels.instance }} expires in {{ $value | humanizeDuration }}; the API server will fail every admission call once it does."),
+			rule("ResourceQuotaWebhookCertExpired",
+				fmt.Sprintf("%s - time() <= 0", webhook.NameServingCertExpiry),
+				"", "critical",
+				"The quota webhook's serving certificate has expired",
+				"The certificate of {{ $labels.instance }} has expired; admission calls fail TLS verification."),
+		}},
+		{Name: "resource-quota-enforcer.config", Rules: []Rule{
+			rule("ResourceQuotaConfigReloadFailing",
+				fmt.Sprintf("%s == 0", metrics.NameConfigLastReloadSuccessful),
+				"10m", "warning",
+				"{{ $labels.instance }} fails to reload its config file",
+				"The last reload of the -config file of {{ $labels.instance }} failed; it keeps running with the values last applied. Check its logs."),
+		}},
+		{Name: "resource-quota-enforcer.maintenance", Rules: []Rule{
+			rule("ResourceQuotaMaintenanceModeActive",
+				fmt.Sprintf("max(%s) == 1", metrics.NameMaintenanceActive),
+				"6h", "warning",
+				"Quota enforcement has been paused for maintenance for hours",
+				"Maintenance mode has been on for 6 hours; pods over quota are neither deleted nor denied. Remove the annotation from the maintenance ConfigMap once the maintenance is over."),
+		}},
+	}
+}
+
+// PrometheusRule returns the rules as a monitoring.coreos.com/v1 PrometheusRule for the
+// Prometheus Operator.
+func PrometheusRule(opts Options) map[string]any {
+	name := opts.Name
+	if name == "" {
+		name = "resource-quota-enforcer"
+	}
+	metadata := map[string]any{"name": name}
+	if opts.Namespace != "" {
+		metadata["namespace"] = opts.Namespace
+	}
+	if len(opts.Labels) > 0 {
+		metadata["labels"] = opts.Labels
+	}
+	return map[string]any{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata":   metadata,
+		"spec":       map[string]any{"groups": Groups(opts)},
+	}
+}
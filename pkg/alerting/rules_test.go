@@ -0,0 +1,52 @@
+package alerting
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+func TestGroups(t *testing.T) {
+	exprs := make(map[string]string)
+	for _, g := range Groups(Options{QuotaThreshold: 0.8, CertExpiry: 48 * time.Hour}) {
+		for _, r := range g.Rules {
+			if _, dup := exprs[r.Alert]; dup {
+				t.Errorf("alert %s defined twice", r.Alert)
+			}
+			if r.Labels["severity"] == "" || r.Annotations["summary"] == "" {
+				t.Errorf("alert %s lacks a severity or summary", r.Alert)
+			}
+			exprs[r.Alert] = r.Expr
+		}
+	}
+
+	for alert, want := range map[string]string{
+		"ResourceQuotaNearLimit":             metrics.NameQuotaUtilization + ") > 0.8",
+		"ResourceQuotaReconcileFailing":      metrics.NameReconcileErrors,
+		"ResourceQuotaWebhookErrors":         webhook.NameAdmissionDuration + `_count{result="error"}`,
+		"ResourceQuotaWebhookCertExpiring":   webhook.NameServingCertExpiry + " - time() < 172800",
+		"ResourceQuotaConfigReloadFailing":   metrics.NameConfigLastReloadSuccessful + " == 0",
+		"ResourceQuotaMaintenanceModeActive": metrics.NameMaintenanceActive + ") == 1",
+	} {
+		if !strings.Contains(exprs[alert], want) {
+			t.Errorf("%s expr = %q, want it to contain %q", alert, exprs[alert], want)
+		}
+	}
+}
+
+func TestPrometheusRule(t *testing.T) {
+	out, err := yaml.Marshal(PrometheusRule(Options{Namespace: "monitoring", Labels: map[string]string{"release": "prometheus"}}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{"kind: PrometheusRule", "name: resource-quota-enforcer", "namespace: monitoring", "release: prometheus", "alert: ResourceQuotaExceeded"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("manifest lacks %q:\n%s", want, out)
+		}
+	}
+}
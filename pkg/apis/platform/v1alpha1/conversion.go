@@ -0,0 +1,173 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// HubSpecAnnotation keeps the full v1beta1 spec on v1alpha1 objects so fields that only exist
+// in v1beta1 survive a round trip through v1alpha1 clients.
+const HubSpecAnnotation = "platform.example.com/v1beta1-spec"
+
+// ConvertTo converts this policy to the v1beta1 hub version.
+func (src *ResourceQuotaPolicy) ConvertTo(dst *v1beta1.ResourceQuotaPolicy) error {
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.APIVersion = v1beta1.SchemeGroupVersion.String()
+	dst.Kind = "ResourceQuotaPolicy"
+
+	// restore hub-only fields first, then let the v1alpha1 fields win
+	dst.Spec = v1beta1.ResourceQuotaPolicySpec{}
+	if raw, ok := dst.Annotations[HubSpecAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &dst.Spec); err != nil {
+			return err
+		}
+		delete(dst.Annotations, HubSpecAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.MaxPods = int32(src.Spec.MaxPods)
+	dst.Spec.MaxCPU = parseQuantity(src.Spec.MaxCPU)
+	dst.Spec.MaxMemory = parseQuantity(src.Spec.MaxMemory)
+	dst.Spec.ScheduledChanges = nil
+	for _, c := range src.Spec.ScheduledChanges {
+		dst.Spec.ScheduledChanges = append(dst.Spec.ScheduledChanges, v1beta1.ScheduledChange{
+			EffectiveFrom: *c.EffectiveFrom.DeepCopy(),
+			MaxPods:       int32(c.MaxPods),
+			MaxCPU:        parseQuantity(c.MaxCPU),
+			MaxMemory:     parseQuantity(c.MaxMemory),
+		})
+	}
+	dst.Spec.SoftLimits = nil
+	if s := src.Spec.SoftLimits; s != nil {
+		dst.Spec.SoftLimits = &v1beta1.SoftLimits{
+			PodsPercent:   int32(s.PodsPercent),
+			CPUPercent:    int32(s.CPUPercent),
+			MemoryPercent: int32(s.MemoryPercent),
+		}
+	}
+	dst.Spec.ValidationRules = nil
+	for _, r := range src.Spec.ValidationRules {
+		dst.Spec.ValidationRules = append(dst.Spec.ValidationRules, v1beta1.ValidationRule{
+			Expression: r.Expression,
+			Message:    r.Message,
+		})
+	}
+
+	dst.Status = v1beta1.ResourceQuotaPolicyStatus{
+		CurrentPods:           int32(src.Status.CurrentPods),
+		CPUUsage:              src.Status.CPUUsage,
+		MemoryUsage:           src.Status.MemoryUsage,
+		Violation:             src.Status.Violation,
+		Message:               src.Status.Message,
+		ActiveScheduledChange: src.Status.ActiveScheduledChange.DeepCopy(),
+	}
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *c.DeepCopy())
+	}
+	for _, h := range src.Status.History {
+		snap := v1beta1.UsageSnapshot{Time: *h.Time.DeepCopy(), Pods: int32(h.Pods)}
+		if q := parseQuantity(h.CPU); q != nil {
+			snap.CPU = *q
+		}
+		if q := parseQuantity(h.Memory); q != nil {
+			snap.Memory = *q
+		}
+		dst.Status.History = append(dst.Status.History, snap)
+	}
+	if f := src.Status.Forecast; f != nil {
+		dst.Status.Forecast = &v1beta1.QuotaForecast{Resource: f.Resource, ExhaustionTime: *f.ExhaustionTime.DeepCopy()}
+	}
+	return nil
+}
+
+// ConvertFrom converts a v1beta1 hub policy to this version.
+func (dst *ResourceQuotaPolicy) ConvertFrom(src *v1beta1.ResourceQuotaPolicy) error {
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.APIVersion = SchemeGroupVersion.String()
+	dst.Kind = "ResourceQuotaPolicy"
+
+	raw, err := json.Marshal(src.Spec)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[HubSpecAnnotation] = string(raw)
+
+	dst.Spec = ResourceQuotaPolicySpec{
+		MaxPods:   int(src.Spec.MaxPods),
+		MaxCPU:    formatQuantity(src.Spec.MaxCPU),
+		MaxMemory: formatQuantity(src.Spec.MaxMemory),
+	}
+	for _, c := range src.Spec.ScheduledChanges {
+		dst.Spec.ScheduledChanges = append(dst.Spec.ScheduledChanges, ScheduledChange{
+			EffectiveFrom: *c.EffectiveFrom.DeepCopy(),
+			MaxPods:       int(c.MaxPods),
+			MaxCPU:        formatQuantity(c.MaxCPU),
+			MaxMemory:     formatQuantity(c.MaxMemory),
+		})
+	}
+	if s := src.Spec.SoftLimits; s != nil {
+		dst.Spec.SoftLimits = &SoftLimits{
+			PodsPercent:   int(s.PodsPercent),
+			CPUPercent:    int(s.CPUPercent),
+			MemoryPercent: int(s.MemoryPercent),
+		}
+	}
+	for _, r := range src.Spec.ValidationRules {
+		dst.Spec.ValidationRules = append(dst.Spec.ValidationRules, ValidationRule{
+			Expression: r.Expression,
+			Message:    r.Message,
+		})
+	}
+
+	dst.Status = ResourceQuotaPolicyStatus{
+		CurrentPods:           int(src.Status.CurrentPods),
+		CPUUsage:              src.Status.CPUUsage,
+		MemoryUsage:           src.Status.MemoryUsage,
+		Violation:             src.Status.Violation,
+		Message:               src.Status.Message,
+		ActiveScheduledChange: src.Status.ActiveScheduledChange.DeepCopy(),
+	}
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *c.DeepCopy())
+	}
+	for _, h := range src.Status.History {
+		dst.Status.History = append(dst.Status.History, UsageSnapshot{
+			Time:   *h.Time.DeepCopy(),
+			Pods:   int(h.Pods),
+			CPU:    h.CPU.String(),
+			Memory: h.Memory.String(),
+		})
+	}
+	if f := src.Status.Forecast; f != nil {
+		dst.Status.Forecast = &QuotaForecast{Resource: f.Resource, ExhaustionTime: *f.ExhaustionTime.DeepCopy()}
+	}
+	return nil
+}
+
+// parseQuantity converts a v1alpha1 quantity string. Unparseable values were always ignored by
+// the enforcer in favor of its defaults, so they convert to unset rather than failing the request.
+func parseQuantity(s string) *resource.Quantity {
+	if s == "" {
+		return nil
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil
+	}
+	return &q
+}
+
+func formatQuantity(q *resource.Quantity) string {
+	if q == nil {
+		return ""
+	}
+	return q.String()
+}
@@ -11,6 +11,40 @@ type ResourceQuotaPolicySpec struct {
 	MaxPods   int    `json:"maxPods,omitempty"`
 	MaxCPU    string `json:"maxCPU,omitempty"`
 	MaxMemory string `json:"maxMemory,omitempty"`
+
+	// ScheduledChanges are one-time limit changes applied once EffectiveFrom has passed.
+	ScheduledChanges []ScheduledChange `json:"scheduledChanges,omitempty"`
+
+	// SoftLimits are warning thresholds; crossing them never denies or deletes anything.
+	SoftLimits *SoftLimits `json:"softLimits,omitempty"`
+
+	// ValidationRules are CEL expressions evaluated by the webhook for every pod admission.
+	ValidationRules []ValidationRule `json:"validationRules,omitempty"`
+}
+
+// ValidationRule is a CEL expression that must evaluate to true for a pod to be admitted.
+// The expression can reference `pod` (the incoming pod) and `usage` (current namespace usage
+// with `pods`, `cpu` in millicores and `memory` in bytes).
+type ValidationRule struct {
+	Expression string `json:"expression"`
+	// Message is returned to the user when the rule denies a pod.
+	Message string `json:"message,omitempty"`
+}
+
+// SoftLimits are expressed as a percentage (1-100) of the corresponding hard limit.
+// A zero value disables the soft limit for that resource.
+type SoftLimits struct {
+	PodsPercent   int `json:"podsPercent,omitempty"`
+	CPUPercent    int `json:"cpuPercent,omitempty"`
+	MemoryPercent int `json:"memoryPercent,omitempty"`
+}
+
+// ScheduledChange replaces the non-empty limits of the spec from EffectiveFrom onwards.
+type ScheduledChange struct {
+	EffectiveFrom metav1.Time `json:"effectiveFrom"`
+	MaxPods       int         `json:"maxPods,omitempty"`
+	MaxCPU        string      `json:"maxCPU,omitempty"`
+	MaxMemory     string      `json:"maxMemory,omitempty"`
 }
 
 // ResourceQuotaPolicyStatus defines observed usage
@@ -20,8 +54,39 @@ type ResourceQuotaPolicyStatus struct {
 	MemoryUsage string `json:"memoryUsage,omitempty"`
 	Violation   bool   `json:"violations,omitempty"`
 	Message     string `json:"message,omitempty"`
+
+	// ActiveScheduledChange is the EffectiveFrom of the scheduled change currently in force.
+	ActiveScheduledChange *metav1.Time `json:"activeScheduledChange,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	History []UsageSnapshot `json:"history,omitempty"`
+
+	Forecast *QuotaForecast `json:"forecast,omitempty"`
+}
+
+// QuotaForecast predicts when a resource will reach its limit.
+type QuotaForecast struct {
+	Resource       string      `json:"resource"`
+	ExhaustionTime metav1.Time `json:"exhaustionTime"`
 }
 
+// UsageSnapshot records the namespace's consumption at a point in time.
+type UsageSnapshot struct {
+	Time   metav1.Time `json:"time"`
+	Pods   int         `json:"pods"`
+	CPU    string      `json:"cpu"`
+	Memory string      `json:"memory"`
+}
+
+const (
+	// ConditionWarning is True while usage is above at least one soft limit.
+	ConditionWarning = "Warning"
+	// ConditionReconcileFailed is True once the controller stopped retrying the namespace after
+	// repeated sync failures, until a later sync succeeds.
+	ConditionReconcileFailed = "ReconcileFailed"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type ResourceQuotaPolicy struct {
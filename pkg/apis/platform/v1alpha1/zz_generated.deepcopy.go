@@ -20,16 +20,34 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaForecast) DeepCopyInto(out *QuotaForecast) {
+	*out = *in
+	in.ExhaustionTime.DeepCopyInto(&out.ExhaustionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaForecast.
+func (in *QuotaForecast) DeepCopy() *QuotaForecast {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaForecast)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceQuotaPolicy) DeepCopyInto(out *ResourceQuotaPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -87,6 +105,23 @@ func (in *ResourceQuotaPolicyList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceQuotaPolicySpec) DeepCopyInto(out *ResourceQuotaPolicySpec) {
 	*out = *in
+	if in.ScheduledChanges != nil {
+		in, out := &in.ScheduledChanges, &out.ScheduledChanges
+		*out = make([]ScheduledChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SoftLimits != nil {
+		in, out := &in.SoftLimits, &out.SoftLimits
+		*out = new(SoftLimits)
+		**out = **in
+	}
+	if in.ValidationRules != nil {
+		in, out := &in.ValidationRules, &out.ValidationRules
+		*out = make([]ValidationRule, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -103,6 +138,29 @@ func (in *ResourceQuotaPolicySpec) DeepCopy() *ResourceQuotaPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceQuotaPolicyStatus) DeepCopyInto(out *ResourceQuotaPolicyStatus) {
 	*out = *in
+	if in.ActiveScheduledChange != nil {
+		in, out := &in.ActiveScheduledChange, &out.ActiveScheduledChange
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]UsageSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = new(QuotaForecast)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -115,3 +173,69 @@ func (in *ResourceQuotaPolicyStatus) DeepCopy() *ResourceQuotaPolicyStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledChange) DeepCopyInto(out *ScheduledChange) {
+	*out = *in
+	in.EffectiveFrom.DeepCopyInto(&out.EffectiveFrom)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledChange.
+func (in *ScheduledChange) DeepCopy() *ScheduledChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftLimits) DeepCopyInto(out *SoftLimits) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftLimits.
+func (in *SoftLimits) DeepCopy() *SoftLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSnapshot) DeepCopyInto(out *UsageSnapshot) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSnapshot.
+func (in *UsageSnapshot) DeepCopy() *UsageSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationRule) DeepCopyInto(out *ValidationRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationRule.
+func (in *ValidationRule) DeepCopy() *ValidationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationRule)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,30 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResolveClusterShare replaces the limits s has a ClusterShare for with that share of
+// allocatable, the cluster's allocatable resources, and clears the share. A resource allocatable
+// has no entry for, e.g. before any node is known, keeps its limit.
+func (s *ResourceQuotaPolicySpec) ResolveClusterShare(allocatable corev1.ResourceList) {
+	share := s.ClusterShare
+	s.ClusterShare = nil
+	if share == nil {
+		return
+	}
+	if q, ok := allocatable[corev1.ResourcePods]; ok && validShare(share.PodsPercent) {
+		s.MaxPods = int32(q.Value() * int64(share.PodsPercent) / 100)
+	}
+	if q, ok := allocatable[corev1.ResourceCPU]; ok && validShare(share.CPUPercent) {
+		s.MaxCPU = resource.NewMilliQuantity(q.MilliValue()*int64(share.CPUPercent)/100, resource.DecimalSI)
+	}
+	if q, ok := allocatable[corev1.ResourceMemory]; ok && validShare(share.MemoryPercent) {
+		s.MaxMemory = resource.NewQuantity(q.Value()*int64(share.MemoryPercent)/100, resource.BinarySI)
+	}
+}
+
+func validShare(p int32) bool {
+	return p > 0 && p <= 100
+}
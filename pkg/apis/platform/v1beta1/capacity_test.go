@@ -0,0 +1,37 @@
+package v1beta1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResolveClusterShare(t *testing.T) {
+	spec := ResourceQuotaPolicySpec{
+		MaxPods:      50,
+		MaxCPU:       quantity("4"),
+		MaxMemory:    quantity("8Gi"),
+		ClusterShare: &ClusterShare{CPUPercent: 10, MemoryPercent: 25},
+	}
+	spec.ResolveClusterShare(corev1.ResourceList{
+		corev1.ResourcePods:   resource.MustParse("330"),
+		corev1.ResourceCPU:    resource.MustParse("95500m"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+	})
+	if spec.ClusterShare != nil {
+		t.Fatalf("share not cleared: %+v", spec.ClusterShare)
+	}
+	// pods have no share and keep their limit
+	if spec.MaxPods != 50 || spec.MaxCPU.String() != "9550m" || spec.MaxMemory.String() != "16Gi" {
+		t.Fatalf("unexpected limits: pods %d, cpu %s, memory %s", spec.MaxPods, spec.MaxCPU, spec.MaxMemory)
+	}
+}
+
+func TestResolveClusterShareWithoutCapacity(t *testing.T) {
+	spec := ResourceQuotaPolicySpec{MaxCPU: quantity("4"), ClusterShare: &ClusterShare{CPUPercent: 10}}
+	spec.ResolveClusterShare(nil)
+	if spec.MaxCPU.String() != "4" || spec.ClusterShare != nil {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+}
@@ -0,0 +1,43 @@
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Defaults applied to limits omitted from a policy.
+const (
+	DefaultMaxPods   = 10
+	DefaultMaxCPU    = "2"
+	DefaultMaxMemory = "2Gi"
+)
+
+// Requests assumed for a container that leaves them out, unless spec.defaultRequests says
+// otherwise.
+const (
+	DefaultContainerCPU    = "100m"
+	DefaultContainerMemory = "128Mi"
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+func init() {
+	localSchemeBuilder.Register(addDefaultingFuncs)
+}
+
+// SetDefaults_ResourceQuotaPolicySpec fills omitted limits with the documented defaults.
+func SetDefaults_ResourceQuotaPolicySpec(spec *ResourceQuotaPolicySpec) {
+	if spec.MaxPods == 0 {
+		spec.MaxPods = DefaultMaxPods
+	}
+	if spec.MaxCPU == nil {
+		q := resource.MustParse(DefaultMaxCPU)
+		spec.MaxCPU = &q
+	}
+	if spec.MaxMemory == nil {
+		q := resource.MustParse(DefaultMaxMemory)
+		spec.MaxMemory = &q
+	}
+}
@@ -0,0 +1,7 @@
+// +k8s:deepcopy-gen=package
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=platform.example.com
+
+// Package v1beta1 is the storage version of the platform API. v1alpha1 objects are converted
+// to and from this version by the conversion webhook.
+package v1beta1
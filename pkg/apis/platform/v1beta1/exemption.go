@@ -0,0 +1,66 @@
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuotaExemptionSpec selects the pods of its namespace that neither count toward its policies
+// nor get deleted to enforce them, until ExpiresAt.
+type QuotaExemptionSpec struct {
+	// PodSelector exempts the pods whose labels it matches.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Workloads exempt the pods they own.
+	Workloads []WorkloadReference `json:"workloads,omitempty"`
+	// ExpiresAt is when the exemption ends and its pods count again.
+	ExpiresAt metav1.Time `json:"expiresAt"`
+	// Reason says why the pods are exempt, e.g. a ticket or an incident.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Kinds of workload a QuotaExemption can name.
+const (
+	WorkloadDeployment  = "Deployment"
+	WorkloadStatefulSet = "StatefulSet"
+	WorkloadDaemonSet   = "DaemonSet"
+	WorkloadReplicaSet  = "ReplicaSet"
+	WorkloadJob         = "Job"
+)
+
+// WorkloadReference names a workload of the exemption's namespace.
+type WorkloadReference struct {
+	// Kind is Deployment, StatefulSet, DaemonSet, ReplicaSet or Job.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// ConditionExpired is set on a QuotaExemption once it has expired.
+const ConditionExpired = "Expired"
+
+// QuotaExemptionStatus is the state of a QuotaExemption, written by the controller.
+type QuotaExemptionStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Active reports whether the exemption is in force at now.
+func (e *QuotaExemption) Active(now time.Time) bool {
+	return now.Before(e.Spec.ExpiresAt.Time)
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type QuotaExemption struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuotaExemptionSpec   `json:"spec,omitempty"`
+	Status QuotaExemptionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type QuotaExemptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuotaExemption `json:"items"`
+}
@@ -0,0 +1,40 @@
+package v1beta1
+
+import (
+	"time"
+)
+
+const (
+	// HistoryInterval is the minimum spacing between two recorded usage snapshots.
+	HistoryInterval = 30 * time.Minute
+	// HistoryRetention is how far back status.history reaches.
+	HistoryRetention = 7 * 24 * time.Hour
+)
+
+// RecordUsage appends snap to the history unless the latest snapshot is less than
+// HistoryInterval older, then drops snapshots older than HistoryRetention. It reports whether
+// snap was recorded.
+func (s *ResourceQuotaPolicyStatus) RecordUsage(snap UsageSnapshot) bool {
+	if n := len(s.History); n > 0 && snap.Time.Sub(s.History[n-1].Time.Time) < HistoryInterval {
+		return false
+	}
+	s.History = append(s.History, snap)
+
+	cutoff := snap.Time.Add(-HistoryRetention)
+	i := 0
+	for i < len(s.History) && s.History[i].Time.Time.Before(cutoff) {
+		i++
+	}
+	s.History = s.History[i:]
+	return true
+}
+
+// UsageSince returns the snapshots taken at or after t, oldest first.
+func (s *ResourceQuotaPolicyStatus) UsageSince(t time.Time) []UsageSnapshot {
+	for i, snap := range s.History {
+		if !snap.Time.Time.Before(t) {
+			return s.History[i:]
+		}
+	}
+	return nil
+}
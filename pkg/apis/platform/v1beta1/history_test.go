@@ -0,0 +1,34 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordUsage_SpacingAndRetention(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var status ResourceQuotaPolicyStatus
+
+	if !status.RecordUsage(UsageSnapshot{Time: metav1.NewTime(base), Pods: 1}) {
+		t.Fatal("first snapshot should always be recorded")
+	}
+	if status.RecordUsage(UsageSnapshot{Time: metav1.NewTime(base.Add(time.Minute)), Pods: 2}) {
+		t.Fatal("snapshot inside HistoryInterval should be skipped")
+	}
+	status.RecordUsage(UsageSnapshot{Time: metav1.NewTime(base.Add(HistoryInterval)), Pods: 3})
+	if len(status.History) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(status.History))
+	}
+
+	late := base.Add(HistoryRetention + HistoryInterval/2)
+	status.RecordUsage(UsageSnapshot{Time: metav1.NewTime(late), Pods: 4})
+	if len(status.History) != 2 || status.History[0].Pods != 3 || status.History[1].Pods != 4 {
+		t.Fatalf("expected the expired snapshot to be dropped, got %+v", status.History)
+	}
+
+	if got := status.UsageSince(late.Add(-time.Hour)); len(got) != 1 || got[0].Pods != 4 {
+		t.Fatalf("unexpected UsageSince result: %+v", got)
+	}
+}
@@ -0,0 +1,163 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MergeStrategy decides how the policies of a namespace that has more than one combine.
+type MergeStrategy string
+
+const (
+	// MergeHighestPriorityOnly enforces the first policy by SortByPriority and ignores the rest.
+	MergeHighestPriorityOnly MergeStrategy = "highestPriorityOnly"
+	// MergeStrictestWins enforces the lowest value of each limit that any policy sets.
+	MergeStrictestWins MergeStrategy = "strictestWins"
+	// MergeSumAllowances enforces the sum of each limit, which stays unlimited when any policy
+	// leaves it unlimited.
+	MergeSumAllowances MergeStrategy = "sumAllowances"
+)
+
+// ParseMergeStrategy validates the value of a -policy-merge flag.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(s); strategy {
+	case MergeHighestPriorityOnly, MergeStrictestWins, MergeSumAllowances:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown merge strategy %q, must be %s, %s or %s", s, MergeHighestPriorityOnly, MergeStrictestWins, MergeSumAllowances)
+}
+
+// Merge combines the effective specs of one namespace's policies, in SortByPriority order, into
+// the spec that is enforced. Under MergeStrictestWins and MergeSumAllowances, maxPods, maxCPU,
+// maxMemory and maxExtendedResources combine as the strategy says, the validation rules of every
+// spec apply, and strictestWins also applies the subjects of every spec and takes the lowest of
+// the object counts (maxReplicasPerWorkload, maxActiveJobs, maxCronJobs, maxLoadBalancers,
+// maxNodePorts and maxDeletionsPerSync) and soft limit percentages and the strictest missingRequestsPolicy. Everything else comes from the first spec.
+func Merge(strategy MergeStrategy, specs []ResourceQuotaPolicySpec) ResourceQuotaPolicySpec {
+	out := *specs[0].DeepCopy()
+	out.ScheduledChanges = nil
+	if strategy != MergeStrictestWins && strategy != MergeSumAllowances {
+		return out
+	}
+
+	strictest := strategy == MergeStrictestWins
+	for _, spec := range specs[1:] {
+		out.MaxPods = mergeCount(strictest, out.MaxPods, spec.MaxPods)
+		out.MaxCPU = mergeQuantity(strictest, out.MaxCPU, spec.MaxCPU)
+		out.MaxMemory = mergeQuantity(strictest, out.MaxMemory, spec.MaxMemory)
+		out.MaxExtendedResources = mergeExtended(strictest, out.MaxExtendedResources, spec.MaxExtendedResources)
+		out.ValidationRules = append(out.ValidationRules, spec.ValidationRules...)
+		if strictest {
+			out.Subjects = append(out.Subjects, spec.Subjects...)
+			out.MaxReplicasPerWorkload = mergeCount(true, out.MaxReplicasPerWorkload, spec.MaxReplicasPerWorkload)
+			out.MaxActiveJobs = mergeCount(true, out.MaxActiveJobs, spec.MaxActiveJobs)
+			out.MaxCronJobs = mergeCount(true, out.MaxCronJobs, spec.MaxCronJobs)
+			out.MaxLoadBalancers = mergeCount(true, out.MaxLoadBalancers, spec.MaxLoadBalancers)
+			out.MaxNodePorts = mergeCount(true, out.MaxNodePorts, spec.MaxNodePorts)
+			out.MaxDeletionsPerSync = mergeCount(true, out.MaxDeletionsPerSync, spec.MaxDeletionsPerSync)
+			out.SoftLimits = mergeSoftLimits(out.SoftLimits, spec.SoftLimits)
+			if missingRequestsStrictness[spec.MissingRequestsPolicy] > missingRequestsStrictness[out.MissingRequestsPolicy] {
+				out.MissingRequestsPolicy = spec.MissingRequestsPolicy
+			}
+		}
+	}
+	return out
+}
+
+var missingRequestsStrictness = map[MissingRequestsPolicy]int{
+	MissingRequestsAssumeDefaults: 1,
+	MissingRequestsDeny:           2,
+}
+
+// mergeCount combines two limits where zero means unlimited.
+func mergeCount(strictest bool, a, b int32) int32 {
+	switch {
+	case strictest && a == 0:
+		return b
+	case strictest && b == 0:
+		return a
+	case strictest:
+		return min(a, b)
+	case a == 0 || b == 0:
+		return 0
+	}
+	return a + b
+}
+
+// mergeQuantity combines two limits where nil or zero means unlimited.
+func mergeQuantity(strictest bool, a, b *resource.Quantity) *resource.Quantity {
+	aSet, bSet := a != nil && a.Sign() > 0, b != nil && b.Sign() > 0
+	switch {
+	case strictest && !aSet:
+		return copyQuantity(b)
+	case strictest && !bSet:
+		return a
+	case strictest && a.Cmp(*b) > 0:
+		return copyQuantity(b)
+	case strictest:
+		return a
+	case !aSet || !bSet:
+		return nil
+	}
+	sum := a.DeepCopy()
+	sum.Add(*b)
+	return &sum
+}
+
+func copyQuantity(q *resource.Quantity) *resource.Quantity {
+	if q == nil {
+		return nil
+	}
+	c := q.DeepCopy()
+	return &c
+}
+
+// mergeExtended combines extended resource limits, where a name that is absent is unlimited and,
+// unlike the other limits, zero forbids the resource.
+func mergeExtended(strictest bool, a, b map[string]resource.Quantity) map[string]resource.Quantity {
+	out := map[string]resource.Quantity{}
+	for name, qa := range a {
+		qb, inBoth := b[name]
+		switch {
+		case !inBoth && strictest:
+			out[name] = qa
+		case !inBoth:
+			// unlimited in b, so unlimited in the sum
+		case strictest && qb.Cmp(qa) < 0:
+			out[name] = qb.DeepCopy()
+		case strictest:
+			out[name] = qa
+		default:
+			sum := qa.DeepCopy()
+			sum.Add(qb)
+			out[name] = sum
+		}
+	}
+	if strictest {
+		for name, qb := range b {
+			if _, inA := a[name]; !inA {
+				out[name] = qb.DeepCopy()
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// mergeSoftLimits takes the lowest of each soft limit percentage, where zero is disabled.
+func mergeSoftLimits(a, b *SoftLimits) *SoftLimits {
+	if a == nil {
+		return b.DeepCopy()
+	}
+	if b == nil {
+		return a
+	}
+	return &SoftLimits{
+		PodsPercent:   mergeCount(true, a.PodsPercent, b.PodsPercent),
+		CPUPercent:    mergeCount(true, a.CPUPercent, b.CPUPercent),
+		MemoryPercent: mergeCount(true, a.MemoryPercent, b.MemoryPercent),
+	}
+}
@@ -0,0 +1,62 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMerge(t *testing.T) {
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	specs := []ResourceQuotaPolicySpec{
+		{
+			MaxPods:              10,
+			MaxCPU:               quantity("2"),
+			MaxExtendedResources: map[string]resource.Quantity{"nvidia.com/gpu": resource.MustParse("2")},
+		},
+		{
+			MaxPods:   4,
+			MaxCPU:    quantity("500m"),
+			MaxMemory: quantity("1Gi"),
+		},
+	}
+
+	for _, tt := range []struct {
+		strategy        MergeStrategy
+		pods            int32
+		cpu, memory     string
+		gpus            string
+		unlimitedGPUs   bool
+		unlimitedMemory bool
+	}{
+		{strategy: MergeHighestPriorityOnly, pods: 10, cpu: "2", unlimitedMemory: true, gpus: "2"},
+		{strategy: MergeStrictestWins, pods: 4, cpu: "500m", memory: "1Gi", gpus: "2"},
+		{strategy: MergeSumAllowances, pods: 14, cpu: "2500m", unlimitedMemory: true, unlimitedGPUs: true},
+	} {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			got := Merge(tt.strategy, specs)
+			if got.MaxPods != tt.pods {
+				t.Errorf("maxPods = %d, want %d", got.MaxPods, tt.pods)
+			}
+			if got.MaxCPU == nil || got.MaxCPU.Cmp(resource.MustParse(tt.cpu)) != 0 {
+				t.Errorf("maxCPU = %v, want %s", got.MaxCPU, tt.cpu)
+			}
+			switch {
+			case tt.unlimitedMemory && got.MaxMemory != nil:
+				t.Errorf("maxMemory = %v, want unlimited", got.MaxMemory)
+			case !tt.unlimitedMemory && (got.MaxMemory == nil || got.MaxMemory.Cmp(resource.MustParse(tt.memory)) != 0):
+				t.Errorf("maxMemory = %v, want %s", got.MaxMemory, tt.memory)
+			}
+			gpus, limited := got.MaxExtendedResources["nvidia.com/gpu"]
+			switch {
+			case tt.unlimitedGPUs && limited:
+				t.Errorf("gpus = %s, want unlimited", gpus.String())
+			case !tt.unlimitedGPUs && (!limited || gpus.Cmp(resource.MustParse(tt.gpus)) != 0):
+				t.Errorf("gpus = %s, want %s", gpus.String(), tt.gpus)
+			}
+		})
+	}
+}
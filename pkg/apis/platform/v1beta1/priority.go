@@ -0,0 +1,18 @@
+package v1beta1
+
+import "sort"
+
+// SortByPriority orders policies of one namespace so the one enforced comes first: highest
+// spec.priority, then the oldest, then the first by name.
+func SortByPriority(policies []*ResourceQuotaPolicy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		a, b := policies[i], policies[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Name < b.Name
+	})
+}
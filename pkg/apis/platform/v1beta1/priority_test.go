@@ -0,0 +1,32 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortByPriority(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := func(name string, priority int32, created time.Time) *ResourceQuotaPolicy {
+		return &ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       ResourceQuotaPolicySpec{Priority: priority},
+		}
+	}
+	policies := []*ResourceQuotaPolicy{
+		policy("b-newer", 0, t0.Add(time.Hour)),
+		policy("d", 0, t0),
+		policy("c", 0, t0),
+		policy("high", 10, t0.Add(2*time.Hour)),
+	}
+	SortByPriority(policies)
+
+	want := []string{"high", "c", "d", "b-newer"}
+	for i, p := range policies {
+		if p.Name != want[i] {
+			t.Fatalf("position %d: got %s, want order %v", i, p.Name, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package v1beta1
+
+import (
+	"sort"
+	"time"
+)
+
+// EffectiveAt returns the spec with every scheduled change whose EffectiveFrom is not after t
+// applied in chronological order. The returned change is the latest one applied, or nil when
+// the base limits are still in force.
+func (s *ResourceQuotaPolicySpec) EffectiveAt(t time.Time) (ResourceQuotaPolicySpec, *ScheduledChange) {
+	out := *s
+	out.ScheduledChanges = nil
+
+	var active *ScheduledChange
+	for _, c := range s.sortedChanges() {
+		if c.EffectiveFrom.Time.After(t) {
+			break
+		}
+		if c.MaxPods != 0 {
+			out.MaxPods = c.MaxPods
+		}
+		if c.MaxCPU != nil {
+			out.MaxCPU = c.MaxCPU
+		}
+		if c.MaxMemory != nil {
+			out.MaxMemory = c.MaxMemory
+		}
+		active = c.DeepCopy()
+	}
+	return out, active
+}
+
+// NextScheduledChange returns the earliest EffectiveFrom strictly after t.
+func (s *ResourceQuotaPolicySpec) NextScheduledChange(t time.Time) (time.Time, bool) {
+	for _, c := range s.sortedChanges() {
+		if c.EffectiveFrom.Time.After(t) {
+			return c.EffectiveFrom.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *ResourceQuotaPolicySpec) sortedChanges() []ScheduledChange {
+	changes := make([]ScheduledChange, len(s.ScheduledChanges))
+	copy(changes, s.ScheduledChanges)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].EffectiveFrom.Before(&changes[j].EffectiveFrom)
+	})
+	return changes
+}
@@ -0,0 +1,45 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEffectiveAt_AppliesPastChangesInOrder(t *testing.T) {
+	base := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	spec := ResourceQuotaPolicySpec{
+		MaxPods:   3,
+		MaxCPU:    quantity("500m"),
+		MaxMemory: quantity("1Gi"),
+		ScheduledChanges: []ScheduledChange{
+			{EffectiveFrom: metav1.NewTime(base.Add(2 * time.Hour)), MaxPods: 10},
+			{EffectiveFrom: metav1.NewTime(base), MaxPods: 6, MaxCPU: quantity("1")},
+		},
+	}
+
+	before, active := spec.EffectiveAt(base.Add(-time.Minute))
+	if active != nil || before.MaxPods != 3 || before.MaxCPU.String() != "500m" {
+		t.Fatalf("expected base limits before cutover, got %+v (active=%v)", before, active)
+	}
+
+	after, active := spec.EffectiveAt(base.Add(time.Hour))
+	if active == nil || !active.EffectiveFrom.Time.Equal(base) {
+		t.Fatalf("expected first change active, got %v", active)
+	}
+	if after.MaxPods != 6 || after.MaxCPU.String() != "1" || after.MaxMemory.String() != "1Gi" {
+		t.Fatalf("unexpected effective spec: %+v", after)
+	}
+
+	next, ok := spec.NextScheduledChange(base.Add(time.Hour))
+	if !ok || !next.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("expected next change at %s, got %s (ok=%v)", base.Add(2*time.Hour), next, ok)
+	}
+}
+
+func quantity(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
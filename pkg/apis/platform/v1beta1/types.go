@@ -0,0 +1,380 @@
+package v1beta1
+
+// +kubebuilder:object:generate=true
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceQuotaPolicySpec defines the desired state
+type ResourceQuotaPolicySpec struct {
+	MaxPods   int32              `json:"maxPods,omitempty"`
+	MaxCPU    *resource.Quantity `json:"maxCPU,omitempty"`
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+	// ClusterShare sets limits as a percentage of the cluster's allocatable resources, which
+	// follow the cluster as nodes come and go. It replaces maxPods, maxCPU and maxMemory, and
+	// their scheduled changes, for each resource it sets.
+	ClusterShare *ClusterShare `json:"clusterShare,omitempty"`
+
+	// ScheduledChanges are one-time limit changes applied once EffectiveFrom has passed.
+	ScheduledChanges []ScheduledChange `json:"scheduledChanges,omitempty"`
+
+	// SoftLimits are warning thresholds; crossing them never denies or deletes anything.
+	SoftLimits *SoftLimits `json:"softLimits,omitempty"`
+
+	// ValidationRules are CEL expressions evaluated by the webhook for every pod admission.
+	ValidationRules []ValidationRule `json:"validationRules,omitempty"`
+
+	// ExternalPolicy hands the webhook's decision on new pods to an OPA endpoint, which gets the
+	// pod, the namespace's usage and this policy as input. The usage is still computed here.
+	ExternalPolicy *ExternalPolicy `json:"externalPolicy,omitempty"`
+
+	// Pricing turns requested resources into an estimated cost for chargeback.
+	Pricing *Pricing `json:"pricing,omitempty"`
+
+	// ExcludePods selects pods that neither count toward the limits nor get deleted to enforce
+	// them. Unset fields fall back to the controller's and webhook's --exclude-* flags.
+	ExcludePods *PodExclusions `json:"excludePods,omitempty"`
+
+	// MaxExtendedResources limits extended resources such as example.com/gpu, keyed by resource
+	// name. Pods count their container requests for the name and, when DRA accounting is
+	// enabled, the devices they claim from a DeviceClass with that extendedResourceName or, for
+	// a class without one, under deviceclass.resource.kubernetes.io/<class>. Unlike maxCPU and
+	// maxMemory, a zero limit forbids the resource.
+	MaxExtendedResources map[string]resource.Quantity `json:"maxExtendedResources,omitempty"`
+
+	// MissingRequestsPolicy decides how containers without a CPU or memory request are treated.
+	// Unset, a missing request counts as zero.
+	MissingRequestsPolicy MissingRequestsPolicy `json:"missingRequestsPolicy,omitempty"`
+	// DefaultRequests are assumed for each container missing a request under either
+	// MissingRequestsPolicy; unset fields fall back to DefaultContainerCPU and
+	// DefaultContainerMemory.
+	DefaultRequests *DefaultRequests `json:"defaultRequests,omitempty"`
+
+	// UsageSource decides what the controller counts as a namespace's CPU and memory usage
+	// against maxCPU and maxMemory. Unset, it is the pods' requests.
+	UsageSource UsageSource `json:"usageSource,omitempty"`
+	// UsageQueries are the PromQL queries of UsageSourcePrometheus; unset fields fall back to
+	// the controller's defaults over cAdvisor metrics.
+	UsageQueries *UsageQueries `json:"usageQueries,omitempty"`
+
+	// Priority orders the policies of a namespace that has more than one: only the one with the
+	// highest priority is enforced, the oldest and then the first by name among equals.
+	Priority int32 `json:"priority,omitempty"`
+
+	// Subjects cap what single service accounts or users may use within the namespace, on top
+	// of the namespace-wide limits, e.g. each pipeline sharing a CI namespace.
+	Subjects []SubjectQuota `json:"subjects,omitempty"`
+
+	// NodeScope caps what the namespace's pods use on groups of nodes, such as a zone or a node
+	// pool, on top of the namespace-wide limits.
+	NodeScope *NodeScope `json:"nodeScope,omitempty"`
+
+	// MaxReplicasPerWorkload caps the replicas of each Deployment, StatefulSet and ReplicaSet, so
+	// a single workload can't scale to take the namespace's whole allocation. Zero is unlimited.
+	MaxReplicasPerWorkload int32 `json:"maxReplicasPerWorkload,omitempty"`
+	// AutoscalingCheck makes the webhook check Deployments, StatefulSets and ReplicaSets, and
+	// the HorizontalPodAutoscalers targeting them, at the HPA's maxReplicas rather than at their
+	// current replicas. Unset, what an HPA could scale to isn't checked.
+	AutoscalingCheck AutoscalingCheck `json:"autoscalingCheck,omitempty"`
+
+	// MaxActiveJobs caps the Jobs that are neither suspended nor finished. The webhook denies
+	// new Jobs over it, and the controller suspends the newest active Jobs past it. Zero is
+	// unlimited.
+	MaxActiveJobs int32 `json:"maxActiveJobs,omitempty"`
+	// MaxCronJobs caps the CronJobs of the namespace. Zero is unlimited.
+	MaxCronJobs int32 `json:"maxCronJobs,omitempty"`
+
+	// MaxLoadBalancers caps the Services of type LoadBalancer. Zero is unlimited.
+	MaxLoadBalancers int32 `json:"maxLoadBalancers,omitempty"`
+	// MaxNodePorts caps the node ports the namespace's Services take: one per port of each
+	// NodePort Service, and of each LoadBalancer Service unless allocateLoadBalancerNodePorts is
+	// false. Zero is unlimited.
+	MaxNodePorts int32 `json:"maxNodePorts,omitempty"`
+
+	// MaxDeletionsPerSync caps the pods the controller deletes to enforce the policy in one sync,
+	// on top of its -enforce-max-iterations, limiting the damage a mistaken policy does at once. A
+	// violation that takes more is left for the next sync with a DeletionCapReached condition.
+	// Zero leaves only the controller's cap.
+	MaxDeletionsPerSync int32 `json:"maxDeletionsPerSync,omitempty"`
+
+	// Impersonate is the identity the controller deletes pods and suspends Jobs as to enforce
+	// the policy, instead of its -as identity or its own. The controller's service account needs
+	// the impersonate verb on it.
+	Impersonate *Impersonation `json:"impersonate,omitempty"`
+
+	// Suspend pauses the policy without deleting it: the webhook admits what it would deny and
+	// the controller deletes no pods and suspends no Jobs for it, but still writes its usage to
+	// status and keeps its history.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// Impersonation is a user, with its groups, that API requests are made as.
+type Impersonation struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// AutoscalingCheck is what the webhook does with a workload whose HorizontalPodAutoscaler could
+// scale it past the policy's limits.
+type AutoscalingCheck string
+
+const (
+	// AutoscalingCheckWarn admits the workload with a warning.
+	AutoscalingCheckWarn AutoscalingCheck = "warn"
+	// AutoscalingCheckDeny denies the workload.
+	AutoscalingCheckDeny AutoscalingCheck = "deny"
+)
+
+// UsageSource is where a policy's CPU and memory usage comes from.
+type UsageSource string
+
+const (
+	// UsageSourceRequests sums the requests of the namespace's pods.
+	UsageSourceRequests UsageSource = "requests"
+	// UsageSourcePrometheus sums what the pods actually use, as reported by the UsageQueries
+	// the controller runs against its -prometheus-url. Pods without a sample yet count their
+	// requests. The webhook still admits new pods on their requests.
+	UsageSourcePrometheus UsageSource = "prometheus"
+	// UsageSourceVPARecommendation sums the requests of the namespace's pods as their
+	// VerticalPodAutoscalers will resize them: each container of a pod selected by a VPA whose
+	// updateMode isn't Off counts the VPA's target for it. The webhook still counts requests.
+	UsageSourceVPARecommendation UsageSource = "vpaRecommendation"
+)
+
+// UsageQueries are PromQL query templates returning, for the namespace in {{.Namespace}}, a
+// vector of the usage of each pod labeled by pod, e.g.
+// sum by (pod) (container_memory_working_set_bytes{namespace="{{.Namespace}}",container!=""}).
+type UsageQueries struct {
+	// CPU returns cores in use.
+	CPU string `json:"cpu,omitempty"`
+	// Memory returns bytes in use.
+	Memory string `json:"memory,omitempty"`
+}
+
+// Kinds of SubjectQuota.
+const (
+	SubjectServiceAccount = "ServiceAccount"
+	SubjectUser           = "User"
+)
+
+// SubjectQuota limits the pods of one subject. A pod belongs to a ServiceAccount through its
+// spec.serviceAccountName and to a User through the AnnotationRequestedBy the webhook sets when
+// the pod is created. Unset limits are unlimited.
+type SubjectQuota struct {
+	// Kind is ServiceAccount or User.
+	Kind string `json:"kind"`
+	// Name is the service account, in the policy's namespace, or the user name.
+	Name      string             `json:"name"`
+	MaxPods   int32              `json:"maxPods,omitempty"`
+	MaxCPU    *resource.Quantity `json:"maxCPU,omitempty"`
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// NodeScope holds the node scopes of a policy. A pod counts toward the scopes whose node selector
+// matches the labels of the node it is bound to, and, until it is bound, toward DefaultScope.
+type NodeScope struct {
+	Scopes []NodeScopeQuota `json:"scopes"`
+	// DefaultScope names the scope pods that aren't bound to a node yet count toward, which
+	// includes new pods unless they set spec.nodeName. Unset, pending pods count toward no scope.
+	DefaultScope string `json:"defaultScope,omitempty"`
+}
+
+// NodeScopeQuota limits the pods bound to the nodes NodeSelector matches. Unset limits are
+// unlimited.
+type NodeScopeQuota struct {
+	// Name is how denials refer to the scope, e.g. "us-east-1a".
+	Name string `json:"name"`
+	// NodeSelector matches node labels, e.g. topology.kubernetes.io/zone: us-east-1a or a node
+	// pool label.
+	NodeSelector map[string]string  `json:"nodeSelector"`
+	MaxPods      int32              `json:"maxPods,omitempty"`
+	MaxCPU       *resource.Quantity `json:"maxCPU,omitempty"`
+	MaxMemory    *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// MissingRequestsPolicy is how a policy handles containers that leave out a request.
+type MissingRequestsPolicy string
+
+const (
+	// MissingRequestsAssumeDefaults counts a missing request as the default request.
+	MissingRequestsAssumeDefaults MissingRequestsPolicy = "assumeDefaults"
+	// MissingRequestsDeny rejects new pods with a container missing a request, and counts
+	// the ones already running as MissingRequestsAssumeDefaults does.
+	MissingRequestsDeny MissingRequestsPolicy = "deny"
+)
+
+// DefaultRequests are per-container requests.
+type DefaultRequests struct {
+	CPU    *resource.Quantity `json:"cpu,omitempty"`
+	Memory *resource.Quantity `json:"memory,omitempty"`
+}
+
+// PodExclusions selects kinds of pods enforcement leaves alone.
+type PodExclusions struct {
+	// DaemonSetPods excludes pods owned by a DaemonSet, which would be recreated right away.
+	DaemonSetPods *bool `json:"daemonSetPods,omitempty"`
+	// MirrorPods excludes the API mirrors of static pods, which cannot be deleted through the API.
+	MirrorPods *bool `json:"mirrorPods,omitempty"`
+}
+
+// Pricing holds unit prices as decimal strings, e.g. "0.035".
+type Pricing struct {
+	// CPUHour is the price of one requested CPU core for an hour.
+	CPUHour string `json:"cpuHour,omitempty"`
+	// MemoryGiBHour is the price of one requested GiB of memory for an hour.
+	MemoryGiBHour string `json:"memoryGiBHour,omitempty"`
+	// Currency is only used as a metric label; defaults to USD.
+	Currency string `json:"currency,omitempty"`
+}
+
+// ScheduledChange replaces the non-empty limits of the spec from EffectiveFrom onwards.
+type ScheduledChange struct {
+	EffectiveFrom metav1.Time        `json:"effectiveFrom"`
+	MaxPods       int32              `json:"maxPods,omitempty"`
+	MaxCPU        *resource.Quantity `json:"maxCPU,omitempty"`
+	MaxMemory     *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// ClusterShare is a percentage (1-100) of the allocatable resources summed over the cluster's
+// schedulable nodes. A zero value leaves the limit of that resource as it is.
+type ClusterShare struct {
+	PodsPercent   int32 `json:"podsPercent,omitempty"`
+	CPUPercent    int32 `json:"cpuPercent,omitempty"`
+	MemoryPercent int32 `json:"memoryPercent,omitempty"`
+}
+
+// SoftLimits are expressed as a percentage (1-100) of the corresponding hard limit.
+// A zero value disables the soft limit for that resource.
+type SoftLimits struct {
+	PodsPercent   int32 `json:"podsPercent,omitempty"`
+	CPUPercent    int32 `json:"cpuPercent,omitempty"`
+	MemoryPercent int32 `json:"memoryPercent,omitempty"`
+}
+
+// ExternalFailurePolicy decides pods whose external decision can't be had.
+type ExternalFailurePolicy string
+
+const (
+	// ExternalFailureIgnore falls back to the decision the webhook takes without the endpoint.
+	ExternalFailureIgnore ExternalFailurePolicy = "Ignore"
+	// ExternalFailureFail denies the pod.
+	ExternalFailureFail ExternalFailurePolicy = "Fail"
+)
+
+// ExternalPolicy is an OPA decision endpoint. The webhook POSTs {"input": ...} to URL with the
+// pod, the namespace's usage before it, the policy's spec and the decision the webhook would
+// take itself, and honors the result: a boolean, or an object with a boolean allow and an
+// optional reason shown to the client on denial.
+type ExternalPolicy struct {
+	// URL is the decision, e.g. http://opa.opa.svc:8181/v1/data/quota/decision.
+	URL string `json:"url"`
+	// FailurePolicy applies when the endpoint fails or answers without a decision; defaults to
+	// Ignore.
+	FailurePolicy ExternalFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// ValidationRule is a CEL expression that must evaluate to true for a pod to be admitted.
+// The expression can reference `pod` (the incoming pod) and `usage` (current namespace usage
+// with `pods`, `cpu` in millicores and `memory` in bytes).
+type ValidationRule struct {
+	Expression string `json:"expression"`
+	// Message is returned to the user when the rule denies a pod.
+	Message string `json:"message,omitempty"`
+}
+
+// ResourceQuotaPolicyStatus defines observed usage
+type ResourceQuotaPolicyStatus struct {
+	CurrentPods int32  `json:"currentPods,omitempty"`
+	CPUUsage    string `json:"cpuUsage,omitempty"`
+	MemoryUsage string `json:"memoryUsage,omitempty"`
+	Violation   bool   `json:"violation,omitempty"`
+	Message     string `json:"message,omitempty"`
+
+	// ActiveScheduledChange is the EffectiveFrom of the scheduled change currently in force.
+	ActiveScheduledChange *metav1.Time `json:"activeScheduledChange,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// History is a ring buffer of usage snapshots, oldest first, covering the last
+	// HistoryRetention at HistoryInterval resolution.
+	History []UsageSnapshot `json:"history,omitempty"`
+
+	// Forecast is the earliest predicted quota exhaustion at the current growth rate. It is
+	// unset while usage is flat or shrinking.
+	Forecast *QuotaForecast `json:"forecast,omitempty"`
+
+	// ReplicaViolations are the workloads with more replicas than MaxReplicasPerWorkload, e.g.
+	// ones created before the limit was set.
+	ReplicaViolations []WorkloadReplicas `json:"replicaViolations,omitempty"`
+}
+
+// WorkloadReplicas is the replica count of one workload.
+type WorkloadReplicas struct {
+	// Kind is Deployment or StatefulSet.
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+// QuotaForecast predicts when a resource will reach its limit.
+type QuotaForecast struct {
+	Resource       string      `json:"resource"`
+	ExhaustionTime metav1.Time `json:"exhaustionTime"`
+}
+
+// UsageSnapshot records the namespace's consumption at a point in time.
+type UsageSnapshot struct {
+	Time   metav1.Time       `json:"time"`
+	Pods   int32             `json:"pods"`
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+}
+
+const (
+	// ConditionWarning is True while usage is above at least one soft limit.
+	ConditionWarning = "Warning"
+	// ConditionReconcileFailed is True once the controller stopped retrying the namespace after
+	// repeated sync failures, until a later sync succeeds.
+	ConditionReconcileFailed = "ReconcileFailed"
+	// ConditionConflict is True on every policy of a namespace that has more than one, naming the
+	// policy that is enforced.
+	ConditionConflict = "Conflict"
+	// ConditionDeletionCapReached is True while a violation is left in place because the last
+	// sync deleted as many pods as it may.
+	ConditionDeletionCapReached = "DeletionCapReached"
+	// ConditionSuspended is True while the policy is suspended by spec.suspend.
+	ConditionSuspended = "Suspended"
+)
+
+// AnnotationDefaultAction on a namespace set to "allow" leaves the namespace unlimited while it
+// has no policy of its own: neither the cluster default policy nor a deny-by-default webhook
+// apply to it.
+const AnnotationDefaultAction = "quota.platform/default-action"
+
+// ExemptFromDefaults reports whether a namespace with annotations opted out of the cluster
+// defaults through AnnotationDefaultAction.
+func ExemptFromDefaults(annotations map[string]string) bool {
+	return annotations[AnnotationDefaultAction] == "allow"
+}
+
+// AnnotationRequestedBy records on a pod the user whose request created it, for User subjects.
+const AnnotationRequestedBy = "quota.platform/requested-by"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ResourceQuotaPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceQuotaPolicySpec   `json:"spec,omitempty"`
+	Status ResourceQuotaPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ResourceQuotaPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceQuotaPolicy `json:"items"`
+}
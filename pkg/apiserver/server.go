@@ -0,0 +1,232 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apiserver/quotapb"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// Server implements quotapb.QuotaServiceServer on top of the cluster state the controller
+// maintains: live pod usage, policy status and the events it records on policies.
+type Server struct {
+	quotapb.UnimplementedQuotaServiceServer
+
+	Client   kubernetes.Interface
+	Policies versioned.Interface
+}
+
+// NewServer returns a Server reading from the given clients.
+func NewServer(client kubernetes.Interface, policies versioned.Interface) *Server {
+	return &Server{Client: client, Policies: policies}
+}
+
+// Serve listens on addr and serves the QuotaService until ctx is done or the listener fails.
+func Serve(ctx context.Context, addr string, srv *Server) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	gs := grpc.NewServer()
+	quotapb.RegisterQuotaServiceServer(gs, srv)
+
+	stop := context.AfterFunc(ctx, gs.Stop)
+	defer stop()
+	logging.L().WithName("apiserver").Info("gRPC API listening", "addr", addr)
+	return gs.Serve(lis)
+}
+
+// GetNamespaceUsage computes usage for every policy in the namespace the same way the
+// controller does.
+func (s *Server) GetNamespaceUsage(ctx context.Context, req *quotapb.GetNamespaceUsageRequest) (*quotapb.GetNamespaceUsageResponse, error) {
+	if req.GetNamespace() == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+
+	list, err := s.Policies.PlatformV1beta1().ResourceQuotaPolicies(req.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list policies: %v", err)
+	}
+
+	allocatable, err := capacity.List(ctx, s.Client)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	enforcer := &handlers.PodEnforcer{Client: s.Client}
+	now := time.Now()
+	resp := &quotapb.GetNamespaceUsageResponse{}
+	for _, item := range list.Items {
+		spec, _ := item.Spec.EffectiveAt(now)
+		spec.ResolveClusterShare(allocatable)
+		policy := handlers.ParsePolicy(&spec)
+		res, err := enforcer.ComputeUsage(ctx, item.Namespace, policy)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "compute usage for %s: %v", item.Namespace, err)
+		}
+		cpu, _ := resource.ParseQuantity(res.CurrentCPU)
+		mem, _ := resource.ParseQuantity(res.CurrentMemory)
+
+		podsUtil := 0.0
+		if policy.MaxPods > 0 {
+			podsUtil = float64(res.CurrentPods) / float64(policy.MaxPods)
+		}
+		resp.Policies = append(resp.Policies, &quotapb.PolicyUsage{
+			Namespace: item.Namespace,
+			Policy:    item.Name,
+			Pods: &quotapb.ResourceUsage{
+				Used:        fmt.Sprint(res.CurrentPods),
+				Limit:       fmt.Sprint(policy.MaxPods),
+				Utilization: podsUtil,
+			},
+			Cpu: &quotapb.ResourceUsage{
+				Used:        cpu.String(),
+				Limit:       policy.MaxCPU.String(),
+				Utilization: handlers.Utilization(cpu, policy.MaxCPU),
+			},
+			Memory: &quotapb.ResourceUsage{
+				Used:        mem.String(),
+				Limit:       policy.MaxMemory.String(),
+				Utilization: handlers.Utilization(mem, policy.MaxMemory),
+			},
+			Violation: res.Violation,
+			Message:   res.Message,
+			Warnings:  res.Warnings,
+		})
+	}
+	return resp, nil
+}
+
+// GetUsageHistory returns the usage snapshots the controller has recorded in the status of
+// every policy in the namespace.
+func (s *Server) GetUsageHistory(ctx context.Context, req *quotapb.GetUsageHistoryRequest) (*quotapb.GetUsageHistoryResponse, error) {
+	if req.GetNamespace() == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+
+	list, err := s.Policies.PlatformV1beta1().ResourceQuotaPolicies(req.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list policies: %v", err)
+	}
+
+	var since time.Time
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+	resp := &quotapb.GetUsageHistoryResponse{}
+	for _, item := range list.Items {
+		h := &quotapb.PolicyHistory{Namespace: item.Namespace, Policy: item.Name}
+		for _, snap := range item.Status.UsageSince(since) {
+			h.Samples = append(h.Samples, &quotapb.UsageSample{
+				Time:   timestamppb.New(snap.Time.Time),
+				Pods:   snap.Pods,
+				Cpu:    snap.CPU.String(),
+				Memory: snap.Memory.String(),
+			})
+		}
+		resp.Policies = append(resp.Policies, h)
+	}
+	return resp, nil
+}
+
+// ListViolations reports the policies whose status says they are in violation.
+func (s *Server) ListViolations(ctx context.Context, req *quotapb.ListViolationsRequest) (*quotapb.ListViolationsResponse, error) {
+	list, err := s.Policies.PlatformV1beta1().ResourceQuotaPolicies(req.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list policies: %v", err)
+	}
+
+	resp := &quotapb.ListViolationsResponse{}
+	for _, item := range list.Items {
+		if !item.Status.Violation {
+			continue
+		}
+		resp.Violations = append(resp.Violations, &quotapb.Violation{
+			Namespace:   item.Namespace,
+			Policy:      item.Name,
+			Message:     item.Status.Message,
+			CurrentPods: item.Status.CurrentPods,
+			CpuUsage:    item.Status.CPUUsage,
+			MemoryUsage: item.Status.MemoryUsage,
+		})
+	}
+	return resp, nil
+}
+
+// StreamEnforcementEvents relays the events the controller records on ResourceQuotaPolicy
+// objects, starting from the moment the stream is opened.
+func (s *Server) StreamEnforcementEvents(req *quotapb.StreamEnforcementEventsRequest, stream quotapb.QuotaService_StreamEnforcementEventsServer) error {
+	ctx := stream.Context()
+	events := s.Client.CoreV1().Events(req.GetNamespace())
+	opts := metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "ResourceQuotaPolicy").String(),
+	}
+
+	// list first so the watch only delivers events recorded after this point
+	list, err := events.List(ctx, opts)
+	if err != nil {
+		return status.Errorf(codes.Internal, "list events: %v", err)
+	}
+	opts.ResourceVersion = list.ResourceVersion
+	w, err := events.Watch(ctx, opts)
+	if err != nil {
+		return status.Errorf(codes.Internal, "watch events: %v", err)
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return status.Error(codes.Unavailable, "event watch closed")
+			}
+			if e.Type != watch.Added && e.Type != watch.Modified {
+				continue
+			}
+			ev, ok := e.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(toEnforcementEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toEnforcementEvent(ev *corev1.Event) *quotapb.EnforcementEvent {
+	t := ev.LastTimestamp.Time
+	if t.IsZero() {
+		t = ev.EventTime.Time
+	}
+	if t.IsZero() {
+		t = ev.CreationTimestamp.Time
+	}
+	return &quotapb.EnforcementEvent{
+		Time:      timestamppb.New(t),
+		Namespace: ev.InvolvedObject.Namespace,
+		Policy:    ev.InvolvedObject.Name,
+		Type:      ev.Type,
+		Reason:    ev.Reason,
+		Message:   ev.Message,
+	}
+}
@@ -0,0 +1,63 @@
+package apiserver
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apiserver/quotapb"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+)
+
+func TestServer_UsageAndViolations(t *testing.T) {
+	cpu := resource.MustParse("1")
+	pods := k8sfake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "team-a"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "c",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("500m"),
+			}},
+		}}},
+	})
+	policies := fake.NewSimpleClientset(
+		&platformv1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+			Spec:       platformv1beta1.ResourceQuotaPolicySpec{MaxPods: 4, MaxCPU: &cpu},
+		},
+		&platformv1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-b"},
+			Status:     platformv1beta1.ResourceQuotaPolicyStatus{Violation: true, Message: "pods:3>max:2", CurrentPods: 3},
+		},
+	)
+	srv := NewServer(pods, policies)
+
+	usage, err := srv.GetNamespaceUsage(context.Background(), &quotapb.GetNamespaceUsageRequest{Namespace: "team-a"})
+	if err != nil {
+		t.Fatalf("GetNamespaceUsage: %v", err)
+	}
+	if len(usage.Policies) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(usage.Policies))
+	}
+	got := usage.Policies[0]
+	if got.Pods.Used != "1" || got.Pods.Limit != "4" || got.Cpu.Used != "500m" || got.Cpu.Utilization != 0.5 {
+		t.Errorf("unexpected usage: %v", got)
+	}
+
+	if _, err := srv.GetNamespaceUsage(context.Background(), &quotapb.GetNamespaceUsageRequest{}); err == nil {
+		t.Error("expected an error without a namespace")
+	}
+
+	violations, err := srv.ListViolations(context.Background(), &quotapb.ListViolationsRequest{})
+	if err != nil {
+		t.Fatalf("ListViolations: %v", err)
+	}
+	if len(violations.Violations) != 1 || violations.Violations[0].Namespace != "team-b" {
+		t.Errorf("expected only team-b in violation, got %v", violations.Violations)
+	}
+}
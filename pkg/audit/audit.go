@@ -0,0 +1,139 @@
+// Package audit records enforcement decisions as JSON lines, separate from operational logging,
+// so every denial and deletion can be reconstructed afterwards.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// Decision is what the enforcer did with a pod.
+type Decision string
+
+const (
+	// DecisionDenied means the admission webhook rejected the pod.
+	DecisionDenied Decision = "denied"
+	// DecisionEvicted means the controller deleted the pod to enforce a policy.
+	DecisionEvicted Decision = "evicted"
+	// DecisionWouldEvict means the controller, running with -enforcement=dry-run, would have
+	// deleted the pod.
+	DecisionWouldEvict Decision = "would_evict"
+)
+
+// Sources of audit events.
+const (
+	SourceWebhook    = "webhook"
+	SourceController = "controller"
+)
+
+// Usage is the namespace consumption the decision was based on.
+type Usage struct {
+	Pods   int64  `json:"pods"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// Event is one audit record.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	// Cluster is set when the controller manages several clusters.
+	Cluster   string   `json:"cluster,omitempty"`
+	Namespace string   `json:"namespace"`
+	Pod       string   `json:"pod"`
+	Policy    string   `json:"policy,omitempty"`
+	Reason    string   `json:"reason"`
+	Usage     Usage    `json:"usage"`
+	Decision  Decision `json:"decision"`
+	// As is the user the controller impersonated to act on the pod, if any.
+	As string `json:"as,omitempty"`
+}
+
+// Logger writes events as JSON lines and hands them to any configured exporters. A nil
+// *Logger discards everything, so callers don't need to check whether auditing is enabled.
+type Logger struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+
+	sinks []*batcher
+}
+
+// New returns a Logger writing to w. A nil w only feeds exporters.
+func New(w io.Writer) *Logger {
+	l := &Logger{}
+	if w != nil {
+		l.enc = json.NewEncoder(w)
+	}
+	return l
+}
+
+// Open returns a Logger appending to path, or writing to stdout when path is "-". An empty
+// path disables auditing and returns a nil Logger.
+func Open(path string) (*Logger, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "-":
+		return New(os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	l := New(f)
+	l.closer = f
+	return l, nil
+}
+
+// AddExporter ships every subsequent event to exp in batches. It must be called before the
+// Logger is shared.
+func (l *Logger) AddExporter(exp Exporter, opts BatchOptions) {
+	l.sinks = append(l.sinks, newBatcher(exp, opts))
+}
+
+// Record writes e, stamping it with the current time if it has none.
+func (l *Logger) Record(e Event) {
+	if l == nil {
+		return
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+
+	if l.enc != nil {
+		l.mu.Lock()
+		if err := l.enc.Encode(e); err != nil {
+			logging.L().WithName("audit").Error(err, "Failed to write audit event", "namespace", e.Namespace, "pod", e.Pod, "policy", e.Policy)
+		}
+		l.mu.Unlock()
+	}
+	for _, s := range l.sinks {
+		s.add(e)
+	}
+}
+
+// Close flushes the exporters and closes the underlying file, if any.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	for _, s := range l.sinks {
+		s.close()
+		if c, ok := s.exp.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				logging.L().WithName("audit").Error(err, "Failed to close exporter", "exporter", s.exp.Name())
+			}
+		}
+	}
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
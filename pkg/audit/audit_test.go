@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogger_Record(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.Record(Event{Source: SourceWebhook, Namespace: "ns1", Pod: "web", Policy: "quota", Reason: "maxPods exceeded: 4 > 3", Usage: Usage{Pods: 3, CPU: "1500m", Memory: "1Gi"}, Decision: DecisionDenied})
+	l.Record(Event{Source: SourceController, Namespace: "ns1", Pod: "web-2", Decision: DecisionEvicted})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d: %s", len(lines), buf.String())
+	}
+	var got Event
+	if err := json.Unmarshal(lines[0], &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Timestamp.IsZero() || got.Decision != DecisionDenied || got.Usage.CPU != "1500m" {
+		t.Fatalf("unexpected event: %+v", got)
+	}
+
+	// a nil logger must be safe to use
+	var disabled *Logger
+	disabled.Record(got)
+	if err := disabled.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type flakyExporter struct {
+	failures int
+	batches  chan []Event
+}
+
+func (f *flakyExporter) Name() string { return "flaky" }
+
+func (f *flakyExporter) Export(_ context.Context, events []Event) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("sink unavailable")
+	}
+	f.batches <- append([]Event(nil), events...)
+	return nil
+}
+
+func TestLogger_ExporterBatchesAndRetries(t *testing.T) {
+	exp := &flakyExporter{failures: 1, batches: make(chan []Event, 4)}
+	l := New(nil)
+	l.AddExporter(exp, BatchOptions{MaxBatch: 2, FlushInterval: time.Hour})
+	l.sinks[0].backoff = time.Millisecond
+
+	for _, pod := range []string{"a", "b", "c"} {
+		l.Record(Event{Namespace: "ns1", Pod: pod, Decision: DecisionEvicted})
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(exp.batches)
+
+	var sizes []int
+	for b := range exp.batches {
+		sizes = append(sizes, len(b))
+	}
+	if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+		t.Fatalf("expected a full batch retried once then the remainder on close, got %v", sizes)
+	}
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"flag"
+	"strings"
+)
+
+// Config selects where audit events are written and exported.
+type Config struct {
+	// Path is a file to append JSON lines to, "-" for stdout.
+	Path string
+	// HTTPURL receives batches as JSON arrays.
+	HTTPURL string
+	// KafkaBrokers is a comma separated broker list; KafkaTopic must be set with it.
+	KafkaBrokers string
+	KafkaTopic   string
+	Batch        BatchOptions
+}
+
+// AddFlags registers the audit flags shared by the controller and the webhook.
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Path, "audit-log", "", "file to append audit records to, - for stdout (disabled when empty)")
+	fs.StringVar(&c.HTTPURL, "audit-http-url", "", "URL to POST batches of audit records to")
+	fs.StringVar(&c.KafkaBrokers, "audit-kafka-brokers", "", "comma separated Kafka brokers to publish audit records to")
+	fs.StringVar(&c.KafkaTopic, "audit-kafka-topic", "rqe-audit", "Kafka topic for audit records")
+	fs.IntVar(&c.Batch.MaxBatch, "audit-batch-size", DefaultBatchOptions.MaxBatch, "maximum audit records per exported batch")
+	fs.DurationVar(&c.Batch.FlushInterval, "audit-flush-interval", DefaultBatchOptions.FlushInterval, "how often queued audit records are exported")
+	fs.IntVar(&c.Batch.MaxRetries, "audit-max-retries", DefaultBatchOptions.MaxRetries, "retries for a failed export before the batch is dropped")
+}
+
+// NewFromConfig builds the Logger described by c. It returns nil, disabling auditing, when
+// nothing is configured.
+func NewFromConfig(c Config) (*Logger, error) {
+	if c.Path == "" && c.HTTPURL == "" && c.KafkaBrokers == "" {
+		return nil, nil
+	}
+
+	l := New(nil)
+	if c.Path != "" {
+		var err error
+		if l, err = Open(c.Path); err != nil {
+			return nil, err
+		}
+	}
+	if c.HTTPURL != "" {
+		l.AddExporter(NewHTTPExporter(c.HTTPURL), c.Batch)
+	}
+	if c.KafkaBrokers != "" {
+		l.AddExporter(NewKafkaExporter(strings.Split(c.KafkaBrokers, ","), c.KafkaTopic), c.Batch)
+	}
+	return l, nil
+}
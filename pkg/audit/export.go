@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// Exporter ships batches of audit events to an external system.
+type Exporter interface {
+	// Name identifies the exporter in logs.
+	Name() string
+	Export(ctx context.Context, events []Event) error
+}
+
+// BatchOptions controls how events are grouped and retried on their way to an Exporter.
+type BatchOptions struct {
+	// MaxBatch flushes as soon as this many events are queued.
+	MaxBatch int
+	// FlushInterval flushes whatever is queued at least this often.
+	FlushInterval time.Duration
+	// MaxRetries is how many times a failed batch is retried before it is dropped.
+	MaxRetries int
+	// QueueSize bounds the events waiting to be batched; events beyond it are dropped so a slow
+	// sink never blocks admission or enforcement.
+	QueueSize int
+}
+
+// DefaultBatchOptions are used for zero fields of BatchOptions.
+var DefaultBatchOptions = BatchOptions{
+	MaxBatch:      100,
+	FlushInterval: 5 * time.Second,
+	MaxRetries:    5,
+	QueueSize:     10000,
+}
+
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.MaxBatch <= 0 {
+		o.MaxBatch = DefaultBatchOptions.MaxBatch
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = DefaultBatchOptions.FlushInterval
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	} else if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultBatchOptions.MaxRetries
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultBatchOptions.QueueSize
+	}
+	return o
+}
+
+// batcher queues events for one exporter and flushes them from a single goroutine.
+type batcher struct {
+	exp     Exporter
+	opts    BatchOptions
+	queue   chan Event
+	done    chan struct{}
+	backoff time.Duration
+}
+
+func newBatcher(exp Exporter, opts BatchOptions) *batcher {
+	b := &batcher{
+		exp:     exp,
+		opts:    opts.withDefaults(),
+		done:    make(chan struct{}),
+		backoff: time.Second,
+	}
+	b.queue = make(chan Event, b.opts.QueueSize)
+	go b.run()
+	return b
+}
+
+func (b *batcher) add(e Event) {
+	select {
+	case b.queue <- e:
+	default:
+		logging.L().WithName("audit").Info("Export queue full, dropping event", "exporter", b.exp.Name(), "namespace", e.Namespace, "pod", e.Pod, "policy", e.Policy)
+	}
+}
+
+// close flushes what is queued and waits for the last export to finish.
+func (b *batcher) close() {
+	close(b.queue)
+	<-b.done
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, b.opts.MaxBatch)
+	for {
+		select {
+		case e, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= b.opts.MaxBatch {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			b.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush exports batch, retrying with exponential backoff.
+func (b *batcher) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	delay := b.backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := b.exp.Export(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= b.opts.MaxRetries {
+			logging.L().WithName("audit").Error(err, "Dropping events after repeated export failures", "exporter", b.exp.Name(), "events", len(batch), "attempts", attempt+1)
+			return
+		}
+		logging.L().WithName("audit").Error(err, "Export failed, retrying", "exporter", b.exp.Name(), "attempt", attempt+1)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
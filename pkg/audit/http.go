@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPExporter POSTs each batch as a JSON array to a URL, e.g. a SIEM HTTP collector.
+type HTTPExporter struct {
+	URL    string
+	Client *http.Client
+	// Header is added to every request, typically for an Authorization token.
+	Header http.Header
+}
+
+// NewHTTPExporter returns an exporter posting to url.
+func NewHTTPExporter(url string) *HTTPExporter {
+	return &HTTPExporter{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (h *HTTPExporter) Name() string { return "http" }
+
+func (h *HTTPExporter) Export(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
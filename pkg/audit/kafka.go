@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaExporter publishes one message per event, keyed by namespace so a namespace's events
+// stay ordered within a partition.
+type KafkaExporter struct {
+	Writer *kafka.Writer
+}
+
+// NewKafkaExporter returns an exporter writing to topic on brokers.
+func NewKafkaExporter(brokers []string, topic string) *KafkaExporter {
+	return &KafkaExporter{Writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// batching and retries are handled by the audit batcher
+		BatchTimeout: 10 * time.Millisecond,
+		MaxAttempts:  1,
+	}}
+}
+
+func (k *KafkaExporter) Name() string { return "kafka" }
+
+func (k *KafkaExporter) Export(ctx context.Context, events []Event) error {
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{Key: []byte(e.Namespace), Value: value, Time: e.Timestamp})
+	}
+	return k.Writer.WriteMessages(ctx, msgs...)
+}
+
+// Close closes the underlying writer.
+func (k *KafkaExporter) Close() error {
+	return k.Writer.Close()
+}
@@ -0,0 +1,262 @@
+// Package bench measures how fast the webhook decides pod admissions, in process or against a
+// running webhook, so that regressions in the evaluation show up as numbers.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+// Target answers one AdmissionReview, encoded as JSON.
+type Target func(ctx context.Context, review []byte) (*admissionv1.AdmissionResponse, error)
+
+// Options say how hard and how long Run loads its target.
+type Options struct {
+	// Concurrency is how many requests are in flight at once.
+	Concurrency int
+	// Requests is how many requests are sent in all.
+	Requests int
+	// Namespace is the namespace of the pods admitted.
+	Namespace string
+	// MeasureAllocs reports the heap allocations per request. They are counted for the whole
+	// process, so they are only meaningful for an in-process target.
+	MeasureAllocs bool
+}
+
+// Result sums up a run.
+type Result struct {
+	Requests int `json:"requests"`
+	Allowed  int `json:"allowed"`
+	Denied   int `json:"denied"`
+	Errors   int `json:"errors"`
+	// FirstError is the first error a request ended in, if any.
+	FirstError string        `json:"firstError,omitempty"`
+	Elapsed    time.Duration `json:"elapsed"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	// AllocsPerRequest and BytesPerRequest are only set with Options.MeasureAllocs.
+	AllocsPerRequest float64 `json:"allocsPerRequest,omitempty"`
+	BytesPerRequest  float64 `json:"bytesPerRequest,omitempty"`
+}
+
+// Throughput is the requests answered per second.
+func (r Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// Run sends opts.Requests pod creations to target, opts.Concurrency at a time, and measures how
+// long each takes to be answered.
+func Run(ctx context.Context, target Target, opts Options) (Result, error) {
+	if opts.Concurrency < 1 || opts.Requests < 1 {
+		return Result{}, errors.New("concurrency and requests must be positive")
+	}
+	reviews := make([][]byte, opts.Requests)
+	for i := range reviews {
+		review, err := json.Marshal(Review(opts.Namespace, i))
+		if err != nil {
+			return Result{}, err
+		}
+		reviews[i] = review
+	}
+
+	latencies := make([]time.Duration, opts.Requests)
+	var allowed, denied, failed atomic.Int64
+	var firstErr error
+	var errOnce sync.Once
+	var next atomic.Int64
+	var before, after runtime.MemStats
+	if opts.MeasureAllocs {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range min(opts.Concurrency, opts.Requests) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= opts.Requests || ctx.Err() != nil {
+					return
+				}
+				t := time.Now()
+				resp, err := target(ctx, reviews[i])
+				latencies[i] = time.Since(t)
+				switch {
+				case err != nil:
+					failed.Add(1)
+					errOnce.Do(func() { firstErr = err })
+				case resp.Allowed:
+					allowed.Add(1)
+				default:
+					denied.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	if opts.MeasureAllocs {
+		runtime.ReadMemStats(&after)
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	slices.Sort(latencies)
+	r := Result{
+		Requests: opts.Requests,
+		Allowed:  int(allowed.Load()),
+		Denied:   int(denied.Load()),
+		Errors:   int(failed.Load()),
+		Elapsed:  elapsed,
+		P50:      percentile(latencies, 50),
+		P95:      percentile(latencies, 95),
+		P99:      percentile(latencies, 99),
+		Max:      latencies[len(latencies)-1],
+	}
+	if firstErr != nil {
+		r.FirstError = firstErr.Error()
+	}
+	if opts.MeasureAllocs {
+		r.AllocsPerRequest = float64(after.Mallocs-before.Mallocs) / float64(opts.Requests)
+		r.BytesPerRequest = float64(after.TotalAlloc-before.TotalAlloc) / float64(opts.Requests)
+	}
+	return r, nil
+}
+
+// percentile returns the p-th percentile of sorted, by the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Review returns the i-th synthetic AdmissionReview, the creation of a pod in namespace
+// requesting 100m CPU and 128Mi of memory.
+func Review(namespace string, i int) *admissionv1.AdmissionReview {
+	pod := benchPod(namespace, fmt.Sprintf("bench-%d", i))
+	raw, _ := json.Marshal(pod)
+	return &admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID(fmt.Sprintf("bench-%d", i)),
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+			Namespace: namespace,
+			Name:      pod.Name,
+			Operation: admissionv1.Create,
+			Object:    k8sruntime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func benchPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": "bench"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Image: "registry.k8s.io/pause:3.10",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// Handler targets the webhook's pod admission handler in process.
+func Handler(h http.HandlerFunc) Target {
+	return func(ctx context.Context, review []byte) (*admissionv1.AdmissionResponse, error) {
+		r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/validate", bytes.NewReader(review))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h(w, r)
+		return decodeResponse(w.Code, w.Body)
+	}
+}
+
+// URL targets a running webhook's pod admission endpoint, e.g. https://localhost:8443/validate.
+func URL(client *http.Client, url string) Target {
+	return func(ctx context.Context, review []byte) (*admissionv1.AdmissionResponse, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(review))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(r)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return decodeResponse(resp.StatusCode, resp.Body)
+	}
+}
+
+func decodeResponse(code int, body io.Reader) (*admissionv1.AdmissionResponse, error) {
+	if code != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(body, 512))
+		return nil, fmt.Errorf("webhook answered %d: %s", code, bytes.TrimSpace(msg))
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(body).Decode(&review); err != nil {
+		return nil, fmt.Errorf("decode admission review: %w", err)
+	}
+	if review.Response == nil {
+		return nil, errors.New("admission review without a response")
+	}
+	return review.Response, nil
+}
+
+// NewServer returns a webhook server for namespace, which already runs pods like those Review
+// creates and has policy. Its clientset is a fake, so the evaluation is measured without the
+// API server's latency.
+func NewServer(namespace string, pods int, policy *v1beta1.ResourceQuotaPolicy) *webhook.WebhookServer {
+	objs := make([]k8sruntime.Object, 0, pods)
+	for i := range pods {
+		objs = append(objs, benchPod(namespace, fmt.Sprintf("running-%d", i)))
+	}
+	policy = policy.DeepCopy()
+	policy.Namespace = namespace
+	return webhook.NewWebhookServerWithInformer(fake.NewSimpleClientset(objs...), staticCache{policy})
+}
+
+// staticCache serves one policy to every namespace.
+type staticCache struct {
+	policy *v1beta1.ResourceQuotaPolicy
+}
+
+func (c staticCache) Get(string) (*v1beta1.ResourceQuotaPolicy, bool) { return c.policy, true }
+func (staticCache) Invalidate(string)                                 {}
+func (staticCache) Run(<-chan struct{})                               {}
+func (staticCache) WaitForReady(time.Duration) error                  { return nil }
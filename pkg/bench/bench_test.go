@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	for p, want := range map[int]time.Duration{50: 50 * time.Millisecond, 95: 95 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%d = %s, want %s", p, got, want)
+		}
+	}
+	if got := percentile([]time.Duration{time.Second}, 50); got != time.Second {
+		t.Errorf("p50 of one = %s, want 1s", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxPods int32
+		allowed bool
+	}{
+		{"room for every pod", 11, true},
+		{"namespace full", 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &v1beta1.ResourceQuotaPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "bench"},
+				Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: tt.maxPods},
+			}
+			server := NewServer("bench", 10, policy)
+			r, err := Run(context.Background(), Handler(server.HandleValidatePods), Options{
+				Concurrency: 4, Requests: 40, Namespace: "bench", MeasureAllocs: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Errors != 0 {
+				t.Fatalf("%d requests failed: %s", r.Errors, r.FirstError)
+			}
+			if tt.allowed && r.Allowed != 40 || !tt.allowed && r.Denied != 40 {
+				t.Errorf("allowed %d, denied %d of 40", r.Allowed, r.Denied)
+			}
+			if r.P50 <= 0 || r.P50 > r.P95 || r.P95 > r.P99 || r.P99 > r.Max {
+				t.Errorf("percentiles out of order: p50 %s, p95 %s, p99 %s, max %s", r.P50, r.P95, r.P99, r.Max)
+			}
+			if r.AllocsPerRequest <= 0 || r.BytesPerRequest <= 0 {
+				t.Errorf("allocations not measured: %+v", r)
+			}
+		})
+	}
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	if _, err := Run(context.Background(), nil, Options{Concurrency: 0, Requests: 1}); err == nil {
+		t.Error("zero concurrency accepted")
+	}
+}
@@ -0,0 +1,176 @@
+// Package capacity tracks the allocatable resources of the cluster's nodes, which the
+// clusterShare of a policy is resolved against.
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resources are the allocatable resources tracked, the ones a ClusterShare can set.
+var resources = []corev1.ResourceName{corev1.ResourcePods, corev1.ResourceCPU, corev1.ResourceMemory}
+
+// Allocatable sums the allocatable pods, CPU and memory of nodes. Cordoned nodes take no new pods
+// and are left out.
+func Allocatable(nodes []*corev1.Node) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, node := range nodes {
+		add(total, nodeAllocatable(node))
+	}
+	return total
+}
+
+// add adds list to total.
+func add(total, list corev1.ResourceList) {
+	for name, q := range list {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+// List sums the allocatable resources of the nodes of client, for one-off callers that don't
+// keep a Tracker.
+func List(ctx context.Context, client kubernetes.Interface) (corev1.ResourceList, error) {
+	list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	nodes := make([]*corev1.Node, len(list.Items))
+	for i := range list.Items {
+		nodes[i] = &list.Items[i]
+	}
+	return Allocatable(nodes), nil
+}
+
+// nodeAllocatable returns what node adds to the cluster's allocatable resources.
+func nodeAllocatable(node *corev1.Node) corev1.ResourceList {
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	out := corev1.ResourceList{}
+	for _, name := range resources {
+		if q, ok := node.Status.Allocatable[name]; ok {
+			out[name] = q.DeepCopy()
+		}
+	}
+	return out
+}
+
+// Tracker keeps the cluster's allocatable resources up to date from a node informer. It is safe
+// for concurrent use; a nil Tracker knows no capacity.
+type Tracker struct {
+	factory      informers.SharedInformerFactory
+	registration cache.ResourceEventHandlerRegistration
+
+	mu       sync.RWMutex
+	nodes    map[string]corev1.ResourceList
+	total    corev1.ResourceList
+	handlers []func()
+}
+
+// NewTracker returns a tracker watching the nodes of client. Start it with Run.
+func NewTracker(client kubernetes.Interface, resync time.Duration) (*Tracker, error) {
+	t := &Tracker{
+		factory: informers.NewSharedInformerFactory(client, resync),
+		nodes:   make(map[string]corev1.ResourceList),
+	}
+	registration, err := t.factory.Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { t.update(obj, false) },
+		UpdateFunc: func(_, newObj interface{}) { t.update(newObj, false) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			t.update(obj, true)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watch nodes: %w", err)
+	}
+	t.registration = registration
+	return t, nil
+}
+
+// AddHandler calls handler whenever the cluster's allocatable resources change. It must be
+// called before Run.
+func (t *Tracker) AddHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+// Run watches the nodes and blocks until ctx is done.
+func (t *Tracker) Run(ctx context.Context) {
+	t.factory.Start(ctx.Done())
+	<-ctx.Done()
+	t.factory.Shutdown()
+}
+
+// HasSynced reports whether every node has been counted once.
+func (t *Tracker) HasSynced() bool {
+	return t.registration.HasSynced()
+}
+
+// Allocatable returns the allocatable pods, CPU and memory of the cluster, or nil for a nil
+// Tracker. The caller may modify it.
+func (t *Tracker) Allocatable() corev1.ResourceList {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.total.DeepCopy()
+}
+
+// Nodes returns the nodes of the cluster, shared with the informer's cache, so they must not be
+// modified.
+func (t *Tracker) Nodes() ([]*corev1.Node, error) {
+	return t.factory.Core().V1().Nodes().Lister().List(labels.Everything())
+}
+
+// update counts the node obj, or stops counting it when it was deleted, and notifies the handlers
+// if the total changed. Most node updates are status heartbeats that change nothing.
+func (t *Tracker) update(obj interface{}, deleted bool) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	var allocatable corev1.ResourceList
+	if !deleted {
+		allocatable = nodeAllocatable(node)
+	}
+
+	t.mu.Lock()
+	old, known := t.nodes[node.Name]
+	if known == !deleted && equality.Semantic.DeepEqual(old, allocatable) {
+		t.mu.Unlock()
+		return
+	}
+	if deleted {
+		delete(t.nodes, node.Name)
+	} else {
+		t.nodes[node.Name] = allocatable
+	}
+	total := corev1.ResourceList{}
+	for _, list := range t.nodes {
+		add(total, list)
+	}
+	t.total = total
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler()
+	}
+}
@@ -0,0 +1,83 @@
+package capacity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func node(name, cpu, memory string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourcePods:   resource.MustParse("110"),
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+			"example.com/gpu":     resource.MustParse("1"),
+		}},
+	}
+}
+
+func TestAllocatable(t *testing.T) {
+	got := Allocatable([]*corev1.Node{
+		node("a", "3920m", "15Gi", false),
+		node("b", "3920m", "15Gi", false),
+		node("cordoned", "8", "30Gi", true),
+	})
+	if len(got) != 3 || got.Pods().String() != "220" || got.Cpu().String() != "7840m" || got.Memory().String() != "30Gi" {
+		t.Fatalf("unexpected allocatable %v", got)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	client := fake.NewSimpleClientset(node("a", "4", "16Gi", false))
+	tracker, err := NewTracker(client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 10)
+	tracker.AddHandler(func() { changed <- struct{}{} })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	cpu := func() string {
+		allocatable := tracker.Allocatable()
+		return allocatable.Cpu().String()
+	}
+	wait := func(want string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for cpu() != want {
+			select {
+			case <-changed:
+			case <-deadline:
+				t.Fatalf("cpu = %s, want %s", cpu(), want)
+			}
+		}
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), tracker.HasSynced) {
+		t.Fatal("tracker not synced")
+	}
+	wait("4")
+	if _, err := client.CoreV1().Nodes().Create(ctx, node("b", "2", "8Gi", false), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	wait("6")
+	if err := client.CoreV1().Nodes().Delete(ctx, "a", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	wait("2")
+
+	var nilTracker *Tracker
+	if nilTracker.Allocatable() != nil {
+		t.Fatal("nil tracker has capacity")
+	}
+}
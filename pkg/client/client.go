@@ -2,25 +2,38 @@ package client
 
 import (
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/version"
 )
 
 func PrepareConfig() (*rest.Config, error) {
 	var kubeconfig *string
-	if home := homeDir(); home != "" {
-		kubeconfig = flag.String("kubeconfig", filepath.Join(home, ".kube", "config"), "(optional) kubeconfig file")
+	if home := DefaultKubeconfig(); home != "" {
+		kubeconfig = flag.String("kubeconfig", home, "(optional) kubeconfig file")
 	} else {
 		kubeconfig = flag.String("kubeconfig", "", "kubeconfig file")
 	}
 	flag.Parse()
 
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	return BuildConfig(*kubeconfig)
+}
+
+// BuildConfig loads the given kubeconfig, falling back to the in-cluster config.
+func BuildConfig(kubeconfig string) (*rest.Config, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
 		config, err = rest.InClusterConfig()
 		if err != nil {
@@ -30,7 +43,71 @@ func PrepareConfig() (*rest.Config, error) {
 	return config, nil
 }
 
+// Components of the module, which their clients name in their User-Agent.
+const (
+	ComponentController = "controller"
+	ComponentWebhook    = "webhook"
+	ComponentCLI        = "cli"
+)
+
+// UserAgent is the User-Agent of the clients of component, e.g.
+// "resource-quota-enforcer/v1.2.3 (controller)", so the API server's audit log and request
+// metrics tell its requests apart from those of other clients.
+func UserAgent(component string) string {
+	return fmt.Sprintf("resource-quota-enforcer/%s (%s)", version.Get(), component)
+}
+
+// SetUserAgent stamps config, and every client built from it, with the UserAgent of component.
+func SetUserAgent(config *rest.Config, component string) {
+	config.UserAgent = UserAgent(component)
+}
+
+// ContextConfig loads the config of the named context of the given kubeconfig, which falls back
+// to the default loading rules when empty.
+func ContextConfig(kubeconfig, context string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+// RateLimits are the client-side limits on requests to the API server, shared by every client
+// built from a config they are applied to.
+type RateLimits struct {
+	// QPS is the sustained rate of requests per second.
+	QPS float64
+	// Burst is how many requests may go above QPS at once.
+	Burst int
+}
+
+// AddFlags registers -kube-api-qps and -kube-api-burst, defaulting to client-go's limits.
+func (l *RateLimits) AddFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&l.QPS, "kube-api-qps", float64(rest.DefaultQPS), "requests per second to the API server the client sustains before throttling itself")
+	fs.IntVar(&l.Burst, "kube-api-burst", rest.DefaultBurst, "requests to the API server the client may send at once above -kube-api-qps")
+}
+
+// Apply sets the limits on config.
+func (l RateLimits) Apply(config *rest.Config) {
+	config.QPS = float32(l.QPS)
+	config.Burst = l.Burst
+}
+
+// DefaultKubeconfig returns ~/.kube/config, or "" when no home directory is known.
+func DefaultKubeconfig() string {
+	if home := homeDir(); home != "" {
+		return filepath.Join(home, ".kube", "config")
+	}
+	return ""
+}
+
+// GetKubernetesClient returns a clientset for the built-in APIs of config. It talks protobuf,
+// which is cheaper to encode and decode than JSON for the pod and namespace lists and watches the
+// informers keep open. config itself is left alone, since clients of custom resources can only
+// use JSON.
 func GetKubernetesClient(config *rest.Config) (*kubernetes.Clientset, error) {
+	config = rest.CopyConfig(config)
+	config.ContentType = runtime.ContentTypeProtobuf
+	config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
 	clientSet, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -42,6 +119,12 @@ func DynamicClient(config *rest.Config) (dynamic.Interface, error) {
 	return dynamic.NewForConfig(config)
 }
 
+// MetadataClient returns a client for the metadata of objects of any resource, for watches that
+// need no more, which are cheaper to keep.
+func MetadataClient(config *rest.Config) (metadata.Interface, error) {
+	return metadata.NewForConfig(config)
+}
+
 func homeDir() string {
 	if h := os.Getenv("HOME"); h != "" {
 		return h
@@ -49,3 +132,30 @@ func homeDir() string {
 
 	return os.Getenv("USERPROFILE")
 }
+
+// PolicyCRDInstalled reports whether the API server serves v1beta1 ResourceQuotaPolicies.
+func PolicyCRDInstalled(client discovery.DiscoveryInterface) (bool, error) {
+	return served(client, "resourcequotapolicies")
+}
+
+// ExemptionCRDInstalled reports whether the API server serves v1beta1 QuotaExemptions.
+func ExemptionCRDInstalled(client discovery.DiscoveryInterface) (bool, error) {
+	return served(client, "quotaexemptions")
+}
+
+// served reports whether the API server serves the v1beta1 resource of the platform group.
+func served(client discovery.DiscoveryInterface, resource string) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(v1beta1.SchemeGroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,296 @@
+// Package config reads the file of -config, a YAML file of flag values for the controller and
+// the webhook, and reloads it while they run.
+//
+// Keys are flag names without the leading dash. Top-level keys are read by both binaries, which
+// skip the ones that aren't flags of theirs; the keys of the controller and webhook sections are
+// read by that binary only, take precedence over top-level keys, and must be flags of it. Lists
+// are joined with commas, and maps with commas between key=value pairs. Flags set on the command
+// line take precedence over the file.
+package config
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+)
+
+// Sections of the file, named after the binary that reads them.
+const (
+	SectionController = "controller"
+	SectionWebhook    = "webhook"
+)
+
+// DefaultInterval is how often the file is checked for changes.
+const DefaultInterval = 30 * time.Second
+
+// Options are the flags naming the file, which it can't set itself.
+type Options struct {
+	// Path of the file; empty reads none.
+	Path string
+	// Interval is how often the file is checked for changes.
+	Interval time.Duration
+}
+
+// AddFlags registers -config and -config-reload-interval.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Path, "config", "", "YAML file of flag values, checked for changes while running; flags on the command line take precedence (none when empty)")
+	fs.DurationVar(&o.Interval, "config-reload-interval", DefaultInterval, "how often the -config file is checked for changes")
+}
+
+// reserved are the flags read before the file, which it can't set.
+var reserved = sets.New("config", "config-reload-interval", "kubeconfig")
+
+// File is a config file applied to the flags of one binary.
+type File struct {
+	path    string
+	section string
+	fs      *flag.FlagSet
+
+	// explicit are the flags set on the command line, which the file leaves alone
+	explicit sets.Set[string]
+	// live are the flags a reload sets while the binary runs, with what to call once they change
+	live map[string]func()
+	// content is the file as last applied in full
+	content []byte
+	// applied are the values the file set, by flag
+	applied map[string]string
+}
+
+// New returns the file at path for the flags of fs, read with the keys of section.
+func New(fs *flag.FlagSet, path, section string) *File {
+	return &File{path: path, section: section, fs: fs, live: map[string]func(){}, applied: map[string]string{}}
+}
+
+// Live makes reloads set the flag name while the binary runs, calling onChange, which may be
+// nil, after it changes. The flag's Value must be safe to set while it is read. Changes to other
+// flags are reported as needing a restart.
+func (f *File) Live(name string, onChange func()) {
+	if onChange == nil {
+		onChange = func() {}
+	}
+	f.live[name] = onChange
+}
+
+// Load sets the flags the file holds values for, except those set on the command line. It is
+// called once fs is parsed; an unreadable file, an unknown key or an invalid value is an error.
+func (f *File) Load() error {
+	f.explicit = sets.New[string]()
+	f.fs.Visit(func(fl *flag.Flag) { f.explicit.Insert(fl.Name) })
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	values, err := f.parse(data)
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(values) {
+		if err := f.fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("%s: invalid value %q for %s: %w", f.path, values[name], name, err)
+		}
+		f.applied[name] = values[name]
+	}
+	f.content = data
+	metrics.ConfigLastReloadSuccessful.Set(1)
+	metrics.ConfigLastReloadSuccessTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// Run reloads the file every interval until ctx is done. A file that can't be read or parsed
+// keeps the values last applied.
+func (f *File) Run(ctx context.Context, interval time.Duration) {
+	logger := logging.L().WithName("config").WithValues("file", f.path)
+	wait.UntilWithContext(ctx, func(context.Context) {
+		if err := f.reload(); err != nil {
+			logger.Error(err, "Failed to reload config file, keeping the values last applied")
+			metrics.ConfigLastReloadSuccessful.Set(0)
+			return
+		}
+		metrics.ConfigLastReloadSuccessful.Set(1)
+	}, interval)
+}
+
+// reload applies the changes to live flags since the file was last applied, and reports changes
+// to the others as needing a restart. A key removed from the file sets its flag back to its
+// default.
+func (f *File) reload() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, f.content) {
+		return nil
+	}
+	values, err := f.parse(data)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.L().WithName("config").WithValues("file", f.path)
+	names := sets.KeySet(values).Union(sets.KeySet(f.applied))
+	var restart, errs []string
+	var changed []func()
+	for _, name := range sets.List(names) {
+		value, inFile := values[name]
+		if !inFile {
+			value = f.fs.Lookup(name).DefValue
+		}
+		current, applied := f.applied[name]
+		if !applied {
+			current = f.fs.Lookup(name).Value.String()
+		}
+		if value == current {
+			continue
+		}
+		onChange, live := f.live[name]
+		if !live {
+			restart = append(restart, name)
+			continue
+		}
+		if err := f.fs.Set(name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q for %s: %v", value, name, err))
+			continue
+		}
+		logger.Info("Applied config change", "flag", name, "value", value)
+		if inFile {
+			f.applied[name] = value
+		} else {
+			delete(f.applied, name)
+		}
+		changed = append(changed, onChange)
+	}
+	for _, onChange := range changed {
+		onChange()
+	}
+
+	if len(restart) > 0 {
+		logger.Info("Config file changes flags that only take effect after a restart", "flags", restart)
+		metrics.ConfigRestartRequired.Set(1)
+	} else {
+		metrics.ConfigRestartRequired.Set(0)
+	}
+	if len(errs) > 0 {
+		// left as last applied, the file is retried until it is fixed
+		return fmt.Errorf("%s: %s", f.path, strings.Join(errs, "; "))
+	}
+	f.content = data
+	metrics.ConfigLastReloadSuccessTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// parse returns the flag values data holds for f's section, leaving out flags set on the
+// command line.
+func (f *File) parse(data []byte) (map[string]string, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	values := map[string]string{}
+	set := func(key string, v any) error {
+		if f.explicit.Has(key) {
+			return nil
+		}
+		value, err := flagValue(v)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", f.path, key, err)
+		}
+		values[key] = value
+		return nil
+	}
+
+	for _, key := range sortedKeys(doc) {
+		if key == SectionController || key == SectionWebhook {
+			continue
+		}
+		if reserved.Has(key) {
+			return nil, fmt.Errorf("%s: %s can only be set on the command line", f.path, key)
+		}
+		if f.fs.Lookup(key) == nil {
+			// a flag of the other binary
+			logging.L().WithName("config").V(logging.Debug).Info("Skipping config key that is not a flag", "file", f.path, "key", key)
+			continue
+		}
+		if err := set(key, doc[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	section, ok := doc[f.section].(map[string]any)
+	if !ok && doc[f.section] != nil {
+		return nil, fmt.Errorf("%s: %s must be a map of flag values", f.path, f.section)
+	}
+	for _, key := range sortedKeys(section) {
+		if reserved.Has(key) {
+			return nil, fmt.Errorf("%s: %s can only be set on the command line", f.path, key)
+		}
+		if f.fs.Lookup(key) == nil {
+			return nil, fmt.Errorf("%s: %s: unknown flag %q", f.path, f.section, key)
+		}
+		if err := set(key, section[key]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// flagValue returns v, as decoded from YAML, as the string its flag is set with.
+func flagValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case map[string]any:
+		// e.g. feature-gates, as Name=value pairs
+		pairs := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			s, err := flagValue(v[key])
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+"="+s)
+		}
+		return strings.Join(pairs, ","), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := flagValue(item)
+			if err != nil {
+				return "", err
+			}
+			if _, isList := item.([]any); isList {
+				return "", fmt.Errorf("lists can't be nested")
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("%T is not a flag value", v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+)
+
+type testFlags struct {
+	fs          *flag.FlagSet
+	workers     *int
+	listen      *string
+	enforcement handlers.EnforcementMode
+	exempt      handlers.ExemptNamespaces
+}
+
+func newTestFlags(t *testing.T, args ...string) *testFlags {
+	t.Helper()
+	f := &testFlags{fs: flag.NewFlagSet("test", flag.ContinueOnError)}
+	f.workers = f.fs.Int("workers", 5, "")
+	f.listen = f.fs.String("metrics-addr", ":8080", "")
+	f.fs.Var(&f.enforcement, "enforcement", "")
+	f.exempt.AddFlags(f.fs)
+	if err := f.fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, `
+workers: 3
+metrics-addr: ":9000"
+listen: ":8443" # a webhook flag
+exempt-namespaces: [kube-system, monitoring]
+controller:
+  workers: 8
+  enforcement: dry-run
+webhook:
+  not-a-controller-flag: true
+`)
+	f := newTestFlags(t, "-metrics-addr=:7000")
+
+	if err := New(f.fs, path, SectionController).Load(); err != nil {
+		t.Fatal(err)
+	}
+	// the section wins over top-level keys, and the command line over both
+	if *f.workers != 8 || *f.listen != ":7000" || !f.enforcement.DryRun() {
+		t.Fatalf("unexpected flags: workers=%d metrics-addr=%s enforcement=%s", *f.workers, *f.listen, f.enforcement.String())
+	}
+	if !f.exempt.Has("kube-system") || !f.exempt.Has("monitoring") || f.exempt.Has("default") {
+		t.Fatalf("unexpected exempt namespaces %q", f.exempt.String())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown section key": "controller:\n  wrkers: 3\n",
+		"invalid value":       "enforcement: sometimes\n",
+		"reserved key":        "config: other.yaml\n",
+		"not a map":           "controller: [workers]\n",
+		"not yaml":            "workers: [3\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, path, content)
+			if err := New(newTestFlags(t).fs, path, SectionController).Load(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "workers: 3\nexempt-namespaces: kube-system\n")
+	f := newTestFlags(t)
+	file := New(f.fs, path, SectionController)
+	changes := 0
+	file.Live("exempt-namespaces", func() { changes++ })
+	file.Live("enforcement", nil)
+	if err := file.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unchanged file changes nothing
+	if err := file.reload(); err != nil || changes != 0 {
+		t.Fatalf("reload of an unchanged file: err=%v changes=%d", err, changes)
+	}
+
+	// live flags change in place; others wait for a restart
+	writeFile(t, path, "workers: 4\nexempt-namespaces: [kube-system, monitoring]\nenforcement: dry-run\n")
+	if err := file.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if !f.exempt.Has("monitoring") || !f.enforcement.DryRun() || changes != 1 {
+		t.Fatalf("live flags not applied: exempt=%q enforcement=%s changes=%d", f.exempt.String(), f.enforcement.String(), changes)
+	}
+	if *f.workers != 3 || testutil.ToFloat64(metrics.ConfigRestartRequired) != 1 {
+		t.Fatalf("expected workers to wait for a restart, got workers=%d restart required=%v", *f.workers, testutil.ToFloat64(metrics.ConfigRestartRequired))
+	}
+
+	// an invalid value is reported and leaves the flag as it was
+	writeFile(t, path, "workers: 3\nexempt-namespaces: kube-system\nenforcement: sometimes\n")
+	err := file.reload()
+	if err == nil || !strings.Contains(err.Error(), "enforcement") {
+		t.Fatalf("expected an error for enforcement, got %v", err)
+	}
+	if !f.enforcement.DryRun() || f.exempt.Has("monitoring") {
+		t.Fatalf("unexpected flags after a failed reload: exempt=%q enforcement=%s", f.exempt.String(), f.enforcement.String())
+	}
+	if testutil.ToFloat64(metrics.ConfigRestartRequired) != 0 {
+		t.Fatal("expected no restart to be required once workers is back to its value")
+	}
+
+	// a key removed from the file goes back to its default
+	writeFile(t, path, "workers: 3\n")
+	if err := file.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if f.enforcement.DryRun() || f.exempt.String() != "" {
+		t.Fatalf("expected defaults once removed, got exempt=%q enforcement=%s", f.exempt.String(), f.enforcement.String())
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/client-go/util/retry"
+)
+
+// FieldManager owns the policy status and the namespace labels and annotations the controller
+// writes with server-side apply. A field another manager owns is never overwritten: the write
+// fails with a conflict instead.
+const FieldManager = "rqe-controller"
+
+// legacyFieldManagers wrote the same fields with updates and merge patches before the controller
+// used server-side apply: the managers client-go derives from the binary name of the image and
+// of `go run ./cmd`.
+var legacyFieldManagers = sets.New("rqe", "main")
+
+// adoptLegacyFields hands the fields earlier versions of the controller own on obj over to
+// FieldManager, so applies can remove them. Without it, a field an update once set, say
+// status.violation, would stay set after the apply that leaves it out. patch sends the JSON
+// patch; nothing is sent when there is nothing to hand over.
+func adoptLegacyFields(obj runtime.Object, patch func(body []byte) error, opts ...csaupgrade.Option) error {
+	body, err := csaupgrade.UpgradeManagedFieldsPatch(obj, legacyFieldManagers, FieldManager, opts...)
+	if err != nil || body == nil {
+		return err
+	}
+	return patch(body)
+}
+
+// applyPolicyStatus is the apply configuration of the status subresource of item.
+func applyPolicyStatus(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus) ([]byte, error) {
+	// spec is left out so the apply owns nothing but status
+	return json.Marshal(map[string]any{
+		"apiVersion": v1beta1.SchemeGroupVersion.String(),
+		"kind":       "ResourceQuotaPolicy",
+		"metadata": map[string]any{
+			"name":      item.Name,
+			"namespace": item.Namespace,
+		},
+		"status": status,
+	})
+}
+
+// updatePolicyStatus applies status to the status subresource of item. Adopting the fields of
+// earlier versions needs an up to date copy of item, so a conflict there is retried with a fresh
+// one; a conflict with another field manager is not.
+func (c *Controller) updatePolicyStatus(ctx context.Context, item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus) (*v1beta1.ResourceQuotaPolicy, error) {
+	policies := c.CRclient.PlatformV1beta1().ResourceQuotaPolicies(item.Namespace)
+	body, err := applyPolicyStatus(item, status)
+	if err != nil {
+		return nil, err
+	}
+
+	current := item
+	var cr *v1beta1.ResourceQuotaPolicy
+	err = retry.OnError(retry.DefaultRetry, staleWrite, func() error {
+		err := adoptLegacyFields(current.DeepCopy(), func(body []byte) error {
+			_, err := policies.Patch(ctx, item.Name, types.JSONPatchType, body, metav1.PatchOptions{}, "status")
+			return err
+		}, csaupgrade.Subresource("status"))
+		if staleWrite(err) {
+			fresh, getErr := policies.Get(ctx, item.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			current = fresh
+			return err
+		}
+		if err != nil {
+			// the apply still succeeds, it just can't remove fields set by older versions yet
+			c.logger.Error(err, "Failed to adopt status fields written by an earlier version", "namespace", item.Namespace, "policy", item.Name)
+		}
+
+		cr, err = policies.Patch(ctx, item.Name, types.ApplyPatchType, body, metav1.PatchOptions{FieldManager: FieldManager}, "status")
+		return err
+	})
+	return cr, err
+}
+
+// staleWrite reports whether err is a conflict caused by writing from an outdated copy of an
+// object, which a retry with a fresh copy resolves, rather than by fields another manager owns.
+func staleWrite(err error) bool {
+	if !apierrors.IsConflict(err) {
+		return false
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if cause.Type == metav1.CauseTypeFieldManagerConflict {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var policiesResource = schema.GroupResource{Group: "platform.example.com", Resource: "resourcequotapolicies"}
+
+// legacyPolicy returns a policy whose status an earlier version of the controller updated.
+func legacyPolicy() *v1beta1.ResourceQuotaPolicy {
+	return &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "p1",
+			Namespace:       "ns1",
+			ResourceVersion: "1",
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:     "rqe",
+				Operation:   metav1.ManagedFieldsOperationUpdate,
+				APIVersion:  "platform.example.com/v1beta1",
+				FieldsType:  "FieldsV1",
+				FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:violation":{}}}`)},
+				Subresource: "status",
+			}},
+		},
+		Status: v1beta1.ResourceQuotaPolicyStatus{Violation: true},
+	}
+}
+
+func TestUpdatePolicyStatusRetriesStaleAdoption(t *testing.T) {
+	policy := legacyPolicy()
+	client := fake.NewSimpleClientset(policy)
+	conflicts := 1
+	client.PrependReactor("patch", "resourcequotapolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetPatchType() == types.JSONPatchType && conflicts > 0 {
+			conflicts--
+			return true, nil, apierrors.NewConflict(policiesResource, "p1", nil)
+		}
+		return false, nil, nil
+	})
+	c := &Controller{CRclient: client, logger: logging.L()}
+
+	if _, err := c.updatePolicyStatus(context.Background(), policy, &v1beta1.ResourceQuotaPolicyStatus{CurrentPods: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var gets, applies int
+	for _, a := range client.Actions() {
+		switch {
+		case a.GetVerb() == "get":
+			gets++
+		case a.GetVerb() == "patch" && a.(k8stesting.PatchAction).GetPatchType() == types.ApplyPatchType:
+			applies++
+		}
+	}
+	if gets != 1 || applies != 1 {
+		t.Fatalf("got %d gets and %d applies, want one of each", gets, applies)
+	}
+}
+
+func TestUpdatePolicyStatusDoesNotRetryFieldManagerConflicts(t *testing.T) {
+	policy := legacyPolicy()
+	policy.ManagedFields = nil
+	client := fake.NewSimpleClientset(policy)
+	client.PrependReactor("patch", "resourcequotapolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		err := apierrors.NewConflict(policiesResource, "p1", nil)
+		err.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: metav1.CauseTypeFieldManagerConflict, Field: ".status.violation"}}
+		return true, nil, err
+	})
+	c := &Controller{CRclient: client, logger: logging.L()}
+
+	_, err := c.updatePolicyStatus(context.Background(), policy, &v1beta1.ResourceQuotaPolicyStatus{})
+	if !apierrors.IsConflict(err) {
+		t.Fatalf("err = %v, want a conflict", err)
+	}
+	if n := len(client.Actions()); n != 1 {
+		t.Fatalf("got %d actions, want the one apply", n)
+	}
+}
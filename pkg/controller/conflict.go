@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setConflictCondition records on the enforced policy item which other policies of its namespace
+// it is merged with, or which it overrides. merged are all the enforced policies, item included.
+func setConflictCondition(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus, merged, overridden []*v1beta1.ResourceQuotaPolicy, strategy v1beta1.MergeStrategy) {
+	if len(merged) > 1 {
+		var names []string
+		for _, p := range merged {
+			if p != item {
+				names = append(names, p.Name)
+			}
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionConflict,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Merged",
+			Message:            fmt.Sprintf("merged with %s (%s)", strings.Join(names, ", "), strategy),
+			ObservedGeneration: item.Generation,
+		})
+		return
+	}
+	if len(overridden) == 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionConflict,
+			Status:             metav1.ConditionFalse,
+			Reason:             "SolePolicy",
+			ObservedGeneration: item.Generation,
+		})
+		return
+	}
+	names := make([]string, len(overridden))
+	for i, p := range overridden {
+		names[i] = p.Name
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "OverridesPolicies",
+		Message:            fmt.Sprintf("enforced in place of %s", strings.Join(names, ", ")),
+		ObservedGeneration: item.Generation,
+	})
+}
+
+// markOverridden records on item that enforced, another policy of its namespace, is enforced in
+// its place. Status is only written, and the event only emitted, when the condition changes.
+func (c *Controller) markOverridden(ctx context.Context, item, enforced *v1beta1.ResourceQuotaPolicy) error {
+	status := item.Status.DeepCopy()
+	msg := fmt.Sprintf("not enforced: %s (priority %d) takes precedence", enforced.Name, enforced.Spec.Priority)
+	if !meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Overridden",
+		Message:            msg,
+		ObservedGeneration: item.Generation,
+	}) {
+		return nil
+	}
+	c.recorder.Event(item, corev1.EventTypeWarning, "PolicyOverridden", msg)
+	_, err := c.updatePolicyStatus(ctx, item, status)
+	return err
+}
@@ -2,75 +2,140 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	"github.com/sri2103/resource-quota-enforcer/pkg/forecast"
 	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	policyinformers "github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions/platform/v1beta1"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
 	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
 	"github.com/sri2103/resource-quota-enforcer/pkg/health"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
 	metrics "github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/klog/v2"
 )
 
 type Controller struct {
 	clientset kubernetes.Interface
 	CRclient  versioned.Interface
 	recorder  record.EventRecorder
+	logger    logr.Logger
 
-	podInformer cache.SharedIndexInformer
-	nsInformer  cache.SharedIndexInformer
+	podInformer    cache.SharedIndexInformer
+	nsInformer     cache.SharedIndexInformer
+	policyInformer cache.SharedIndexInformer
+	policyLister   listers.ResourceQuotaPolicyLister
 
 	enforcer *handlers.PodEnforcer
 	scheme   *runtime.Scheme
 
-	queue     workqueue.TypedRateLimitingInterface[any]
-	cacheLock sync.RWMutex
+	queue workqueue.TypedRateLimitingInterface[any]
+
+	// StallTimeout is how long queued work may go unprocessed before Check fails.
+	StallTimeout time.Duration
+	// DefaultPolicy is enforced in namespaces without a policy of their own, unless they opt out
+	// through v1beta1.AnnotationDefaultAction; nil leaves them unlimited.
+	DefaultPolicy *v1beta1.ResourceQuotaPolicy
+	// Source holds policies read from files or ConfigMaps, enforced in namespaces without a
+	// ResourceQuotaPolicy of their own; nil adds none. The caller runs it.
+	Source *policysource.Source
+	// Capacity resolves the clusterShare of policies, and every namespace is synced again when
+	// it changes; nil leaves policies to their maxPods, maxCPU and maxMemory. The caller runs it.
+	Capacity *capacity.Tracker
+	// Exempt are namespaces enforced as if they had no policy; nil exempts none. Call Resync
+	// after changing them.
+	Exempt *handlers.ExemptNamespaces
+	// Exemptions watches the QuotaExemptions whose pods are left out of counting and
+	// enforcement until they expire; nil exempts no pods. Run starts it.
+	Exemptions policyinformers.QuotaExemptionInformer
+	// WithoutCRD leaves the policy informer stopped, for clusters where the ResourceQuotaPolicy
+	// CRD isn't installed; policies then only come from Source and DefaultPolicy.
+	WithoutCRD bool
+
+	workers       atomic.Pointer[[]*worker]
+	lastProcessed atomic.Int64
+
+	opts       Options
+	failuresMu sync.Mutex
+	failures   map[string]int // consecutive sync failures per namespace
+
+	statusWrites statusWrites
+	exemptions   exemptionCache
+	usage        policycache.Store[NamespaceUsage]
 }
 
-// NewController constructs the controller.
-func NewController(clientset kubernetes.Interface, dynamicClient versioned.Interface, podInformer, nsInformer cache.SharedIndexInformer, enforcer *handlers.PodEnforcer, scheme *runtime.Scheme) *Controller {
+// NewController constructs the controller. Policies are read from policyInformer's lister; the
+// client is only used to write their status.
+func NewController(clientset kubernetes.Interface, dynamicClient versioned.Interface, podInformer, nsInformer cache.SharedIndexInformer, policyInformer policyinformers.ResourceQuotaPolicyInformer, enforcer *handlers.PodEnforcer, scheme *runtime.Scheme, opts Options) *Controller {
 	q := workqueue.
 		NewNamedRateLimitingQueue(
-			workqueue.DefaultTypedItemBasedRateLimiter[any](),
-			"resource-quota-enforcer",
+			opts.rateLimiter(),
+			opts.queueName(),
 		)
-	v1alpha1.Install(scheme)
+	v1beta1.Install(scheme)
 	rec := record.NewBroadcaster()
 	rec.StartRecordingToSink(&v1.EventSinkImpl{
 		Interface: clientset.CoreV1().Events(""),
 	})
 
 	recorder := rec.NewRecorder(scheme, corev1.EventSource{Component: "resourcequotapolicy-controller"})
+	logger := logging.L().WithName("controller")
+	if opts.Cluster != "" {
+		logger = logger.WithValues("cluster", opts.Cluster)
+	}
 
 	return &Controller{
-		clientset:   clientset,
-		CRclient:    dynamicClient,
-		podInformer: podInformer,
-		nsInformer:  nsInformer,
-		enforcer:    enforcer,
-		queue:       q,
-		recorder:    recorder,
+		clientset:      clientset,
+		CRclient:       dynamicClient,
+		podInformer:    podInformer,
+		nsInformer:     nsInformer,
+		policyInformer: policyInformer.Informer(),
+		policyLister:   policyInformer.Lister(),
+		enforcer:       enforcer,
+		queue:          q,
+		recorder:       recorder,
+		logger:         logger,
+
+		StallTimeout: DefaultStallTimeout,
+		opts:         opts,
+		failures:     make(map[string]int),
 	}
 }
 
 // Run starts informers and worker goroutines. `workers` is how many goroutines process the queue.
-func (c *Controller) Run(stopCh <-chan struct{}, workers int) {
-	log.Println("[Controller] Starting ResourceQuotaEnforcer controller...")
+// It blocks until ctx is cancelled, then cancels in-flight syncs and waits for the workers to exit.
+func (c *Controller) Run(ctx context.Context, workers int) {
+	c.logger.Info("Starting ResourceQuotaEnforcer controller")
 
+	var wg sync.WaitGroup
 	defer func() {
-		log.Println("[Controller] Shutting down work queue...")
+		c.logger.Info("Shutting down work queue")
 		c.queue.ShutDown()
+		wg.Wait()
 	}()
 
 	// 1️⃣ Register event handlers
@@ -83,72 +148,168 @@ func (c *Controller) Run(stopCh <-chan struct{}, workers int) {
 	c.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			if pod, ok := obj.(*corev1.Pod); ok {
-				c.queue.AddRateLimited(pod.Namespace)
+				c.trackPod(pod, false)
 			}
 		},
 		UpdateFunc: func(_, newObj interface{}) {
 			if pod, ok := newObj.(*corev1.Pod); ok {
-				c.queue.AddRateLimited(pod.Namespace)
+				c.trackPod(pod, false)
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
 			if pod, ok := obj.(*corev1.Pod); ok {
-				c.queue.AddRateLimited(pod.Namespace)
+				c.trackPod(pod, true)
 			}
 		},
 	})
 
+	c.policyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueuePolicy(obj) },
+		UpdateFunc: func(_, newObj interface{}) { c.enqueuePolicy(newObj) },
+		DeleteFunc: func(obj interface{}) { c.enqueuePolicy(obj) },
+	})
+
+	synced := []cache.InformerSynced{c.nsInformer.HasSynced, c.podInformer.HasSynced}
+	if c.Source != nil {
+		c.Source.AddHandler(func(ns string) { c.queue.Add(ns) })
+		synced = append(synced, c.Source.HasSynced)
+	}
+	if c.Capacity != nil {
+		c.Capacity.AddHandler(c.Resync)
+		synced = append(synced, c.Capacity.HasSynced)
+	}
+	if m := c.enforcer.Maintenance; m != nil {
+		// pods and jobs left in place during maintenance are dealt with once it ends
+		m.AddHandler(c.Resync)
+		synced = append(synced, m.HasSynced)
+	}
+	if c.Exemptions != nil {
+		informer := c.Exemptions.Informer()
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueueExemption(obj) },
+			UpdateFunc: func(_, newObj interface{}) { c.enqueueExemption(newObj) },
+			DeleteFunc: func(obj interface{}) { c.enqueueExemption(obj) },
+		})
+		go informer.Run(ctx.Done())
+		synced = append(synced, informer.HasSynced)
+	}
+
 	// 2️⃣ Start informers
-	go c.nsInformer.Run(stopCh)
-	go c.podInformer.Run(stopCh)
+	go c.nsInformer.Run(ctx.Done())
+	go c.podInformer.Run(ctx.Done())
+	if c.WithoutCRD {
+		c.logger.Info("ResourceQuotaPolicy CRD not installed, enforcing policies from files, ConfigMaps and the default policy only")
+	} else {
+		go c.policyInformer.Run(ctx.Done())
+		synced = append(synced, c.policyInformer.HasSynced)
+	}
 
-	if ok := cache.WaitForCacheSync(stopCh, c.nsInformer.HasSynced, c.podInformer.HasSynced); !ok {
-		log.Println("[Controller] ❌ Failed to sync caches, exiting...")
+	if ok := cache.WaitForCacheSync(ctx.Done(), synced...); !ok {
+		c.logger.Error(nil, "Failed to sync caches, exiting")
 		return
 	}
 
 	health.SetReady()
 
 	// 4️⃣ Start worker goroutines
-	log.Printf("[Controller] Starting %d workers...", workers)
-	for i := 0; i < workers; i++ {
-		go func(id int) {
+	c.logger.Info("Starting workers", "workers", workers)
+	c.markProcessed()
+	ws := make([]*worker, workers)
+	for i := range ws {
+		ws[i] = &worker{}
+	}
+	c.workers.Store(&ws)
+	for i, w := range ws {
+		wg.Add(1)
+		go func(id int, w *worker) {
+			defer wg.Done()
 			defer func() {
+				w.exited.Store(true)
 				if r := recover(); r != nil {
-					log.Printf("[Worker-%d] ⚠️ Panic recovered: %v", id, r)
+					c.logger.Error(fmt.Errorf("panic: %v", r), "Worker panic recovered, worker exited", "worker", id)
 				}
 			}()
-			for c.processNextItem() {
+			for c.processNextItem(ctx, w) {
 			}
-		}(i)
+		}(i, w)
 	}
 
 	// 5️⃣ Periodic full resync
 	go func() {
-		ticker := time.NewTicker(60 * time.Second)
+		if c.opts.FullResync <= 0 {
+			return
+		}
+		ticker := time.NewTicker(c.opts.FullResync)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				namespaces, err := c.clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+				namespaces, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 				if err != nil {
-					log.Printf("[Resync] Error listing namespaces: %v", err)
+					c.logger.Error(err, "Resync failed to list namespaces")
 					continue
 				}
 				for _, ns := range namespaces.Items {
 					c.queue.AddRateLimited(ns.Name)
 				}
-				log.Printf("[Resync] Queued %d namespaces for periodic enforcement", len(namespaces.Items))
-			case <-stopCh:
-				log.Println("[Resync] Stopping periodic sync loop")
+				c.logger.Info("Queued namespaces for periodic enforcement", "namespaces", len(namespaces.Items))
+			case <-ctx.Done():
+				c.logger.Info("Stopping periodic sync loop")
 				return
 			}
 		}
 	}()
 
-	// 6️⃣ Block until stop signal
-	<-stopCh
-	log.Println("[Controller] 🛑 Controller stopped gracefully")
+	// 6️⃣ Periodic audit of the running usage totals against the pod cache
+	if c.enforcer.Usage != nil && c.opts.UsageAudit > 0 {
+		go c.auditUsage(ctx)
+	}
+
+	// 7️⃣ Block until stop signal
+	<-ctx.Done()
+	c.logger.Info("Controller stopping")
+}
+
+// trackPod applies a pod event to the running usage totals, then queues the pod's namespace, so
+// the sync it triggers already sees the change.
+func (c *Controller) trackPod(pod *corev1.Pod, deleted bool) {
+	if usage := c.enforcer.Usage; usage != nil {
+		if deleted {
+			usage.Delete(pod)
+		} else {
+			usage.Update(pod)
+		}
+	}
+	c.queue.AddRateLimited(pod.Namespace)
+}
+
+// auditUsage rebuilds the running usage totals from the pod cache every UsageAudit, correcting
+// any drift, until ctx is cancelled.
+func (c *Controller) auditUsage(ctx context.Context) {
+	ticker := time.NewTicker(c.opts.UsageAudit)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			drifted, err := c.enforcer.Usage.Audit(time.Now())
+			if err != nil {
+				c.logger.Error(err, "Usage audit failed")
+				continue
+			}
+			for _, ns := range drifted {
+				metrics.UsageDriftCorrections.WithLabelValues(ns, c.opts.Cluster).Inc()
+				c.queue.Add(ns)
+			}
+			if len(drifted) > 0 {
+				c.logger.Info("Corrected drifted usage totals", "namespaces", drifted)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 func (c *Controller) enqueueNamespace(obj interface{}) {
@@ -169,18 +330,32 @@ func (c *Controller) enqueueNamespace(obj interface{}) {
 	}
 }
 
+// enqueuePolicy queues the namespace of a policy that was added, changed or deleted.
+func (c *Controller) enqueuePolicy(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if policy, ok := obj.(*v1beta1.ResourceQuotaPolicy); ok {
+		c.queue.Add(policy.Namespace)
+	}
+}
+
 // processNextItem processes a single key from the queue.
-func (c *Controller) processNextItem() bool {
-	ctx := context.TODO()
+func (c *Controller) processNextItem(ctx context.Context, w *worker) bool {
 	obj, shutdown := c.queue.Get()
 	if shutdown {
 		return false
 	}
-	defer c.queue.Done(obj)
+	w.start()
+	defer func() {
+		c.queue.Done(obj)
+		w.done()
+		c.markProcessed()
+	}()
 
 	ns, ok := obj.(string)
 	if !ok {
-		klog.Errorf("expected string in workqueue but got %#v", obj)
+		c.logger.Error(nil, "Expected string in workqueue", "item", fmt.Sprintf("%#v", obj))
 		c.queue.Forget(obj)
 		return true
 	}
@@ -189,130 +364,443 @@ func (c *Controller) processNextItem() bool {
 		// Protect from unexpected panics inside syncNamespace
 		defer func() {
 			if r := recover(); r != nil {
-				klog.Errorf("panic while syncing namespace %q: %v", ns, r)
+				c.logger.Error(fmt.Errorf("panic: %v", r), "Panic while syncing namespace", "namespace", ns)
 				err = fmt.Errorf("panic: %v", r)
 			}
 		}()
-		return c.syncHandler(ctx, ns)
+		syncCtx := ctx
+		if c.opts.SyncTimeout > 0 {
+			var cancel context.CancelFunc
+			syncCtx, cancel = context.WithTimeout(ctx, c.opts.SyncTimeout)
+			defer cancel()
+		}
+		return c.syncHandler(syncCtx, ns)
 	}()
 	if err != nil {
-		// Retry with rate limit
-		c.queue.AddRateLimited(ns)
-		klog.Errorf("error syncing namespace %q: %v (will retry)", ns, err)
+		if ctx.Err() != nil {
+			// shutting down; the sync was cancelled rather than failed
+			c.logger.V(logging.Debug).Info("Sync cancelled by shutdown", "namespace", ns)
+			return true
+		}
+		c.retryOrPark(ctx, ns, err)
 		return true
 	}
 
 	// Successful reconciliation
 	c.queue.Forget(ns)
-	// klog.Infof("successfully synced namespace %q", ns)
+	c.resetFailures(ns)
 	return true
 }
 
 // syncHandler ensures policy cache for namespace and runs enforcement.
 // core reconciler logic
 // It also updates CRD status (if policy CR exists).
-func (c *Controller) syncHandler(ctx context.Context, ns string) error {
-	klog.V(4).Infof("Reconciling namespace: %s", ns)
-
-	// Step 1: List all CRs in this namespace
-	list, err := c.CRclient.
-		PlatformV1alpha1().
-		ResourceQuotaPolicies(ns).
-		List(ctx, metav1.ListOptions{})
+func (c *Controller) syncHandler(ctx context.Context, ns string) (err error) {
+	logger := c.logger.WithValues("namespace", ns)
+	logger.V(logging.Debug).Info("Reconciling namespace")
+	ctx, span := tracing.Tracer().Start(ctx, "reconcile.namespace",
+		trace.WithAttributes(attribute.String("k8s.namespace.name", ns)))
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	// Step 1: List all CRs in this namespace; the lister's objects are shared with the informer
+	// and must not be modified
+	items, err := c.policyLister.ResourceQuotaPolicies(ns).List(labels.Everything())
 	if err != nil {
 		return fmt.Errorf("list CRs: %w", err)
 	}
 
-	if len(list.Items) == 0 {
-		c.cacheLock.Lock()
-		delete(c.enforcer.PolicyCache, ns)
-		c.cacheLock.Unlock()
-		klog.V(4).Infof("No policies found in namespace %s, removed from cache", ns)
+	exempt := c.Exempt.Has(ns)
+	if !exempt {
+		if err := c.syncExemptions(ctx, ns, time.Now()); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 && c.Source != nil && !exempt {
+		// a custom resource takes precedence over the namespace's policies from files and ConfigMaps
+		if policies := c.Source.List(ns); len(policies) > 0 {
+			return c.syncSourcePolicies(ctx, ns, policies)
+		}
+	}
+	if len(items) == 0 && !exempt {
+		if applies, err := c.defaultPolicyApplies(ctx, ns); err != nil {
+			return fmt.Errorf("get namespace: %w", err)
+		} else if applies {
+			return c.syncDefaultPolicy(ctx, ns)
+		}
+	}
+	if len(items) == 0 || exempt {
+		if c.enforcer.Usage != nil {
+			c.enforcer.Usage.Forget(ns)
+		}
+		c.statusWrites.forget(ns)
+		c.exemptions.forget(ns)
+		c.stopEnforcing(ctx, ns)
+		if exempt {
+			logger.V(logging.Debug).Info("Namespace is exempt, removed from cache")
+		} else {
+			logger.V(logging.Debug).Info("No policies found in namespace, removed from cache")
+		}
 		return nil
 	}
 
-	// Step 2: Process each CR (you can later extend for multiple)
-	for _, item := range list.Items {
+	// Step 2: Enforce the policies that aren't suspended, combined by the merge strategy; under
+	// highestPriorityOnly the others are only told they are overridden. Suspended policies only
+	// get their usage written to status.
+	v1beta1.SortByPriority(items)
+	active, suspended := splitSuspended(items)
+	var errs []error
+	if len(active) == 0 {
+		c.stopEnforcing(ctx, ns)
+		logger.V(logging.Debug).Info("All policies suspended, removed from cache")
+	}
+	for _, item := range suspended {
+		if err := c.syncSuspended(ctx, ns, item, time.Now()); err != nil {
+			logger.Error(err, "Failed to sync suspended policy", "policy", item.Name)
+			errs = append(errs, err)
+		}
+	}
+	if len(active) == 0 {
+		return errors.Join(errs...)
+	}
+	enforced, overridden := active, []*v1beta1.ResourceQuotaPolicy(nil)
+	if c.opts.MergeStrategy == v1beta1.MergeHighestPriorityOnly || c.opts.MergeStrategy == "" {
+		enforced, overridden = active[:1], active[1:]
+	}
+	if err := c.syncPolicies(ctx, ns, enforced, overridden); err != nil {
+		errs = append(errs, err)
+	}
+	for _, item := range overridden {
+		if err := c.markOverridden(ctx, item, active[0]); err != nil {
+			logger.Error(err, "Failed to update status", "policy", item.Name)
+			errs = append(errs, fmt.Errorf("update status of %s: %w", item.Name, err))
+		}
+	}
+
+	logger.V(logging.Debug).Info("Finished syncing namespace")
+	return errors.Join(errs...)
+}
+
+// stopEnforcing drops what the controller keeps to enforce policies in ns, once it has none left
+// or all of them are suspended, and the quota labels and annotations it put on the namespace.
+func (c *Controller) stopEnforcing(ctx context.Context, ns string) {
+	c.enforcer.PolicyCache.Delete(ns)
+	c.enforcer.Limiter.Forget(ns)
+	metrics.ForgetNamespace(c.opts.Cluster, ns)
+	c.usage.Delete(ns)
+	if err := c.markNamespace(ctx, ns, namespaceMarks{}); err != nil {
+		c.logger.Error(err, "Failed to remove quota labels and annotations from namespace", "namespace", ns)
+	}
+}
+
+// Resync queues every namespace to be synced again.
+func (c *Controller) Resync() {
+	for _, ns := range c.nsInformer.GetStore().ListKeys() {
+		c.queue.Add(ns)
+	}
+}
 
-		spec := item.Spec
+// syncPolicies enforces items, policies of ns in SortByPriority order, as one policy merged by
+// the merge strategy, and writes the resulting usage to the status of each of them. overridden
+// are the namespace's other policies, which are not enforced.
+func (c *Controller) syncPolicies(ctx context.Context, ns string, items, overridden []*v1beta1.ResourceQuotaPolicy) error {
+	logger := c.logger.WithValues("namespace", ns)
+	now := time.Now()
+	specs := make([]v1beta1.ResourceQuotaPolicySpec, len(items))
+	actives := make([]*v1beta1.ScheduledChange, len(items))
+	names := make([]string, len(items))
+	for i, item := range items {
+		specs[i], actives[i] = item.Spec.EffectiveAt(now)
+		specs[i].ResolveClusterShare(c.Capacity.Allocatable())
+		names[i] = item.Name
+		if next, ok := item.Spec.NextScheduledChange(now); ok {
+			// make sure the cutover is picked up even if nothing else happens in the namespace
+			c.queue.AddAfter(ns, time.Until(next))
+		}
+	}
+	spec := v1beta1.Merge(c.opts.MergeStrategy, specs)
 
-		policy := handlers.ParsePolicy(&spec)
+	policy := handlers.ParsePolicy(&spec)
+	policy.Exclude = c.exclusions(ns, &spec)
+	policy.Name = strings.Join(names, "+")
 
-		// Update cache
-		c.cacheLock.Lock()
-		c.enforcer.PolicyCache[ns] = policy
-		c.cacheLock.Unlock()
+	// Update cache
+	c.enforcer.PolicyCache.Set(ns, policy)
 
-		// record event:
+	for _, item := range items {
 		c.recorder.Eventf(
-			&item,
+			item,
 			corev1.EventTypeNormal,
 			"ReconcileStarted",
 			"Started reconciling ResourceQuotaPolicy %s", item.Name,
 		)
+	}
 
-		// Step 3: Enforce policy
-		enforced, err := c.enforcer.EnforceUntilOK(ns, policy)
-		metrics.ReconcileTotal.WithLabelValues("pod", ns).Inc()
-		if err != nil {
-			metrics.ReconcileErrors.WithLabelValues("pod", ns).Inc()
-			klog.Errorf("enforce error for namespace %s: %v", ns, err)
+	// Step 3: Enforce policy
+	_, enforceSpan := tracing.Tracer().Start(ctx, "reconcile.enforce",
+		trace.WithAttributes(attribute.String("rqe.policy", policy.Name)))
+	enforced, err := c.enforcer.EnforceUntilOK(ctx, ns, policy)
+	enforceSpan.SetAttributes(attribute.Int("rqe.evicted", len(enforced.Evicted)))
+	enforceSpan.End()
+	metrics.ReconcileTotal.WithLabelValues("pod", ns, c.opts.Cluster).Inc()
+	for _, item := range items {
+		for _, pod := range enforced.Evicted {
+			c.recorder.Eventf(
+				item,
+				corev1.EventTypeWarning,
+				"PodEvicted",
+				"Deleted pod %s to enforce ResourceQuotaPolicy %s", pod, item.Name,
+			)
+		}
+	}
+	objs := make([]runtime.Object, len(items))
+	for i, item := range items {
+		objs[i] = item
+	}
+	c.reportDryRun(ns, enforced, "ResourceQuotaPolicy "+policy.Name, objs...)
+	if err != nil {
+		metrics.ReconcileErrors.WithLabelValues("pod", ns, c.opts.Cluster).Inc()
+		logger.Error(err, "Enforcement failed", "policy", policy.Name)
+		for _, item := range items {
 			// 🔹 Record a failure event if enforcement failed
 			c.recorder.Eventf(
-				&item,
+				item,
 				corev1.EventTypeWarning,
 				"EnforcementFailed",
 				"Failed to enforce policy %s: %v", item.Name, err.Error(),
 			)
-			continue
 		}
+		return fmt.Errorf("enforce %s: %w", policy.Name, err)
+	}
+	c.deferRateLimited(ns, enforced, objs...)
+	c.recordCost(ns, policy, enforced)
+	c.recordUsage(ns, policy, enforced)
+	c.setUsage(ns, policy, enforced)
+	replicaViolations, replicasErr := c.replicaViolations(ctx, ns, spec.MaxReplicasPerWorkload)
+	if replicasErr != nil {
+		// keep what the policies reported last time rather than fail the sync
+		logger.Error(replicasErr, "Failed to list workloads over maxReplicasPerWorkload", "policy", policy.Name)
+	}
 
-		// Step 4: Update status
-		status := &v1alpha1.ResourceQuotaPolicyStatus{
-			CurrentPods: enforced.CurrentPods,
+	// Step 4: Update status
+	var errs []error
+	if err := c.suspendExcessJobs(ctx, ns, spec.MaxActiveJobs, spec.Impersonate, items); err != nil {
+		logger.Error(err, "Failed to suspend jobs over maxActiveJobs", "policy", policy.Name)
+		errs = append(errs, err)
+	}
+	for i, item := range items {
+		status := &v1beta1.ResourceQuotaPolicyStatus{
+			CurrentPods: int32(enforced.CurrentPods),
 			CPUUsage:    enforced.CurrentCPU,
 			MemoryUsage: enforced.CurrentMemory,
 			Violation:   enforced.Violation,
 			Message:     enforced.Message,
 		}
+		status.Conditions = append([]metav1.Condition(nil), item.Status.Conditions...)
+		status.History = append([]v1beta1.UsageSnapshot(nil), item.Status.History...)
+		status.RecordUsage(v1beta1.UsageSnapshot{
+			Time:   statusTime(now),
+			Pods:   int32(enforced.CurrentPods),
+			CPU:    resource.MustParse(enforced.CurrentCPU),
+			Memory: resource.MustParse(enforced.CurrentMemory),
+		})
+		status.Forecast = c.forecastExhaustion(ns, status.History, policy, now)
+		status.ReplicaViolations = replicaViolations
+		if replicasErr != nil {
+			status.ReplicaViolations = item.Status.ReplicaViolations
+		}
+		c.setWarningCondition(item, status, enforced.Warnings)
+		c.setDeletionCapCondition(item, status, enforced)
+		setConflictCondition(item, status, items, overridden, c.opts.MergeStrategy)
+		c.setResumedCondition(item, status)
+		if meta.FindStatusCondition(status.Conditions, v1beta1.ConditionReconcileFailed) != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionReconcileFailed,
+				Status:             metav1.ConditionFalse,
+				Reason:             "Reconciled",
+				ObservedGeneration: item.Generation,
+			})
+		}
+		if active := actives[i]; active != nil {
+			status.ActiveScheduledChange = active.EffectiveFrom.DeepCopy()
+			if prev := item.Status.ActiveScheduledChange; prev == nil || !prev.Equal(status.ActiveScheduledChange) {
+				c.recorder.Eventf(
+					item,
+					corev1.EventTypeNormal,
+					"ScheduledChangeApplied",
+					"Scheduled change effective from %s is now in force for %s", active.EffectiveFrom.UTC().Format(time.RFC3339), item.Name,
+				)
+			}
+		}
 
-		if cr, err := c.updatePolicyStatus(ctx, ns, item.GetName(), status); err != nil {
-			klog.Errorf("failed to update status for %s/%s: %v", ns, item.GetName(), err)
+		if c.statusCurrent(item, status, now) {
+			logger.V(logging.Debug).Info("Status unchanged, not updating", "policy", item.GetName())
+		} else if cr, err := c.updatePolicyStatus(ctx, item, status); err != nil {
+			logger.Error(err, "Failed to update status", "policy", item.GetName())
+			errs = append(errs, fmt.Errorf("update status of %s: %w", item.GetName(), err))
 			continue
 		} else {
-			log.Printf("status of the updated: %v", cr.Status)
+			c.statusWrites.written(item, now)
+			logger.V(logging.Debug).Info("Updated status", "policy", cr.Name, "pods", cr.Status.CurrentPods, "cpu", cr.Status.CPUUsage, "memory", cr.Status.MemoryUsage, "violation", cr.Status.Violation)
 		}
 
 		c.recorder.Eventf(
-			&item,
+			item,
 			corev1.EventTypeNormal,
 			"ReconcileSucceeded",
 			"Successfully enforced ResourceQuotaPolicy %s", item.Name,
 		)
+	}
+	if err := c.markNamespace(ctx, ns, marksFor(policy, enforced)); err != nil {
+		// the marks are informational, so a failure doesn't fail the sync
+		logger.Error(err, "Failed to label and annotate namespace with quota state", "policy", policy.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// CachedPolicies returns a copy of the parsed policies the enforcer is using, keyed by
+// namespace, limited to namespace when it is not empty.
+func (c *Controller) CachedPolicies(namespace string) map[string]handlers.Policy {
+	return c.enforcer.PolicyCache.Snapshot(namespace)
+}
 
+// setWarningCondition reflects soft limit crossings in status. Events and metrics are only emitted
+// when the condition flips to True so a namespace sitting above its soft limit doesn't spam them.
+func (c *Controller) setWarningCondition(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus, warnings []string) {
+	if len(warnings) == 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionWarning,
+			Status:             metav1.ConditionFalse,
+			Reason:             "WithinSoftLimits",
+			ObservedGeneration: item.Generation,
+		})
+		return
 	}
 
-	klog.V(3).Infof("Finished syncing namespace %s", ns)
-	return nil
+	msg := fmt.Sprintf("usage above soft limit for: %s", strings.Join(warnings, ", "))
+	if !meta.IsStatusConditionTrue(item.Status.Conditions, v1beta1.ConditionWarning) {
+		for _, res := range warnings {
+			metrics.SoftLimitExceeded.WithLabelValues(res, item.Namespace, c.opts.Cluster).Inc()
+		}
+		c.recorder.Event(item, corev1.EventTypeWarning, "SoftLimitExceeded", msg)
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionWarning,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SoftLimitExceeded",
+		Message:            msg,
+		ObservedGeneration: item.Generation,
+	})
 }
 
-// updatePolicyStatus writes the status subresource for CRD. If API server doesn't support subresource, fallback to Update.
-func (c *Controller) updatePolicyStatus(ctx context.Context, namespace, name string, status *v1alpha1.ResourceQuotaPolicyStatus) (*v1alpha1.ResourceQuotaPolicy, error) {
-	// get object
-	obj, err := c.CRclient.
-		PlatformV1alpha1().
-		ResourceQuotaPolicies(namespace).
-		Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
+// setDeletionCapCondition reflects in status whether the sync stopped deleting pods at the
+// deletion cap with the violation still in place. The event is only emitted when the condition
+// flips to True.
+func (c *Controller) setDeletionCapCondition(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus, enforced handlers.EnforcementResult) {
+	if !enforced.DeletionCapReached {
+		if meta.FindStatusCondition(status.Conditions, v1beta1.ConditionDeletionCapReached) != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionDeletionCapReached,
+				Status:             metav1.ConditionFalse,
+				Reason:             "WithinCap",
+				ObservedGeneration: item.Generation,
+			})
+		}
+		return
+	}
+
+	msg := fmt.Sprintf("deleted as many pods as one sync may, violation left for the next sync: %s", enforced.Message)
+	if !meta.IsStatusConditionTrue(item.Status.Conditions, v1beta1.ConditionDeletionCapReached) {
+		c.recorder.Event(item, corev1.EventTypeWarning, "DeletionCapReached", msg)
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionDeletionCapReached,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DeletionCapReached",
+		Message:            msg,
+		ObservedGeneration: item.Generation,
+	})
+}
+
+// forecastExhaustion publishes the time-to-exhaustion gauges for the namespace and returns the
+// earliest estimate, or nil when no resource is trending toward its limit.
+func (c *Controller) forecastExhaustion(ns string, history []v1beta1.UsageSnapshot, policy handlers.Policy, now time.Time) *v1beta1.QuotaForecast {
+	var earliest *v1beta1.QuotaForecast
+	seen := map[string]bool{}
+	for _, est := range forecast.Estimates(history, policy, now) {
+		seen[est.Resource] = true
+		metrics.QuotaExhaustionSeconds.WithLabelValues(est.Resource, ns, c.opts.Cluster).Set(est.At.Sub(now).Seconds())
+		if earliest == nil || est.At.Before(earliest.ExhaustionTime.Time) {
+			earliest = &v1beta1.QuotaForecast{Resource: est.Resource, ExhaustionTime: statusTime(est.At)}
+		}
+	}
+	for _, res := range []string{"pods", "cpu", "memory"} {
+		if !seen[res] {
+			metrics.QuotaExhaustionSeconds.DeleteLabelValues(res, ns, c.opts.Cluster)
+		}
+	}
+	return earliest
+}
+
+// recordUsage publishes the per-namespace usage and limit gauges.
+func (c *Controller) recordUsage(ns string, policy handlers.Policy, enforced handlers.EnforcementResult) {
+	cpu := resource.MustParse(enforced.CurrentCPU)
+	mem := resource.MustParse(enforced.CurrentMemory)
+
+	metrics.NamespacePods.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(float64(enforced.CurrentPods))
+	metrics.NamespaceCPURequests.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(cpu.AsApproximateFloat64())
+	metrics.NamespaceMemoryRequests.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(mem.AsApproximateFloat64())
+	metrics.NamespacePodsLimit.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(float64(policy.MaxPods))
+	metrics.NamespaceCPULimit.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(policy.MaxCPU.AsApproximateFloat64())
+	metrics.NamespaceMemoryLimit.WithLabelValues(ns, policy.Name, c.opts.Cluster).Set(policy.MaxMemory.AsApproximateFloat64())
+
+	if policy.MaxPods > 0 {
+		metrics.QuotaUtilization.WithLabelValues(ns, "pods", c.opts.Cluster).Set(float64(enforced.CurrentPods) / float64(policy.MaxPods))
 	}
+	metrics.QuotaUtilization.WithLabelValues(ns, "cpu", c.opts.Cluster).Set(handlers.Utilization(cpu, policy.MaxCPU))
+	metrics.QuotaUtilization.WithLabelValues(ns, "memory", c.opts.Cluster).Set(handlers.Utilization(mem, policy.MaxMemory))
+}
 
-	obj.Status = *status
+// reportDryRun records the pods a dry run would have deleted in ns to enforce policy, with an
+// event on each of objs per pod.
+func (c *Controller) reportDryRun(ns string, enforced handlers.EnforcementResult, policy string, objs ...runtime.Object) {
+	if len(enforced.WouldEvict) == 0 {
+		return
+	}
+	metrics.EnforcementActions.WithLabelValues("would_evict", ns, c.opts.Cluster).Add(float64(len(enforced.WouldEvict)))
+	for _, obj := range objs {
+		for _, pod := range enforced.WouldEvict {
+			c.recorder.Eventf(obj, corev1.EventTypeWarning, "PodWouldBeEvicted",
+				"Would delete pod %s to enforce %s (dry run)", pod, policy)
+		}
+	}
+}
 
-	// fallback to Update if UpdateStatus not allowed
-	cr, err := c.CRclient.
-		PlatformV1alpha1().
-		ResourceQuotaPolicies(namespace).
-		UpdateStatus(ctx, obj, metav1.UpdateOptions{})
-	return cr, err
+// deferRateLimited reports enforcement in ns that stopped at the deletion rate limit with an
+// event on each of objs, and queues ns for when the next deletion is allowed.
+func (c *Controller) deferRateLimited(ns string, enforced handlers.EnforcementResult, objs ...runtime.Object) {
+	if !enforced.RateLimited {
+		return
+	}
+	metrics.EnforcementActions.WithLabelValues("rate_limited", ns, c.opts.Cluster).Inc()
+	for _, obj := range objs {
+		c.recorder.Eventf(obj, corev1.EventTypeWarning, "DeletionRateLimited",
+			"Deletion rate limit reached, violation left in place: %s", enforced.Message)
+	}
+	c.queue.AddAfter(ns, c.enforcer.Limiter.Interval())
+}
+
+// recordCost publishes the estimated hourly cost gauges for priced policies.
+func (c *Controller) recordCost(ns string, policy handlers.Policy, enforced handlers.EnforcementResult) {
+	metrics.EstimatedHourlyCost.DeletePartialMatch(map[string]string{"namespace": ns, "cluster": c.opts.Cluster})
+	if !policy.Priced() {
+		return
+	}
+	cpuCost, memoryCost := policy.HourlyCost(resource.MustParse(enforced.CurrentCPU), resource.MustParse(enforced.CurrentMemory))
+	metrics.EstimatedHourlyCost.WithLabelValues("cpu", ns, policy.Currency, c.opts.Cluster).Set(cpuCost)
+	metrics.EstimatedHourlyCost.WithLabelValues("memory", ns, policy.Currency, c.opts.Cluster).Set(memoryCost)
 }
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	metrics "github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultPolicyApplies reports whether c.DefaultPolicy is enforced in ns, which has no policy of
+// its own.
+func (c *Controller) defaultPolicyApplies(ctx context.Context, ns string) (bool, error) {
+	if c.DefaultPolicy == nil {
+		return false, nil
+	}
+	namespace, err := c.namespace(ctx, ns)
+	if err != nil || namespace == nil {
+		return false, err
+	}
+	return !v1beta1.ExemptFromDefaults(namespace.Annotations), nil
+}
+
+// syncDefaultPolicy enforces c.DefaultPolicy in ns.
+func (c *Controller) syncDefaultPolicy(ctx context.Context, ns string) error {
+	return c.syncWithoutResource(ctx, ns, []*v1beta1.ResourceQuotaPolicy{c.DefaultPolicy}, "the cluster default policy "+c.DefaultPolicy.Name)
+}
+
+// syncSourcePolicies enforces policies, those c.Source holds for ns, combined by the merge
+// strategy.
+func (c *Controller) syncSourcePolicies(ctx context.Context, ns string, policies []*v1beta1.ResourceQuotaPolicy) error {
+	v1beta1.SortByPriority(policies)
+	if c.opts.MergeStrategy == v1beta1.MergeHighestPriorityOnly || c.opts.MergeStrategy == "" {
+		policies = policies[:1]
+	}
+	names := make([]string, len(policies))
+	origins := make([]string, len(policies))
+	for i, p := range policies {
+		names[i] = p.Name
+		origins[i] = p.Annotations[policysource.AnnotationOrigin]
+	}
+	described := fmt.Sprintf("policy %s from %s", strings.Join(names, "+"), strings.Join(origins, ", "))
+	return c.syncWithoutResource(ctx, ns, policies, described)
+}
+
+// syncWithoutResource enforces policies in ns, merged in SortByPriority order. They have no custom
+// resource to write status to, so events go to the namespace and usage is only published as
+// metrics and namespace marks. described names the policies in events, e.g. "the cluster default
+// policy cluster-default".
+func (c *Controller) syncWithoutResource(ctx context.Context, ns string, policies []*v1beta1.ResourceQuotaPolicy, described string) error {
+	now := time.Now()
+	specs := make([]v1beta1.ResourceQuotaPolicySpec, len(policies))
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		specs[i], _ = p.Spec.EffectiveAt(now)
+		specs[i].ResolveClusterShare(c.Capacity.Allocatable())
+		names[i] = p.Name
+		if next, ok := p.Spec.NextScheduledChange(now); ok {
+			c.queue.AddAfter(ns, time.Until(next))
+		}
+	}
+	spec := v1beta1.Merge(c.opts.MergeStrategy, specs)
+
+	policy := handlers.ParsePolicy(&spec)
+	policy.Exclude = c.exclusions(ns, &spec)
+	policy.Name = strings.Join(names, "+")
+	c.enforcer.PolicyCache.Set(ns, policy)
+	logger := c.logger.WithValues("namespace", ns, "policy", described)
+
+	enforced, err := c.enforcer.EnforceUntilOK(ctx, ns, policy)
+	metrics.ReconcileTotal.WithLabelValues("pod", ns, c.opts.Cluster).Inc()
+	ref := &corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: ns}
+	for _, pod := range enforced.Evicted {
+		c.recorder.Eventf(ref, corev1.EventTypeWarning, "PodEvicted",
+			"Deleted pod %s to enforce %s", pod, described)
+	}
+	c.reportDryRun(ns, enforced, described, ref)
+	if err != nil {
+		metrics.ReconcileErrors.WithLabelValues("pod", ns, c.opts.Cluster).Inc()
+		logger.Error(err, "Enforcement failed")
+		return fmt.Errorf("enforce %s: %w", described, err)
+	}
+
+	c.deferRateLimited(ns, enforced, ref)
+	if enforced.DeletionCapReached {
+		c.recorder.Eventf(ref, corev1.EventTypeWarning, "DeletionCapReached",
+			"Deleted as many pods as one sync may, violation of %s left for the next sync: %s", described, enforced.Message)
+	}
+	c.recordCost(ns, policy, enforced)
+	c.recordUsage(ns, policy, enforced)
+	c.setUsage(ns, policy, enforced)
+	if err := c.markNamespace(ctx, ns, marksFor(policy, enforced)); err != nil {
+		logger.Error(err, "Failed to label and annotate namespace with quota state")
+	}
+	logger.V(logging.Debug).Info("Enforced policy without custom resource", "pods", enforced.CurrentPods, "cpu", enforced.CurrentCPU, "memory", enforced.CurrentMemory, "violation", enforced.Violation)
+	return nil
+}
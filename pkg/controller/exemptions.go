@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// exemptionCache keeps the Exemptions of each namespace while its active QuotaExemptions are
+// unchanged. The usage tracker counts a namespace again whenever its exclusions change, so
+// building them afresh on every sync would have it recount every time.
+type exemptionCache struct {
+	mu          sync.Mutex
+	byNamespace map[string]cachedExemptions
+}
+
+type cachedExemptions struct {
+	key        string // names and resourceVersions of the active exemptions
+	exemptions *handlers.Exemptions
+}
+
+// get returns the exemptions of ns last stored, nil if none.
+func (e *exemptionCache) get(ns string) *handlers.Exemptions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.byNamespace[ns].exemptions
+}
+
+// forget drops ns, e.g. once it has no policy left.
+func (e *exemptionCache) forget(ns string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.byNamespace, ns)
+}
+
+// enqueueExemption queues the namespace of an exemption that was added, changed or deleted.
+func (c *Controller) enqueueExemption(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if exemption, ok := obj.(*v1beta1.QuotaExemption); ok {
+		c.queue.Add(exemption.Namespace)
+	}
+}
+
+// syncExemptions stores the exemptions of ns in force at now for exclusions to pick up, marks
+// the ones that have expired, and queues ns again for when the next one expires.
+func (c *Controller) syncExemptions(ctx context.Context, ns string, now time.Time) error {
+	if c.Exemptions == nil {
+		return nil
+	}
+	items, err := c.Exemptions.Lister().QuotaExemptions(ns).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list QuotaExemptions: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	var key strings.Builder
+	var active []*v1beta1.QuotaExemption
+	for _, item := range items {
+		if !item.Active(now) {
+			if err := c.expireExemption(ctx, item); err != nil {
+				// the exemption no longer applies either way, so a failure doesn't fail the sync
+				c.logger.Error(err, "Failed to mark QuotaExemption expired", "namespace", ns, "exemption", item.Name)
+			}
+			continue
+		}
+		active = append(active, item)
+		fmt.Fprintf(&key, "%s/%s,", item.Name, item.ResourceVersion)
+		c.queue.AddAfter(ns, item.Spec.ExpiresAt.Sub(now))
+	}
+
+	c.exemptions.mu.Lock()
+	defer c.exemptions.mu.Unlock()
+	if cached, ok := c.exemptions.byNamespace[ns]; ok && cached.key == key.String() {
+		return nil
+	}
+	exemptions, err := handlers.NewExemptions(active, now)
+	if err != nil {
+		c.logger.Error(err, "Ignoring invalid QuotaExemptions", "namespace", ns)
+	}
+	if c.exemptions.byNamespace == nil {
+		c.exemptions.byNamespace = make(map[string]cachedExemptions)
+	}
+	c.exemptions.byNamespace[ns] = cachedExemptions{key: key.String(), exemptions: exemptions}
+	c.logger.V(logging.Debug).Info("QuotaExemptions changed", "namespace", ns, "active", len(active))
+	return nil
+}
+
+// expireExemption sets the Expired condition of item, unless it is already set.
+func (c *Controller) expireExemption(ctx context.Context, item *v1beta1.QuotaExemption) error {
+	if meta.IsStatusConditionTrue(item.Status.Conditions, v1beta1.ConditionExpired) {
+		return nil
+	}
+	updated := item.DeepCopy()
+	msg := fmt.Sprintf("expired at %s", item.Spec.ExpiresAt.UTC().Format(time.RFC3339))
+	meta.SetStatusCondition(&updated.Status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionExpired,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ExpiresAtPassed",
+		Message:            msg,
+		ObservedGeneration: item.Generation,
+	})
+	if _, err := c.CRclient.PlatformV1beta1().QuotaExemptions(item.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	c.recorder.Event(item, corev1.EventTypeNormal, "ExemptionExpired", "QuotaExemption "+item.Name+" "+msg+", its pods count again")
+	return nil
+}
+
+// exclusions returns the pods a policy of ns with spec leaves out: the cluster-wide defaults as
+// overridden by spec, and the pods of the namespace's QuotaExemptions.
+func (c *Controller) exclusions(ns string, spec *v1beta1.ResourceQuotaPolicySpec) handlers.Exclusions {
+	x := c.enforcer.Exclude.With(spec.ExcludePods)
+	x.Exemptions = c.exemptions.get(ns)
+	return x
+}
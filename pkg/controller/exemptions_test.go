@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestSyncExemptions(t *testing.T) {
+	now := time.Now()
+	active := &v1beta1.QuotaExemption{
+		ObjectMeta: metav1.ObjectMeta{Name: "load-test", Namespace: "ns1", ResourceVersion: "1"},
+		Spec: v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "loadgen"}},
+			ExpiresAt:   metav1.NewTime(now.Add(time.Hour)),
+		},
+	}
+	expired := &v1beta1.QuotaExemption{
+		ObjectMeta: metav1.ObjectMeta{Name: "incident", Namespace: "ns1", ResourceVersion: "1"},
+		Spec: v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			ExpiresAt:   metav1.NewTime(now.Add(-time.Minute)),
+		},
+	}
+	client := fake.NewSimpleClientset(active, expired)
+	informer := externalversions.NewSharedInformerFactory(client, 0).Platform().V1beta1().QuotaExemptions()
+	for _, item := range []*v1beta1.QuotaExemption{active, expired} {
+		if err := informer.Informer().GetIndexer().Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := DefaultOptions
+	c := &Controller{
+		CRclient:   client,
+		Exemptions: informer,
+		enforcer:   &handlers.PodEnforcer{},
+		recorder:   record.NewFakeRecorder(10),
+		logger:     logging.L(),
+		queue:      workqueue.NewTypedRateLimitingQueue(opts.rateLimiter()),
+		opts:       opts,
+	}
+	ctx := context.Background()
+
+	if err := c.syncExemptions(ctx, "ns1", now); err != nil {
+		t.Fatal(err)
+	}
+	x := c.exclusions("ns1", &v1beta1.ResourceQuotaPolicySpec{})
+	loadgen := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "loadgen"}}}
+	web := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}}
+	if !x.Excludes(loadgen) || x.Excludes(web) {
+		t.Fatalf("expected only the active exemption to apply, got %+v", x)
+	}
+
+	got, err := client.PlatformV1beta1().QuotaExemptions("ns1").Get(ctx, "incident", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, v1beta1.ConditionExpired) {
+		t.Fatalf("expected %s condition, got %+v", v1beta1.ConditionExpired, got.Status.Conditions)
+	}
+
+	// unchanged exemptions keep their snapshot, so the usage tracker doesn't recount the namespace
+	if err := c.syncExemptions(ctx, "ns1", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if again := c.exclusions("ns1", &v1beta1.ResourceQuotaPolicySpec{}); again != x {
+		t.Fatal("exclusions changed although the exemptions did not")
+	}
+
+	if err := c.syncExemptions(ctx, "ns1", now.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if c.exclusions("ns1", &v1beta1.ResourceQuotaPolicySpec{}).Excludes(loadgen) {
+		t.Fatal("expired exemption still applies")
+	}
+}
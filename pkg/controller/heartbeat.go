@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStallTimeout is how long queued work may go unprocessed before the controller reports
+// itself unhealthy.
+const DefaultStallTimeout = 5 * time.Minute
+
+// worker is the heartbeat of one worker goroutine.
+type worker struct {
+	// busySince is when the item being processed was taken off the queue, in unix nanoseconds;
+	// zero while the worker is waiting for work.
+	busySince atomic.Int64
+	exited    atomic.Bool
+}
+
+func (w *worker) start() { w.busySince.Store(time.Now().UnixNano()) }
+func (w *worker) done()  { w.busySince.Store(0) }
+
+// markProcessed records that a worker finished an item.
+func (c *Controller) markProcessed() {
+	c.lastProcessed.Store(time.Now().UnixNano())
+}
+
+// Check fails when the queue has items, none has been processed for StallTimeout, and no
+// worker is free to take one: every worker is stuck on an item or exited after a panic. It
+// backs /healthz so Kubernetes restarts a controller whose workers stopped making progress.
+func (c *Controller) Check(context.Context) error {
+	ws := c.workers.Load()
+	queued := c.queue.Len()
+	if ws == nil || queued == 0 {
+		return nil
+	}
+	since := time.Since(time.Unix(0, c.lastProcessed.Load()))
+	if since < c.StallTimeout {
+		return nil
+	}
+
+	var stuck, exited int
+	for _, w := range *ws {
+		switch {
+		case w.exited.Load():
+			exited++
+		case w.busySince.Load() != 0:
+			stuck++
+		default:
+			// idle worker about to pick up the queued item
+			return nil
+		}
+	}
+	return fmt.Errorf("%d items queued but none processed for %s (%d workers stuck, %d exited)",
+		queued, since.Round(time.Second), stuck, exited)
+}
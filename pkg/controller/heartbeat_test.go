@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestCheckDetectsStalledWorkers(t *testing.T) {
+	newController := func(queued bool, workers ...*worker) *Controller {
+		c := &Controller{
+			queue:        workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[any]()),
+			StallTimeout: time.Minute,
+		}
+		if queued {
+			c.queue.Add("ns1")
+		}
+		c.workers.Store(&workers)
+		c.lastProcessed.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+		return c
+	}
+	busy := func() *worker { w := &worker{}; w.start(); return w }
+	exited := func() *worker { w := &worker{}; w.exited.Store(true); return w }
+
+	tests := []struct {
+		name    string
+		c       *Controller
+		wantErr bool
+	}{
+		{"empty queue", newController(false, busy(), exited()), false},
+		{"idle worker available", newController(true, busy(), &worker{}), false},
+		{"all workers stuck or exited", newController(true, busy(), exited()), true},
+	}
+	for _, tt := range tests {
+		if err := tt.c.Check(context.Background()); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	recent := newController(true, busy(), exited())
+	recent.markProcessed()
+	if err := recent.Check(context.Background()); err != nil {
+		t.Errorf("item processed within StallTimeout: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// suspendPatch sets spec.suspend on a Job; the Job controller then stops its pods, and resuming
+// the Job starts them again.
+var suspendPatch = []byte(`{"spec":{"suspend":true}}`)
+
+// suspendExcessJobs suspends the newest active Jobs of ns past maxActive, e.g. ones created
+// before the limit was set or while the webhook was unavailable, and records an event on items
+// for each. Jobs are suspended as the identity the policy impersonates, if any. Suspended Jobs
+// stay suspended until someone resumes them.
+func (c *Controller) suspendExcessJobs(ctx context.Context, ns string, maxActive int32, impersonate *v1beta1.Impersonation, items []*v1beta1.ResourceQuotaPolicy) error {
+	if maxActive <= 0 {
+		return nil
+	}
+	jobs, err := c.clientset.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list jobs: %w", err)
+	}
+	for _, job := range handlers.ExcessJobs(jobs.Items, maxActive) {
+		if c.enforcer.DryRun() {
+			c.logger.Info("Would suspend job over maxActiveJobs (dry run)", "namespace", ns, "job", job.Name, "maxActiveJobs", maxActive)
+			for _, item := range items {
+				c.recorder.Eventf(item, corev1.EventTypeWarning, "JobWouldBeSuspended",
+					"Would suspend job %s to keep at most %d active jobs under ResourceQuotaPolicy %s (dry run)", job.Name, maxActive, item.Name)
+			}
+			continue
+		}
+		writer, _, err := c.enforcer.Writer(impersonate)
+		if err != nil {
+			return err
+		}
+		_, err = writer.BatchV1().Jobs(ns).Patch(ctx, job.Name, types.MergePatchType, suspendPatch, metav1.PatchOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("suspend job %s: %w", job.Name, err)
+		}
+		c.logger.Info("Suspended job over maxActiveJobs", "namespace", ns, "job", job.Name, "maxActiveJobs", maxActive)
+		for _, item := range items {
+			c.recorder.Eventf(
+				item,
+				corev1.EventTypeWarning,
+				"JobSuspended",
+				"Suspended job %s to keep at most %d active jobs under ResourceQuotaPolicy %s", job.Name, maxActive, item.Name,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// Annotations the controller keeps on each namespace with a policy, so head-room shows up in
+// `kubectl describe ns` without querying the enforcer. A remaining-* annotation is absent when
+// the policy doesn't limit that resource.
+const (
+	AnnotationPolicy          = "quota.platform/policy"
+	AnnotationRemainingPods   = "quota.platform/remaining-pods"
+	AnnotationRemainingCPU    = "quota.platform/remaining-cpu"
+	AnnotationRemainingMemory = "quota.platform/remaining-memory"
+)
+
+// LabelState is set on each namespace with a policy to one of the QuotaState* values, so
+// dashboards and other controllers can select namespaces by it.
+const LabelState = "quota.platform/state"
+
+const (
+	QuotaStateOK        = "ok"
+	QuotaStateWarning   = "warning"
+	QuotaStateViolating = "violating"
+)
+
+var (
+	quotaAnnotations = []string{AnnotationPolicy, AnnotationRemainingPods, AnnotationRemainingCPU, AnnotationRemainingMemory}
+	quotaLabels      = []string{LabelState}
+)
+
+// namespaceMarks are the labels and annotations the controller keeps on a namespace. The zero
+// value removes them all.
+type namespaceMarks struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// marksFor returns the marks for policy at the usage in enforced.
+func marksFor(policy handlers.Policy, enforced handlers.EnforcementResult) namespaceMarks {
+	annotations := map[string]string{AnnotationPolicy: policy.Name}
+	if policy.MaxPods > 0 {
+		annotations[AnnotationRemainingPods] = strconv.Itoa(max(policy.MaxPods-enforced.CurrentPods, 0))
+	}
+	if policy.MaxCPU.Sign() > 0 {
+		annotations[AnnotationRemainingCPU] = remaining(policy.MaxCPU, resource.MustParse(enforced.CurrentCPU))
+	}
+	if policy.MaxMemory.Sign() > 0 {
+		annotations[AnnotationRemainingMemory] = remaining(policy.MaxMemory, resource.MustParse(enforced.CurrentMemory))
+	}
+
+	state := QuotaStateOK
+	switch {
+	case enforced.Violation:
+		state = QuotaStateViolating
+	case len(enforced.Warnings) > 0:
+		state = QuotaStateWarning
+	}
+	return namespaceMarks{labels: map[string]string{LabelState: state}, annotations: annotations}
+}
+
+// remaining formats what used leaves of limit, never below zero.
+func remaining(limit, used resource.Quantity) string {
+	left := limit.DeepCopy()
+	left.Sub(used)
+	if left.Sign() < 0 {
+		return "0"
+	}
+	return left.String()
+}
+
+// markNamespace sets the quota labels and annotations of ns to marks, removing those not in it.
+// They are applied as FieldManager, so labels and annotations of other owners are left alone.
+// Nothing is written when the namespace already carries them, so the update it triggers doesn't
+// lead to another write on the next sync.
+func (c *Controller) markNamespace(ctx context.Context, ns string, marks namespaceMarks) error {
+	if !c.opts.AnnotateNamespaces && !c.opts.LabelNamespaces {
+		return nil
+	}
+	current, err := c.namespace(ctx, ns)
+	if err != nil || current == nil {
+		return err
+	}
+
+	apply := corev1ac.Namespace(ns)
+	changed := false
+	if c.opts.AnnotateNamespaces {
+		changed = changed || marksChanged(quotaAnnotations, current.Annotations, marks.annotations)
+		apply.WithAnnotations(marks.annotations)
+	}
+	if c.opts.LabelNamespaces {
+		changed = changed || marksChanged(quotaLabels, current.Labels, marks.labels)
+		apply.WithLabels(marks.labels)
+	}
+	if !changed {
+		return nil
+	}
+
+	namespaces := c.clientset.CoreV1().Namespaces()
+	err = adoptLegacyFields(current.DeepCopy(), func(body []byte) error {
+		_, err := namespaces.Patch(ctx, ns, types.JSONPatchType, body, metav1.PatchOptions{})
+		return err
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		c.logger.Error(err, "Failed to adopt namespace marks written by an earlier version", "namespace", ns)
+	}
+	_, err = namespaces.Apply(ctx, apply, metav1.ApplyOptions{FieldManager: FieldManager})
+	if apierrors.IsNotFound(err) {
+		// the namespace is gone along with anything to mark
+		return nil
+	}
+	return err
+}
+
+// marksChanged reports whether the keys of have differ from want.
+func marksChanged(keys []string, have, want map[string]string) bool {
+	for _, key := range keys {
+		w, set := want[key]
+		h, had := have[key]
+		if set != had || h != w {
+			return true
+		}
+	}
+	return false
+}
+
+// namespace reads ns from the informer cache, or from the API server when there is no
+// informer. It returns nil when the namespace doesn't exist.
+func (c *Controller) namespace(ctx context.Context, ns string) (*corev1.Namespace, error) {
+	if c.nsInformer != nil {
+		obj, exists, err := c.nsInformer.GetStore().GetByKey(ns)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return obj.(*corev1.Namespace), nil
+	}
+	namespace, err := c.clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return namespace, err
+}
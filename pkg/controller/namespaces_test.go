@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMarkNamespace(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Annotations: map[string]string{"owner": "team-a"}}}
+	client := fakeclient.NewClientset(ns)
+	c := &Controller{clientset: client, opts: Options{AnnotateNamespaces: true, LabelNamespaces: true}}
+	ctx := context.Background()
+
+	policy := handlers.Policy{Name: "quota", MaxPods: 10, MaxCPU: resource.MustParse("2")}
+	enforced := handlers.EnforcementResult{CurrentPods: 4, CurrentCPU: "2500m", CurrentMemory: "1Gi", Violation: true}
+	if err := c.markNamespace(ctx, "ns1", marksFor(policy, enforced)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.CoreV1().Namespaces().Get(ctx, "ns1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// memory is not limited, and cpu is already over its limit
+	want := map[string]string{
+		"owner":                 "team-a",
+		AnnotationPolicy:        "quota",
+		AnnotationRemainingPods: "6",
+		AnnotationRemainingCPU:  "0",
+	}
+	if !reflect.DeepEqual(got.Annotations, want) {
+		t.Fatalf("annotations = %v, want %v", got.Annotations, want)
+	}
+	if state := got.Labels[LabelState]; state != QuotaStateViolating {
+		t.Fatalf("state label = %q, want %q", state, QuotaStateViolating)
+	}
+
+	// unchanged annotations are not written again
+	actions := len(client.Actions())
+	if err := c.markNamespace(ctx, "ns1", marksFor(policy, enforced)); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range client.Actions()[actions:] {
+		if a.GetVerb() == "patch" {
+			t.Fatal("patched namespace with unchanged annotations")
+		}
+	}
+
+	// removing the policy removes the labels and annotations
+	if err := c.markNamespace(ctx, "ns1", namespaceMarks{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = client.CoreV1().Namespaces().Get(ctx, "ns1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"owner": "team-a"}; !reflect.DeepEqual(got.Annotations, want) {
+		t.Fatalf("annotations = %v, want %v", got.Annotations, want)
+	}
+	if _, ok := got.Labels[LabelState]; ok {
+		t.Fatalf("state label left on namespace: %v", got.Labels)
+	}
+}
+
+func TestMarkNamespaceAdoptsLegacyMarks(t *testing.T) {
+	// marks merge-patched by a version of the controller that predates server-side apply
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ns1",
+		Labels:      map[string]string{LabelState: QuotaStateOK},
+		Annotations: map[string]string{AnnotationPolicy: "quota", "owner": "team-a"},
+		ManagedFields: []metav1.ManagedFieldsEntry{{
+			Manager:    "kubectl",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:owner":{}}}}`)},
+		}, {
+			Manager:    "rqe",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:quota.platform/policy":{}},"f:labels":{"f:quota.platform/state":{}}}}`)},
+		}},
+	}}
+	client := fakeclient.NewClientset(ns)
+	c := &Controller{clientset: client, logger: logging.L(), opts: Options{AnnotateNamespaces: true, LabelNamespaces: true}}
+	ctx := context.Background()
+
+	if err := c.markNamespace(ctx, "ns1", namespaceMarks{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.CoreV1().Namespaces().Get(ctx, "ns1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"owner": "team-a"}; !reflect.DeepEqual(got.Annotations, want) {
+		t.Fatalf("annotations = %v, want %v", got.Annotations, want)
+	}
+	if len(got.Labels) != 0 {
+		t.Fatalf("labels = %v, want none", got.Labels)
+	}
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"flag"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// Options tunes how often the controller syncs namespaces and how it retries those that fail.
+type Options struct {
+	// FullResync is how often every namespace is queued regardless of events; zero disables it.
+	FullResync time.Duration
+	// UsageAudit is how often the running usage totals are rebuilt from the pod cache to correct
+	// drift; zero disables it.
+	UsageAudit time.Duration
+	// SyncTimeout bounds one namespace sync, API calls included; zero means no limit.
+	SyncTimeout time.Duration
+
+	// MaxStatusAge is how long an unchanged policy status may go without being written again;
+	// zero only writes it when it changes.
+	MaxStatusAge time.Duration
+
+	// RetryBaseDelay is the backoff after the first failure; it doubles on every further one.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff.
+	RetryMaxDelay time.Duration
+	// MaxRetries is how many consecutive failures a namespace gets before it is parked with a
+	// ReconcileFailed condition instead of being requeued. Zero retries forever.
+	MaxRetries int
+
+	// AnnotateNamespaces keeps the remaining quota in annotations on each namespace with a policy.
+	AnnotateNamespaces bool
+	// LabelNamespaces keeps the quota.platform/state label on each namespace with a policy.
+	LabelNamespaces bool
+
+	// MergeStrategy combines the policies of namespaces that have more than one.
+	MergeStrategy v1beta1.MergeStrategy
+
+	// Cluster names the cluster the controller enforces policies in, in its metrics, logs, audit
+	// events and work queue, when one process manages several clusters; empty for a single one.
+	// It has no flag.
+	Cluster string
+}
+
+// DefaultOptions keeps client-go's default per-item backoff and parks a namespace after roughly
+// 80 seconds of consecutive failures.
+var DefaultOptions = Options{
+	FullResync:     60 * time.Second,
+	UsageAudit:     10 * time.Minute,
+	SyncTimeout:    2 * time.Minute,
+	MaxStatusAge:   10 * time.Minute,
+	RetryBaseDelay: 5 * time.Millisecond,
+	RetryMaxDelay:  1000 * time.Second,
+	MaxRetries:     15,
+
+	AnnotateNamespaces: true,
+	LabelNamespaces:    true,
+
+	MergeStrategy: v1beta1.MergeHighestPriorityOnly,
+}
+
+// AddFlags registers the resync, usage audit, status refresh, retry, namespace marking and policy merge flags.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&o.SyncTimeout, "sync-timeout", DefaultOptions.SyncTimeout, "maximum time one namespace sync, API calls included, may take before it is cancelled and retried (0 disables)")
+	fs.DurationVar(&o.FullResync, "full-resync", DefaultOptions.FullResync, "how often every namespace is re-enforced even without events (0 disables)")
+	fs.DurationVar(&o.UsageAudit, "usage-audit-interval", DefaultOptions.UsageAudit, "how often the running usage totals are rebuilt from the pod cache to correct drift (0 disables)")
+	fs.DurationVar(&o.MaxStatusAge, "max-status-age", DefaultOptions.MaxStatusAge, "how long an unchanged policy status goes without being rewritten (0 only writes it on change)")
+	fs.DurationVar(&o.RetryBaseDelay, "retry-base-delay", DefaultOptions.RetryBaseDelay, "backoff after a namespace first fails to sync, doubled on each further failure")
+	fs.DurationVar(&o.RetryMaxDelay, "retry-max-delay", DefaultOptions.RetryMaxDelay, "maximum backoff between retries of a failing namespace")
+	fs.IntVar(&o.MaxRetries, "max-retries", DefaultOptions.MaxRetries, "consecutive failures before a namespace is parked with a ReconcileFailed condition (0 retries forever)")
+	fs.BoolVar(&o.AnnotateNamespaces, "annotate-namespaces", DefaultOptions.AnnotateNamespaces, "keep quota.platform/remaining-* annotations with the remaining quota on namespaces with a policy")
+	fs.BoolVar(&o.LabelNamespaces, "label-namespaces", DefaultOptions.LabelNamespaces, "keep a quota.platform/state=ok|warning|violating label on namespaces with a policy")
+	o.MergeStrategy = DefaultOptions.MergeStrategy
+	fs.Func("policy-merge", "how the policies of a namespace with several combine: highestPriorityOnly, strictestWins or sumAllowances (default highestPriorityOnly)", func(s string) (err error) {
+		o.MergeStrategy, err = v1beta1.ParseMergeStrategy(s)
+		return err
+	})
+}
+
+// queueName names the work queue in the workqueue metrics.
+func (o Options) queueName() string {
+	if o.Cluster == "" {
+		return "resource-quota-enforcer"
+	}
+	return "resource-quota-enforcer-" + o.Cluster
+}
+
+func (o Options) rateLimiter() workqueue.TypedRateLimiter[any] {
+	return workqueue.NewTypedItemExponentialFailureRateLimiter[any](o.RetryBaseDelay, o.RetryMaxDelay)
+}
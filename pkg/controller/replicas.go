@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// replicaViolations lists the Deployments and StatefulSets of ns with more than maxReplicas
+// replicas. The webhook keeps new ones from getting there; these are the ones it didn't see, e.g.
+// created before the limit was set.
+func (c *Controller) replicaViolations(ctx context.Context, ns string, maxReplicas int32) ([]v1beta1.WorkloadReplicas, error) {
+	if maxReplicas <= 0 {
+		return nil, nil
+	}
+	var out []v1beta1.WorkloadReplicas
+	deployments, err := c.clientset.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		if replicas := replicasOrDefault(d.Spec.Replicas); replicas > maxReplicas {
+			out = append(out, v1beta1.WorkloadReplicas{Kind: "Deployment", Name: d.Name, Replicas: replicas})
+		}
+	}
+	statefulSets, err := c.clientset.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ss := range statefulSets.Items {
+		if replicas := replicasOrDefault(ss.Spec.Replicas); replicas > maxReplicas {
+			out = append(out, v1beta1.WorkloadReplicas{Kind: "StatefulSet", Name: ss.Name, Replicas: replicas})
+		}
+	}
+	return out, nil
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// retryOrPark requeues ns after a failed sync, or parks it once it has failed MaxRetries times
+// in a row. A parked namespace is picked up again by the next pod or namespace event or the
+// periodic resync, starting from a clean failure count.
+func (c *Controller) retryOrPark(ctx context.Context, ns string, syncErr error) {
+	c.failuresMu.Lock()
+	c.failures[ns]++
+	failures := c.failures[ns]
+	if c.opts.MaxRetries > 0 && failures >= c.opts.MaxRetries {
+		delete(c.failures, ns)
+	}
+	c.failuresMu.Unlock()
+
+	if c.opts.MaxRetries == 0 || failures < c.opts.MaxRetries {
+		c.queue.AddRateLimited(ns)
+		c.logger.Error(syncErr, "Error syncing namespace, will retry", "namespace", ns, "failures", failures)
+		return
+	}
+
+	c.queue.Forget(ns)
+	c.logger.Error(syncErr, "Giving up on namespace until its next change", "namespace", ns, "failures", failures)
+	if err := c.markReconcileFailed(ctx, ns, failures, syncErr); err != nil {
+		c.logger.Error(err, "Failed to set ReconcileFailed condition", "namespace", ns)
+	}
+}
+
+// resetFailures clears the failure count of ns after a successful sync.
+func (c *Controller) resetFailures(ns string) {
+	c.failuresMu.Lock()
+	delete(c.failures, ns)
+	c.failuresMu.Unlock()
+}
+
+// markReconcileFailed sets the ReconcileFailed condition on every policy in ns.
+func (c *Controller) markReconcileFailed(ctx context.Context, ns string, failures int, syncErr error) error {
+	items, err := c.policyLister.ResourceQuotaPolicies(ns).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("gave up after %d consecutive failures: %v", failures, syncErr)
+	for _, item := range items {
+		status := item.Status.DeepCopy()
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionReconcileFailed,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RetriesExhausted",
+			Message:            msg,
+			ObservedGeneration: item.Generation,
+		})
+		if _, err := c.updatePolicyStatus(ctx, item, status); err != nil {
+			return err
+		}
+		c.recorder.Event(item, corev1.EventTypeWarning, "ReconcileParked", msg)
+	}
+	return nil
+}
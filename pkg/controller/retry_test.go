@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestRetryOrParkParksAfterMaxRetries(t *testing.T) {
+	policy := &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns1"}}
+	opts := Options{RetryBaseDelay: DefaultOptions.RetryBaseDelay, RetryMaxDelay: DefaultOptions.RetryMaxDelay, MaxRetries: 3}
+	c := &Controller{
+		CRclient:     fake.NewSimpleClientset(policy),
+		policyLister: policyLister(t, policy),
+		recorder:     record.NewFakeRecorder(10),
+		logger:       logging.L(),
+		queue:        workqueue.NewTypedRateLimitingQueue(opts.rateLimiter()),
+		opts:         opts,
+		failures:     make(map[string]int),
+	}
+	ctx := context.Background()
+	syncErr := errors.New("apiserver unavailable")
+
+	for i := 1; i < opts.MaxRetries; i++ {
+		c.retryOrPark(ctx, "ns1", syncErr)
+		if got := c.queue.NumRequeues("ns1"); got != i {
+			t.Fatalf("after %d failures NumRequeues = %d, want %d", i, got, i)
+		}
+	}
+
+	c.retryOrPark(ctx, "ns1", syncErr)
+	if got := c.queue.NumRequeues("ns1"); got != 0 {
+		t.Fatalf("parked namespace still has %d requeues", got)
+	}
+	if len(c.failures) != 0 {
+		t.Fatalf("failure count not reset after parking: %v", c.failures)
+	}
+
+	got, err := c.CRclient.PlatformV1beta1().ResourceQuotaPolicies("ns1").Get(ctx, "p1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, v1beta1.ConditionReconcileFailed) {
+		t.Fatalf("expected %s condition, got %+v", v1beta1.ConditionReconcileFailed, got.Status.Conditions)
+	}
+}
+
+// policyLister returns a lister serving policies, as the controller's policy informer would.
+func policyLister(t *testing.T, policies ...*v1beta1.ResourceQuotaPolicy) listers.ResourceQuotaPolicyLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, p := range policies {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return listers.NewResourceQuotaPolicyLister(indexer)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusTime returns t as the API server keeps it in a status: metav1.Time serializes to whole
+// seconds, so a status built with sub-second times would never equal the one read back.
+func statusTime(t time.Time) metav1.Time {
+	return metav1.NewTime(t.Truncate(time.Second))
+}
+
+// statusWrites remembers when the controller last wrote the status of each policy, so an
+// unchanged status is only rewritten once it is older than Options.MaxStatusAge.
+type statusWrites struct {
+	mu   sync.Mutex
+	last map[string]time.Time // by namespace/name
+}
+
+func (w *statusWrites) written(item *v1beta1.ResourceQuotaPolicy, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.last == nil {
+		w.last = make(map[string]time.Time)
+	}
+	w.last[item.Namespace+"/"+item.Name] = at
+}
+
+func (w *statusWrites) lastWritten(item *v1beta1.ResourceQuotaPolicy) (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	at, ok := w.last[item.Namespace+"/"+item.Name]
+	return at, ok
+}
+
+// forget drops the policies of ns, e.g. once it has none left.
+func (w *statusWrites) forget(ns string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key := range w.last {
+		if strings.HasPrefix(key, ns+"/") {
+			delete(w.last, key)
+		}
+	}
+}
+
+// statusCurrent reports whether item already carries status and was written by this controller
+// less than MaxStatusAge before now, so writing it again can be skipped.
+func (c *Controller) statusCurrent(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus, now time.Time) bool {
+	if !apiequality.Semantic.DeepEqual(&item.Status, status) {
+		return false
+	}
+	if c.opts.MaxStatusAge <= 0 {
+		return true
+	}
+	last, ok := c.statusWrites.lastWritten(item)
+	return ok && now.Sub(last) < c.opts.MaxStatusAge
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusCurrent(t *testing.T) {
+	item := &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns1"},
+		Status:     v1beta1.ResourceQuotaPolicyStatus{CurrentPods: 3, CPUUsage: "1500m", MemoryUsage: "1Gi"},
+	}
+	c := &Controller{opts: Options{MaxStatusAge: time.Minute}}
+	now := time.Now()
+	same := item.Status.DeepCopy()
+
+	if c.statusCurrent(item, same, now) {
+		t.Fatal("status never written by this controller reported current")
+	}
+	c.statusWrites.written(item, now)
+	if !c.statusCurrent(item, same, now.Add(30*time.Second)) {
+		t.Fatal("unchanged, recently written status not reported current")
+	}
+	if c.statusCurrent(item, same, now.Add(2*time.Minute)) {
+		t.Fatal("status older than MaxStatusAge reported current")
+	}
+	changed := same.DeepCopy()
+	changed.CurrentPods++
+	if c.statusCurrent(item, changed, now) {
+		t.Fatal("changed status reported current")
+	}
+
+	c.statusWrites.forget("ns1")
+	if _, ok := c.statusWrites.lastWritten(item); ok {
+		t.Fatal("write time kept after forgetting the namespace")
+	}
+}
+
+// The API server keeps status times to the second, so a status with a forecast has to compare
+// equal to itself once written and read back, or every sync would rewrite it.
+func TestStatusCurrentWithForecast(t *testing.T) {
+	c := &Controller{opts: Options{MaxStatusAge: time.Hour}}
+	policy := handlers.Policy{Name: "p1", MaxPods: 10}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 678901234, time.UTC)
+	build := func(history []v1beta1.UsageSnapshot, now time.Time) *v1beta1.ResourceQuotaPolicyStatus {
+		status := &v1beta1.ResourceQuotaPolicyStatus{CurrentPods: 3, CPUUsage: "0", MemoryUsage: "0"}
+		status.History = append([]v1beta1.UsageSnapshot(nil), history...)
+		status.RecordUsage(v1beta1.UsageSnapshot{Time: statusTime(now), Pods: 3, CPU: resource.MustParse("0"), Memory: resource.MustParse("0")})
+		status.Forecast = c.forecastExhaustion("ns1", status.History, policy, now)
+		return status
+	}
+
+	var history []v1beta1.UsageSnapshot
+	for i, pods := range []int32{1, 3} {
+		at := now.Add(time.Duration(i-2) * 47 * time.Minute)
+		history = append(history, v1beta1.UsageSnapshot{Time: statusTime(at), Pods: pods, CPU: resource.MustParse("0"), Memory: resource.MustParse("0")})
+	}
+	written := build(history, now)
+	if written.Forecast == nil {
+		t.Fatal("no forecast for growing pod usage")
+	}
+
+	raw, err := json.Marshal(written)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns1"}}
+	if err := json.Unmarshal(raw, &item.Status); err != nil {
+		t.Fatal(err)
+	}
+	c.statusWrites.written(item, now)
+
+	later := now.Add(time.Minute + 300*time.Millisecond)
+	if next := build(item.Status.History, later); !c.statusCurrent(item, next, later) {
+		t.Fatalf("forecast %v read back as %v reported changed", next.Forecast.ExhaustionTime, item.Status.Forecast.ExhaustionTime)
+	}
+}
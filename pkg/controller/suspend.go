@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// splitSuspended separates the policies of a namespace that spec.suspend pauses from the ones
+// that are enforced, keeping their order.
+func splitSuspended(items []*v1beta1.ResourceQuotaPolicy) (active, suspended []*v1beta1.ResourceQuotaPolicy) {
+	for _, item := range items {
+		if item.Spec.Suspend {
+			suspended = append(suspended, item)
+		} else {
+			active = append(active, item)
+		}
+	}
+	return active, suspended
+}
+
+// syncSuspended writes the usage of ns, as item would count it, to the status of item, a
+// suspended policy, without enforcing it. Its history keeps being recorded so it is complete
+// once the policy is resumed.
+func (c *Controller) syncSuspended(ctx context.Context, ns string, item *v1beta1.ResourceQuotaPolicy, now time.Time) error {
+	spec, _ := item.Spec.EffectiveAt(now)
+	spec.ResolveClusterShare(c.Capacity.Allocatable())
+	policy := handlers.ParsePolicy(&spec)
+	policy.Exclude = c.exclusions(ns, &spec)
+	policy.Name = item.Name
+
+	usage, err := c.enforcer.ComputeUsage(ctx, ns, policy)
+	if err != nil {
+		return fmt.Errorf("compute usage for %s: %w", item.Name, err)
+	}
+	msg := "suspended, not enforced"
+	if usage.Violation {
+		msg += ": " + usage.Message
+	}
+	status := &v1beta1.ResourceQuotaPolicyStatus{
+		CurrentPods: int32(usage.CurrentPods),
+		CPUUsage:    usage.CurrentCPU,
+		MemoryUsage: usage.CurrentMemory,
+		Violation:   usage.Violation,
+		Message:     msg,
+	}
+	status.Conditions = append([]metav1.Condition(nil), item.Status.Conditions...)
+	status.History = append([]v1beta1.UsageSnapshot(nil), item.Status.History...)
+	status.RecordUsage(v1beta1.UsageSnapshot{
+		Time:   statusTime(now),
+		Pods:   int32(usage.CurrentPods),
+		CPU:    resource.MustParse(usage.CurrentCPU),
+		Memory: resource.MustParse(usage.CurrentMemory),
+	})
+	if !meta.IsStatusConditionTrue(item.Status.Conditions, v1beta1.ConditionSuspended) {
+		c.recorder.Event(item, corev1.EventTypeNormal, "PolicySuspended", "ResourceQuotaPolicy "+item.Name+" is suspended, its limits are not enforced")
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionSuspended,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SpecSuspend",
+		Message:            "spec.suspend is true",
+		ObservedGeneration: item.Generation,
+	})
+
+	if c.statusCurrent(item, status, now) {
+		c.logger.V(logging.Debug).Info("Status unchanged, not updating", "namespace", ns, "policy", item.Name)
+		return nil
+	}
+	if _, err := c.updatePolicyStatus(ctx, item, status); err != nil {
+		return fmt.Errorf("update status of %s: %w", item.Name, err)
+	}
+	c.statusWrites.written(item, now)
+	return nil
+}
+
+// setResumedCondition records on item, an enforced policy, that it is no longer suspended, if it
+// ever was.
+func (c *Controller) setResumedCondition(item *v1beta1.ResourceQuotaPolicy, status *v1beta1.ResourceQuotaPolicyStatus) {
+	if meta.FindStatusCondition(status.Conditions, v1beta1.ConditionSuspended) == nil {
+		return
+	}
+	if meta.IsStatusConditionTrue(item.Status.Conditions, v1beta1.ConditionSuspended) {
+		c.recorder.Event(item, corev1.EventTypeNormal, "PolicyResumed", "ResourceQuotaPolicy "+item.Name+" is no longer suspended, its limits are enforced again")
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionSuspended,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Resumed",
+		ObservedGeneration: item.Generation,
+	})
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSyncSuspended(t *testing.T) {
+	policy := &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "ns1", Generation: 2},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 1, Suspend: true},
+	}
+	var pods []runtime.Object
+	for _, name := range []string{"a", "b"} {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	kube := kubefake.NewSimpleClientset(pods...)
+	client := fake.NewSimpleClientset(policy)
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{
+		CRclient: client,
+		enforcer: &handlers.PodEnforcer{Client: kube},
+		recorder: recorder,
+		logger:   logging.L(),
+	}
+
+	if err := c.syncSuspended(context.Background(), "ns1", policy, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range kube.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Fatalf("suspended policy deleted a pod: %v", a)
+		}
+	}
+
+	var status *v1beta1.ResourceQuotaPolicyStatus
+	for _, a := range client.Actions() {
+		if patch, ok := a.(k8stesting.PatchAction); ok && patch.GetPatchType() == types.ApplyPatchType {
+			var applied v1beta1.ResourceQuotaPolicy
+			if err := json.Unmarshal(patch.GetPatch(), &applied); err != nil {
+				t.Fatal(err)
+			}
+			status = &applied.Status
+		}
+	}
+	if status == nil {
+		t.Fatal("status not written")
+	}
+	if status.CurrentPods != 2 || !status.Violation || !strings.HasPrefix(status.Message, "suspended") || len(status.History) != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, v1beta1.ConditionSuspended) {
+		t.Fatalf("expected %s condition, got %+v", v1beta1.ConditionSuspended, status.Conditions)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "PolicySuspended") {
+		t.Fatalf("unexpected event %q", event)
+	}
+
+	// resuming flips the condition back
+	policy.Status = *status
+	resumed := status.DeepCopy()
+	c.setResumedCondition(policy, resumed)
+	if cond := meta.FindStatusCondition(resumed.Conditions, v1beta1.ConditionSuspended); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("expected %s=False after resuming, got %+v", v1beta1.ConditionSuspended, resumed.Conditions)
+	}
+}
+
+func TestSplitSuspended(t *testing.T) {
+	a := &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "a"}}
+	b := &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: v1beta1.ResourceQuotaPolicySpec{Suspend: true}}
+	c := &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "c"}}
+	active, suspended := splitSuspended([]*v1beta1.ResourceQuotaPolicy{a, b, c})
+	if len(active) != 2 || active[0] != a || active[1] != c || len(suspended) != 1 || suspended[0] != b {
+		t.Fatalf("splitSuspended = %v, %v", active, suspended)
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// NamespaceUsage is the usage of a namespace at its last sync, against the limits enforced then.
+type NamespaceUsage struct {
+	Policy    string
+	Pods      int
+	MaxPods   int
+	CPU       resource.Quantity
+	MaxCPU    resource.Quantity
+	Memory    resource.Quantity
+	MaxMemory resource.Quantity
+	Violation bool
+	Synced    time.Time
+}
+
+// setUsage keeps the usage enforced found in ns for Usage.
+func (c *Controller) setUsage(ns string, policy handlers.Policy, enforced handlers.EnforcementResult) {
+	c.usage.Set(ns, NamespaceUsage{
+		Policy:    policy.Name,
+		Pods:      enforced.CurrentPods,
+		MaxPods:   policy.MaxPods,
+		CPU:       resource.MustParse(enforced.CurrentCPU),
+		MaxCPU:    policy.MaxCPU,
+		Memory:    resource.MustParse(enforced.CurrentMemory),
+		MaxMemory: policy.MaxMemory,
+		Violation: enforced.Violation,
+		Synced:    time.Now(),
+	})
+}
+
+// Usage returns the usage of every namespace with a policy at its last sync.
+func (c *Controller) Usage() map[string]NamespaceUsage {
+	return c.usage.Snapshot("")
+}
@@ -0,0 +1,195 @@
+// Package dashboard serves a small read-only web page of quota usage, denials and enforcement
+// actions, drawn from what the controller and the webhook already hold in memory, for clusters
+// without Grafana.
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// RefreshInterval is how often the page reloads itself.
+const RefreshInterval = 15 * time.Second
+
+// Namespace is the usage of a namespace at its last sync.
+type Namespace struct {
+	Name      string
+	Policy    string
+	Resources []Resource
+	Violation bool
+	Synced    time.Time
+}
+
+// Resource is the usage of one resource against its limit.
+type Resource struct {
+	Name  string
+	Used  string
+	Limit string
+	// Fraction is Used / Limit.
+	Fraction float64
+}
+
+// NewResource returns the usage of resource name. A zero limit is full as soon as anything is
+// used.
+func NewResource(name string, used, limit resource.Quantity) Resource {
+	r := Resource{Name: name, Used: used.String(), Limit: limit.String()}
+	switch {
+	case !limit.IsZero():
+		r.Fraction = used.AsApproximateFloat64() / limit.AsApproximateFloat64()
+	case !used.IsZero():
+		r.Fraction = 1
+	}
+	return r
+}
+
+// Percent is Fraction as a bar width, capped at 100.
+func (r Resource) Percent() int {
+	return int(math.Min(100, math.Round(r.Fraction*100)))
+}
+
+// Level classes the bar: "over" past the limit, "high" from 80% and "ok" below.
+func (r Resource) Level() string {
+	switch {
+	case r.Fraction > 1:
+		return "over"
+	case r.Fraction >= 0.8:
+		return "high"
+	}
+	return "ok"
+}
+
+// Handler renders the dashboard.
+type Handler struct {
+	// Title heads the page, e.g. the name of the binary serving it.
+	Title string
+	// Usage returns the usage of every namespace; nil leaves usage out.
+	Usage func() []Namespace
+	// Recent holds the denials and enforcement actions shown; nil leaves them out.
+	Recent *Recent
+}
+
+type page struct {
+	Title      string
+	Refresh    int
+	Now        time.Time
+	ShowUsage  bool
+	Namespaces []Namespace
+	ShowEvents bool
+	Events     []audit.Event
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := page{
+		Title:      h.Title,
+		Refresh:    int(RefreshInterval.Seconds()),
+		Now:        time.Now(),
+		ShowUsage:  h.Usage != nil,
+		ShowEvents: h.Recent != nil,
+	}
+	if h.Usage != nil {
+		p.Namespaces = h.Usage()
+		sort.Slice(p.Namespaces, func(i, j int) bool { return p.Namespaces[i].Name < p.Namespaces[j].Name })
+	}
+	if h.Recent != nil {
+		p.Events = h.Recent.Events()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, p); err != nil {
+		logging.L().WithName("dashboard").Error(err, "Failed to render dashboard")
+	}
+}
+
+var pageTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"ago": func(now, t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return now.Sub(t).Truncate(time.Second).String() + " ago"
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 4px 12px; border-bottom: 1px solid #ddd; vertical-align: middle; }
+.bar { width: 160px; height: 12px; background: #eee; display: inline-block; vertical-align: middle; }
+.bar div { height: 100%; }
+.ok { background: #4caf50; } .high { background: #ff9800; } .over { background: #f44336; }
+.violation { color: #f44336; font-weight: bold; }
+.muted { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Read-only, refreshed every {{.Refresh}}s.</p>
+{{if .ShowUsage}}
+<h2>Usage</h2>
+{{if .Namespaces}}
+<table>
+<tr><th>Namespace</th><th>Policy</th><th>Resource</th><th>Usage</th><th>Used / limit</th><th>Synced</th></tr>
+{{range $ns := .Namespaces}}{{range $i, $r := $ns.Resources}}
+<tr>
+{{if eq $i 0}}<td rowspan="{{len $ns.Resources}}">{{$ns.Name}}{{if $ns.Violation}} <span class="violation">violated</span>{{end}}</td><td rowspan="{{len $ns.Resources}}">{{$ns.Policy}}</td>{{end}}
+<td>{{$r.Name}}</td>
+<td><span class="bar"><div class="{{$r.Level}}" style="width: {{$r.Percent}}%"></div></span></td>
+<td>{{$r.Used}} / {{$r.Limit}}</td>
+{{if eq $i 0}}<td rowspan="{{len $ns.Resources}}" class="muted">{{ago $.Now $ns.Synced}}</td>{{end}}
+</tr>
+{{end}}{{end}}
+</table>
+{{else}}<p class="muted">No namespace has a policy yet.</p>{{end}}
+{{end}}
+{{if .ShowEvents}}
+<h2>Recent denials and enforcement actions</h2>
+{{if .Events}}
+<table>
+<tr><th>When</th><th>Decision</th><th>Namespace</th><th>Pod</th><th>Policy</th><th>Reason</th></tr>
+{{range .Events}}
+<tr><td class="muted">{{ago $.Now .Timestamp}}</td><td>{{.Decision}}</td><td>{{.Namespace}}</td><td>{{.Pod}}</td><td>{{.Policy}}</td><td>{{.Reason}}</td></tr>
+{{end}}
+</table>
+{{else}}<p class="muted">Nothing denied or deleted since startup.</p>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// ListenAndServe serves h on addr until ctx is done or the listener fails.
+func ListenAndServe(ctx context.Context, addr string, h *Handler) error {
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      h,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	stop := context.AfterFunc(ctx, func() { srv.Close() })
+	defer stop()
+	logging.L().WithName("dashboard").Info("Serving dashboard", "addr", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
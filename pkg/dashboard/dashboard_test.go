@@ -0,0 +1,86 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+)
+
+func TestRecentKeepsNewest(t *testing.T) {
+	r := NewRecent(3)
+	if got := r.Events(); len(got) != 0 {
+		t.Fatalf("Events() = %v, want none", got)
+	}
+	var events []audit.Event
+	for _, pod := range []string{"a", "b", "c", "d", "e"} {
+		events = append(events, audit.Event{Pod: pod})
+	}
+	_ = r.Export(context.Background(), events[:2])
+	_ = r.Export(context.Background(), events[2:])
+
+	var pods []string
+	for _, e := range r.Events() {
+		pods = append(pods, e.Pod)
+	}
+	if got := strings.Join(pods, ","); got != "e,d,c" {
+		t.Fatalf("Events() = %s, want e,d,c", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	recent := NewRecent(10)
+	_ = recent.Export(context.Background(), []audit.Event{{
+		Timestamp: time.Now(),
+		Namespace: "team-a",
+		Pod:       "web-<1>",
+		Decision:  audit.DecisionDenied,
+		Reason:    "maxPods exceeded: 3 > 2",
+	}})
+	h := &Handler{
+		Title: "Quota",
+		Usage: func() []Namespace {
+			return []Namespace{{
+				Name:      "team-a",
+				Policy:    "quota",
+				Violation: true,
+				Resources: []Resource{
+					NewResource("cpu", resource.MustParse("3"), resource.MustParse("2")),
+					NewResource("memory", resource.MustParse("1Gi"), resource.MustParse("4Gi")),
+				},
+			}}
+		},
+		Recent: recent,
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<div class="over" style="width: 100%">`,
+		`<div class="ok" style="width: 25%">`,
+		"3 / 2",
+		"violated",
+		"maxPods exceeded: 3 &gt; 2",
+		"web-&lt;1&gt;",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status of /other = %d, want 404", rec.Code)
+	}
+}
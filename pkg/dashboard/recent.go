@@ -0,0 +1,58 @@
+package dashboard
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+)
+
+// DefaultRecent is how many audit events a Recent keeps by default.
+const DefaultRecent = 100
+
+// Recent is an audit.Exporter keeping the latest audit events in memory: the denials of the
+// webhook and the deletions of the controller. It is safe for concurrent use.
+type Recent struct {
+	mu     sync.Mutex
+	events []audit.Event
+	next   int
+	full   bool
+}
+
+// NewRecent returns a Recent keeping the last size events, DefaultRecent if size isn't positive.
+func NewRecent(size int) *Recent {
+	if size <= 0 {
+		size = DefaultRecent
+	}
+	return &Recent{events: make([]audit.Event, size)}
+}
+
+// Name implements audit.Exporter.
+func (r *Recent) Name() string { return "dashboard" }
+
+// Export implements audit.Exporter. It never fails.
+func (r *Recent) Export(_ context.Context, events []audit.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range events {
+		r.events[r.next] = e
+		r.next = (r.next + 1) % len(r.events)
+		r.full = r.full || r.next == 0
+	}
+	return nil
+}
+
+// Events returns the events kept, newest first.
+func (r *Recent) Events() []audit.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.events)
+	}
+	out := make([]audit.Event, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.events[(r.next-i+len(r.events))%len(r.events)])
+	}
+	return out
+}
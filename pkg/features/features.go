@@ -0,0 +1,160 @@
+// Package features holds the feature gates of the controller and the webhook. Risky behavior
+// ships behind a gate, off by default while it is alpha, and is turned on per cluster with
+// -feature-gates=Name=true,Other=false, as for Kubernetes components.
+package features
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// Stage is how mature a feature is.
+type Stage string
+
+// Stages of a feature, which gates name in -help.
+const (
+	// Alpha features are off by default and may change or go away.
+	Alpha Stage = "ALPHA"
+	// Beta features are on by default and expected to become GA.
+	Beta Stage = "BETA"
+	// GA features are always on; their gates are kept for a while so setting them isn't an error.
+	GA Stage = ""
+	// Deprecated features are going away.
+	Deprecated Stage = "DEPRECATED"
+)
+
+// Spec is a feature's default and stage.
+type Spec struct {
+	Default    bool
+	PreRelease Stage
+	// LockToDefault rejects a -feature-gates value other than Default.
+	LockToDefault bool
+}
+
+// The feature gates. Add one for every behavior that should be possible to turn off in a cluster
+// until it has proven itself, and register it in defaultFeatures.
+const (
+	// PodEvictionAPI removes pods through the Eviction API instead of deleting them, so
+	// enforcement respects PodDisruptionBudgets and moves on to other pods when one blocks it.
+	PodEvictionAPI Feature = "PodEvictionAPI"
+
+	// WorkloadAdmission checks Deployments, StatefulSets, ReplicaSets and HorizontalPodAutoscalers
+	// against maxReplicasPerWorkload and the pod quota at admission, in the webhook.
+	WorkloadAdmission Feature = "WorkloadAdmission"
+)
+
+// defaultFeatures are the gates both binaries know.
+var defaultFeatures = map[Feature]Spec{
+	PodEvictionAPI:    {Default: false, PreRelease: Alpha},
+	WorkloadAdmission: {Default: true, PreRelease: Beta},
+}
+
+// DefaultGate is the gate of the running binary, set with -feature-gates.
+var DefaultGate = NewGate(defaultFeatures)
+
+// Enabled reports whether feature is on in DefaultGate.
+func Enabled(feature Feature) bool {
+	return DefaultGate.Enabled(feature)
+}
+
+// Gate holds which features are on. It is a flag.Value of comma-separated Name=bool pairs, and
+// safe to read while it is set.
+type Gate struct {
+	known map[Feature]Spec
+	// set are the features given a value, by name
+	set atomic.Pointer[map[Feature]bool]
+}
+
+// NewGate returns a gate of the known features, each at its default.
+func NewGate(known map[Feature]Spec) *Gate {
+	return &Gate{known: known}
+}
+
+// AddFlags registers -feature-gates.
+func (g *Gate) AddFlags(fs *flag.FlagSet) {
+	fs.Var(g, "feature-gates", "comma-separated Name=true|false pairs turning features on or off. Options are:\n"+strings.Join(g.KnownFeatures(), "\n"))
+}
+
+// Set replaces the features given a value with the comma-separated Name=bool pairs of value. An
+// unknown feature, an invalid bool or a locked feature set away from its default is an error.
+func (g *Gate) Set(value string) error {
+	set := map[Feature]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("missing bool value for %s", pair)
+		}
+		feature := Feature(strings.TrimSpace(name))
+		spec, known := g.known[feature]
+		if !known {
+			return fmt.Errorf("unrecognized feature gate: %s", feature)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid value of %s=%s: %w", feature, v, err)
+		}
+		if spec.LockToDefault && enabled != spec.Default {
+			return fmt.Errorf("cannot set feature gate %v to %v, feature is locked to %v", feature, enabled, spec.Default)
+		}
+		set[feature] = enabled
+	}
+	g.set.Store(&set)
+	return nil
+}
+
+// String returns the features given a value as Name=bool pairs, sorted by name.
+func (g *Gate) String() string {
+	if g == nil {
+		return ""
+	}
+	set := g.set.Load()
+	if set == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*set))
+	for feature, enabled := range *set {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", feature, enabled))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Enabled reports whether feature is on. Asking for a feature the gate doesn't know is a bug,
+// and panics.
+func (g *Gate) Enabled(feature Feature) bool {
+	spec, known := g.known[feature]
+	if !known {
+		panic(fmt.Sprintf("feature %q is not registered in the feature gate", feature))
+	}
+	if set := g.set.Load(); set != nil {
+		if enabled, ok := (*set)[feature]; ok {
+			return enabled
+		}
+	}
+	return spec.Default
+}
+
+// KnownFeatures returns the known features, other than GA ones, as lines for -help, e.g.
+// "PodEvictionAPI=true|false (ALPHA - default=false)".
+func (g *Gate) KnownFeatures() []string {
+	var lines []string
+	for feature, spec := range g.known {
+		if spec.PreRelease == GA {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s=true|false (%s - default=%t)", feature, spec.PreRelease, spec.Default))
+	}
+	sort.Strings(lines)
+	return lines
+}
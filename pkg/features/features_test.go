@@ -0,0 +1,52 @@
+package features
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	gate := NewGate(map[Feature]Spec{
+		"Experimental": {Default: false, PreRelease: Alpha},
+		"Rolling":      {Default: true, PreRelease: Beta},
+		"Done":         {Default: true, PreRelease: GA, LockToDefault: true},
+	})
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	gate.AddFlags(fs)
+	if !strings.Contains(fs.Lookup("feature-gates").Usage, "Experimental=true|false (ALPHA - default=false)") {
+		t.Errorf("usage lacks the alpha feature: %s", fs.Lookup("feature-gates").Usage)
+	}
+
+	if gate.Enabled("Experimental") || !gate.Enabled("Rolling") {
+		t.Fatal("expected the defaults before any value is set")
+	}
+	if err := fs.Parse([]string{"-feature-gates=Experimental=true, Rolling=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gate.Enabled("Experimental") || gate.Enabled("Rolling") || !gate.Enabled("Done") {
+		t.Fatalf("unexpected gates after %s", gate.String())
+	}
+	if got := gate.String(); got != "Experimental=true,Rolling=false" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, value := range []string{"Unknown=true", "Experimental", "Experimental=maybe", "Done=false"} {
+		if err := gate.Set(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+	// a failed Set leaves the gate alone
+	if !gate.Enabled("Experimental") {
+		t.Error("a failed Set changed the gate")
+	}
+}
+
+func TestEnabledPanicsOnUnknownFeature(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	NewGate(nil).Enabled("Unknown")
+}
@@ -0,0 +1,18 @@
+package features
+
+import "testing"
+
+// SetForTesting turns feature on or off in DefaultGate until the test tb ends.
+func SetForTesting(tb testing.TB, feature Feature, enabled bool) {
+	tb.Helper()
+	previous := DefaultGate.set.Load()
+	set := map[Feature]bool{}
+	if previous != nil {
+		for f, v := range *previous {
+			set[f] = v
+		}
+	}
+	set[feature] = enabled
+	DefaultGate.set.Store(&set)
+	tb.Cleanup(func() { DefaultGate.set.Store(previous) })
+}
@@ -0,0 +1,93 @@
+// Package forecast estimates when a namespace will run out of quota from its usage history.
+package forecast
+
+import (
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// Window is how much recorded history feeds a forecast.
+const Window = 24 * time.Hour
+
+// minPoints is the fewest samples worth fitting a trend through.
+const minPoints = 3
+
+// Point is one usage sample.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Estimate is the predicted time a resource reaches its limit.
+type Estimate struct {
+	Resource string
+	At       time.Time
+}
+
+// Exhaustion fits a least-squares line through points and returns when it reaches limit.
+// It reports false when there are too few points, usage isn't growing, or there is no limit.
+func Exhaustion(points []Point, limit float64) (time.Time, bool) {
+	if len(points) < minPoints || limit <= 0 {
+		return time.Time{}, false
+	}
+	last := points[len(points)-1]
+	if last.Value >= limit {
+		return last.Time, true
+	}
+
+	// x is seconds relative to the first sample to keep the sums small
+	origin := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Time.Sub(origin).Seconds()
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return time.Time{}, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	if slope <= 0 {
+		return time.Time{}, false
+	}
+	intercept := (sumY - slope*sumX) / n
+
+	secs := (limit - intercept) / slope
+	return origin.Add(time.Duration(secs * float64(time.Second))), true
+}
+
+// Estimates forecasts exhaustion of each limited resource from the history recorded in the
+// last Window before now.
+func Estimates(history []v1beta1.UsageSnapshot, policy handlers.Policy, now time.Time) []Estimate {
+	var pods, cpu, memory []Point
+	for _, snap := range history {
+		if snap.Time.Time.Before(now.Add(-Window)) {
+			continue
+		}
+		pods = append(pods, Point{snap.Time.Time, float64(snap.Pods)})
+		cpu = append(cpu, Point{snap.Time.Time, float64(snap.CPU.MilliValue())})
+		memory = append(memory, Point{snap.Time.Time, float64(snap.Memory.Value())})
+	}
+
+	var out []Estimate
+	for _, r := range []struct {
+		name   string
+		points []Point
+		limit  float64
+	}{
+		{"pods", pods, float64(policy.MaxPods)},
+		{"cpu", cpu, float64(policy.MaxCPU.MilliValue())},
+		{"memory", memory, float64(policy.MaxMemory.Value())},
+	} {
+		if at, ok := Exhaustion(r.points, r.limit); ok {
+			out = append(out, Estimate{Resource: r.name, At: at})
+		}
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExhaustion(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	growing := []Point{
+		{base, 2},
+		{base.Add(time.Hour), 4},
+		{base.Add(2 * time.Hour), 6},
+	}
+	at, ok := Exhaustion(growing, 10)
+	if !ok || !at.Equal(base.Add(4*time.Hour)) {
+		t.Fatalf("expected exhaustion at %s, got %s (ok=%v)", base.Add(4*time.Hour), at, ok)
+	}
+
+	shrinking := []Point{
+		{base, 6},
+		{base.Add(time.Hour), 4},
+		{base.Add(2 * time.Hour), 2},
+	}
+	if _, ok := Exhaustion(shrinking, 10); ok {
+		t.Error("expected no forecast for shrinking usage")
+	}
+	if _, ok := Exhaustion(growing[:2], 10); ok {
+		t.Error("expected no forecast with too few points")
+	}
+	if at, ok := Exhaustion(growing, 5); !ok || !at.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("expected exhaustion at the last sample once over the limit, got %s (ok=%v)", at, ok)
+	}
+}
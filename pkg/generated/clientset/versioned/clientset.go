@@ -17,10 +17,11 @@
 package versioned
 
 import (
-	"fmt"
-	"net/http"
+	fmt "fmt"
+	http "net/http"
 
 	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1alpha1"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -29,12 +30,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	PlatformV1alpha1() platformv1alpha1.PlatformV1alpha1Interface
+	PlatformV1beta1() platformv1beta1.PlatformV1beta1Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	platformV1alpha1 *platformv1alpha1.PlatformV1alpha1Client
+	platformV1beta1  *platformv1beta1.PlatformV1beta1Client
 }
 
 // PlatformV1alpha1 retrieves the PlatformV1alpha1Client
@@ -42,6 +45,11 @@ func (c *Clientset) PlatformV1alpha1() platformv1alpha1.PlatformV1alpha1Interfac
 	return c.platformV1alpha1
 }
 
+// PlatformV1beta1 retrieves the PlatformV1beta1Client
+func (c *Clientset) PlatformV1beta1() platformv1beta1.PlatformV1beta1Interface {
+	return c.platformV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -90,6 +98,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.platformV1beta1, err = platformv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -112,6 +124,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.platformV1alpha1 = platformv1alpha1.New(c)
+	cs.platformV1beta1 = platformv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
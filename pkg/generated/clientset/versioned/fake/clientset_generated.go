@@ -20,6 +20,9 @@ import (
 	clientset "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
 	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1alpha1"
 	fakeplatformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1alpha1/fake"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1beta1"
+	fakeplatformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1beta1/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -47,9 +50,13 @@ func NewSimpleClientset(objects ...runtime.Object) *Clientset {
 	cs.discovery = &fakediscovery.FakeDiscovery{Fake: &cs.Fake}
 	cs.AddReactor("*", "*", testing.ObjectReaction(o))
 	cs.AddWatchReactor("*", func(action testing.Action) (handled bool, ret watch.Interface, err error) {
+		var opts metav1.ListOptions
+		if watchActcion, ok := action.(testing.WatchActionImpl); ok {
+			opts = watchActcion.ListOptions
+		}
 		gvr := action.GetResource()
 		ns := action.GetNamespace()
-		watch, err := o.Watch(gvr, ns)
+		watch, err := o.Watch(gvr, ns, opts)
 		if err != nil {
 			return false, nil, err
 		}
@@ -85,3 +92,8 @@ var (
 func (c *Clientset) PlatformV1alpha1() platformv1alpha1.PlatformV1alpha1Interface {
 	return &fakeplatformv1alpha1.FakePlatformV1alpha1{Fake: &c.Fake}
 }
+
+// PlatformV1beta1 retrieves the PlatformV1beta1Client
+func (c *Clientset) PlatformV1beta1() platformv1beta1.PlatformV1beta1Interface {
+	return &fakeplatformv1beta1.FakePlatformV1beta1{Fake: &c.Fake}
+}
@@ -18,6 +18,7 @@ package fake
 
 import (
 	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,6 +31,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 
 var localSchemeBuilder = runtime.SchemeBuilder{
 	platformv1alpha1.AddToScheme,
+	platformv1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
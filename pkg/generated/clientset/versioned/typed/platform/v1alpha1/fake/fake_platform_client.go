@@ -27,7 +27,7 @@ type FakePlatformV1alpha1 struct {
 }
 
 func (c *FakePlatformV1alpha1) ResourceQuotaPolicies(namespace string) v1alpha1.ResourceQuotaPolicyInterface {
-	return &FakeResourceQuotaPolicies{c, namespace}
+	return newFakeResourceQuotaPolicies(c, namespace)
 }
 
 // RESTClient returns a RESTClient that is used to communicate
@@ -17,129 +17,34 @@
 package fake
 
 import (
-	"context"
-
 	v1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	labels "k8s.io/apimachinery/pkg/labels"
-	types "k8s.io/apimachinery/pkg/types"
-	watch "k8s.io/apimachinery/pkg/watch"
-	testing "k8s.io/client-go/testing"
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/typed/platform/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
 )
 
-// FakeResourceQuotaPolicies implements ResourceQuotaPolicyInterface
-type FakeResourceQuotaPolicies struct {
+// fakeResourceQuotaPolicies implements ResourceQuotaPolicyInterface
+type fakeResourceQuotaPolicies struct {
+	*gentype.FakeClientWithList[*v1alpha1.ResourceQuotaPolicy, *v1alpha1.ResourceQuotaPolicyList]
 	Fake *FakePlatformV1alpha1
-	ns   string
-}
-
-var resourcequotapoliciesResource = v1alpha1.SchemeGroupVersion.WithResource("resourcequotapolicies")
-
-var resourcequotapoliciesKind = v1alpha1.SchemeGroupVersion.WithKind("ResourceQuotaPolicy")
-
-// Get takes name of the resourceQuotaPolicy, and returns the corresponding resourceQuotaPolicy object, and an error if there is any.
-func (c *FakeResourceQuotaPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ResourceQuotaPolicy, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicy{}
-	obj, err := c.Fake.
-		Invokes(testing.NewGetActionWithOptions(resourcequotapoliciesResource, c.ns, name, options), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
-	}
-	return obj.(*v1alpha1.ResourceQuotaPolicy), err
-}
-
-// List takes label and field selectors, and returns the list of ResourceQuotaPolicies that match those selectors.
-func (c *FakeResourceQuotaPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ResourceQuotaPolicyList, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicyList{}
-	obj, err := c.Fake.
-		Invokes(testing.NewListActionWithOptions(resourcequotapoliciesResource, resourcequotapoliciesKind, c.ns, opts), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
-	}
-
-	label, _, _ := testing.ExtractFromListOptions(opts)
-	if label == nil {
-		label = labels.Everything()
-	}
-	list := &v1alpha1.ResourceQuotaPolicyList{ListMeta: obj.(*v1alpha1.ResourceQuotaPolicyList).ListMeta}
-	for _, item := range obj.(*v1alpha1.ResourceQuotaPolicyList).Items {
-		if label.Matches(labels.Set(item.Labels)) {
-			list.Items = append(list.Items, item)
-		}
-	}
-	return list, err
-}
-
-// Watch returns a watch.Interface that watches the requested resourceQuotaPolicies.
-func (c *FakeResourceQuotaPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
-	return c.Fake.
-		InvokesWatch(testing.NewWatchActionWithOptions(resourcequotapoliciesResource, c.ns, opts))
-
-}
-
-// Create takes the representation of a resourceQuotaPolicy and creates it.  Returns the server's representation of the resourceQuotaPolicy, and an error, if there is any.
-func (c *FakeResourceQuotaPolicies) Create(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.CreateOptions) (result *v1alpha1.ResourceQuotaPolicy, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicy{}
-	obj, err := c.Fake.
-		Invokes(testing.NewCreateActionWithOptions(resourcequotapoliciesResource, c.ns, resourceQuotaPolicy, opts), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
-	}
-	return obj.(*v1alpha1.ResourceQuotaPolicy), err
-}
-
-// Update takes the representation of a resourceQuotaPolicy and updates it. Returns the server's representation of the resourceQuotaPolicy, and an error, if there is any.
-func (c *FakeResourceQuotaPolicies) Update(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (result *v1alpha1.ResourceQuotaPolicy, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicy{}
-	obj, err := c.Fake.
-		Invokes(testing.NewUpdateActionWithOptions(resourcequotapoliciesResource, c.ns, resourceQuotaPolicy, opts), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
-	}
-	return obj.(*v1alpha1.ResourceQuotaPolicy), err
-}
-
-// UpdateStatus was generated because the type contains a Status member.
-// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeResourceQuotaPolicies) UpdateStatus(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (result *v1alpha1.ResourceQuotaPolicy, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicy{}
-	obj, err := c.Fake.
-		Invokes(testing.NewUpdateSubresourceActionWithOptions(resourcequotapoliciesResource, "status", c.ns, resourceQuotaPolicy, opts), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
-	}
-	return obj.(*v1alpha1.ResourceQuotaPolicy), err
-}
-
-// Delete takes name of the resourceQuotaPolicy and deletes it. Returns an error if one occurs.
-func (c *FakeResourceQuotaPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
-	_, err := c.Fake.
-		Invokes(testing.NewDeleteActionWithOptions(resourcequotapoliciesResource, c.ns, name, opts), &v1alpha1.ResourceQuotaPolicy{})
-
-	return err
 }
 
-// DeleteCollection deletes a collection of objects.
-func (c *FakeResourceQuotaPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
-	action := testing.NewDeleteCollectionActionWithOptions(resourcequotapoliciesResource, c.ns, opts, listOpts)
-
-	_, err := c.Fake.Invokes(action, &v1alpha1.ResourceQuotaPolicyList{})
-	return err
-}
-
-// Patch applies the patch and returns the patched resourceQuotaPolicy.
-func (c *FakeResourceQuotaPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ResourceQuotaPolicy, err error) {
-	emptyResult := &v1alpha1.ResourceQuotaPolicy{}
-	obj, err := c.Fake.
-		Invokes(testing.NewPatchSubresourceActionWithOptions(resourcequotapoliciesResource, c.ns, name, pt, data, opts, subresources...), emptyResult)
-
-	if obj == nil {
-		return emptyResult, err
+func newFakeResourceQuotaPolicies(fake *FakePlatformV1alpha1, namespace string) platformv1alpha1.ResourceQuotaPolicyInterface {
+	return &fakeResourceQuotaPolicies{
+		gentype.NewFakeClientWithList[*v1alpha1.ResourceQuotaPolicy, *v1alpha1.ResourceQuotaPolicyList](
+			fake.Fake,
+			namespace,
+			v1alpha1.SchemeGroupVersion.WithResource("resourcequotapolicies"),
+			v1alpha1.SchemeGroupVersion.WithKind("ResourceQuotaPolicy"),
+			func() *v1alpha1.ResourceQuotaPolicy { return &v1alpha1.ResourceQuotaPolicy{} },
+			func() *v1alpha1.ResourceQuotaPolicyList { return &v1alpha1.ResourceQuotaPolicyList{} },
+			func(dst, src *v1alpha1.ResourceQuotaPolicyList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.ResourceQuotaPolicyList) []*v1alpha1.ResourceQuotaPolicy {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.ResourceQuotaPolicyList, items []*v1alpha1.ResourceQuotaPolicy) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
 	}
-	return obj.(*v1alpha1.ResourceQuotaPolicy), err
 }
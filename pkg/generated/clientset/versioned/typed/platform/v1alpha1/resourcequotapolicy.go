@@ -17,9 +17,9 @@
 package v1alpha1
 
 import (
-	"context"
+	context "context"
 
-	v1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
 	scheme "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -35,33 +35,34 @@ type ResourceQuotaPoliciesGetter interface {
 
 // ResourceQuotaPolicyInterface has methods to work with ResourceQuotaPolicy resources.
 type ResourceQuotaPolicyInterface interface {
-	Create(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.CreateOptions) (*v1alpha1.ResourceQuotaPolicy, error)
-	Update(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (*v1alpha1.ResourceQuotaPolicy, error)
+	Create(ctx context.Context, resourceQuotaPolicy *platformv1alpha1.ResourceQuotaPolicy, opts v1.CreateOptions) (*platformv1alpha1.ResourceQuotaPolicy, error)
+	Update(ctx context.Context, resourceQuotaPolicy *platformv1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (*platformv1alpha1.ResourceQuotaPolicy, error)
 	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-	UpdateStatus(ctx context.Context, resourceQuotaPolicy *v1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (*v1alpha1.ResourceQuotaPolicy, error)
+	UpdateStatus(ctx context.Context, resourceQuotaPolicy *platformv1alpha1.ResourceQuotaPolicy, opts v1.UpdateOptions) (*platformv1alpha1.ResourceQuotaPolicy, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ResourceQuotaPolicy, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ResourceQuotaPolicyList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*platformv1alpha1.ResourceQuotaPolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*platformv1alpha1.ResourceQuotaPolicyList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ResourceQuotaPolicy, err error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *platformv1alpha1.ResourceQuotaPolicy, err error)
 	ResourceQuotaPolicyExpansion
 }
 
 // resourceQuotaPolicies implements ResourceQuotaPolicyInterface
 type resourceQuotaPolicies struct {
-	*gentype.ClientWithList[*v1alpha1.ResourceQuotaPolicy, *v1alpha1.ResourceQuotaPolicyList]
+	*gentype.ClientWithList[*platformv1alpha1.ResourceQuotaPolicy, *platformv1alpha1.ResourceQuotaPolicyList]
 }
 
 // newResourceQuotaPolicies returns a ResourceQuotaPolicies
 func newResourceQuotaPolicies(c *PlatformV1alpha1Client, namespace string) *resourceQuotaPolicies {
 	return &resourceQuotaPolicies{
-		gentype.NewClientWithList[*v1alpha1.ResourceQuotaPolicy, *v1alpha1.ResourceQuotaPolicyList](
+		gentype.NewClientWithList[*platformv1alpha1.ResourceQuotaPolicy, *platformv1alpha1.ResourceQuotaPolicyList](
 			"resourcequotapolicies",
 			c.RESTClient(),
 			scheme.ParameterCodec,
 			namespace,
-			func() *v1alpha1.ResourceQuotaPolicy { return &v1alpha1.ResourceQuotaPolicy{} },
-			func() *v1alpha1.ResourceQuotaPolicyList { return &v1alpha1.ResourceQuotaPolicyList{} }),
+			func() *platformv1alpha1.ResourceQuotaPolicy { return &platformv1alpha1.ResourceQuotaPolicy{} },
+			func() *platformv1alpha1.ResourceQuotaPolicyList { return &platformv1alpha1.ResourceQuotaPolicyList{} },
+		),
 	}
 }
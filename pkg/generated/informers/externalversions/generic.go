@@ -17,9 +17,10 @@
 package externalversions
 
 import (
-	"fmt"
+	fmt "fmt"
 
 	v1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	v1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -54,6 +55,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("resourcequotapolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Platform().V1alpha1().ResourceQuotaPolicies().Informer()}, nil
 
+		// Group=platform.example.com, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("quotaexemptions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Platform().V1beta1().QuotaExemptions().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("resourcequotapolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Platform().V1beta1().ResourceQuotaPolicies().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -17,13 +17,13 @@
 package v1alpha1
 
 import (
-	"context"
+	context "context"
 	time "time"
 
-	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	apisplatformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
 	versioned "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
 	internalinterfaces "github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions/internalinterfaces"
-	v1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1alpha1"
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -34,7 +34,7 @@ import (
 // ResourceQuotaPolicies.
 type ResourceQuotaPolicyInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.ResourceQuotaPolicyLister
+	Lister() platformv1alpha1.ResourceQuotaPolicyLister
 }
 
 type resourceQuotaPolicyInformer struct {
@@ -60,16 +60,28 @@ func NewFilteredResourceQuotaPolicyInformer(client versioned.Interface, namespac
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).List(context.TODO(), options)
+				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).List(context.Background(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).Watch(context.TODO(), options)
+				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.PlatformV1alpha1().ResourceQuotaPolicies(namespace).Watch(ctx, options)
 			},
 		},
-		&platformv1alpha1.ResourceQuotaPolicy{},
+		&apisplatformv1alpha1.ResourceQuotaPolicy{},
 		resyncPeriod,
 		indexers,
 	)
@@ -80,9 +92,9 @@ func (f *resourceQuotaPolicyInformer) defaultInformer(client versioned.Interface
 }
 
 func (f *resourceQuotaPolicyInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&platformv1alpha1.ResourceQuotaPolicy{}, f.defaultInformer)
+	return f.factory.InformerFor(&apisplatformv1alpha1.ResourceQuotaPolicy{}, f.defaultInformer)
 }
 
-func (f *resourceQuotaPolicyInformer) Lister() v1alpha1.ResourceQuotaPolicyLister {
-	return v1alpha1.NewResourceQuotaPolicyLister(f.Informer().GetIndexer())
+func (f *resourceQuotaPolicyInformer) Lister() platformv1alpha1.ResourceQuotaPolicyLister {
+	return platformv1alpha1.NewResourceQuotaPolicyLister(f.Informer().GetIndexer())
 }
@@ -17,10 +17,10 @@
 package v1alpha1
 
 import (
-	v1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/listers"
-	"k8s.io/client-go/tools/cache"
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
 )
 
 // ResourceQuotaPolicyLister helps list ResourceQuotaPolicies.
@@ -28,7 +28,7 @@ import (
 type ResourceQuotaPolicyLister interface {
 	// List lists all ResourceQuotaPolicies in the indexer.
 	// Objects returned here must be treated as read-only.
-	List(selector labels.Selector) (ret []*v1alpha1.ResourceQuotaPolicy, err error)
+	List(selector labels.Selector) (ret []*platformv1alpha1.ResourceQuotaPolicy, err error)
 	// ResourceQuotaPolicies returns an object that can list and get ResourceQuotaPolicies.
 	ResourceQuotaPolicies(namespace string) ResourceQuotaPolicyNamespaceLister
 	ResourceQuotaPolicyListerExpansion
@@ -36,17 +36,17 @@ type ResourceQuotaPolicyLister interface {
 
 // resourceQuotaPolicyLister implements the ResourceQuotaPolicyLister interface.
 type resourceQuotaPolicyLister struct {
-	listers.ResourceIndexer[*v1alpha1.ResourceQuotaPolicy]
+	listers.ResourceIndexer[*platformv1alpha1.ResourceQuotaPolicy]
 }
 
 // NewResourceQuotaPolicyLister returns a new ResourceQuotaPolicyLister.
 func NewResourceQuotaPolicyLister(indexer cache.Indexer) ResourceQuotaPolicyLister {
-	return &resourceQuotaPolicyLister{listers.New[*v1alpha1.ResourceQuotaPolicy](indexer, v1alpha1.Resource("resourcequotapolicy"))}
+	return &resourceQuotaPolicyLister{listers.New[*platformv1alpha1.ResourceQuotaPolicy](indexer, platformv1alpha1.Resource("resourcequotapolicy"))}
 }
 
 // ResourceQuotaPolicies returns an object that can list and get ResourceQuotaPolicies.
 func (s *resourceQuotaPolicyLister) ResourceQuotaPolicies(namespace string) ResourceQuotaPolicyNamespaceLister {
-	return resourceQuotaPolicyNamespaceLister{listers.NewNamespaced[*v1alpha1.ResourceQuotaPolicy](s.ResourceIndexer, namespace)}
+	return resourceQuotaPolicyNamespaceLister{listers.NewNamespaced[*platformv1alpha1.ResourceQuotaPolicy](s.ResourceIndexer, namespace)}
 }
 
 // ResourceQuotaPolicyNamespaceLister helps list and get ResourceQuotaPolicies.
@@ -54,15 +54,15 @@ func (s *resourceQuotaPolicyLister) ResourceQuotaPolicies(namespace string) Reso
 type ResourceQuotaPolicyNamespaceLister interface {
 	// List lists all ResourceQuotaPolicies in the indexer for a given namespace.
 	// Objects returned here must be treated as read-only.
-	List(selector labels.Selector) (ret []*v1alpha1.ResourceQuotaPolicy, err error)
+	List(selector labels.Selector) (ret []*platformv1alpha1.ResourceQuotaPolicy, err error)
 	// Get retrieves the ResourceQuotaPolicy from the indexer for a given namespace and name.
 	// Objects returned here must be treated as read-only.
-	Get(name string) (*v1alpha1.ResourceQuotaPolicy, error)
+	Get(name string) (*platformv1alpha1.ResourceQuotaPolicy, error)
 	ResourceQuotaPolicyNamespaceListerExpansion
 }
 
 // resourceQuotaPolicyNamespaceLister implements the ResourceQuotaPolicyNamespaceLister
 // interface.
 type resourceQuotaPolicyNamespaceLister struct {
-	listers.ResourceIndexer[*v1alpha1.ResourceQuotaPolicy]
+	listers.ResourceIndexer[*platformv1alpha1.ResourceQuotaPolicy]
 }
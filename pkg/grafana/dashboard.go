@@ -0,0 +1,206 @@
+// Package grafana generates Grafana dashboards for the metrics of the controller and the webhook.
+// Queries are built from the metric names in pkg/metrics and pkg/webhook, so a renamed metric
+// changes the dashboards with it.
+package grafana
+
+import (
+	"fmt"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+// DefaultUID is the uid of the generated dashboard, kept stable so re-importing replaces it.
+const DefaultUID = "rqe-overview"
+
+// Options customizes the generated dashboard.
+type Options struct {
+	// Title of the dashboard; empty uses "Resource Quota Enforcer".
+	Title string
+	// UID of the dashboard; empty uses DefaultUID.
+	UID string
+	// Datasource is the uid of the Prometheus data source selected by default; empty leaves the
+	// choice to whoever imports the dashboard.
+	Datasource string
+}
+
+// panel is a Grafana time series or stat panel with its PromQL targets.
+type panel struct {
+	title   string
+	kind    string // "timeseries" or "stat"
+	unit    string
+	targets []target
+}
+
+type target struct {
+	expr   string
+	legend string
+}
+
+// selector is the label matcher of every per-namespace query.
+const selector = `{namespace=~"$namespace"}`
+
+// rows are the dashboard's sections and their panels, in order.
+func rows() []struct {
+	title  string
+	panels []panel
+} {
+	ns := func(name string) string { return name + selector }
+	rate := func(name string) string { return fmt.Sprintf("rate(%s[$__rate_interval])", ns(name)) }
+	return []struct {
+		title  string
+		panels []panel
+	}{
+		{"Usage", []panel{
+			{title: "Quota utilization", kind: "timeseries", unit: "percentunit", targets: []target{
+				{fmt.Sprintf("max by (namespace, resource) (%s)", ns(metrics.NameQuotaUtilization)), "{{namespace}} {{resource}}"},
+			}},
+			{title: "Pods", kind: "timeseries", unit: "short", targets: []target{
+				{fmt.Sprintf("sum by (namespace) (%s)", ns(metrics.NameNamespacePods)), "{{namespace}} used"},
+				{fmt.Sprintf("max by (namespace) (%s)", ns(metrics.NameNamespacePodsLimit)), "{{namespace}} limit"},
+			}},
+			{title: "CPU requests", kind: "timeseries", unit: "short", targets: []target{
+				{fmt.Sprintf("sum by (namespace) (%s)", ns(metrics.NameNamespaceCPURequests)), "{{namespace}} requested"},
+				{fmt.Sprintf("max by (namespace) (%s)", ns(metrics.NameNamespaceCPULimit)), "{{namespace}} limit"},
+			}},
+			{title: "Memory requests", kind: "timeseries", unit: "bytes", targets: []target{
+				{fmt.Sprintf("sum by (namespace) (%s)", ns(metrics.NameNamespaceMemoryRequests)), "{{namespace}} requested"},
+				{fmt.Sprintf("max by (namespace) (%s)", ns(metrics.NameNamespaceMemoryLimit)), "{{namespace}} limit"},
+			}},
+			{title: "Time until quota is exhausted", kind: "timeseries", unit: "s", targets: []target{
+				{fmt.Sprintf("min by (namespace, resource) (%s)", ns(metrics.NameQuotaExhaustionSeconds)), "{{namespace}} {{resource}}"},
+			}},
+			{title: "Estimated hourly cost", kind: "timeseries", unit: "short", targets: []target{
+				{fmt.Sprintf("sum by (namespace, currency) (%s)", ns(metrics.NameEstimatedHourlyCost)), "{{namespace}} ({{currency}})"},
+			}},
+		}},
+		{"Enforcement", []panel{
+			{title: "Enforcement actions", kind: "timeseries", unit: "ops", targets: []target{
+				{fmt.Sprintf("sum by (namespace, action) (%s)", rate(metrics.NameEnforcementActions)), "{{namespace}} {{action}}"},
+			}},
+			{title: "Soft limit crossings", kind: "timeseries", unit: "ops", targets: []target{
+				{fmt.Sprintf("sum by (namespace, resource) (%s)", rate(metrics.NameSoftLimitExceeded)), "{{namespace}} {{resource}}"},
+			}},
+			{title: "Reconcile errors", kind: "timeseries", unit: "ops", targets: []target{
+				{fmt.Sprintf("sum by (namespace) (%s)", rate(metrics.NameReconcileErrors)), "{{namespace}}"},
+			}},
+			{title: "Usage drift corrections", kind: "timeseries", unit: "ops", targets: []target{
+				{fmt.Sprintf("sum by (namespace) (%s)", rate(metrics.NameUsageDriftCorrections)), "{{namespace}}"},
+			}},
+			{title: "Work queue depth", kind: "timeseries", unit: "short", targets: []target{
+				{fmt.Sprintf("sum by (name) (%s)", metrics.NameWorkqueueDepth), "{{name}}"},
+			}},
+			{title: "Sync duration (p99)", kind: "timeseries", unit: "s", targets: []target{
+				{fmt.Sprintf("histogram_quantile(0.99, sum by (le) (rate(%s_bucket[$__rate_interval])))", metrics.NameWorkqueueWorkDuration), "p99"},
+			}},
+			{title: "API client throttling (p99)", kind: "timeseries", unit: "s", targets: []target{
+				{fmt.Sprintf("histogram_quantile(0.99, sum by (le, host) (rate(%s_bucket[$__rate_interval])))", metrics.NameRestClientRateLimiterDuration), "{{host}}"},
+			}},
+		}},
+		{"Admission", []panel{
+			{title: "Admission requests", kind: "timeseries", unit: "reqps", targets: []target{
+				{fmt.Sprintf("sum by (result) (%s)", rate(webhook.NameAdmissionRequests)), "{{result}}"},
+			}},
+			{title: "Denials by reason", kind: "timeseries", unit: "reqps", targets: []target{
+				{fmt.Sprintf("sum by (namespace, reason) (%s)", rate(webhook.NameAdmissionViolations)), "{{namespace}} {{reason}}"},
+			}},
+			{title: "Admission latency (p99)", kind: "timeseries", unit: "s", targets: []target{
+				{fmt.Sprintf("histogram_quantile(0.99, sum by (le, result) (rate(%s_bucket[$__rate_interval])))", webhook.NameAdmissionDuration), "{{result}}"},
+			}},
+			{title: "Policy cache hit ratio", kind: "stat", unit: "percentunit", targets: []target{
+				{fmt.Sprintf("sum(rate(%[1]s[$__rate_interval])) / (sum(rate(%[1]s[$__rate_interval])) + sum(rate(%[2]s[$__rate_interval])))", webhook.NameCacheHits, webhook.NameCacheMisses), "hit ratio"},
+			}},
+			{title: "External policy decisions", kind: "timeseries", unit: "reqps", targets: []target{
+				{fmt.Sprintf("sum by (result) (rate(%s[$__rate_interval]))", webhook.NameExternalDecisions), "{{result}}"},
+			}},
+		}},
+	}
+}
+
+// Dashboard returns the dashboard as Grafana's JSON model, ready to import or provision. It has
+// a data source variable and a namespace variable that every per-namespace panel filters by.
+func Dashboard(opts Options) map[string]any {
+	if opts.Title == "" {
+		opts.Title = "Resource Quota Enforcer"
+	}
+	if opts.UID == "" {
+		opts.UID = DefaultUID
+	}
+	datasource := map[string]any{"type": "prometheus", "uid": "${datasource}"}
+
+	var panels []any
+	id, y := 1, 0
+	for _, row := range rows() {
+		panels = append(panels, map[string]any{
+			"id":        id,
+			"type":      "row",
+			"title":     row.title,
+			"collapsed": false,
+			"gridPos":   map[string]any{"x": 0, "y": y, "w": 24, "h": 1},
+			"panels":    []any{},
+		})
+		id, y = id+1, y+1
+		for i, p := range row.panels {
+			targets := make([]any, len(p.targets))
+			for j, t := range p.targets {
+				targets[j] = map[string]any{
+					"datasource":   datasource,
+					"expr":         t.expr,
+					"legendFormat": t.legend,
+					"refId":        string(rune('A' + j)),
+				}
+			}
+			panels = append(panels, map[string]any{
+				"id":         id,
+				"type":       p.kind,
+				"title":      p.title,
+				"datasource": datasource,
+				"gridPos":    map[string]any{"x": (i % 3) * 8, "y": y + (i/3)*8, "w": 8, "h": 8},
+				"fieldConfig": map[string]any{
+					"defaults":  map[string]any{"unit": p.unit},
+					"overrides": []any{},
+				},
+				"targets": targets,
+			})
+			id++
+		}
+		y += (len(row.panels) + 2) / 3 * 8
+	}
+
+	var current any = map[string]any{}
+	if opts.Datasource != "" {
+		current = map[string]any{"value": opts.Datasource}
+	}
+	return map[string]any{
+		"uid":           opts.UID,
+		"title":         opts.Title,
+		"tags":          []any{"resource-quota-enforcer"},
+		"timezone":      "browser",
+		"schemaVersion": 39,
+		"editable":      true,
+		"refresh":       "1m",
+		"time":          map[string]any{"from": "now-6h", "to": "now"},
+		"templating": map[string]any{"list": []any{
+			map[string]any{
+				"name":    "datasource",
+				"label":   "Data source",
+				"type":    "datasource",
+				"query":   "prometheus",
+				"current": current,
+			},
+			map[string]any{
+				"name":       "namespace",
+				"label":      "Namespace",
+				"type":       "query",
+				"datasource": datasource,
+				"query":      fmt.Sprintf("label_values(%s, namespace)", metrics.NameNamespacePods),
+				"refresh":    2,
+				"multi":      true,
+				"includeAll": true,
+				"allValue":   ".*",
+				"current":    map[string]any{"text": "All", "value": "$__all"},
+			},
+		}},
+		"panels": panels,
+	}
+}
@@ -0,0 +1,67 @@
+package grafana
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+func TestDashboard(t *testing.T) {
+	data, err := json.Marshal(Dashboard(Options{Datasource: "prom"}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got struct {
+		UID    string `json:"uid"`
+		Title  string `json:"title"`
+		Panels []struct {
+			ID      int    `json:"id"`
+			Type    string `json:"type"`
+			Targets []struct {
+				Expr  string `json:"expr"`
+				RefID string `json:"refId"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.UID != DefaultUID || got.Title != "Resource Quota Enforcer" {
+		t.Errorf("uid, title = %q, %q; want defaults", got.UID, got.Title)
+	}
+
+	ids := make(map[int]bool)
+	var exprs []string
+	for _, p := range got.Panels {
+		if ids[p.ID] {
+			t.Errorf("panel id %d used twice", p.ID)
+		}
+		ids[p.ID] = true
+		if p.Type != "row" && len(p.Targets) == 0 {
+			t.Errorf("panel %d has no queries", p.ID)
+		}
+		for _, target := range p.Targets {
+			exprs = append(exprs, target.Expr)
+		}
+	}
+	all := strings.Join(exprs, "\n")
+	for _, name := range []string{
+		metrics.NameQuotaUtilization,
+		metrics.NameNamespacePods,
+		metrics.NameEnforcementActions,
+		metrics.NameWorkqueueDepth,
+		webhook.NameAdmissionRequests,
+		webhook.NameAdmissionDuration,
+		webhook.NameCacheHits,
+	} {
+		if !strings.Contains(all, name) {
+			t.Errorf("no query uses %s", name)
+		}
+	}
+	if !strings.Contains(string(data), `"value":"prom"`) {
+		t.Errorf("default data source not selected: %s", data)
+	}
+}
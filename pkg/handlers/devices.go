@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1 "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DeviceResolver counts the devices pods claim through Dynamic Resource Allocation
+// (spec.resourceClaims) by resolving their ResourceClaims, ResourceClaimTemplates and
+// DeviceClasses. A nil *DeviceResolver counts no devices.
+type DeviceResolver struct {
+	Client kubernetes.Interface
+}
+
+// ExtendedRequests returns how much of each of names pod requests: its container requests,
+// counted like PodRequests, plus the devices it claims when r is not nil. Claims already in
+// seen are skipped and the ones counted are added to it, so a claim shared by several pods of a
+// namespace counts once; a nil seen counts every claim of pod.
+func (r *DeviceResolver) ExtendedRequests(ctx context.Context, pod *corev1.Pod, names []string, seen map[string]bool) (map[string]resource.Quantity, error) {
+	out := make(map[string]resource.Quantity, len(names))
+	for _, name := range names {
+		out[name] = effectiveRequest(pod, corev1.ResourceName(name))
+	}
+	if r == nil || len(pod.Spec.ResourceClaims) == 0 {
+		return out, nil
+	}
+
+	devices, err := r.podDevices(ctx, pod, seen)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if n := devices[name]; n > 0 {
+			q := out[name]
+			q.Add(*resource.NewQuantity(n, resource.DecimalSI))
+			out[name] = q
+		}
+	}
+	return out, nil
+}
+
+// podDevices returns the number of devices pod claims, keyed by extended resource name.
+func (r *DeviceResolver) podDevices(ctx context.Context, pod *corev1.Pod, seen map[string]bool) (map[string]int64, error) {
+	devices := map[string]int64{}
+	for _, rc := range pod.Spec.ResourceClaims {
+		var spec *resourcev1.ResourceClaimSpec
+		var allocation *resourcev1.AllocationResult
+
+		claimName, needed := podClaimName(pod, rc)
+		switch {
+		case !needed:
+			continue
+		case claimName != "":
+			if seen[claimName] {
+				continue
+			}
+			claim, err := r.Client.ResourceV1().ResourceClaims(pod.Namespace).Get(ctx, claimName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				// the pod cannot start until its claim exists
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("get resource claim %s: %w", claimName, err)
+			}
+			if seen != nil {
+				seen[claimName] = true
+			}
+			spec, allocation = &claim.Spec, claim.Status.Allocation
+		case rc.ResourceClaimTemplateName != nil:
+			// the claim has not been generated yet, e.g. at admission
+			tmpl, err := r.Client.ResourceV1().ResourceClaimTemplates(pod.Namespace).Get(ctx, *rc.ResourceClaimTemplateName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("get resource claim template %s: %w", *rc.ResourceClaimTemplateName, err)
+			}
+			spec = &tmpl.Spec.Spec
+		default:
+			continue
+		}
+
+		for class, n := range claimedDevices(spec, allocation) {
+			name, err := r.resourceName(ctx, class)
+			if err != nil {
+				return nil, err
+			}
+			devices[name] += n
+		}
+	}
+	return devices, nil
+}
+
+// podClaimName returns the name of the ResourceClaim behind a pod claim, which is empty while a
+// template-based claim has not been generated. needed is false when the pod status says no
+// claim was necessary.
+func podClaimName(pod *corev1.Pod, rc corev1.PodResourceClaim) (name string, needed bool) {
+	if rc.ResourceClaimName != nil {
+		return *rc.ResourceClaimName, true
+	}
+	for _, s := range pod.Status.ResourceClaimStatuses {
+		if s.Name == rc.Name {
+			if s.ResourceClaimName == nil {
+				return "", false
+			}
+			return *s.ResourceClaimName, true
+		}
+	}
+	return "", true
+}
+
+// claimedDevices returns the devices a claim takes per DeviceClass: what was allocated once the
+// claim is allocated, otherwise what it requests. Requests for all matching devices count as one
+// until allocated, since the number is not known before.
+func claimedDevices(spec *resourcev1.ResourceClaimSpec, allocation *resourcev1.AllocationResult) map[string]int64 {
+	allocated := map[string]int64{}
+	if allocation != nil {
+		for _, res := range allocation.Devices.Results {
+			allocated[res.Request]++
+		}
+	}
+
+	byClass := map[string]int64{}
+	for _, req := range spec.Devices.Requests {
+		if req.Exactly != nil {
+			if allocation != nil {
+				byClass[req.Exactly.DeviceClassName] += allocated[req.Name]
+			} else {
+				byClass[req.Exactly.DeviceClassName] += requestedCount(req.Exactly.AllocationMode, req.Exactly.Count)
+			}
+			continue
+		}
+		for _, sub := range req.FirstAvailable {
+			if allocation == nil {
+				// the scheduler tries subrequests in order; assume the preferred one
+				byClass[sub.DeviceClassName] += requestedCount(sub.AllocationMode, sub.Count)
+				break
+			}
+			if n := allocated[req.Name+"/"+sub.Name]; n > 0 {
+				byClass[sub.DeviceClassName] += n
+				break
+			}
+		}
+	}
+	return byClass
+}
+
+func requestedCount(mode resourcev1.DeviceAllocationMode, count int64) int64 {
+	if mode == resourcev1.DeviceAllocationModeAll || count <= 0 {
+		return 1
+	}
+	return count
+}
+
+// resourceName returns the extended resource name devices of class are counted under.
+func (r *DeviceResolver) resourceName(ctx context.Context, class string) (string, error) {
+	dc, err := r.Client.ResourceV1().DeviceClasses().Get(ctx, class, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return resourcev1.ResourceDeviceClassPrefix + class, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get device class %s: %w", class, err)
+	}
+	if dc.Spec.ExtendedResourceName != nil && *dc.Spec.ExtendedResourceName != "" {
+		return *dc.Spec.ExtendedResourceName, nil
+	}
+	return resourcev1.ResourceDeviceClassPrefix + class, nil
+}
+
+// ExtendedNames returns the resource names limits covers, sorted.
+func ExtendedNames(limits map[string]resource.Quantity) []string {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcev1 "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const gpu = "example.com/gpu"
+
+func gpuClaimSpec(count int64) resourcev1.ResourceClaimSpec {
+	return resourcev1.ResourceClaimSpec{Devices: resourcev1.DeviceClaim{Requests: []resourcev1.DeviceRequest{{
+		Name:    "gpu",
+		Exactly: &resourcev1.ExactDeviceRequest{DeviceClassName: "gpu.example.com", Count: count},
+	}}}}
+}
+
+func withClaims(pod *corev1.Pod, claims ...corev1.PodResourceClaim) *corev1.Pod {
+	pod.Spec.ResourceClaims = claims
+	return pod
+}
+
+func deviceClient() *fake.Clientset {
+	name := gpu
+	return fake.NewSimpleClientset(
+		&resourcev1.DeviceClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu.example.com"},
+			Spec:       resourcev1.DeviceClassSpec{ExtendedResourceName: &name},
+		},
+		&resourcev1.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns1"},
+			Spec:       gpuClaimSpec(1),
+		},
+		&resourcev1.ResourceClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "two-gpus", Namespace: "ns1"},
+			Spec:       resourcev1.ResourceClaimTemplateSpec{Spec: gpuClaimSpec(2)},
+		},
+	)
+}
+
+func TestExtendedRequests(t *testing.T) {
+	r := &DeviceResolver{Client: deviceClient()}
+	shared, tmpl := "shared", "two-gpus"
+
+	plugin := runningPod("plugin", time.Minute)
+	plugin.Spec.Containers = []corev1.Container{{Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{gpu: resource.MustParse("1")},
+	}}}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want int64
+	}{
+		{"device plugin request", plugin, 1},
+		{"claim from template", withClaims(runningPod("a", time.Minute), corev1.PodResourceClaim{Name: "gpu", ResourceClaimTemplateName: &tmpl}), 2},
+		{"shared claim", withClaims(runningPod("b", time.Minute), corev1.PodResourceClaim{Name: "gpu", ResourceClaimName: &shared}), 1},
+		{"shared claim counted once", withClaims(runningPod("c", time.Minute), corev1.PodResourceClaim{Name: "gpu", ResourceClaimName: &shared}), 0},
+	}
+	seen := map[string]bool{}
+	for _, tt := range tests {
+		got, err := r.ExtendedRequests(context.Background(), tt.pod, []string{gpu}, seen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q := got[gpu]; q.Value() != tt.want {
+			t.Errorf("%s: got %s, want %d", tt.name, q.String(), tt.want)
+		}
+	}
+}
+
+func TestClaimedDevicesUsesAllocation(t *testing.T) {
+	spec := gpuClaimSpec(0)
+	spec.Devices.Requests[0].Exactly.AllocationMode = resourcev1.DeviceAllocationModeAll
+	if got := claimedDevices(&spec, nil)["gpu.example.com"]; got != 1 {
+		t.Fatalf("unallocated All request: got %d, want 1", got)
+	}
+
+	allocation := &resourcev1.AllocationResult{Devices: resourcev1.DeviceAllocationResult{Results: []resourcev1.DeviceRequestAllocationResult{
+		{Request: "gpu", Device: "gpu-0"}, {Request: "gpu", Device: "gpu-1"}, {Request: "gpu", Device: "gpu-2"},
+	}}}
+	if got := claimedDevices(&spec, allocation)["gpu.example.com"]; got != 3 {
+		t.Fatalf("allocated All request: got %d, want 3", got)
+	}
+}
+
+func TestEnforceUntilOKDeletesExtendedResourceConsumer(t *testing.T) {
+	tmpl := "two-gpus"
+	cs := deviceClient()
+	// newest pod holds no GPU, so it must not be the one deleted
+	for _, pod := range []*corev1.Pod{
+		withClaims(runningPod("trainer-0", 3*time.Minute), corev1.PodResourceClaim{Name: "gpu", ResourceClaimTemplateName: &tmpl}),
+		withClaims(runningPod("trainer-1", 2*time.Minute), corev1.PodResourceClaim{Name: "gpu", ResourceClaimTemplateName: &tmpl}),
+		runningPod("web", time.Minute),
+	} {
+		if _, err := cs.CoreV1().Pods("ns1").Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := &PodEnforcer{Client: cs, Devices: &DeviceResolver{Client: cs}}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{
+		MaxPods:     10,
+		MaxCPU:      resource.MustParse("1"),
+		MaxMemory:   resource.MustParse("1Gi"),
+		MaxExtended: map[string]resource.Quantity{gpu: resource.MustParse("2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Violation || len(res.Evicted) != 1 || res.Evicted[0] != "trainer-1" || res.CurrentExtended[gpu] != "2" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
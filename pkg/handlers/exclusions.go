@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"flag"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Exclusions selects pods that neither count toward a policy nor get deleted to enforce it.
+type Exclusions struct {
+	DaemonSetPods bool
+	MirrorPods    bool
+	// Exemptions are the QuotaExemptions of the policy's namespace in force; nil exempts no pod.
+	Exemptions *Exemptions
+}
+
+// AddFlags registers the cluster-wide exclusion defaults, which policies can override through
+// spec.excludePods.
+func (x *Exclusions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&x.DaemonSetPods, "exclude-daemonset-pods", false, "ignore DaemonSet pods in usage and eviction unless a policy says otherwise")
+	fs.BoolVar(&x.MirrorPods, "exclude-mirror-pods", false, "ignore static (mirror) pods in usage and eviction unless a policy says otherwise")
+}
+
+// With returns x with the fields set in spec taking precedence.
+func (x Exclusions) With(spec *v1beta1.PodExclusions) Exclusions {
+	if spec == nil {
+		return x
+	}
+	if spec.DaemonSetPods != nil {
+		x.DaemonSetPods = *spec.DaemonSetPods
+	}
+	if spec.MirrorPods != nil {
+		x.MirrorPods = *spec.MirrorPods
+	}
+	return x
+}
+
+// Excludes reports whether pod is left out of enforcement.
+func (x Exclusions) Excludes(pod *corev1.Pod) bool {
+	if _, ok := x.Exemptions.Exempts(pod); ok {
+		return true
+	}
+	if x.MirrorPods {
+		if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			return true
+		}
+	}
+	if x.DaemonSetPods {
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
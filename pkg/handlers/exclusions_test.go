@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExclusions(t *testing.T) {
+	isController := true
+	ds := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+		{Kind: "DaemonSet", Name: "agent", Controller: &isController},
+	}}}
+	mirror := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		corev1.MirrorPodAnnotationKey: "abc",
+	}}}
+	plain := &corev1.Pod{}
+
+	off, on := false, true
+	x := Exclusions{DaemonSetPods: true}.With(&v1beta1.PodExclusions{MirrorPods: &on})
+	if !x.Excludes(ds) || !x.Excludes(mirror) || x.Excludes(plain) {
+		t.Fatalf("unexpected exclusions %+v", x)
+	}
+
+	x = x.With(&v1beta1.PodExclusions{DaemonSetPods: &off})
+	if x.Excludes(ds) || !x.Excludes(mirror) {
+		t.Fatalf("policy override not applied: %+v", x)
+	}
+
+	if (Exclusions{}).Excludes(ds) || (Exclusions{}).Excludes(mirror) {
+		t.Fatal("nothing is excluded by default")
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"flag"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ExemptNamespaces are namespaces left alone whatever their policies: the controller deletes
+// nothing in them and the webhook admits everything. A config file reload may change them while
+// they are read.
+type ExemptNamespaces struct {
+	names atomic.Pointer[sets.Set[string]]
+}
+
+// AddFlags registers -exempt-namespaces.
+func (x *ExemptNamespaces) AddFlags(fs *flag.FlagSet) {
+	fs.Var(x, "exempt-namespaces", "comma-separated namespaces that are never enforced, whatever their policies")
+}
+
+// Set replaces the namespaces with the comma-separated list names.
+func (x *ExemptNamespaces) Set(names string) error {
+	set := sets.New[string]()
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			set.Insert(name)
+		}
+	}
+	x.names.Store(&set)
+	return nil
+}
+
+func (x *ExemptNamespaces) String() string {
+	if x == nil {
+		return ""
+	}
+	names := x.names.Load()
+	if names == nil {
+		return ""
+	}
+	list := names.UnsortedList()
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+// Has reports whether ns is exempt; a nil x exempts nothing.
+func (x *ExemptNamespaces) Has(ns string) bool {
+	if x == nil {
+		return false
+	}
+	names := x.names.Load()
+	return names != nil && names.Has(ns)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Exemptions are the QuotaExemptions of a namespace in force at one time. Their pods neither
+// count toward the namespace's policies nor get deleted to enforce them.
+type Exemptions struct {
+	items []exemption
+}
+
+type exemption struct {
+	name      string
+	selector  labels.Selector // nil without a podSelector
+	workloads []v1beta1.WorkloadReference
+}
+
+// NewExemptions returns the exemptions of items in force at now, or nil when none is. An
+// exemption with an invalid pod selector is left out and reported in the error.
+func NewExemptions(items []*v1beta1.QuotaExemption, now time.Time) (*Exemptions, error) {
+	var x Exemptions
+	var errs []error
+	for _, item := range items {
+		if !item.Active(now) {
+			continue
+		}
+		e := exemption{name: item.Name, workloads: item.Spec.Workloads}
+		if item.Spec.PodSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(item.Spec.PodSelector)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("QuotaExemption %s: podSelector: %w", item.Name, err))
+				continue
+			}
+			e.selector = selector
+		}
+		x.items = append(x.items, e)
+	}
+	if len(x.items) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &x, errors.Join(errs...)
+}
+
+// Exempts returns the name of the exemption that exempts pod, if any; a nil x exempts nothing.
+func (x *Exemptions) Exempts(pod *corev1.Pod) (string, bool) {
+	if x == nil {
+		return "", false
+	}
+	for _, e := range x.items {
+		if e.selector != nil && !e.selector.Empty() && e.selector.Matches(labels.Set(pod.Labels)) {
+			return e.name, true
+		}
+		for _, w := range e.workloads {
+			if ownedBy(pod, w) {
+				return e.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ownedBy reports whether pod belongs to the workload w.
+func ownedBy(pod *corev1.Pod, w v1beta1.WorkloadReference) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return false
+	}
+	if w.Kind == v1beta1.WorkloadDeployment {
+		// a Deployment's pods belong to its ReplicaSets, named after it and their pod template
+		hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		return owner.Kind == v1beta1.WorkloadReplicaSet && hash != "" && owner.Name == w.Name+"-"+hash
+	}
+	return owner.Kind == w.Kind && owner.Name == w.Name
+}
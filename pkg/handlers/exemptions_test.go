@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExemptions(t *testing.T) {
+	now := time.Now()
+	isController := true
+	owned := func(kind, name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: labels, OwnerReferences: []metav1.OwnerReference{
+			{Kind: kind, Name: name, Controller: &isController},
+		}}}
+	}
+	exemption := func(name string, expiresAt time.Time, spec v1beta1.QuotaExemptionSpec) *v1beta1.QuotaExemption {
+		spec.ExpiresAt = metav1.NewTime(expiresAt)
+		return &v1beta1.QuotaExemption{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+
+	x, err := NewExemptions([]*v1beta1.QuotaExemption{
+		exemption("load-test", now.Add(time.Hour), v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "loadgen"}},
+		}),
+		exemption("migration", now.Add(time.Hour), v1beta1.QuotaExemptionSpec{
+			Workloads: []v1beta1.WorkloadReference{
+				{Kind: v1beta1.WorkloadDeployment, Name: "api"},
+				{Kind: v1beta1.WorkloadJob, Name: "backfill"},
+			},
+		}),
+		exemption("expired", now.Add(-time.Minute), v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		}),
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		pod  *corev1.Pod
+		want string
+	}{
+		{"selector", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "loadgen"}}}, "load-test"},
+		{"deployment", owned("ReplicaSet", "api-5d8f7", map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5d8f7"}), "migration"},
+		{"job", owned("Job", "backfill", nil), "migration"},
+		{"other replicaset", owned("ReplicaSet", "api-old", map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5d8f7"}), ""},
+		{"other kind", owned("StatefulSet", "api", nil), ""},
+		{"expired", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}}, ""},
+	} {
+		got, ok := x.Exempts(tc.pod)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("%s: Exempts() = %q, %t, want %q", tc.name, got, ok, tc.want)
+		}
+		if excluded := (Exclusions{Exemptions: x}).Excludes(tc.pod); excluded != ok {
+			t.Errorf("%s: Excludes() = %t", tc.name, excluded)
+		}
+	}
+
+	x, err = NewExemptions([]*v1beta1.QuotaExemption{
+		exemption("invalid", now.Add(time.Hour), v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}},
+		}),
+	}, now)
+	if err == nil || x != nil {
+		t.Fatalf("expected an error and no exemptions, got %v, %+v", err, x)
+	}
+	if _, ok := x.Exempts(&corev1.Pod{}); ok {
+		t.Fatal("nil exemptions exempt nothing")
+	}
+}
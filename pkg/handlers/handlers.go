@@ -3,22 +3,72 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/promusage"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 )
 
 // Policy holds parsed values used for enforcement.
 type Policy struct {
+	// Name of the ResourceQuotaPolicy this was parsed from, for audit records.
+	Name string
+
 	MaxPods   int
 	MaxCPU    resource.Quantity
 	MaxMemory resource.Quantity
+
+	// Soft limits only produce warnings; zero means disabled.
+	SoftPods   int
+	SoftCPU    resource.Quantity
+	SoftMemory resource.Quantity
+
+	// Unit prices for cost estimates; zero means not priced.
+	CPUHourPrice       float64
+	MemoryGiBHourPrice float64
+	Currency           string
+
+	// Exclude selects pods this policy ignores.
+	Exclude Exclusions
+
+	// MaxExtended limits extended resources by name; a zero limit forbids the resource.
+	MaxExtended map[string]resource.Quantity
+
+	// Defaults are the requests assumed for containers that leave them out.
+	Defaults RequestDefaults
+
+	// MaxDeletions caps the pods one EnforceUntilOK call deletes; zero leaves only MaxIterations.
+	MaxDeletions int
+
+	// Measured counts the CPU and memory pods use, as these queries report it, instead of their
+	// requests; nil counts requests.
+	Measured *v1beta1.UsageQueries
+	// Recommended counts the requests of pods selected by a VerticalPodAutoscaler at its
+	// recommendation.
+	Recommended bool
+
+	// Impersonate is the identity pods are deleted as; nil uses PodEnforcer.Impersonator's
+	// default.
+	Impersonate *v1beta1.Impersonation
 }
 
 // EnforcementResult returns current usage and violation state after enforcement attempt.
@@ -26,93 +76,459 @@ type EnforcementResult struct {
 	CurrentPods   int    `json:"currentPods"`
 	CurrentCPU    string `json:"currentCpu"`
 	CurrentMemory string `json:"currentMemory"`
-	Violation     bool   `json:"violation"`
-	Message       string `json:"message"`
+	// CurrentExtended is the usage of each extended resource the policy limits.
+	CurrentExtended map[string]string `json:"currentExtended,omitempty"`
+	Violation       bool              `json:"violation"`
+	Message         string            `json:"message"`
+	// Warnings lists the resources ("pods", "cpu", "memory") above their soft limit.
+	Warnings []string `json:"warnings,omitempty"`
+	// Evicted lists the pods deleted to bring the namespace back within its limits.
+	Evicted []string `json:"evicted,omitempty"`
+	// RateLimited is set when a violation was left in place because the namespace ran out of
+	// deletions under PodEnforcer.Limiter.
+	RateLimited bool `json:"rateLimited,omitempty"`
+	// DeletionCapReached is set when a violation was left in place because the call deleted as
+	// many pods as Policy.MaxDeletions or PodEnforcer.MaxIterations allow.
+	DeletionCapReached bool `json:"deletionCapReached,omitempty"`
+	// WouldEvict lists the pods a dry run picked for deletion but left in place.
+	WouldEvict []string `json:"wouldEvict,omitempty"`
+}
+
+// Enforcement modes of the controller, set with -enforcement.
+const (
+	// EnforcementActive deletes pods to bring namespaces back within their policies.
+	EnforcementActive = "active"
+	// EnforcementDryRun only reports the pods that would be deleted.
+	EnforcementDryRun = "dry-run"
+)
+
+// ParseEnforcement validates the value of the -enforcement flag.
+func ParseEnforcement(mode string) (string, error) {
+	switch mode {
+	case EnforcementActive, EnforcementDryRun:
+		return mode, nil
+	case "":
+		return EnforcementActive, nil
+	}
+	return "", fmt.Errorf("unknown enforcement mode %q, must be %q or %q", mode, EnforcementActive, EnforcementDryRun)
+}
+
+// EnforcementMode is the -enforcement flag, which a config file reload may change while
+// enforcers read it. The zero EnforcementMode is active.
+type EnforcementMode struct {
+	dryRun atomic.Bool
+}
+
+// Set sets the mode from the value of the -enforcement flag.
+func (m *EnforcementMode) Set(mode string) error {
+	mode, err := ParseEnforcement(mode)
+	if err != nil {
+		return err
+	}
+	m.dryRun.Store(mode == EnforcementDryRun)
+	return nil
 }
 
+func (m *EnforcementMode) String() string {
+	if m.DryRun() {
+		return EnforcementDryRun
+	}
+	return EnforcementActive
+}
+
+// DryRun reports whether m is EnforcementDryRun; a nil m is active.
+func (m *EnforcementMode) DryRun() bool {
+	return m != nil && m.dryRun.Load()
+}
+
+const (
+	// DefaultMaxIterations is how many pods EnforceUntilOK deletes at most per call.
+	DefaultMaxIterations = 10
+	// DefaultDeletionTimeout is how long EnforceUntilOK waits for a deleted pod to disappear.
+	DefaultDeletionTimeout = 30 * time.Second
+)
+
 // PodEnforcer enforces policies per namespace.
 type PodEnforcer struct {
-	Client      kubernetes.Interface
-	PolicyCache map[string]Policy // namespace → policy
+	Client kubernetes.Interface
+	// PolicyCache holds the policy enforced in each namespace.
+	PolicyCache *policycache.Store[Policy]
+	// Audit receives a record of every pod deleted; nil disables auditing.
+	Audit *audit.Logger
+	// Cluster names the cluster in audit events, when the controller manages several.
+	Cluster string
+
+	// Exclude holds the cluster-wide exclusion defaults that policies are parsed against.
+	Exclude Exclusions
+
+	// Usage keeps running totals per namespace from the pod informer; nil lists pods on every
+	// usage computation. Policies limiting extended resources always list.
+	Usage *UsageTracker
+
+	// Devices resolves DRA resource claims for extended resource limits; nil counts container
+	// requests only.
+	Devices *DeviceResolver
+
+	// Prometheus measures usage for policies with usageSource prometheus; nil fails to
+	// enforce them.
+	Prometheus *promusage.Client
+
+	// VPA reads VerticalPodAutoscaler recommendations for policies with usageSource
+	// vpaRecommendation; nil counts requests as declared.
+	VPA *VPAResolver
+
+	// Impersonator builds the clients pods are deleted with when a policy or the controller
+	// impersonates another identity; nil deletes with Client.
+	Impersonator *Impersonator
+
+	// Limiter caps the pods deleted per namespace per minute; nil deletes without limit.
+	Limiter *DeletionLimiter
+
+	// MaxIterations caps the pods deleted per EnforceUntilOK call; zero means DefaultMaxIterations.
+	MaxIterations int
+	// DeletionTimeout bounds the wait for each deleted pod to go away, which can take up to
+	// its termination grace period; zero means DefaultDeletionTimeout.
+	DeletionTimeout time.Duration
+
+	// Mode is the enforcement mode; a dry run picks pods for deletion as usual but leaves them in
+	// place, reporting them in EnforcementResult.WouldEvict and the audit log instead. nil is
+	// active.
+	Mode *EnforcementMode
+	// Maintenance turns enforcement into a dry run while maintenance mode is on; nil never does.
+	Maintenance *maintenance.Mode
+}
+
+// DryRun reports whether e runs in EnforcementDryRun mode, or maintenance mode makes it.
+func (e *PodEnforcer) DryRun() bool {
+	return e.Mode.DryRun() || e.Maintenance.Active()
 }
 
+// deleteBackoff spaces out retries after a failed delete.
+var deleteBackoff = wait.Backoff{Duration: 100 * time.Millisecond, Factor: 2, Jitter: 0.1, Cap: 5 * time.Second, Steps: math.MaxInt32}
+
+// deletionPollBackoff spaces out checks for a deleted pod to disappear.
+var deletionPollBackoff = wait.Backoff{Duration: 50 * time.Millisecond, Factor: 2, Jitter: 0.1, Cap: 2 * time.Second, Steps: math.MaxInt32}
+
 // EnforceUntilOK enforces the policy by deleting pods until usage <= policy or maxIterations reached.
-// Returns final usage summary and whether violation still exists.
-func (e *PodEnforcer) EnforceUntilOK(namespace string, policy Policy) (EnforcementResult, error) {
-	maxIterations := 10 // safety limit
+// Returns final usage summary and whether violation still exists. It stops early, returning
+// ctx.Err(), once ctx is done. In a dry run, it reports the usage as it is, with the pods it
+// would have deleted in WouldEvict.
+func (e *PodEnforcer) EnforceUntilOK(ctx context.Context, namespace string, policy Policy) (EnforcementResult, error) {
+	res, err := e.enforce(ctx, namespace, policy)
+	if err != nil || len(res.WouldEvict) == 0 {
+		return res, err
+	}
+	actual, err := e.listUsage(ctx, namespace, policy, nil)
+	if err != nil {
+		return EnforcementResult{}, err
+	}
+	actual.WouldEvict = res.WouldEvict
+	actual.DeletionCapReached = res.DeletionCapReached
+	return actual, nil
+}
+
+// enforce is EnforceUntilOK, except that in a dry run the usage it reports is what deleting
+// the pods in WouldEvict would leave.
+func (e *PodEnforcer) enforce(ctx context.Context, namespace string, policy Policy) (EnforcementResult, error) {
+	maxIterations := e.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxIterations
+	}
 	var lastErr error
+	var evicted, wouldEvict []string
+	// picked are the pods a dry run would have deleted so far
+	picked := sets.New[string]()
+	// blocked are the pods a PodDisruptionBudget kept from being evicted, which still count
+	blocked := sets.New[string]()
+	backoff := deleteBackoff
+	// the mode may change under us; one call sticks to the mode it started in
+	dryRun := e.DryRun()
 
 	for i := range maxIterations {
-		res, err := e.computeUsage(namespace, policy)
+		// once pods are being deleted, count from a List: the informer may not have seen them go
+		var res EnforcementResult
+		var err error
+		if i == 0 {
+			res, err = e.ComputeUsage(ctx, namespace, policy)
+		} else {
+			res, err = e.listUsage(ctx, namespace, policy, picked)
+		}
 		if err != nil {
 			return EnforcementResult{}, err
 		}
 
 		// if no violation -> we're done
 		if !res.Violation {
+			res.Evicted, res.WouldEvict = evicted, wouldEvict
+			return res, nil
+		}
+
+		if deleted := len(evicted) + len(wouldEvict); policy.MaxDeletions > 0 && deleted >= policy.MaxDeletions {
+			logging.L().WithName("enforcer").Info("Deletion cap reached, leaving violation in place", "namespace", namespace, "policy", policy.Name, "deleted", deleted, "dryRun", dryRun)
+			res.Evicted, res.WouldEvict = evicted, wouldEvict
+			res.DeletionCapReached = true
 			return res, nil
 		}
 
 		// if pods exceed -> delete oldest repeatedly until pods <= max
-		pods, err := e.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		var pods []corev1.Pod
+		err = EachPod(ctx, e.Client, namespace, func(pod *corev1.Pod) error {
+			if !picked.Has(pod.Name) && !blocked.Has(pod.Name) {
+				pods = append(pods, *pod)
+			}
+			return nil
+		})
 		if err != nil {
 			lastErr = err
 			break
 		}
+		candidates := pods
+		if res.Reason() == "extended" {
+			// only deleting a pod that holds the resource brings its usage down
+			candidates, err = e.consumers(ctx, pods, res.extendedResource())
+			if err != nil {
+				lastErr = err
+				break
+			}
+		}
 		// If pod deletion required (either due to pod count or resource oversubscription), pick a deletion target.
-		target, ok := selectPodToDelete(pods.Items, res.Reason())
+		target, ok := selectPodToDelete(candidates, res.Reason(), policy.Exclude)
 		if !ok {
 			// nothing to delete => break
 			res.Message = "violation but no suitable pod to delete"
+			res.Evicted, res.WouldEvict = evicted, wouldEvict
+			return res, nil
+		}
+
+		if dryRun {
+			// deleting nothing takes nothing from the rate limit either
+			picked.Insert(target.Name)
+			wouldEvict = append(wouldEvict, target.Name)
+			e.Audit.Record(audit.Event{
+				Source:    audit.SourceController,
+				Cluster:   e.Cluster,
+				Namespace: namespace,
+				Pod:       target.Name,
+				Policy:    policy.Name,
+				Reason:    res.Message,
+				Usage:     audit.Usage{Pods: int64(res.CurrentPods), CPU: res.CurrentCPU, Memory: res.CurrentMemory},
+				Decision:  audit.DecisionWouldEvict,
+			})
+			logging.L().WithName("enforcer").Info("Would delete pod to enforce policy (dry run)", "namespace", namespace, "pod", target.Name, "policy", policy.Name, "iteration", i+1)
+			continue
+		}
+
+		if !e.Limiter.Allow(namespace) {
+			// report the violation and leave it to a later sync, once the bucket has refilled
+			logging.L().WithName("enforcer").Info("Deletion rate limit reached, leaving violation in place", "namespace", namespace, "policy", policy.Name, "pod", target.Name)
+			res.Message += "; deletion rate limit reached"
+			res.Evicted = evicted
+			res.RateLimited = true
 			return res, nil
 		}
 
-		if delErr := e.Client.CoreV1().Pods(namespace).Delete(context.TODO(), target.Name, metav1.DeleteOptions{}); delErr != nil {
+		writer, as, err := e.Writer(policy.Impersonate)
+		if err != nil {
+			return EnforcementResult{Evicted: evicted}, err
+		}
+		delErr := deletePod(ctx, writer, namespace, &target)
+		if apierrors.IsTooManyRequests(delErr) {
+			// a PodDisruptionBudget allows no more disruptions; try the next pod instead
+			logging.L().WithName("enforcer").Info("Eviction blocked by a PodDisruptionBudget, picking another pod", "namespace", namespace, "pod", target.Name, "policy", policy.Name)
+			blocked.Insert(target.Name)
+			continue
+		}
+		if apierrors.IsConflict(delErr) || apierrors.IsNotFound(delErr) {
+			// the pod we picked is gone or was replaced; recompute usage and pick again
+			logging.L().WithName("enforcer").V(logging.Debug).Info("Victim changed before deletion, retrying", "namespace", namespace, "pod", target.Name, "policy", policy.Name)
+			continue
+		}
+		if delErr != nil {
 			lastErr = delErr
-			log.Printf("failed to delete pod %s/%s: %v", namespace, target.Name, delErr)
+			logging.L().WithName("enforcer").Error(delErr, "Failed to delete pod", "namespace", namespace, "pod", target.Name, "policy", policy.Name)
 			// backoff before retry
-			time.Sleep(500 * time.Millisecond)
+			if err := sleep(ctx, backoff.Step()); err != nil {
+				return EnforcementResult{Evicted: evicted}, err
+			}
 			continue
 		}
-		log.Printf("Deleted %s/%s to enforce policy (iteration %d)", namespace, target.Name, i+1)
-		// small sleep to let API state converge
-		time.Sleep(400 * time.Millisecond)
+		evicted = append(evicted, target.Name)
+		e.Audit.Record(audit.Event{
+			Source:    audit.SourceController,
+			Cluster:   e.Cluster,
+			Namespace: namespace,
+			Pod:       target.Name,
+			Policy:    policy.Name,
+			Reason:    res.Message,
+			Usage:     audit.Usage{Pods: int64(res.CurrentPods), CPU: res.CurrentCPU, Memory: res.CurrentMemory},
+			Decision:  audit.DecisionEvicted,
+			As:        as,
+		})
+		logging.L().WithName("enforcer").Info("Deleted pod to enforce policy", "namespace", namespace, "pod", target.Name, "policy", policy.Name, "iteration", i+1)
+		// wait for the pod to leave the namespace so the next usage computation doesn't count it
+		if err := e.waitForDeletion(ctx, namespace, target.Name, target.UID); err != nil {
+			if ctx.Err() != nil {
+				return EnforcementResult{Evicted: evicted}, ctx.Err()
+			}
+			// the pod counts until it is gone, so picking another victim now would delete pods
+			// that aren't needed to get within the limits; its deletion event triggers the next
+			// sync, which carries on from the usage it leaves
+			logging.L().WithName("enforcer").Info("Deleted pod still terminating, leaving the rest of the violation to the next sync", "namespace", namespace, "pod", target.Name, "policy", policy.Name, "timeout", e.deletionTimeout())
+			res, err := e.listUsage(ctx, namespace, policy, nil)
+			if err != nil {
+				return EnforcementResult{Evicted: evicted}, err
+			}
+			if res.Violation {
+				res.Message += "; waiting for deleted pod " + target.Name + " to terminate"
+			}
+			res.Evicted = evicted
+			return res, nil
+		}
 	}
 
 	// final check
-	final, err := e.computeUsage(namespace, policy)
+	final, err := e.listUsage(ctx, namespace, policy, picked)
 	if err != nil {
 		return EnforcementResult{}, err
 	}
+	final.Evicted, final.WouldEvict = evicted, wouldEvict
+	final.DeletionCapReached = final.Violation && lastErr == nil
 	return final, lastErr
 }
 
-// computeUsage returns an EnforcementResult describing current usage and whether it violates policy.
-// This function does not mutate cluster state.
-func (e *PodEnforcer) computeUsage(namespace string, policy Policy) (EnforcementResult, error) {
-	pods, err := e.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+// deletePod deletes pod from namespace, or evicts it with the PodEvictionAPI feature, never
+// touching a pod recreated under the same name since it was listed.
+func deletePod(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod) error {
+	propagation := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     metav1.NewUIDPreconditions(string(pod.UID)),
+	}
+	if features.Enabled(features.PodEvictionAPI) {
+		return client.PolicyV1().Evictions(namespace).Evict(ctx, &policyv1.Eviction{
+			ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: namespace},
+			DeleteOptions: &opts,
+		})
+	}
+	return client.CoreV1().Pods(namespace).Delete(ctx, pod.Name, opts)
+}
+
+func (e *PodEnforcer) deletionTimeout() time.Duration {
+	if e.DeletionTimeout > 0 {
+		return e.DeletionTimeout
+	}
+	return DefaultDeletionTimeout
+}
+
+// waitForDeletion polls, with backoff, until the pod named name with uid is gone or replaced by
+// a new pod of the same name. It gives up after DeletionTimeout.
+func (e *PodEnforcer) waitForDeletion(ctx context.Context, namespace, name string, uid types.UID) error {
+	ctx, cancel := context.WithTimeout(ctx, e.deletionTimeout())
+	defer cancel()
+	return wait.ExponentialBackoffWithContext(ctx, deletionPollBackoff, func(ctx context.Context) (bool, error) {
+		pod, err := e.Client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			// transient API error; keep polling until the timeout
+			return false, nil
+		}
+		return pod.UID != uid, nil
+	})
+}
+
+// sleep waits for d, or returns ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ComputeUsage returns an EnforcementResult describing current usage and whether it violates policy.
+// This function does not mutate cluster state. Usage is read from Usage when it is set and the
+// policy limits no extended resources and counts requests as declared, and counted from a pod List otherwise.
+func (e *PodEnforcer) ComputeUsage(ctx context.Context, namespace string, policy Policy) (EnforcementResult, error) {
+	if e.Usage == nil || len(policy.MaxExtended) > 0 || policy.Measured != nil || policy.Recommended {
+		return e.listUsage(ctx, namespace, policy, nil)
+	}
+	totals, err := e.Usage.Usage(namespace, policy, time.Now())
 	if err != nil {
-		return EnforcementResult{}, fmt.Errorf("list pods: %w", err)
+		return EnforcementResult{}, fmt.Errorf("track usage: %w", err)
 	}
+	return evaluateUsage(policy, totals.Pods, totals.CPU, totals.Memory, nil), nil
+}
 
+// listUsage is ComputeUsage counted from a pod List, which sees deletions the informer behind
+// Usage may not have caught up with yet. The pods named in skip are not counted.
+func (e *PodEnforcer) listUsage(ctx context.Context, namespace string, policy Policy, skip sets.Set[string]) (EnforcementResult, error) {
 	totalCPU := resource.MustParse("0")
 	totalMem := resource.MustParse("0")
 	count := 0
-	for _, pod := range pods.Items {
-		// ignore completed pods
-		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
-			continue
+	extendedNames := ExtendedNames(policy.MaxExtended)
+	totalExtended := make(map[string]resource.Quantity, len(extendedNames))
+	claimsSeen := map[string]bool{}
+	var measured promusage.Usage
+	if policy.Measured != nil {
+		if e.Prometheus == nil {
+			return EnforcementResult{}, fmt.Errorf("policy %s measures usage with Prometheus, but no Prometheus is configured", policy.Name)
+		}
+		var err error
+		if measured, err = e.Prometheus.Usage(ctx, namespace, policy.Measured); err != nil {
+			return EnforcementResult{}, fmt.Errorf("measure usage: %w", err)
+		}
+	}
+	var recommended *Recommendations
+	if policy.Recommended {
+		var err error
+		if recommended, err = e.VPA.Recommendations(ctx, namespace); err != nil {
+			return EnforcementResult{}, err
+		}
+	}
+	now := time.Now()
+	err := EachPod(ctx, e.Client, namespace, func(pod *corev1.Pod) error {
+		if !CountsTowardUsage(pod, now) || policy.Exclude.Excludes(pod) || skip.Has(pod.Name) {
+			return nil
 		}
 		count++
-		for _, c := range pod.Spec.Containers {
-			if cpuReq, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
-				totalCPU.Add(cpuReq)
-			}
-			if memReq, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
-				totalMem.Add(memReq)
-			}
+		cpu, mem := policy.Defaults.PodRequests(recommended.Apply(pod))
+		if q, ok := measured.CPU[pod.Name]; ok {
+			cpu = q
+		}
+		if q, ok := measured.Memory[pod.Name]; ok {
+			mem = q
 		}
+		totalCPU.Add(cpu)
+		totalMem.Add(mem)
+		if len(extendedNames) == 0 {
+			return nil
+		}
+		extended, err := e.Devices.ExtendedRequests(ctx, pod, extendedNames, claimsSeen)
+		if err != nil {
+			return err
+		}
+		for name, q := range extended {
+			total := totalExtended[name]
+			total.Add(q)
+			totalExtended[name] = total
+		}
+		return nil
+	})
+	if err != nil {
+		return EnforcementResult{}, fmt.Errorf("list pods: %w", err)
 	}
+	return evaluateUsage(policy, count, totalCPU, totalMem, totalExtended), nil
+}
+
+// evaluateUsage checks usage against the hard and soft limits of policy. totalExtended holds
+// the usage of each extended resource policy limits.
+func evaluateUsage(policy Policy, count int, totalCPU, totalMem resource.Quantity, totalExtended map[string]resource.Quantity) EnforcementResult {
+	extendedNames := ExtendedNames(policy.MaxExtended)
 
 	// check violations
 	violation := false
@@ -129,19 +545,52 @@ func (e *PodEnforcer) computeUsage(namespace string, policy Policy) (Enforcement
 		violation = true
 		msg = fmt.Sprintf("memory:%s>max:%s", totalMem.String(), policy.MaxMemory.String())
 	}
+	var currentExtended map[string]string
+	if len(extendedNames) > 0 {
+		currentExtended = make(map[string]string, len(extendedNames))
+	}
+	for _, name := range extendedNames {
+		used, limit := totalExtended[name], policy.MaxExtended[name]
+		currentExtended[name] = used.String()
+		if used.Cmp(limit) > 0 {
+			violation = true
+			msg = fmt.Sprintf("extended:%s:%s>max:%s", name, used.String(), limit.String())
+		}
+	}
+
+	// check soft limits
+	var warnings []string
+	if policy.SoftPods > 0 && count > policy.SoftPods {
+		warnings = append(warnings, "pods")
+	}
+	if !policy.SoftCPU.IsZero() && totalCPU.Cmp(policy.SoftCPU) > 0 {
+		warnings = append(warnings, "cpu")
+	}
+	if !policy.SoftMemory.IsZero() && totalMem.Cmp(policy.SoftMemory) > 0 {
+		warnings = append(warnings, "memory")
+	}
 
 	return EnforcementResult{
-		CurrentPods:   count,
-		CurrentCPU:    totalCPU.String(),
-		CurrentMemory: totalMem.String(),
-		Violation:     violation,
-		Message:       msg,
-	}, nil
+		CurrentPods:     count,
+		CurrentCPU:      totalCPU.String(),
+		CurrentMemory:   totalMem.String(),
+		CurrentExtended: currentExtended,
+		Violation:       violation,
+		Message:         msg,
+		Warnings:        warnings,
+	}
 }
 
 // selectPodToDelete chooses which pod to delete: oldest if pod count problem, newest if resource oversubscription.
 // returns (pod, true) if found, (zero, false) if none.
-func selectPodToDelete(pods []corev1.Pod, reason string) (corev1.Pod, bool) {
+func selectPodToDelete(all []corev1.Pod, reason string, exclude Exclusions) (corev1.Pod, bool) {
+	// deleting a pod that is already terminating or has completed frees nothing
+	var pods []corev1.Pod
+	for _, p := range all {
+		if p.DeletionTimestamp == nil && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed && !exclude.Excludes(&p) {
+			pods = append(pods, p)
+		}
+	}
 	if len(pods) == 0 {
 		return corev1.Pod{}, false
 	}
@@ -174,30 +623,118 @@ func (r EnforcementResult) Reason() string {
 	if len(r.Message) >= 6 && r.Message[:6] == "memory" {
 		return "memory"
 	}
+	if strings.HasPrefix(r.Message, "extended:") {
+		return "extended"
+	}
 	return ""
 }
 
-func ParsePolicy(spec *v1alpha1.ResourceQuotaPolicySpec) Policy {
-	maxPods := 10
-	maxCPU := resource.MustParse("2")
-	maxMem := resource.MustParse("2Gi")
+// extendedResource returns the extended resource named in an "extended:<name>:..." message.
+func (r EnforcementResult) extendedResource() string {
+	parts := strings.SplitN(r.Message, ":", 3)
+	if len(parts) < 3 || parts[0] != "extended" {
+		return ""
+	}
+	return parts[1]
+}
+
+// consumers returns the pods requesting or claiming the extended resource name.
+func (e *PodEnforcer) consumers(ctx context.Context, pods []corev1.Pod, name string) ([]corev1.Pod, error) {
+	var out []corev1.Pod
+	for _, p := range pods {
+		req, err := e.Devices.ExtendedRequests(ctx, &p, []string{name}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if q := req[name]; q.Sign() > 0 {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func ParsePolicy(spec *v1beta1.ResourceQuotaPolicySpec) Policy {
+	// objects admitted through the defaulting webhook already carry these, older ones may not
+	defaulted := spec.DeepCopy()
+	v1beta1.SetDefaults_ResourceQuotaPolicySpec(defaulted)
 
-	if pods := spec.MaxPods; pods != 0 {
-		maxPods = int(pods)
+	maxPods := int(defaulted.MaxPods)
+	maxCPU := defaulted.MaxCPU.DeepCopy()
+	maxMem := defaulted.MaxMemory.DeepCopy()
+
+	policy := Policy{MaxPods: maxPods, MaxCPU: maxCPU, MaxMemory: maxMem}
+	policy.Exclude = Exclusions{}.With(spec.ExcludePods)
+	policy.Defaults = RequestDefaultsFor(spec)
+	policy.MaxDeletions = int(spec.MaxDeletionsPerSync)
+	if spec.UsageSource == v1beta1.UsageSourcePrometheus {
+		policy.Measured = &v1beta1.UsageQueries{}
+		if spec.UsageQueries != nil {
+			policy.Measured = spec.UsageQueries.DeepCopy()
+		}
 	}
-	if v := spec.MaxCPU; v != "" {
-		q, err := resource.ParseQuantity(v)
-		if err == nil {
-			maxCPU = q
+	policy.Recommended = spec.UsageSource == v1beta1.UsageSourceVPARecommendation
+	policy.Impersonate = spec.Impersonate.DeepCopy()
+	if len(spec.MaxExtendedResources) > 0 {
+		policy.MaxExtended = make(map[string]resource.Quantity, len(spec.MaxExtendedResources))
+		for name, q := range spec.MaxExtendedResources {
+			policy.MaxExtended[name] = q.DeepCopy()
+		}
+	}
+	if soft := spec.SoftLimits; soft != nil {
+		if validPercent(soft.PodsPercent) {
+			policy.SoftPods = maxPods * int(soft.PodsPercent) / 100
+		}
+		if validPercent(soft.CPUPercent) {
+			policy.SoftCPU = *resource.NewMilliQuantity(maxCPU.MilliValue()*int64(soft.CPUPercent)/100, maxCPU.Format)
+		}
+		if validPercent(soft.MemoryPercent) {
+			policy.SoftMemory = *resource.NewQuantity(maxMem.Value()*int64(soft.MemoryPercent)/100, maxMem.Format)
 		}
 	}
-	if v := spec.MaxMemory; v != "" {
-		q, err := resource.ParseQuantity(v)
-		if err == nil {
-			maxMem = q
+
+	if p := spec.Pricing; p != nil {
+		policy.CPUHourPrice, _ = strconv.ParseFloat(p.CPUHour, 64)
+		policy.MemoryGiBHourPrice, _ = strconv.ParseFloat(p.MemoryGiBHour, 64)
+		policy.Currency = p.Currency
+		if policy.Currency == "" {
+			policy.Currency = "USD"
 		}
 	}
 
-	log.Printf("📋 Parsed policy: Pods=%d CPU=%s Mem=%s", maxPods, maxCPU.String(), maxMem.String())
-	return Policy{MaxPods: maxPods, MaxCPU: maxCPU, MaxMemory: maxMem}
+	logging.L().WithName("enforcer").V(logging.Debug).Info("Parsed policy", "pods", maxPods, "cpu", maxCPU.String(), "memory", maxMem.String())
+	return policy
+}
+
+func validPercent(p int32) bool {
+	return p > 0 && p <= 100
+}
+
+// Priced reports whether the policy carries any unit price.
+func (p Policy) Priced() bool {
+	return p.CPUHourPrice > 0 || p.MemoryGiBHourPrice > 0
+}
+
+// HourlyCost estimates what the given requests cost per hour at the policy's prices.
+func (p Policy) HourlyCost(cpu, memory resource.Quantity) (cpuCost, memoryCost float64) {
+	cpuCost = float64(cpu.MilliValue()) / 1000 * p.CPUHourPrice
+	memoryCost = memory.AsApproximateFloat64() / (1 << 30) * p.MemoryGiBHourPrice
+	return cpuCost, memoryCost
+}
+
+// Utilization returns used/limit, or 0 when there is no limit.
+func Utilization(used, limit resource.Quantity) float64 {
+	if limit.Sign() <= 0 {
+		return 0
+	}
+	return used.AsApproximateFloat64() / limit.AsApproximateFloat64()
+}
+
+// CountsTowardUsage reports whether pod is counted against a policy at now. Completed pods are
+// not; terminating pods are until their grace period, which ends at the deletion timestamp,
+// runs out.
+func CountsTowardUsage(pod *corev1.Pod, now time.Time) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	return pod.DeletionTimestamp == nil || now.Before(pod.DeletionTimestamp.Time)
 }
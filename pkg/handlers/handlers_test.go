@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	"github.com/sri2103/resource-quota-enforcer/pkg/promusage"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func runningPod(name string, age time.Duration) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns1",
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestEnforceUntilOKWaitsForDeletion(t *testing.T) {
+	var objs []runtime.Object
+	for i := range 4 {
+		objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+	}
+	e := &PodEnforcer{Client: fake.NewSimpleClientset(objs...)}
+
+	start := time.Now()
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Violation || res.CurrentPods != 2 || len(res.Evicted) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	// the fake client deletes immediately, so convergence must not wait out fixed sleeps
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("enforcement took %s", elapsed)
+	}
+}
+
+func TestEnforceUntilOKStopsWhileDeletedPodTerminates(t *testing.T) {
+	var objs []runtime.Object
+	for i := range 5 {
+		objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+	}
+	cs := fake.NewSimpleClientset(objs...)
+	// as for a pod with a long termination grace period: the delete only marks it terminating
+	var deletes int
+	cs.PrependReactor("delete", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		deletes++
+		name := a.(k8stesting.DeleteActionImpl).GetName()
+		pod, err := cs.Tracker().Get(corev1.SchemeGroupVersion.WithResource("pods"), "ns1", name)
+		if err != nil {
+			return true, nil, err
+		}
+		terminating := pod.(*corev1.Pod).DeepCopy()
+		terminating.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(time.Hour)}
+		return true, nil, cs.Tracker().Update(corev1.SchemeGroupVersion.WithResource("pods"), terminating, "ns1")
+	})
+	e := &PodEnforcer{Client: cs, DeletionTimeout: 50 * time.Millisecond}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deletes != 1 || len(res.Evicted) != 1 {
+		t.Fatalf("deleted %d pods (%v), want only the one that gets within maxPods", deletes, res.Evicted)
+	}
+	if !res.Violation || res.CurrentPods != 5 {
+		t.Fatalf("want the violation reported while the pod terminates, got %+v", res)
+	}
+}
+
+func TestEnforceUntilOKRespectsMaxIterations(t *testing.T) {
+	var objs []runtime.Object
+	for i := range 5 {
+		objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+	}
+	e := &PodEnforcer{Client: fake.NewSimpleClientset(objs...), MaxIterations: 1}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Violation || len(res.Evicted) != 1 {
+		t.Fatalf("expected one eviction and a remaining violation, got %+v", res)
+	}
+}
+
+func TestEnforceUntilOKStopsAtPolicyDeletionCap(t *testing.T) {
+	var objs []runtime.Object
+	for i := range 5 {
+		objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+	}
+	e := &PodEnforcer{Client: fake.NewSimpleClientset(objs...)}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 1, MaxDeletions: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Violation || !res.DeletionCapReached || len(res.Evicted) != 2 {
+		t.Fatalf("expected two evictions and a violation left at the cap, got %+v", res)
+	}
+}
+
+func TestEnforceUntilOKDryRunDeletesNothing(t *testing.T) {
+	var dryRun EnforcementMode
+	if err := dryRun.Set(EnforcementDryRun); err != nil {
+		t.Fatal(err)
+	}
+	for name, e := range map[string]*PodEnforcer{
+		"dry run":     {Mode: &dryRun},
+		"maintenance": {Maintenance: maintenance.ForTesting(true, "")},
+	} {
+		var objs []runtime.Object
+		for i := range 4 {
+			objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+		}
+		client := fake.NewSimpleClientset(objs...)
+		var buf bytes.Buffer
+		e.Client, e.Audit = client, audit.New(&buf)
+
+		res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the oldest pods are picked, and the violation is still reported as it stands
+		if !res.Violation || res.CurrentPods != 4 || len(res.Evicted) != 0 || !slices.Equal(res.WouldEvict, []string{"web-3", "web-2"}) {
+			t.Fatalf("%s: unexpected result: %+v", name, res)
+		}
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "delete" {
+				t.Fatalf("%s: deleted a pod: %v", name, action)
+			}
+		}
+		if got := bytes.Count(buf.Bytes(), []byte(`"decision":"would_evict"`)); got != 2 {
+			t.Fatalf("%s: expected 2 would_evict audit records, got %d: %s", name, got, buf.String())
+		}
+	}
+}
+
+func TestComputeUsageMeasured(t *testing.T) {
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), "cpu") {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"web-0"},"value":[1,"0.2"]},{"metric":{"pod":"web-1"},"value":[1,"0.3"]}]}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer prom.Close()
+
+	var objs []runtime.Object
+	for i := range 3 {
+		pod := runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute)
+		pod.Spec.Containers = []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}}}
+		objs = append(objs, pod)
+	}
+	policy := Policy{MaxPods: 10, MaxCPU: resource.MustParse("1500m"), MaxMemory: resource.MustParse("4Gi"), Measured: &v1beta1.UsageQueries{}}
+
+	e := &PodEnforcer{Client: fake.NewSimpleClientset(objs...)}
+	if _, err := e.ComputeUsage(context.Background(), "ns1", policy); err == nil {
+		t.Fatal("measured usage without a Prometheus client succeeded")
+	}
+
+	// web-2 has no sample yet and counts its requests; nobody has a memory sample
+	e.Prometheus = &promusage.Client{URL: prom.URL}
+	res, err := e.ComputeUsage(context.Background(), "ns1", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Violation || res.CurrentCPU != "1500m" || res.CurrentMemory != "3Gi" {
+		t.Fatalf("unexpected measured usage: %+v", res)
+	}
+}
+
+func TestEnforceUntilOKStopsAtDeletionRateLimit(t *testing.T) {
+	var objs []runtime.Object
+	for i := range 5 {
+		objs = append(objs, runningPod(fmt.Sprintf("web-%d", i), time.Duration(i)*time.Minute))
+	}
+	e := &PodEnforcer{Client: fake.NewSimpleClientset(objs...), Limiter: NewDeletionLimiter(2)}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Violation || !res.RateLimited || len(res.Evicted) != 2 {
+		t.Fatalf("expected two evictions and a rate limited violation, got %+v", res)
+	}
+
+	// other namespaces have buckets of their own
+	if !e.Limiter.Allow("ns2") {
+		t.Fatal("deletion in another namespace was rate limited")
+	}
+}
+
+func TestEnforceUntilOKDeletesWithUIDPrecondition(t *testing.T) {
+	cs := fake.NewSimpleClientset(runningPod("web-0", time.Minute), runningPod("web-1", 0))
+	conflicted := false
+	cs.PrependReactor("delete", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		opts := a.(k8stesting.DeleteActionImpl).GetDeleteOptions()
+		if opts.Preconditions == nil || opts.Preconditions.UID == nil {
+			t.Errorf("delete of %s sent without a UID precondition", a.(k8stesting.DeleteActionImpl).GetName())
+		}
+		if !conflicted {
+			// as if the victim was recreated between list and delete
+			conflicted = true
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "web-1", errors.New("uid mismatch"))
+		}
+		return false, nil, nil
+	})
+	e := &PodEnforcer{Client: cs}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 1})
+	if err != nil {
+		t.Fatalf("conflict surfaced as an error: %v", err)
+	}
+	if res.Violation || len(res.Evicted) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestEnforceUntilOKEvictsWithPodEvictionAPI(t *testing.T) {
+	features.SetForTesting(t, features.PodEvictionAPI, true)
+	cs := fake.NewSimpleClientset(runningPod("web-0", 0), runningPod("web-1", time.Minute), runningPod("web-2", 2*time.Minute))
+	var evicted []string
+	cs.PrependReactor("create", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		create := a.(k8stesting.CreateAction)
+		if create.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := create.GetObject().(*policyv1.Eviction)
+		if eviction.DeleteOptions == nil || eviction.DeleteOptions.Preconditions == nil {
+			t.Errorf("eviction of %s sent without a UID precondition", eviction.Name)
+		}
+		if eviction.Name == "web-2" {
+			// as if a PodDisruptionBudget allowed no disruption
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		evicted = append(evicted, eviction.Name)
+		return true, nil, cs.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), "ns1", eviction.Name)
+	})
+	cs.PrependReactor("delete", "pods", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		t.Errorf("deleted %s instead of evicting it", a.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+	e := &PodEnforcer{Client: cs}
+
+	res, err := e.EnforceUntilOK(context.Background(), "ns1", Policy{MaxPods: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the oldest pod is protected, so the next oldest goes instead
+	if res.Violation || !slices.Equal(res.Evicted, []string{"web-1"}) || !slices.Equal(evicted, []string{"web-1"}) {
+		t.Fatalf("unexpected result: %+v, evicted %v", res, evicted)
+	}
+}
+
+func TestSelectPodToDeleteSkipsTerminatingPods(t *testing.T) {
+	oldest := runningPod("oldest", time.Hour)
+	oldest.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(30 * time.Second)}
+	pods := []corev1.Pod{*oldest, *runningPod("older", 30*time.Minute), *runningPod("new", 0)}
+
+	got, ok := selectPodToDelete(pods, "pods", Exclusions{})
+	if !ok || got.Name != "older" {
+		t.Fatalf("picked %q, want the oldest pod not already terminating", got.Name)
+	}
+
+	if _, ok := selectPodToDelete([]corev1.Pod{*oldest}, "pods", Exclusions{}); ok {
+		t.Fatal("picked a terminating pod")
+	}
+}
+
+func TestCountsTowardUsage(t *testing.T) {
+	now := time.Now()
+	terminating := func(at time.Time) *corev1.Pod {
+		p := runningPod("p", 0)
+		p.DeletionTimestamp = &metav1.Time{Time: at}
+		return p
+	}
+	succeeded := runningPod("p", 0)
+	succeeded.Status.Phase = corev1.PodSucceeded
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{"running", runningPod("p", 0), true},
+		{"completed", succeeded, false},
+		{"terminating within grace period", terminating(now.Add(10 * time.Second)), true},
+		{"grace period over", terminating(now.Add(-time.Second)), false},
+	}
+	for _, tt := range tests {
+		if got := CountsTowardUsage(tt.pod, now); got != tt.want {
+			t.Errorf("%s: CountsTowardUsage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/client"
+)
+
+// Impersonator builds the clients that enforcement writes with, acting as another identity than
+// the one reading the cluster, so that deletions show up under it in the API server's audit log
+// and it can be granted only the writes enforcement makes.
+type Impersonator struct {
+	config *rest.Config
+	// Default is the identity used for policies without one of their own; nil writes as the
+	// identity of config.
+	Default *v1beta1.Impersonation
+
+	newClient func(*rest.Config) (kubernetes.Interface, error)
+
+	mu      sync.Mutex
+	clients map[string]kubernetes.Interface
+}
+
+// NewImpersonator returns an Impersonator building clients from config.
+func NewImpersonator(config *rest.Config, def *v1beta1.Impersonation) *Impersonator {
+	return &Impersonator{
+		config:  config,
+		Default: def,
+		newClient: func(config *rest.Config) (kubernetes.Interface, error) {
+			return client.GetKubernetesClient(config)
+		},
+		clients: map[string]kubernetes.Interface{},
+	}
+}
+
+// identity returns the identity writes for a policy impersonating identity are made as, or nil
+// for none.
+func (i *Impersonator) identity(identity *v1beta1.Impersonation) *v1beta1.Impersonation {
+	if identity != nil && identity.User != "" {
+		return identity
+	}
+	if i != nil && i.Default != nil && i.Default.User != "" {
+		return i.Default
+	}
+	return nil
+}
+
+// client returns the client acting as identity, built once per identity.
+func (i *Impersonator) client(identity *v1beta1.Impersonation) (kubernetes.Interface, error) {
+	key := identity.User + "\x00" + strings.Join(identity.Groups, "\x00")
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if c, ok := i.clients[key]; ok {
+		return c, nil
+	}
+	config := rest.CopyConfig(i.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: identity.User, Groups: identity.Groups}
+	c, err := i.newClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("build client impersonating %s: %w", identity.User, err)
+	}
+	i.clients[key] = c
+	return c, nil
+}
+
+// Writer returns the client enforcement writes with for a policy impersonating identity, which
+// may be nil, and the user it acts as, which is empty when it acts as e.Client.
+func (e *PodEnforcer) Writer(identity *v1beta1.Impersonation) (kubernetes.Interface, string, error) {
+	identity = e.Impersonator.identity(identity)
+	if identity == nil {
+		return e.Client, "", nil
+	}
+	if e.Impersonator == nil {
+		return nil, "", fmt.Errorf("policy impersonates %s but the controller has no impersonation set up", identity.User)
+	}
+	c, err := e.Impersonator.client(identity)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, identity.User, nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+func TestWriterImpersonates(t *testing.T) {
+	var built []rest.ImpersonationConfig
+	impersonator := NewImpersonator(&rest.Config{Host: "https://example.com"}, &v1beta1.Impersonation{User: "rqe-enforcer"})
+	impersonator.newClient = func(config *rest.Config) (kubernetes.Interface, error) {
+		built = append(built, config.Impersonate)
+		return fake.NewSimpleClientset(), nil
+	}
+	reader := fake.NewSimpleClientset()
+	e := &PodEnforcer{Client: reader, Impersonator: impersonator}
+
+	def, as, err := e.Writer(nil)
+	if err != nil || as != "rqe-enforcer" || def == kubernetes.Interface(reader) {
+		t.Fatalf("default identity: as=%q err=%v", as, err)
+	}
+	if again, _, _ := e.Writer(&v1beta1.Impersonation{}); again != def {
+		t.Fatal("client for the default identity built twice")
+	}
+	own, as, err := e.Writer(&v1beta1.Impersonation{User: "team-a-enforcer", Groups: []string{"quota"}})
+	if err != nil || as != "team-a-enforcer" || own == def {
+		t.Fatalf("policy identity: as=%q err=%v", as, err)
+	}
+	if len(built) != 2 || built[1].UserName != "team-a-enforcer" || len(built[1].Groups) != 1 {
+		t.Fatalf("unexpected clients built: %+v", built)
+	}
+
+	// without a default the enforcer writes as itself, and fails a policy it can't impersonate for
+	e = &PodEnforcer{Client: reader}
+	if w, as, err := e.Writer(nil); err != nil || as != "" || w != kubernetes.Interface(reader) {
+		t.Fatalf("expected the reading client, got as=%q err=%v", as, err)
+	}
+	if _, _, err := e.Writer(&v1beta1.Impersonation{User: "team-a-enforcer"}); err == nil {
+		t.Fatal("expected an error impersonating without an Impersonator")
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"sort"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// JobActive reports whether job counts toward a policy's maxActiveJobs: it is neither suspended
+// nor finished.
+func JobActive(job *batchv1.Job) bool {
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return false
+	}
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// ExcessJobs returns the active jobs beyond the first maxActive, newest first, which are the
+// ones to suspend. It returns nil when maxActive is zero, which is unlimited.
+func ExcessJobs(jobs []batchv1.Job, maxActive int32) []*batchv1.Job {
+	if maxActive <= 0 {
+		return nil
+	}
+	var active []*batchv1.Job
+	for i := range jobs {
+		if JobActive(&jobs[i]) {
+			active = append(active, &jobs[i])
+		}
+	}
+	if len(active) <= int(maxActive) {
+		return nil
+	}
+	sort.Slice(active, func(i, j int) bool {
+		a, b := active[i].CreationTimestamp, active[j].CreationTimestamp
+		if !a.Equal(&b) {
+			return b.Before(&a)
+		}
+		return active[i].Name > active[j].Name
+	})
+	return active[:len(active)-int(maxActive)]
+}
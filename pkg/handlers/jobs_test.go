@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExcessJobs(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	job := func(name string, age time.Duration) batchv1.Job {
+		return batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(t0.Add(-age))}}
+	}
+	suspend := true
+	suspended := job("suspended", 0)
+	suspended.Spec.Suspend = &suspend
+	done := job("done", 0)
+	done.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	jobs := []batchv1.Job{job("oldest", 3*time.Hour), suspended, job("newest", 0), done, job("middle", time.Hour)}
+
+	names := func(jobs []*batchv1.Job) []string {
+		var out []string
+		for _, j := range jobs {
+			out = append(out, j.Name)
+		}
+		return out
+	}
+	if got := names(ExcessJobs(jobs, 1)); !reflect.DeepEqual(got, []string{"newest", "middle"}) {
+		t.Fatalf("excess jobs = %v, want the two newest active ones", got)
+	}
+	if got := ExcessJobs(jobs, 3); got != nil {
+		t.Fatalf("excess jobs = %v, want none within the limit", names(got))
+	}
+	if got := ExcessJobs(jobs, 0); got != nil {
+		t.Fatalf("excess jobs = %v, want none without a limit", names(got))
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/pager"
+)
+
+// PodPageSize is how many pods one List call asks the API server for.
+const PodPageSize = 500
+
+// activePods selects pods that have not completed. Completed pods never count toward a policy nor
+// get deleted to enforce one, so the API server filters them out rather than sending them over.
+var activePods = fields.AndSelectors(
+	fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+	fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+).String()
+
+// EachPod calls fn for every pod in namespace that has not completed, listing them PodPageSize
+// at a time so a namespace with tens of thousands of pods is never fetched in one response. It
+// stops at the first error fn returns.
+func EachPod(ctx context.Context, client kubernetes.Interface, namespace string, fn func(*corev1.Pod) error) error {
+	p := pager.New(pager.SimplePageFunc(func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.CoreV1().Pods(namespace).List(ctx, opts)
+	}))
+	p.PageSize = PodPageSize
+	return p.EachListItem(ctx, metav1.ListOptions{FieldSelector: activePods}, func(obj runtime.Object) error {
+		return fn(obj.(*corev1.Pod))
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestEachPodSkipsCompletedPodsServerSide(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	if err := EachPod(context.Background(), client, "ns1", func(*corev1.Pod) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	actions := client.Actions()
+	if len(actions) != 1 {
+		t.Fatalf("got %d actions, want one list", len(actions))
+	}
+	fs := actions[0].(k8stesting.ListAction).GetListRestrictions().Fields
+	for _, phase := range []string{"Succeeded", "Failed"} {
+		if !fs.Matches(fields.Set{"status.phase": "Running"}) || fs.Matches(fields.Set{"status.phase": phase}) {
+			t.Fatalf("field selector %q does not exclude %s pods", fs, phase)
+		}
+	}
+}
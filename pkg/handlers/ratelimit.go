@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DeletionLimiter is a token bucket per namespace that caps how many pods enforcement deletes
+// there per minute, so a tiny quota set by mistake on a large namespace can't empty it at once.
+// A nil DeletionLimiter allows every deletion. It is safe for concurrent use.
+type DeletionLimiter struct {
+	perMinute int
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+// NewDeletionLimiter allows perMinute deletions per namespace per minute, in bursts of up to
+// perMinute. It returns nil, allowing everything, when perMinute is not positive.
+func NewDeletionLimiter(perMinute int) *DeletionLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &DeletionLimiter{perMinute: perMinute, buckets: make(map[string]*rate.Limiter)}
+}
+
+// Allow takes a token from the bucket of namespace, reporting whether there was one.
+func (l *DeletionLimiter) Allow(namespace string) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	b, ok := l.buckets[namespace]
+	if !ok {
+		b = rate.NewLimiter(rate.Every(time.Minute/time.Duration(l.perMinute)), l.perMinute)
+		l.buckets[namespace] = b
+	}
+	l.mu.Unlock()
+	return b.Allow()
+}
+
+// Interval is how long it takes a namespace to earn back one deletion.
+func (l *DeletionLimiter) Interval() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return time.Minute / time.Duration(l.perMinute)
+}
+
+// Forget drops the bucket of namespace, e.g. once it no longer has a policy.
+func (l *DeletionLimiter) Forget(namespace string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	delete(l.buckets, namespace)
+	l.mu.Unlock()
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PodRequests returns the CPU and memory requests a pod counts against a policy. It matches
+// the scheduler: init containers run one at a time before the app containers start, so the
+// pod needs the larger of the sum of its containers and its largest init container, plus the
+// RuntimeClass overhead (e.g. the VM of a Kata pod) recorded in spec.overhead.
+//
+// Sidecars, init containers with restartPolicy Always, keep running once started: they add to
+// the app containers' sum and to every init container that starts after them. Ephemeral debug
+// containers, which the scheduler ignores, are added until they terminate.
+func PodRequests(pod *corev1.Pod) (cpu, memory resource.Quantity) {
+	cpu = effectiveRequest(pod, corev1.ResourceCPU)
+	memory = effectiveRequest(pod, corev1.ResourceMemory)
+	return cpu, memory
+}
+
+func effectiveRequest(pod *corev1.Pod, name corev1.ResourceName) resource.Quantity {
+	var total resource.Quantity
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[name]; ok {
+			total.Add(q)
+		}
+	}
+
+	// sidecars started so far, and the peak while init containers run
+	var sidecars, initPeak resource.Quantity
+	for _, c := range pod.Spec.InitContainers {
+		q := c.Resources.Requests[name]
+		running := sidecars.DeepCopy()
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			total.Add(q)
+			sidecars.Add(q)
+			running = sidecars.DeepCopy()
+		} else {
+			running.Add(q)
+		}
+		if running.Cmp(initPeak) > 0 {
+			initPeak = running
+		}
+	}
+	if initPeak.Cmp(total) > 0 {
+		total = initPeak
+	}
+
+	for _, c := range pod.Spec.EphemeralContainers {
+		if q, ok := c.Resources.Requests[name]; ok && !ephemeralTerminated(pod, c.Name) {
+			total.Add(q)
+		}
+	}
+
+	if q, ok := pod.Spec.Overhead[name]; ok {
+		total.Add(q)
+	}
+	return total
+}
+
+// RequestDefaults are the CPU and memory requests assumed for containers that leave them out.
+// The zero value assumes nothing, so a missing request counts as zero.
+type RequestDefaults struct {
+	Enabled bool
+	CPU     resource.Quantity
+	Memory  resource.Quantity
+}
+
+// RequestDefaultsFor returns the defaults spec.missingRequestsPolicy asks for.
+func RequestDefaultsFor(spec *v1beta1.ResourceQuotaPolicySpec) RequestDefaults {
+	if spec.MissingRequestsPolicy == "" {
+		return RequestDefaults{}
+	}
+	d := RequestDefaults{
+		Enabled: true,
+		CPU:     resource.MustParse(v1beta1.DefaultContainerCPU),
+		Memory:  resource.MustParse(v1beta1.DefaultContainerMemory),
+	}
+	if dr := spec.DefaultRequests; dr != nil {
+		if dr.CPU != nil {
+			d.CPU = dr.CPU.DeepCopy()
+		}
+		if dr.Memory != nil {
+			d.Memory = dr.Memory.DeepCopy()
+		}
+	}
+	return d
+}
+
+// PodRequests is PodRequests with d assumed for every request a container leaves out.
+func (d RequestDefaults) PodRequests(pod *corev1.Pod) (cpu, memory resource.Quantity) {
+	if !d.Enabled || len(MissingRequests(pod)) == 0 {
+		return PodRequests(pod)
+	}
+	pod = pod.DeepCopy()
+	for _, cs := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range cs {
+			d.fill(&cs[i].Resources)
+		}
+	}
+	for i := range pod.Spec.EphemeralContainers {
+		d.fill(&pod.Spec.EphemeralContainers[i].Resources)
+	}
+	return PodRequests(pod)
+}
+
+func (d RequestDefaults) fill(res *corev1.ResourceRequirements) {
+	if res.Requests == nil {
+		res.Requests = corev1.ResourceList{}
+	}
+	if _, ok := res.Requests[corev1.ResourceCPU]; !ok {
+		res.Requests[corev1.ResourceCPU] = d.CPU
+	}
+	if _, ok := res.Requests[corev1.ResourceMemory]; !ok {
+		res.Requests[corev1.ResourceMemory] = d.Memory
+	}
+}
+
+// MissingRequests returns the names of pod's containers, init and ephemeral containers included,
+// that do not request both CPU and memory.
+func MissingRequests(pod *corev1.Pod) []string {
+	var names []string
+	missing := func(name string, res corev1.ResourceRequirements) {
+		_, hasCPU := res.Requests[corev1.ResourceCPU]
+		_, hasMemory := res.Requests[corev1.ResourceMemory]
+		if !hasCPU || !hasMemory {
+			names = append(names, name)
+		}
+	}
+	for _, cs := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range cs {
+			missing(c.Name, c.Resources)
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		missing(c.Name, c.Resources)
+	}
+	return names
+}
+
+// AddedEphemeralContainers returns a pod holding only the ephemeral containers pod has and
+// oldPod does not, or nil if there are none.
+func AddedEphemeralContainers(oldPod, pod *corev1.Pod) *corev1.Pod {
+	existing := make(map[string]bool, len(oldPod.Spec.EphemeralContainers))
+	for _, c := range oldPod.Spec.EphemeralContainers {
+		existing[c.Name] = true
+	}
+	var added []corev1.EphemeralContainer
+	for _, c := range pod.Spec.EphemeralContainers {
+		if !existing[c.Name] {
+			added = append(added, c)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	return &corev1.Pod{Spec: corev1.PodSpec{EphemeralContainers: added}}
+}
+
+func ephemeralTerminated(pod *corev1.Pod, name string) bool {
+	for _, st := range pod.Status.EphemeralContainerStatuses {
+		if st.Name == name {
+			return st.State.Terminated != nil
+		}
+	}
+	return false
+}
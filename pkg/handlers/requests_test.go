@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func container(cpu, memory string) corev1.Container {
+	return corev1.Container{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}}}
+}
+
+func sidecar(cpu, memory string) corev1.Container {
+	c := container(cpu, memory)
+	always := corev1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+	return c
+}
+
+func TestPodRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []corev1.Container
+		initContainers []corev1.Container
+		overhead       corev1.ResourceList
+		wantCPU        string
+		wantMemory     string
+	}{
+		{
+			name:       "containers are summed",
+			containers: []corev1.Container{container("100m", "64Mi"), container("200m", "128Mi")},
+			wantCPU:    "300m", wantMemory: "192Mi",
+		},
+		{
+			name:           "init container smaller than the sum",
+			containers:     []corev1.Container{container("100m", "64Mi"), container("200m", "128Mi")},
+			initContainers: []corev1.Container{container("250m", "100Mi")},
+			wantCPU:        "300m", wantMemory: "192Mi",
+		},
+		{
+			name:           "largest init container wins per resource",
+			containers:     []corev1.Container{container("100m", "64Mi")},
+			initContainers: []corev1.Container{container("1", "32Mi"), container("50m", "256Mi")},
+			wantCPU:        "1", wantMemory: "256Mi",
+		},
+		{
+			name:           "runtime class overhead is added on top",
+			containers:     []corev1.Container{container("100m", "64Mi")},
+			initContainers: []corev1.Container{container("500m", "32Mi")},
+			overhead:       corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m"), corev1.ResourceMemory: resource.MustParse("120Mi")},
+			wantCPU:        "750m", wantMemory: "184Mi",
+		},
+		{
+			name:           "sidecars run alongside the app containers",
+			containers:     []corev1.Container{container("100m", "64Mi")},
+			initContainers: []corev1.Container{sidecar("50m", "32Mi"), container("120m", "16Mi")},
+			wantCPU:        "170m", wantMemory: "96Mi",
+		},
+		{
+			name:       "init container after a sidecar runs next to it",
+			containers: []corev1.Container{container("100m", "64Mi")},
+			// 50m sidecar + 300m init container beats 150m of app container and sidecar
+			initContainers: []corev1.Container{sidecar("50m", "32Mi"), container("300m", "16Mi")},
+			wantCPU:        "350m", wantMemory: "96Mi",
+		},
+		{
+			name:           "init container before a sidecar does not overlap it",
+			containers:     []corev1.Container{container("100m", "64Mi")},
+			initContainers: []corev1.Container{container("300m", "16Mi"), sidecar("50m", "32Mi")},
+			wantCPU:        "300m", wantMemory: "96Mi",
+		},
+	}
+	for _, tt := range tests {
+		pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: tt.containers, InitContainers: tt.initContainers, Overhead: tt.overhead}}
+		cpu, mem := PodRequests(pod)
+		if cpu.Cmp(resource.MustParse(tt.wantCPU)) != 0 || mem.Cmp(resource.MustParse(tt.wantMemory)) != 0 {
+			t.Errorf("%s: got cpu=%s memory=%s, want cpu=%s memory=%s", tt.name, cpu.String(), mem.String(), tt.wantCPU, tt.wantMemory)
+		}
+	}
+}
+
+func TestRequestDefaults(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}}},
+		{Name: "proxy"},
+	}}}
+	if got := MissingRequests(pod); len(got) != 2 {
+		t.Fatalf("MissingRequests = %v, want both containers", got)
+	}
+
+	d := RequestDefaultsFor(&v1beta1.ResourceQuotaPolicySpec{MissingRequestsPolicy: v1beta1.MissingRequestsAssumeDefaults})
+	cpu, memory := d.PodRequests(pod)
+	// the set 300m request is kept, the other three requests fall back to 100m / 128Mi
+	if cpu.String() != "400m" || memory.String() != "256Mi" {
+		t.Fatalf("got cpu=%s memory=%s, want 400m and 256Mi", cpu.String(), memory.String())
+	}
+	if _, ok := pod.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU]; ok {
+		t.Fatal("PodRequests modified the pod")
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/cache"
+)
+
+// UsageTracker keeps running pod, CPU and memory totals per namespace, updated from pod informer
+// events, so a reconcile reads usage without listing pods. A namespace is tracked from the first
+// time its usage is read; its totals are built for the exclusions and request defaults of that
+// policy and rebuilt from the informer's cache whenever they change. It is safe for concurrent use.
+type UsageTracker struct {
+	// pods is the pod informer's indexer, with the default namespace index.
+	pods cache.Indexer
+
+	mu         sync.Mutex
+	namespaces map[string]*trackedNamespace
+}
+
+// trackedNamespace holds the running totals of one namespace.
+type trackedNamespace struct {
+	exclude  Exclusions
+	defaults RequestDefaults
+
+	pods map[string]trackedPod // by name
+	// terminating are the counted pods with a deletion timestamp, which stop counting once it passes
+	terminating map[string]time.Time
+	count       int
+	cpu, memory resource.Quantity
+}
+
+// trackedPod is what one counted pod adds to the totals.
+type trackedPod struct {
+	cpu, memory resource.Quantity
+}
+
+// Totals are the usage UsageTracker keeps for a namespace.
+type Totals struct {
+	Pods   int
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// NewUsageTracker returns a tracker that builds totals from pods, the pod informer's indexer.
+func NewUsageTracker(pods cache.Indexer) *UsageTracker {
+	return &UsageTracker{pods: pods, namespaces: make(map[string]*trackedNamespace)}
+}
+
+// Update applies an added or changed pod to the totals of its namespace.
+func (t *UsageTracker) Update(pod *corev1.Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ns, ok := t.namespaces[pod.Namespace]; ok {
+		ns.remove(pod.Name)
+		ns.add(pod, time.Now())
+	}
+}
+
+// Delete removes a deleted pod from the totals of its namespace.
+func (t *UsageTracker) Delete(pod *corev1.Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ns, ok := t.namespaces[pod.Namespace]; ok {
+		ns.remove(pod.Name)
+	}
+}
+
+// Forget stops tracking namespace, e.g. once it no longer has a policy.
+func (t *UsageTracker) Forget(namespace string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.namespaces, namespace)
+}
+
+// Usage returns the totals of namespace as policy counts them at now.
+func (t *UsageTracker) Usage(namespace string, policy Policy, now time.Time) (Totals, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ns, ok := t.namespaces[namespace]
+	if !ok || !ns.countsLike(policy) {
+		var err error
+		if ns, err = t.build(namespace, policy, now); err != nil {
+			return Totals{}, err
+		}
+		t.namespaces[namespace] = ns
+	}
+	for name, deadline := range ns.terminating {
+		if !now.Before(deadline) {
+			ns.remove(name)
+		}
+	}
+	return Totals{Pods: ns.count, CPU: ns.cpu.DeepCopy(), Memory: ns.memory.DeepCopy()}, nil
+}
+
+// Audit rebuilds the totals of every tracked namespace from the informer's cache and returns
+// the namespaces whose running totals had drifted from it.
+func (t *UsageTracker) Audit(now time.Time) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var drifted []string
+	for name, ns := range t.namespaces {
+		fresh, err := t.build(name, Policy{Exclude: ns.exclude, Defaults: ns.defaults}, now)
+		if err != nil {
+			return drifted, err
+		}
+		for pod, deadline := range ns.terminating {
+			if !now.Before(deadline) {
+				ns.remove(pod)
+			}
+		}
+		if ns.count != fresh.count || ns.cpu.Cmp(fresh.cpu) != 0 || ns.memory.Cmp(fresh.memory) != 0 {
+			drifted = append(drifted, name)
+		}
+		t.namespaces[name] = fresh
+	}
+	return drifted, nil
+}
+
+// build computes the totals of namespace from the informer's cache.
+func (t *UsageTracker) build(namespace string, policy Policy, now time.Time) (*trackedNamespace, error) {
+	objs, err := t.pods.ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ns := &trackedNamespace{
+		exclude:     policy.Exclude,
+		defaults:    policy.Defaults,
+		pods:        make(map[string]trackedPod, len(objs)),
+		terminating: make(map[string]time.Time),
+	}
+	for _, obj := range objs {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			ns.add(pod, now)
+		}
+	}
+	return ns, nil
+}
+
+// countsLike reports whether the totals were built the way policy counts pods.
+func (ns *trackedNamespace) countsLike(policy Policy) bool {
+	d := policy.Defaults
+	return ns.exclude == policy.Exclude && ns.defaults.Enabled == d.Enabled &&
+		ns.defaults.CPU.Cmp(d.CPU) == 0 && ns.defaults.Memory.Cmp(d.Memory) == 0
+}
+
+// add counts pod, unless it does not count toward usage at now.
+func (ns *trackedNamespace) add(pod *corev1.Pod, now time.Time) {
+	if !CountsTowardUsage(pod, now) || ns.exclude.Excludes(pod) {
+		return
+	}
+	p := trackedPod{}
+	p.cpu, p.memory = ns.defaults.PodRequests(pod)
+	ns.pods[pod.Name] = p
+	if pod.DeletionTimestamp != nil {
+		ns.terminating[pod.Name] = pod.DeletionTimestamp.Time
+	}
+	ns.count++
+	ns.cpu.Add(p.cpu)
+	ns.memory.Add(p.memory)
+}
+
+// remove uncounts the pod named name, if it is counted.
+func (ns *trackedNamespace) remove(name string) {
+	p, ok := ns.pods[name]
+	if !ok {
+		return
+	}
+	delete(ns.pods, name)
+	delete(ns.terminating, name)
+	ns.count--
+	ns.cpu.Sub(p.cpu)
+	ns.memory.Sub(p.memory)
+}
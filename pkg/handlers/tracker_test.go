@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func podRequesting(name, cpu string) *corev1.Pod {
+	pod := runningPod(name, time.Minute)
+	pod.Spec.Containers = []corev1.Container{{
+		Name:      "app",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+	}}
+	return pod
+}
+
+func TestUsageTrackerAppliesEvents(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	add := func(pod *corev1.Pod) {
+		if err := indexer.Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(podRequesting("web-0", "500m"))
+	add(podRequesting("web-1", "500m"))
+	tracker := NewUsageTracker(indexer)
+	now := time.Now()
+
+	usage := func() Totals {
+		t.Helper()
+		totals, err := tracker.Usage("ns1", Policy{}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return totals
+	}
+	if got := usage(); got.Pods != 2 || got.CPU.Cmp(resource.MustParse("1")) != 0 {
+		t.Fatalf("initial totals = %d pods, %s cpu", got.Pods, got.CPU.String())
+	}
+
+	// an added pod, a resized one and a completed one
+	added := podRequesting("web-2", "250m")
+	add(added)
+	tracker.Update(added)
+	resized := podRequesting("web-0", "1")
+	add(resized)
+	tracker.Update(resized)
+	completed := podRequesting("web-1", "500m")
+	completed.Status.Phase = corev1.PodSucceeded
+	add(completed)
+	tracker.Update(completed)
+	if got := usage(); got.Pods != 2 || got.CPU.Cmp(resource.MustParse("1250m")) != 0 {
+		t.Fatalf("totals after updates = %d pods, %s cpu", got.Pods, got.CPU.String())
+	}
+
+	// a terminating pod counts until its deletion timestamp passes
+	terminating := podRequesting("web-2", "250m")
+	terminating.DeletionTimestamp = &metav1.Time{Time: now.Add(time.Second)}
+	add(terminating)
+	tracker.Update(terminating)
+	if got := usage(); got.Pods != 2 {
+		t.Fatalf("terminating pod not counted: %d pods", got.Pods)
+	}
+	now = now.Add(2 * time.Second)
+	if got := usage(); got.Pods != 1 || got.CPU.Cmp(resource.MustParse("1")) != 0 {
+		t.Fatalf("totals after grace period = %d pods, %s cpu", got.Pods, got.CPU.String())
+	}
+
+	// events missed by the tracker are corrected by the audit
+	add(podRequesting("web-3", "2"))
+	drifted, err := tracker.Audit(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifted) != 1 || drifted[0] != "ns1" {
+		t.Fatalf("drifted = %v, want [ns1]", drifted)
+	}
+	if got := usage(); got.Pods != 2 || got.CPU.Cmp(resource.MustParse("3")) != 0 {
+		t.Fatalf("totals after audit = %d pods, %s cpu", got.Pods, got.CPU.String())
+	}
+}
+
+func TestUsageTrackerRebuildsForNewExclusions(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	mirror := runningPod("static-web", time.Minute)
+	mirror.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "hash"}
+	for _, pod := range []*corev1.Pod{runningPod("web-0", time.Minute), mirror} {
+		if err := indexer.Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker := NewUsageTracker(indexer)
+
+	for _, tc := range []struct {
+		exclude Exclusions
+		want    int
+	}{
+		{Exclusions{}, 2},
+		{Exclusions{MirrorPods: true}, 1},
+		{Exclusions{}, 2},
+	} {
+		got, err := tracker.Usage("ns1", Policy{Exclude: tc.exclude}, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Pods != tc.want {
+			t.Fatalf("exclude %+v: %d pods, want %d", tc.exclude, got.Pods, tc.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VerticalPodAutoscalerResource is the resource of the VerticalPodAutoscalers read for policies
+// with usageSource vpaRecommendation.
+var VerticalPodAutoscalerResource = schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+
+// VPAResolver reads the recommendations of the VerticalPodAutoscalers of a namespace. A nil
+// *VPAResolver finds none.
+type VPAResolver struct {
+	Client  kubernetes.Interface
+	Dynamic dynamic.Interface
+}
+
+// verticalPodAutoscaler holds the fields of an autoscaling.k8s.io/v1 VerticalPodAutoscaler
+// read here, so the VPA's Go module isn't needed.
+type verticalPodAutoscaler struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		TargetRef *struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"targetRef"`
+		UpdatePolicy *struct {
+			UpdateMode string `json:"updateMode"`
+		} `json:"updatePolicy"`
+	} `json:"spec"`
+	Status struct {
+		Recommendation *struct {
+			ContainerRecommendations []struct {
+				ContainerName string              `json:"containerName"`
+				Target        corev1.ResourceList `json:"target"`
+			} `json:"containerRecommendations"`
+		} `json:"recommendation"`
+	} `json:"status"`
+}
+
+// Recommendations are the CPU and memory targets VerticalPodAutoscalers recommend for the
+// containers of the pods they select.
+type Recommendations struct {
+	vpas []vpaTargets
+}
+
+type vpaTargets struct {
+	selector   labels.Selector
+	containers map[string]corev1.ResourceList
+}
+
+// Recommendations returns the recommendations of the VPAs of namespace that resize pods, i.e.
+// whose updateMode isn't Off, and that target a Deployment, StatefulSet, DaemonSet or
+// ReplicaSet. A cluster without the VPA CRD has none.
+func (r *VPAResolver) Recommendations(ctx context.Context, namespace string) (*Recommendations, error) {
+	recs := &Recommendations{}
+	if r == nil {
+		return recs, nil
+	}
+	list, err := r.Dynamic.Resource(VerticalPodAutoscalerResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return recs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list verticalpodautoscalers: %w", err)
+	}
+	// several VPAs selecting the same pod is a misconfiguration the VPA itself refuses; the
+	// first by name wins here
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	for _, item := range list.Items {
+		raw, err := json.Marshal(item.Object)
+		if err != nil {
+			return nil, err
+		}
+		var vpa verticalPodAutoscaler
+		if err := json.Unmarshal(raw, &vpa); err != nil {
+			return nil, fmt.Errorf("decode verticalpodautoscaler %s: %w", item.GetName(), err)
+		}
+		ref, rec := vpa.Spec.TargetRef, vpa.Status.Recommendation
+		if ref == nil || rec == nil || len(rec.ContainerRecommendations) == 0 {
+			continue
+		}
+		if p := vpa.Spec.UpdatePolicy; p != nil && p.UpdateMode == "Off" {
+			continue
+		}
+		selector, err := r.targetSelector(ctx, namespace, ref.Kind, ref.Name)
+		if apierrors.IsNotFound(err) || selector == nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolve target of verticalpodautoscaler %s: %w", vpa.Name, err)
+		}
+		targets := vpaTargets{selector: selector, containers: map[string]corev1.ResourceList{}}
+		for _, c := range rec.ContainerRecommendations {
+			targets.containers[c.ContainerName] = c.Target
+		}
+		recs.vpas = append(recs.vpas, targets)
+	}
+	return recs, nil
+}
+
+// targetSelector returns the pod selector of the workload kind/name, or nil for kinds other
+// than Deployment, StatefulSet, DaemonSet and ReplicaSet.
+func (r *VPAResolver) targetSelector(ctx context.Context, namespace, kind, name string) (labels.Selector, error) {
+	var selector *metav1.LabelSelector
+	apps := r.Client.AppsV1()
+	switch kind {
+	case "Deployment":
+		obj, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "StatefulSet":
+		obj, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "DaemonSet":
+		obj, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "ReplicaSet":
+		obj, err := apps.ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	default:
+		return nil, nil
+	}
+	if selector == nil {
+		return nil, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || s.Empty() {
+		// never let a broken or empty selector take over every pod of the namespace
+		return nil, nil
+	}
+	return s, nil
+}
+
+// Apply returns pod with the CPU and memory requests of its containers replaced by the targets
+// recommended for them, or pod itself when no VPA selects it. Init and ephemeral containers,
+// which the VPA doesn't resize, keep their requests.
+func (recs *Recommendations) Apply(pod *corev1.Pod) *corev1.Pod {
+	if recs == nil {
+		return pod
+	}
+	for _, vpa := range recs.vpas {
+		if !vpa.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		out := pod.DeepCopy()
+		for i := range out.Spec.Containers {
+			c := &out.Spec.Containers[i]
+			target, ok := vpa.containers[c.Name]
+			if !ok {
+				continue
+			}
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if q, ok := target[name]; ok {
+					if c.Resources.Requests == nil {
+						c.Resources.Requests = corev1.ResourceList{}
+					}
+					c.Resources.Requests[name] = q
+				}
+			}
+		}
+		return out
+	}
+	return pod
+}
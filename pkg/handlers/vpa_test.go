@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func vpa(name, target, mode string, cpu, memory string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]any{"name": name, "namespace": "ns1"},
+		"spec": map[string]any{
+			"targetRef":    map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+			"updatePolicy": map[string]any{"updateMode": mode},
+		},
+		"status": map[string]any{"recommendation": map[string]any{"containerRecommendations": []any{
+			map[string]any{"containerName": "app", "target": map[string]any{"cpu": cpu, "memory": memory}},
+		}}},
+	}}
+}
+
+func TestComputeUsageRecommended(t *testing.T) {
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}}},
+		}
+	}
+	pod := func(name, app string) *corev1.Pod {
+		p := runningPod(name, time.Minute)
+		p.Labels = map[string]string{"app": app}
+		p.Spec.Containers = []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}}},
+			{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("100m"),
+			}}},
+		}
+		return p
+	}
+
+	client := fake.NewSimpleClientset(
+		deployment("web"), deployment("batch"),
+		pod("web-1", "web"), pod("batch-1", "batch"), pod("other-1", "other"),
+	)
+	scheme := runtime.NewScheme()
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{VerticalPodAutoscalerResource: "VerticalPodAutoscalerList"},
+		vpa("web", "web", "Auto", "250m", "256Mi"),
+		// recommendations that are never applied don't count
+		vpa("batch", "batch", "Off", "50m", "64Mi"),
+	)
+	e := &PodEnforcer{Client: client, VPA: &VPAResolver{Client: client, Dynamic: dyn}}
+	policy := Policy{MaxPods: 10, MaxCPU: resource.MustParse("10"), MaxMemory: resource.MustParse("10Gi"), Recommended: true}
+
+	res, err := e.ComputeUsage(context.Background(), "ns1", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// web-1 counts 250m + 100m for its sidecar, the others 1100m each
+	if res.CurrentCPU != "2550m" || res.CurrentMemory != "2304Mi" {
+		t.Fatalf("usage = %s cpu, %s memory; want 2550m, 2304Mi", res.CurrentCPU, res.CurrentMemory)
+	}
+
+	policy.Recommended = false
+	if res, _ := e.ComputeUsage(context.Background(), "ns1", policy); res.CurrentCPU != "3300m" {
+		t.Fatalf("declared cpu = %s, want 3300m", res.CurrentCPU)
+	}
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkTimeout bounds each readiness check so a hung dependency fails the probe instead of
+// stalling it.
+const checkTimeout = 5 * time.Second
+
+// Check returns nil when the dependency it covers is healthy.
+type Check func(ctx context.Context) error
+
+// NamedCheck is a Check reported under Name.
+type NamedCheck struct {
+	Name  string
+	Check Check
+}
+
+// ChecksHandler runs every check and answers 200 only if all pass, listing each result in the
+// style of the API server's /readyz?verbose. It backs both /healthz and /readyz.
+func ChecksHandler(checks ...NamedCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var out strings.Builder
+		failed := false
+		for _, c := range checks {
+			ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+			err := c.Check(ctx)
+			cancel()
+			if err != nil {
+				failed = true
+				fmt.Fprintf(&out, "[-]%s failed: %v\n", c.Name, err)
+			} else {
+				fmt.Fprintf(&out, "[+]%s ok\n", c.Name)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			out.WriteString("check failed\n")
+		} else {
+			w.WriteHeader(http.StatusOK)
+			out.WriteString("ok\n")
+		}
+		_, _ = w.Write([]byte(out.String()))
+	}
+}
+
+// CertExpiryCheck fails once cert expires within window, or if it is not yet valid.
+func CertExpiryCheck(cert *x509.Certificate, window time.Duration) Check {
+	return func(context.Context) error {
+		now := time.Now()
+		if now.Before(cert.NotBefore) {
+			return fmt.Errorf("certificate not valid until %s", cert.NotBefore.UTC().Format(time.RFC3339))
+		}
+		if left := cert.NotAfter.Sub(now); left < window {
+			return fmt.Errorf("certificate expires at %s, within %s", cert.NotAfter.UTC().Format(time.RFC3339), window)
+		}
+		return nil
+	}
+}
+
+// APIServerCheck fails when the API server cannot be reached with client.
+func APIServerCheck(client kubernetes.Interface) Check {
+	return func(ctx context.Context) error {
+		return client.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+	}
+}
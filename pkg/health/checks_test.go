@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecksHandler(t *testing.T) {
+	ok := NamedCheck{Name: "cache", Check: func(context.Context) error { return nil }}
+	bad := NamedCheck{Name: "apiserver", Check: func(context.Context) error { return errors.New("connection refused") }}
+
+	rec := httptest.NewRecorder()
+	ChecksHandler(ok)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("all checks passing: status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	ChecksHandler(ok, bad)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("one check failing: status = %d, want 503", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "[+]cache ok") || !strings.Contains(body, "[-]apiserver failed: connection refused") {
+		t.Fatalf("unexpected body:\n%s", body)
+	}
+}
+
+func TestCertExpiryCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		wantErr   bool
+	}{
+		{"valid", now.Add(-time.Hour), now.Add(72 * time.Hour), false},
+		{"expires within window", now.Add(-time.Hour), now.Add(time.Hour), true},
+		{"expired", now.Add(-72 * time.Hour), now.Add(-time.Hour), true},
+		{"not yet valid", now.Add(time.Hour), now.Add(72 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		cert := &x509.Certificate{NotBefore: tt.notBefore, NotAfter: tt.notAfter}
+		err := CertExpiryCheck(cert, 24*time.Hour)(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strings"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// CacheDumper returns the policy cache contents for namespace, or for every namespace when it is
+// empty, in a form that encodes to JSON.
+type CacheDumper func(namespace string) any
+
+// RegisterDebug mounts /debug/cache and /debug/config on mux. Callers must present a bearer
+// token that the API server authenticates and authorizes for "get" on the request path, the
+// same check as `kubectl get --raw /debug/cache`.
+func RegisterDebug(mux *http.ServeMux, client kubernetes.Interface, cache CacheDumper) {
+	mux.Handle("/debug/cache", RequireAPIServerAuth(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, cache(r.URL.Query().Get("namespace")))
+	})))
+	mux.Handle("/debug/config", RequireAPIServerAuth(client, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, flagValues(flag.CommandLine))
+	})))
+}
+
+// RequireAPIServerAuth only calls next for requests from a user the API server authenticates
+// and allows to access the non-resource URL being requested, with the verb of the request's
+// method: "get" for a GET, "create" for a POST. The user is the one the bearer token passes a
+// TokenReview as. A client certificate is never taken for a user: the CA that verifies it
+// authenticates the API server calling the webhook, and doesn't vouch for names and groups.
+func RequireAPIServerAuth(client kubernetes.Interface, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "bearer token required", http.StatusUnauthorized)
+			return
+		}
+		tr, err := client.AuthenticationV1().TokenReviews().Create(r.Context(), &authnv1.TokenReview{
+			Spec: authnv1.TokenReviewSpec{Token: token},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			logging.L().WithName("debug").Error(err, "TokenReview failed", "path", r.URL.Path)
+			http.Error(w, "authentication failed", http.StatusInternalServerError)
+			return
+		}
+		if !tr.Status.Authenticated {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		user := tr.Status.User
+
+		extra := make(map[string]authzv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			extra[k] = authzv1.ExtraValue(v)
+		}
+		sar, err := client.AuthorizationV1().SubjectAccessReviews().Create(r.Context(), &authzv1.SubjectAccessReview{
+			Spec: authzv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra,
+				NonResourceAttributes: &authzv1.NonResourceAttributes{
+					Path: r.URL.Path,
+					Verb: nonResourceVerb(r.Method),
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			logging.L().WithName("debug").Error(err, "SubjectAccessReview failed", "path", r.URL.Path, "user", user.Username)
+			http.Error(w, "authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if !sar.Status.Allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		logging.L().WithName("debug").Info("Serving authorized request", "path", r.URL.Path, "user", user.Username)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// nonResourceVerb is the verb the API server authorizes a request with method to a non-resource
+// URL as.
+func nonResourceVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return "get"
+}
+
+// flagValues is the effective value of every flag in fs, defaults included.
+func flagValues(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeAuthClient authenticates only "good-token" as alice and lets alice get allowedPath.
+func fakeAuthClient(allowedPath string) *fake.Clientset {
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("create", "tokenreviews", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		tr := a.(k8stesting.CreateAction).GetObject().(*authnv1.TokenReview).DeepCopy()
+		if tr.Spec.Token == "good-token" {
+			tr.Status.Authenticated = true
+			tr.Status.User = authnv1.UserInfo{Username: "alice", Groups: []string{"system:authenticated"}}
+		}
+		return true, tr, nil
+	})
+	cs.PrependReactor("create", "subjectaccessreviews", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		sar := a.(k8stesting.CreateAction).GetObject().(*authzv1.SubjectAccessReview).DeepCopy()
+		attrs := sar.Spec.NonResourceAttributes
+		sar.Status.Allowed = sar.Spec.User == "alice" && attrs != nil && attrs.Verb == "get" && attrs.Path == allowedPath
+		return true, sar, nil
+	})
+	return cs
+}
+
+func TestRegisterDebugAuth(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterDebug(mux, fakeAuthClient("/debug/cache"), func(ns string) any {
+		return map[string]string{"namespace": ns}
+	})
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"no token", "/debug/cache", "", http.StatusUnauthorized},
+		{"unknown token", "/debug/cache", "bad-token", http.StatusUnauthorized},
+		{"not authorized for path", "/debug/config", "good-token", http.StatusForbidden},
+		{"authorized", "/debug/cache?namespace=ns1", "good-token", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var got map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got["namespace"] != "ns1" {
+				t.Fatalf("cache dump got namespace %q, want ns1", got["namespace"])
+			}
+		})
+	}
+}
+
+func TestRequireAPIServerAuthIgnoresClientCert(t *testing.T) {
+	cs := fakeAuthClient("/invalidate")
+	var reviews int
+	cs.PrependReactor("create", "subjectaccessreviews", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		return false, nil, nil
+	})
+	h := RequireAPIServerAuth(cs, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	// a certificate from the webhook's client CA that claims to be a cluster admin
+	masters := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{
+		Subject: pkix.Name{CommonName: "admin", Organization: []string{"system:masters"}},
+	}}}}
+
+	req := httptest.NewRequest(http.MethodPost, "/invalidate", nil)
+	req.TLS = masters
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("system:masters client certificate: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if reviews != 0 {
+		t.Errorf("client certificate was authorized with %d SubjectAccessReviews", reviews)
+	}
+
+	// a token is still checked as usual, with the verb of the method
+	req = httptest.NewRequest(http.MethodGet, "/invalidate", nil)
+	req.TLS = masters
+	req.Header.Set("Authorization", "Bearer good-token")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET with a token: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/invalidate", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST authorized as get: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -7,7 +7,12 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func NewNamespaceInformer(clientset kubernetes.Interface) informers.SharedInformerFactory {
-	factory := informers.NewSharedInformerFactory(clientset, 30*time.Second)
+// DefaultResync is how often informers replay their cache as update events.
+const DefaultResync = 30 * time.Second
+
+// NewNamespaceInformer returns a factory whose informers resync every resync; zero disables
+// informer resyncs.
+func NewNamespaceInformer(clientset kubernetes.Interface, resync time.Duration) informers.SharedInformerFactory {
+	factory := informers.NewSharedInformerFactory(clientset, resync)
 	return factory
 }
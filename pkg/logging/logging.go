@@ -0,0 +1,82 @@
+// Package logging builds the structured logger shared by every binary. Messages carry their
+// context as key/value pairs (namespace, policy, pod, ...) instead of formatted strings.
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/klog/v2"
+)
+
+// Debug is the logr verbosity used for per-item detail that is only printed at --log-level=debug.
+const Debug = 1
+
+// Options selects the log level and encoding.
+type Options struct {
+	// Level is one of debug, info, warn or error.
+	Level string
+	// Format is text for human readable console output or json for one object per line.
+	Format string
+}
+
+// AddFlags registers the logging flags shared by the controller and the webhook.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Level, "log-level", "info", "minimum level to log: debug, info, warn or error")
+	fs.StringVar(&o.Format, "log-format", "text", "log encoding: text or json")
+}
+
+var (
+	// output is where logs are written; tests swap it out.
+	output io.Writer = os.Stderr
+
+	mu     sync.RWMutex
+	global = newLogger(zapcore.InfoLevel, "text")
+)
+
+// Setup replaces the global logger according to o. klog, and so client-go, is redirected to
+// the same logger so the binary writes a single stream.
+func Setup(o Options) error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(o.Level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: %w", o.Level, err)
+	}
+	if o.Format != "text" && o.Format != "json" {
+		return fmt.Errorf("invalid --log-format %q: must be text or json", o.Format)
+	}
+
+	l := newLogger(level, o.Format)
+	mu.Lock()
+	global = l
+	mu.Unlock()
+	klog.SetLogger(l.WithName("klog"))
+	return nil
+}
+
+// L returns the global logger.
+func L() logr.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return global
+}
+
+func newLogger(level zapcore.Level, format string) logr.Logger {
+	enc := zap.NewProductionEncoderConfig()
+	enc.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	if format == "json" {
+		encoder = zapcore.NewJSONEncoder(enc)
+	} else {
+		enc.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(enc)
+	}
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(output)), level)
+	return zapr.NewLogger(zap.New(core, zap.AddCaller()))
+}
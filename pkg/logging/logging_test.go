@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSetupJSON(t *testing.T) {
+	var buf bytes.Buffer
+	output = &buf
+	if err := Setup(Options{Level: "info", Format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	L().V(Debug).Info("hidden at info level")
+	L().WithName("controller").Info("reconciled", "namespace", "ns1", "policy", "p1")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "reconciled" || line["namespace"] != "ns1" || line["policy"] != "p1" || line["logger"] != "controller" {
+		t.Fatalf("unexpected log line: %v", line)
+	}
+}
+
+func TestSetupRejectsInvalidOptions(t *testing.T) {
+	for _, o := range []Options{
+		{Level: "verbose", Format: "text"},
+		{Level: "info", Format: "xml"},
+	} {
+		if err := Setup(o); err == nil {
+			t.Errorf("Setup(%+v) accepted invalid options", o)
+		}
+	}
+}
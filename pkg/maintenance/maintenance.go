@@ -0,0 +1,177 @@
+// Package maintenance pauses destructive enforcement cluster-wide while a well-known ConfigMap is
+// annotated quota.platform/maintenance=true, e.g. during incident response or an upgrade. The
+// controller then only reports the pods and jobs it would act on, as with -enforcement=dry-run,
+// and the webhook admits what it would deny, with a warning.
+package maintenance
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+)
+
+const (
+	// AnnotationMaintenance turns maintenance mode on while it is "true" on the ConfigMap.
+	AnnotationMaintenance = "quota.platform/maintenance"
+	// AnnotationReason on the ConfigMap says why, for logs and admission warnings.
+	AnnotationReason = "quota.platform/maintenance-reason"
+)
+
+// Config selects the ConfigMap that turns maintenance mode on.
+type Config struct {
+	// ConfigMap is the namespace/name of the ConfigMap; empty disables maintenance mode.
+	ConfigMap string
+}
+
+// AddFlags registers -maintenance-configmap.
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.ConfigMap, "maintenance-configmap", "", "namespace/name of a ConfigMap that pauses pod deletion and job suspension, and turns admission denials into warnings, while annotated "+AnnotationMaintenance+"=true; empty disables maintenance mode")
+}
+
+// Mode tells whether maintenance mode is on, from the ConfigMap it watches. It is safe for
+// concurrent use; a nil Mode is always off.
+type Mode struct {
+	// Cluster names the cluster in metrics and logs, when the controller manages several.
+	Cluster string
+
+	state    atomic.Pointer[state]
+	mu       sync.Mutex
+	handlers []func()
+
+	name         string
+	factory      informers.SharedInformerFactory
+	registration cache.ResourceEventHandlerRegistration
+}
+
+type state struct {
+	active bool
+	reason string
+}
+
+// NewFromConfig returns the Mode watching the ConfigMap of c, or nil when c names none. Start it
+// with Run.
+func NewFromConfig(c Config, client kubernetes.Interface, resync time.Duration) (*Mode, error) {
+	if c.ConfigMap == "" {
+		return nil, nil
+	}
+	namespace, name, ok := strings.Cut(c.ConfigMap, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("-maintenance-configmap %q is not namespace/name", c.ConfigMap)
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(client, resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	m := &Mode{name: name, factory: factory}
+	registration, err := factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { m.set(obj) },
+		UpdateFunc: func(_, newObj interface{}) { m.set(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cm, ok := obj.(*corev1.ConfigMap); ok && cm.Name == m.name {
+				m.set(nil)
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watch maintenance ConfigMap: %w", err)
+	}
+	m.registration = registration
+	return m, nil
+}
+
+// AddHandler calls handler whenever maintenance mode turns on or off. It must be called before
+// Run.
+func (m *Mode) AddHandler(handler func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Run watches the ConfigMap until ctx is done.
+func (m *Mode) Run(ctx context.Context) {
+	m.factory.Start(ctx.Done())
+	<-ctx.Done()
+	m.factory.Shutdown()
+}
+
+// HasSynced reports whether the ConfigMap, if there is one, has been read.
+func (m *Mode) HasSynced() bool {
+	return m.registration.HasSynced()
+}
+
+// Active reports whether maintenance mode is on.
+func (m *Mode) Active() bool {
+	if m == nil {
+		return false
+	}
+	s := m.state.Load()
+	return s != nil && s.active
+}
+
+// Reason returns the reason given on the ConfigMap, if any.
+func (m *Mode) Reason() string {
+	if m == nil {
+		return ""
+	}
+	if s := m.state.Load(); s != nil {
+		return s.reason
+	}
+	return ""
+}
+
+// set reads the state from obj, the ConfigMap, or nil once it is deleted.
+func (m *Mode) set(obj interface{}) {
+	next := &state{}
+	if cm, ok := obj.(*corev1.ConfigMap); ok {
+		if cm.Name != m.name {
+			return
+		}
+		next.active = cm.Annotations[AnnotationMaintenance] == "true"
+		next.reason = cm.Annotations[AnnotationReason]
+	}
+	prev := m.state.Swap(next)
+	if prev == nil {
+		prev = &state{}
+	}
+	if prev.active == next.active && (!next.active || prev.reason == next.reason) {
+		return
+	}
+	logger := logging.L().WithName("maintenance")
+	if m.Cluster != "" {
+		logger = logger.WithValues("cluster", m.Cluster)
+	}
+	if next.active {
+		metrics.MaintenanceActive.WithLabelValues(m.Cluster).Set(1)
+		logger.Info("Maintenance mode on, pausing enforcement", "reason", next.reason)
+	} else {
+		metrics.MaintenanceActive.WithLabelValues(m.Cluster).Set(0)
+		logger.Info("Maintenance mode off, resuming enforcement")
+	}
+	if prev.active == next.active {
+		return
+	}
+	m.mu.Lock()
+	handlers := m.handlers
+	m.mu.Unlock()
+	for _, h := range handlers {
+		h()
+	}
+}
@@ -0,0 +1,78 @@
+package maintenance
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMode(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "rqe-maintenance"}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "other", Annotations: map[string]string{
+		AnnotationMaintenance: "true",
+	}}}
+	client := fakeclient.NewSimpleClientset(cm, other)
+
+	m, err := NewFromConfig(Config{ConfigMap: "kube-system/rqe-maintenance"}, client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var toggled atomic.Int32
+	m.AddHandler(func() { toggled.Add(1) })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), m.HasSynced) {
+		t.Fatal("maintenance mode did not sync")
+	}
+	if m.Active() {
+		t.Fatal("maintenance mode is on without the annotation")
+	}
+
+	cm.Annotations = map[string]string{AnnotationMaintenance: "true", AnnotationReason: "upgrading to 1.31"}
+	if _, err := client.CoreV1().ConfigMaps("kube-system").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "maintenance mode on", func() bool { return m.Active() })
+	if m.Reason() != "upgrading to 1.31" {
+		t.Errorf("Reason() = %q", m.Reason())
+	}
+
+	if err := client.CoreV1().ConfigMaps("kube-system").Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "maintenance mode off", func() bool { return !m.Active() })
+	if got := toggled.Load(); got != 2 {
+		t.Errorf("handlers called %d times, want 2", got)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	if m, err := NewFromConfig(Config{}, nil, 0); m != nil || err != nil {
+		t.Fatalf("expected no mode without a ConfigMap, got %v, %v", m, err)
+	}
+	if _, err := NewFromConfig(Config{ConfigMap: "rqe-maintenance"}, nil, 0); err == nil {
+		t.Fatal("expected an error for a ConfigMap without a namespace")
+	}
+	var m *Mode
+	if m.Active() || m.Reason() != "" {
+		t.Fatal("a nil mode is off")
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,9 @@
+package maintenance
+
+// ForTesting returns a Mode that is on or off as given and watches nothing, for tests of the
+// controller and the webhook.
+func ForTesting(active bool, reason string) *Mode {
+	m := &Mode{}
+	m.state.Store(&state{active: active, reason: reason})
+	return m
+}
@@ -0,0 +1,102 @@
+// Package manager runs the long-running parts of the controller and the webhook under a
+// controller-runtime manager. With leader election, only the controller replica holding the
+// Lease enforces policies; the others serve metrics and probes and wait to take over.
+package manager
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// LeaseName is the Lease the controller's replicas compete for.
+const LeaseName = "rqe-controller"
+
+// Config holds the leader election flags.
+type Config struct {
+	// LeaderElect runs what needs the Lease only in the replica that holds it.
+	LeaderElect bool
+	// LeaseNamespace holds the Lease; empty means the namespace the pod runs in.
+	LeaseNamespace string
+}
+
+// AddFlags registers -leader-elect and -leader-elect-namespace.
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.LeaderElect, "leader-elect", false, "enforce only in the replica holding the "+LeaseName+" Lease, so several replicas can run for failover")
+	fs.StringVar(&c.LeaseNamespace, "leader-elect-namespace", "", "namespace of the Lease; empty uses the namespace the controller runs in, which has to be set out of cluster")
+}
+
+// New returns a manager for config. Its own metrics, health probe and pprof servers are off:
+// the binaries serve those themselves, under the names dashboards and alerts refer to.
+func New(config *rest.Config, c Config) (manager.Manager, error) {
+	ctrllog.SetLogger(logging.L())
+	return manager.New(config, manager.Options{
+		Logger:                  logging.L().WithName("manager"),
+		Metrics:                 metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress:  "0",
+		LeaderElection:          c.LeaderElect,
+		LeaderElectionID:        LeaseName,
+		LeaderElectionNamespace: c.LeaseNamespace,
+		// the binaries exit once Start returns, so the next leader needn't wait out the Lease
+		LeaderElectionReleaseOnCancel: true,
+		// workers finish the sync they are in, however long it takes, as before the manager
+		GracefulShutdownTimeout: ptr.To(time.Duration(-1)),
+	})
+}
+
+// Runnable is what Leader, Always and Server return, for the manager's Add.
+type Runnable = manager.Runnable
+
+// Leader runs fn in the replica holding the Lease, or in every replica without leader election.
+// The manager stops, and Start returns, once fn returns an error.
+func Leader(fn func(ctx context.Context) error) Runnable {
+	return runnable{fn: fn, leader: true}
+}
+
+// Always runs fn in every replica, whether or not it holds the Lease.
+func Always(fn func(ctx context.Context) error) Runnable {
+	return runnable{fn: fn}
+}
+
+type runnable struct {
+	fn     func(ctx context.Context) error
+	leader bool
+}
+
+func (r runnable) Start(ctx context.Context) error { return r.fn(ctx) }
+
+func (r runnable) NeedLeaderElection() bool { return r.leader }
+
+// Server runs srv in every replica until the manager stops, then closes it. serve is
+// srv.ListenAndServe or srv.ListenAndServeTLS with its arguments.
+func Server(srv *http.Server, serve func() error) Runnable {
+	return Always(func(ctx context.Context) error {
+		stop := context.AfterFunc(ctx, func() { srv.Close() })
+		defer stop()
+		if err := serve(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Standby reports whether mgr is waiting for the Lease. Without leader election, it stops
+// waiting as soon as it starts.
+func Standby(mgr manager.Manager) bool {
+	select {
+	case <-mgr.Elected():
+		return false
+	default:
+		return true
+	}
+}
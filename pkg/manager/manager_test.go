@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+)
+
+// unreachable is an API server nothing listens on, so a Lease can never be acquired from it.
+var unreachable = &rest.Config{Host: "http://127.0.0.1:1", Timeout: 100 * time.Millisecond}
+
+func TestRunnablesNeedLeaderElection(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	if !Leader(noop).(runnable).NeedLeaderElection() {
+		t.Error("Leader runnables should need the Lease")
+	}
+	if Always(noop).(runnable).NeedLeaderElection() {
+		t.Error("Always runnables should run without the Lease")
+	}
+}
+
+func TestWithoutLeaderElectionEverythingRuns(t *testing.T) {
+	mgr, err := New(unreachable, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan string, 2)
+	for name, r := range map[string]func(func(context.Context) error) Runnable{"leader": Leader, "always": Always} {
+		if err := mgr.Add(r(func(context.Context) error { ran <- name; return nil })); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- mgr.Start(ctx) }()
+	for range 2 {
+		select {
+		case <-ran:
+		case <-time.After(5 * time.Second):
+			t.Fatal("runnables didn't run")
+		}
+	}
+	select {
+	case <-mgr.Elected():
+	case <-time.After(5 * time.Second):
+	}
+	if Standby(mgr) {
+		t.Error("a started manager without leader election should not be on standby")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
+
+func TestWithoutLeaseOnlyAlwaysRuns(t *testing.T) {
+	mgr, err := New(unreachable, Config{LeaderElect: true, LeaseNamespace: "kube-system"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader, always := make(chan struct{}), make(chan struct{})
+	if err := mgr.Add(Leader(func(context.Context) error { close(leader); return nil })); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Add(Always(func(context.Context) error { close(always); return nil })); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- mgr.Start(ctx) }()
+	select {
+	case <-always:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Always runnable didn't run without the Lease")
+	}
+	select {
+	case <-leader:
+		t.Fatal("Leader runnable ran without the Lease")
+	case <-time.After(300 * time.Millisecond):
+	}
+	if !Standby(mgr) {
+		t.Error("want standby while the Lease can't be acquired")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
+
+func TestServerClosesOnStop(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Server(srv, func() error { return srv.Serve(lis) }).Start(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("want nil once the manager stops, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not closed once the manager stopped")
+	}
+}
+
+func TestServerReturnsListenErrors(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	// the address is taken
+	srv := &http.Server{Addr: lis.Addr().String()}
+	if err := Server(srv, srv.ListenAndServe).Start(context.Background()); err == nil {
+		t.Fatal("want the listen error")
+	}
+}
+
+// controller-runtime registers its own API client metrics with client-go, which only takes the
+// first registration; ours has to stay the one the binaries export.
+func TestClientMetricsStayOurs(t *testing.T) {
+	metrics.InitClientMetrics()
+	clientmetrics.RequestResult.Increment(context.Background(), "200", "GET", "probe.example")
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != metrics.NameRestClientRequests {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "host" && l.GetValue() == "probe.example" {
+					return
+				}
+			}
+		}
+	}
+	t.Fatalf("%s didn't count the request", metrics.NameRestClientRequests)
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Names of the -config file metrics alerts refer to.
+const (
+	NameConfigLastReloadSuccessful = "rqe_config_last_reload_successful"
+	NameConfigRestartRequired      = "rqe_config_restart_required"
+)
+
+// Metrics of the -config file, shared by the controller and the webhook.
+var (
+	ConfigLastReloadSuccessful = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: NameConfigLastReloadSuccessful,
+		Help: "Whether the last reload of the -config file was applied in full (1) or failed (0)",
+	})
+
+	ConfigLastReloadSuccessTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rqe_config_last_reload_success_timestamp_seconds",
+		Help: "Unix time of the last successful load or reload of the -config file",
+	})
+
+	ConfigRestartRequired = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: NameConfigRestartRequired,
+		Help: "Whether the -config file changes settings that only take effect after a restart (1) or not (0)",
+	})
+)
+
+// InitConfigMetrics registers the -config file metrics, for binaries started with one.
+func InitConfigMetrics() {
+	Registry.MustRegister(ConfigLastReloadSuccessful, ConfigLastReloadSuccessTimestamp, ConfigRestartRequired)
+}
@@ -0,0 +1,19 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NameMaintenanceActive is the maintenance mode metric alerts refer to.
+const NameMaintenanceActive = "rqe_maintenance_active"
+
+// MaintenanceActive is 1 while maintenance mode pauses enforcement in a cluster, shared by the
+// controller and the webhook.
+var MaintenanceActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: NameMaintenanceActive,
+	Help: "Whether maintenance mode pauses enforcement (1) or not (0)",
+}, []string{"cluster"})
+
+// InitMaintenanceMetrics registers the maintenance mode metric, for binaries started with
+// -maintenance-configmap.
+func InitMaintenanceMetrics() {
+	Registry.MustRegister(MaintenanceActive)
+}
@@ -4,39 +4,149 @@ import (
 	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// Names of the controller's metrics, for dashboards and alerts generated from code. Every
+// per-namespace metric also has a cluster label, which is empty unless the controller manages
+// several clusters.
+const (
+	NameReconcileTotal          = "resource_quota_enforcer_reconcile_total"
+	NameReconcileErrors         = "resource_quota_enforcer_reconcile_errors_total"
+	NameEnforcementActions      = "resource_quota_enforcer_actions_total"
+	NameSoftLimitExceeded       = "resource_quota_enforcer_soft_limit_exceeded_total"
+	NameQuotaExhaustionSeconds  = "resource_quota_enforcer_quota_exhaustion_seconds"
+	NameEstimatedHourlyCost     = "resource_quota_enforcer_estimated_hourly_cost"
+	NameUsageDriftCorrections   = "resource_quota_enforcer_usage_drift_corrections_total"
+	NameNamespacePods           = "rqe_namespace_pods"
+	NameNamespaceCPURequests    = "rqe_namespace_cpu_requests"
+	NameNamespaceMemoryRequests = "rqe_namespace_memory_requests"
+	NameNamespacePodsLimit      = "rqe_namespace_pods_limit"
+	NameNamespaceCPULimit       = "rqe_namespace_cpu_limit"
+	NameNamespaceMemoryLimit    = "rqe_namespace_memory_limit"
+	NameQuotaUtilization        = "rqe_quota_utilization"
+)
+
 var (
 	ReconcileTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "resource_quota_enforcer_reconcile_total",
+			Name: NameReconcileTotal,
 			Help: "Number of reconcile attempts per resource",
 		},
-		[]string{"resource", "namespace"},
+		[]string{"resource", "namespace", "cluster"},
 	)
 
 	ReconcileErrors = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "resource_quota_enforcer_reconcile_errors_total",
+			Name: NameReconcileErrors,
 			Help: "Number of reconcile errors per resource",
 		},
-		[]string{"resource", "namespace"},
+		[]string{"resource", "namespace", "cluster"},
 	)
 
 	EnforcementActions = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "resource_quota_enforcer_actions_total",
+			Name: NameEnforcementActions,
 			Help: "Number of enforcement actions taken by policy",
 		},
-		[]string{"action", "namespace"},
+		[]string{"action", "namespace", "cluster"},
+	)
+
+	SoftLimitExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: NameSoftLimitExceeded,
+			Help: "Number of times usage crossed a soft limit",
+		},
+		[]string{"resource", "namespace", "cluster"},
+	)
+
+	QuotaExhaustionSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: NameQuotaExhaustionSeconds,
+			Help: "Estimated seconds until usage reaches the limit at the current growth rate",
+		},
+		[]string{"resource", "namespace", "cluster"},
+	)
+
+	EstimatedHourlyCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: NameEstimatedHourlyCost,
+			Help: "Estimated cost per hour of the resources requested in a namespace, from the policy pricing",
+		},
+		[]string{"resource", "namespace", "currency", "cluster"},
+	)
+
+	UsageDriftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: NameUsageDriftCorrections,
+			Help: "Number of times the running usage totals of a namespace disagreed with the pod cache and were rebuilt",
+		},
+		[]string{"namespace", "cluster"},
 	)
+
+	NamespacePods           = newNamespaceGauge(NameNamespacePods, "Pods counted against the namespace policy")
+	NamespaceCPURequests    = newNamespaceGauge(NameNamespaceCPURequests, "CPU cores requested by pods in the namespace")
+	NamespaceMemoryRequests = newNamespaceGauge(NameNamespaceMemoryRequests, "Memory bytes requested by pods in the namespace")
+	NamespacePodsLimit      = newNamespaceGauge(NameNamespacePodsLimit, "Pod limit in force for the namespace")
+	NamespaceCPULimit       = newNamespaceGauge(NameNamespaceCPULimit, "CPU core limit in force for the namespace")
+	NamespaceMemoryLimit    = newNamespaceGauge(NameNamespaceMemoryLimit, "Memory byte limit in force for the namespace")
+)
+
+// QuotaUtilization is used/limit per resource, 1.0 meaning the namespace is at its limit.
+var QuotaUtilization = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: NameQuotaUtilization,
+		Help: "Fraction of the limit in use after the last reconcile (0.0-1.0+)",
+	},
+	[]string{"namespace", "resource", "cluster"},
 )
 
+// namespaceGauges are the per-namespace usage and limit gauges, cleared together when a
+// namespace loses its policy.
+var namespaceGauges = []*prometheus.GaugeVec{
+	NamespacePods, NamespaceCPURequests, NamespaceMemoryRequests,
+	NamespacePodsLimit, NamespaceCPULimit, NamespaceMemoryLimit,
+	QuotaUtilization,
+}
+
+func newNamespaceGauge(name, help string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, []string{"namespace", "policy", "cluster"})
+}
+
+// ForgetNamespace drops every per-namespace series for ns of cluster.
+func ForgetNamespace(cluster, ns string) {
+	match := prometheus.Labels{"namespace": ns, "cluster": cluster}
+	for _, g := range namespaceGauges {
+		g.DeletePartialMatch(match)
+	}
+	QuotaExhaustionSeconds.DeletePartialMatch(match)
+	EstimatedHourlyCost.DeletePartialMatch(match)
+	UsageDriftCorrections.DeletePartialMatch(match)
+}
+
+// Registry holds every metric a binary exports. Each binary serves it once, via Handler, on
+// the address given by its --metrics-addr flag.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// InitMetrics registers the controller metrics, the API client ones included.
 func InitMetrics() {
-	prometheus.MustRegister(ReconcileTotal, ReconcileErrors, EnforcementActions)
-	go func() {
-		http.Handle("/metrics", promhttp.Handler())
-		http.ListenAndServe(":2112", nil)
-	}()
+	Registry.MustRegister(ReconcileTotal, ReconcileErrors, EnforcementActions, SoftLimitExceeded, QuotaExhaustionSeconds, EstimatedHourlyCost, UsageDriftCorrections)
+	for _, g := range namespaceGauges {
+		Registry.MustRegister(g)
+	}
+	Registry.MustRegister(workqueueCollectors...)
+	InitClientMetrics()
 }
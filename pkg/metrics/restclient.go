@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// Names of the API client metrics dashboards and alerts refer to.
+const (
+	NameRestClientRateLimiterDuration = "rest_client_rate_limiter_duration_seconds"
+	NameRestClientRequests            = "rest_client_requests_total"
+)
+
+// API client metrics, labeled by host so the clusters of a multi-cluster controller can be told
+// apart. The names match the ones Kubernetes components export.
+var (
+	restClientRateLimiterDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    NameRestClientRateLimiterDuration,
+		Help:    "How long requests to the API server waited for the client-side rate limiter (-kube-api-qps, -kube-api-burst)",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"verb", "host"})
+
+	restClientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rest_client_request_duration_seconds",
+		Help:    "Latency of requests to the API server, rate limiting excluded",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"verb", "host"})
+
+	restClientRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: NameRestClientRequests,
+		Help: "Requests to the API server, by status code, method and host",
+	}, []string{"code", "method", "host"})
+)
+
+// restClientCollectors are registered by InitMetrics and InitClientMetrics.
+var restClientCollectors = []prometheus.Collector{
+	restClientRateLimiterDuration, restClientRequestDuration, restClientRequests,
+}
+
+func init() {
+	// client-go only takes the first registration, made before any client is built
+	clientmetrics.Register(clientmetrics.RegisterOpts{
+		RateLimiterLatency: latencyMetric{restClientRateLimiterDuration},
+		RequestLatency:     latencyMetric{restClientRequestDuration},
+		RequestResult:      resultMetric{restClientRequests},
+	})
+}
+
+// InitClientMetrics registers the API client metrics, for binaries that don't call InitMetrics.
+func InitClientMetrics() {
+	Registry.MustRegister(restClientCollectors...)
+}
+
+type latencyMetric struct{ vec *prometheus.HistogramVec }
+
+func (m latencyMetric) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	m.vec.WithLabelValues(verb, u.Host).Observe(latency.Seconds())
+}
+
+type resultMetric struct{ vec *prometheus.CounterVec }
+
+func (m resultMetric) Increment(_ context.Context, code, method, host string) {
+	m.vec.WithLabelValues(code, method, host).Inc()
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Names of the workqueue metrics dashboards and alerts refer to.
+const (
+	NameWorkqueueDepth        = "workqueue_depth"
+	NameWorkqueueRetries      = "workqueue_retries_total"
+	NameWorkqueueWorkDuration = "workqueue_work_duration_seconds"
+)
+
+// Workqueue metrics, labeled by queue name. The names match the ones client-go documents so
+// existing dashboards work unchanged.
+var (
+	workqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: NameWorkqueueDepth,
+		Help: "Current depth of the workqueue",
+	}, []string{"name"})
+
+	workqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "workqueue_adds_total",
+		Help: "Total number of adds handled by the workqueue",
+	}, []string{"name"})
+
+	workqueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "workqueue_queue_duration_seconds",
+		Help:    "How long an item stays in the workqueue before being processed",
+		Buckets: prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"name"})
+
+	workqueueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    NameWorkqueueWorkDuration,
+		Help:    "How long processing an item from the workqueue takes",
+		Buckets: prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"name"})
+
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workqueue_unfinished_work_seconds",
+		Help: "Seconds of work in progress that hasn't been observed by work_duration yet; large values indicate stuck workers",
+	}, []string{"name"})
+
+	workqueueLongestRunning = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workqueue_longest_running_processor_seconds",
+		Help: "How long the longest running processor for the workqueue has been running",
+	}, []string{"name"})
+
+	workqueueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: NameWorkqueueRetries,
+		Help: "Total number of retries handled by the workqueue",
+	}, []string{"name"})
+)
+
+// workqueueCollectors are registered by InitMetrics.
+var workqueueCollectors = []prometheus.Collector{
+	workqueueDepth, workqueueAdds, workqueueLatency, workqueueWorkDuration,
+	workqueueUnfinishedWork, workqueueLongestRunning, workqueueRetries,
+}
+
+func init() {
+	// the provider has to be in place before any named queue is created
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunning.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
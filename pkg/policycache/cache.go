@@ -0,0 +1,262 @@
+// Package policycache holds the caches the controller and the webhook share: an informer-backed
+// view of the ResourceQuotaPolicies, and a Store for what each binary derives from them.
+package policycache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	clientset "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	informers "github.com/sri2103/resource-quota-enforcer/pkg/generated/informers/externalversions"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Interface is what consumers of the policy cache need; Informer implements it.
+type Interface interface {
+	Get(namespace string) (*platformv1beta1.ResourceQuotaPolicy, bool)
+	Invalidate(namespace string)
+	Run(stopCh <-chan struct{})
+	WaitForReady(timeout time.Duration) error
+}
+
+// Informer uses generated informers + listers for fast CRD lookups. It is safe for concurrent use.
+type Informer struct {
+	client   clientset.Interface
+	factory  informers.SharedInformerFactory
+	informer cache.SharedIndexInformer
+	lister   listers.ResourceQuotaPolicyLister
+
+	// Merge combines the policies of namespaces with more than one; it must match the
+	// controller's -policy-merge. The zero value is MergeHighestPriorityOnly.
+	Merge platformv1beta1.MergeStrategy
+	// Source holds policies read from files or ConfigMaps, for namespaces without a
+	// ResourceQuotaPolicy of their own; nil adds none. The caller runs it.
+	Source *policysource.Source
+	// Capacity resolves the clusterShare of policies merged here, as Merge needs their limits;
+	// nil leaves them to their maxPods, maxCPU and maxMemory. The caller runs it.
+	Capacity *capacity.Tracker
+	// WithoutCRD leaves the informer stopped, for clusters where the ResourceQuotaPolicy CRD isn't
+	// installed; policies then only come from Source.
+	WithoutCRD bool
+
+	readyMtx sync.RWMutex
+	ready    bool
+}
+
+// NewInformer creates a new informer-backed cache.
+func NewInformer(client clientset.Interface, resync time.Duration) *Informer {
+	factory := informers.NewSharedInformerFactory(client, resync)
+	inf := factory.Platform().V1beta1().ResourceQuotaPolicies().Informer()
+	lister := factory.Platform().V1beta1().ResourceQuotaPolicies().Lister()
+
+	return &Informer{
+		client:   client,
+		factory:  factory,
+		informer: inf,
+		lister:   lister,
+	}
+}
+
+// Run starts the informer factory and marks cache as ready after sync.
+func (pc *Informer) Run(stopCh <-chan struct{}) {
+	var synced []cache.InformerSynced
+	if pc.WithoutCRD {
+		logging.L().WithName("cache").Info("ResourceQuotaPolicy CRD not installed, serving policies from files and ConfigMaps only")
+	} else {
+		logging.L().WithName("cache").Info("Starting informer factory")
+		pc.factory.Start(stopCh)
+		synced = append(synced, pc.informer.HasSynced)
+	}
+	if pc.Source != nil {
+		synced = append(synced, pc.Source.HasSynced)
+	}
+	if pc.Capacity != nil {
+		synced = append(synced, pc.Capacity.HasSynced)
+	}
+
+	if ok := cache.WaitForCacheSync(stopCh, synced...); !ok {
+		logging.L().WithName("cache").Error(nil, "Cache sync failed")
+		return
+	}
+
+	pc.readyMtx.Lock()
+	pc.ready = true
+	pc.readyMtx.Unlock()
+	logging.L().WithName("cache").Info("Cache synced successfully")
+
+	<-stopCh
+}
+
+// Get retrieves the policy for a namespace.
+func (pc *Informer) Get(namespace string) (*platformv1beta1.ResourceQuotaPolicy, bool) {
+	pc.readyMtx.RLock()
+	if !pc.ready {
+		pc.readyMtx.RUnlock()
+		return nil, false
+	}
+	pc.readyMtx.RUnlock()
+
+	policies, err := pc.list(namespace)
+	if err != nil || len(policies) == 0 {
+		return nil, false
+	}
+	// the same policy the controller enforces
+	platformv1beta1.SortByPriority(policies)
+	return pc.merge(unsuspended(policies), time.Now()), true
+}
+
+// unsuspended returns the policies, sorted by SortByPriority, that are not suspended, or only the
+// first when all are: admission is then checked against a suspended policy, which admits
+// everything, rather than against none, which may deny by default.
+func unsuspended(policies []*platformv1beta1.ResourceQuotaPolicy) []*platformv1beta1.ResourceQuotaPolicy {
+	var out []*platformv1beta1.ResourceQuotaPolicy
+	for _, p := range policies {
+		if !p.Spec.Suspend {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return policies[:1]
+	}
+	return out
+}
+
+// list returns the policies of namespace, or of every namespace when it is empty: the custom
+// resources, and for namespaces without any, those of Source.
+func (pc *Informer) list(namespace string) ([]*platformv1beta1.ResourceQuotaPolicy, error) {
+	var policies []*platformv1beta1.ResourceQuotaPolicy
+	var err error
+	if namespace == "" {
+		policies, err = pc.lister.List(labels.Everything())
+	} else {
+		policies, err = pc.lister.ResourceQuotaPolicies(namespace).List(labels.Everything())
+	}
+	if err != nil || pc.Source == nil {
+		return policies, err
+	}
+	withResources := make(map[string]bool, len(policies))
+	for _, p := range policies {
+		withResources[p.Namespace] = true
+	}
+	for _, p := range pc.Source.List(namespace) {
+		if !withResources[p.Namespace] {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+// merges reports whether the policies of a namespace are merged rather than the first one
+// enforced alone.
+func (pc *Informer) merges() bool {
+	return pc.Merge == platformv1beta1.MergeStrictestWins || pc.Merge == platformv1beta1.MergeSumAllowances
+}
+
+// merge returns the policy enforced in a namespace with policies, sorted by SortByPriority. When
+// several are merged it is a copy of the first named after all of them, e.g. "a+b", with their
+// specs in force at now merged into one without scheduled changes.
+func (pc *Informer) merge(policies []*platformv1beta1.ResourceQuotaPolicy, now time.Time) *platformv1beta1.ResourceQuotaPolicy {
+	if len(policies) == 1 || !pc.merges() {
+		return policies[0]
+	}
+	specs := make([]platformv1beta1.ResourceQuotaPolicySpec, len(policies))
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		specs[i], _ = p.Spec.EffectiveAt(now)
+		specs[i].ResolveClusterShare(pc.Capacity.Allocatable())
+		names[i] = p.Name
+	}
+	out := policies[0].DeepCopy()
+	out.Name = strings.Join(names, "+")
+	out.Spec = platformv1beta1.Merge(pc.Merge, specs)
+	return out
+}
+
+// Entry describes what is enforced in a namespace.
+type Entry struct {
+	// Policy is the name of the policy admission decisions are made against.
+	Policy string `json:"policy"`
+	// Effective is its spec with scheduled changes in force right now applied and its
+	// clusterShare resolved.
+	Effective platformv1beta1.ResourceQuotaPolicySpec `json:"effective"`
+	// Ignored lists other policies in the namespace, which are not enforced because
+	// they are not merged or are suspended.
+	Ignored []string `json:"ignored,omitempty"`
+}
+
+// Dump returns the cached policies per namespace, limited to namespace when it is not empty.
+func (pc *Informer) Dump(namespace string) map[string]Entry {
+	out := make(map[string]Entry)
+	policies, err := pc.list(namespace)
+	if err != nil {
+		return out
+	}
+
+	platformv1beta1.SortByPriority(policies)
+	byNamespace := make(map[string][]*platformv1beta1.ResourceQuotaPolicy)
+	for _, p := range policies {
+		byNamespace[p.Namespace] = append(byNamespace[p.Namespace], p)
+	}
+	now := time.Now()
+	for ns, policies := range byNamespace {
+		// admission is checked against a single, possibly merged, policy per namespace, as Get does
+		merged := unsuspended(policies)
+		if !pc.merges() {
+			merged = merged[:1]
+		}
+		enforced := pc.merge(merged, now)
+		entry := Entry{Policy: enforced.Name}
+		entry.Effective, _ = enforced.Spec.EffectiveAt(now)
+		entry.Effective.ResolveClusterShare(pc.Capacity.Allocatable())
+		for _, p := range policies {
+			if !slices.Contains(merged, p) {
+				entry.Ignored = append(entry.Ignored, p.Name)
+			}
+		}
+		out[ns] = entry
+	}
+	return out
+}
+
+// Check fails until the informer has synced; it is used as the webhook's readiness check.
+func (pc *Informer) Check(context.Context) error {
+	pc.readyMtx.RLock()
+	defer pc.readyMtx.RUnlock()
+	if !pc.ready {
+		return fmt.Errorf("policy cache not synced")
+	}
+	return nil
+}
+
+// Invalidate is a no-op (informers keep the cache up-to-date automatically).
+func (pc *Informer) Invalidate(namespace string) {}
+
+// WaitForReady waits until informer cache is synced or times out.
+func (pc *Informer) WaitForReady(timeout time.Duration) error {
+	t := time.After(timeout)
+	tick := time.Tick(200 * time.Millisecond)
+	for {
+		select {
+		case <-t:
+			return fmt.Errorf("timeout waiting for cache ready")
+		case <-tick:
+			pc.readyMtx.RLock()
+			r := pc.ready
+			pc.readyMtx.RUnlock()
+			if r {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package policycache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	fake "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policysource"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestInformerPolicyCache_GetAndReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	// Use fake dynamic client
+	fake.AddToScheme(scheme)
+	// gen := fake.NewSimpleDynamicClient(scheme)
+	gen := fake.NewSimpleClientset()
+
+	cache := NewInformer(gen, 10*time.Second)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go cache.Run(stopCh)
+
+	// wait for readiness (timeout short since fake client is immediate)
+	if err := cache.WaitForReady(2 * time.Second); err != nil {
+		t.Fatalf("cache not ready: %v", err)
+	}
+
+	obj := v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ps1",
+			Namespace: "ns1",
+		},
+		Spec: v1beta1.ResourceQuotaPolicySpec{
+			MaxPods:   2,
+			MaxCPU:    resource.NewMilliQuantity(500, resource.DecimalSI),
+			MaxMemory: resource.NewQuantity(256*1024*1024, resource.BinarySI),
+		},
+	}
+
+	_, err := gen.PlatformV1beta1().ResourceQuotaPolicies("ns1").Create(context.TODO(), &obj, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create policy failed: %v", err)
+	}
+
+	// wait briefly for informer to pick up
+	time.Sleep(200 * time.Millisecond)
+
+	policy, found := cache.Get("ns1")
+	if !found {
+		t.Fatalf("expected policy found in cache")
+	}
+	if policy == nil || policy.Spec.MaxPods == 0 {
+		t.Fatalf("spec missing maxPods")
+	}
+}
+
+func TestInformerPolicyCache_Source(t *testing.T) {
+	dir := t.TempDir()
+	for _, ns := range []string{"ns1", "ns2"} {
+		manifest := "apiVersion: platform.example.com/v1beta1\nkind: ResourceQuotaPolicy\nmetadata:\n  namespace: " + ns + "\nspec:\n  maxPods: 7\n"
+		if err := os.WriteFile(filepath.Join(dir, ns+".yaml"), []byte(manifest), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen := fake.NewSimpleClientset(&v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ps1", Namespace: "ns1"},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 2},
+	})
+
+	cache := NewInformer(gen, 10*time.Second)
+	cache.Source = policysource.New()
+	cache.Source.AddDir(dir, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cache.Source.Run(ctx)
+	go cache.Run(ctx.Done())
+	if err := cache.WaitForReady(2 * time.Second); err != nil {
+		t.Fatalf("cache not ready: %v", err)
+	}
+
+	// the custom resource takes precedence
+	if policy, found := cache.Get("ns1"); !found || policy.Name != "ps1" {
+		t.Fatalf("Get(ns1) = %v, %v, want ps1", policy, found)
+	}
+	if policy, found := cache.Get("ns2"); !found || policy.Name != "ns2" || policy.Spec.MaxPods != 7 {
+		t.Fatalf("Get(ns2) = %v, %v, want ns2 from the directory", policy, found)
+	}
+	if dump := cache.Dump(""); len(dump) != 2 || dump["ns1"].Policy != "ps1" || dump["ns2"].Policy != "ns2" {
+		t.Fatalf("Dump = %+v", dump)
+	}
+}
+
+func TestInformerPolicyCache_Suspended(t *testing.T) {
+	gen := fake.NewSimpleClientset(
+		&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "high", Namespace: "ns1"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 2, Priority: 10, Suspend: true},
+		},
+		&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "low", Namespace: "ns1"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 5},
+		},
+		&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "paused", Namespace: "ns2"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 1, Suspend: true},
+		},
+	)
+
+	cache := NewInformer(gen, 10*time.Second)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go cache.Run(stopCh)
+	if err := cache.WaitForReady(2 * time.Second); err != nil {
+		t.Fatalf("cache not ready: %v", err)
+	}
+
+	// a suspended policy gives way to the next one
+	if policy, found := cache.Get("ns1"); !found || policy.Name != "low" {
+		t.Fatalf("Get(ns1) = %v, %v, want low", policy, found)
+	}
+	// with every policy suspended, the suspended one is returned so admission allows
+	if policy, found := cache.Get("ns2"); !found || policy.Name != "paused" || !policy.Spec.Suspend {
+		t.Fatalf("Get(ns2) = %v, %v, want the suspended policy", policy, found)
+	}
+	if entry := cache.Dump("ns1")["ns1"]; entry.Policy != "low" || len(entry.Ignored) != 1 || entry.Ignored[0] != "high" {
+		t.Fatalf("Dump(ns1) = %+v", entry)
+	}
+}
@@ -0,0 +1,53 @@
+package policycache
+
+import "sync"
+
+// Store maps namespaces to T, e.g. the parsed policy the controller enforces in each. It is safe
+// for concurrent use, and the zero value is an empty store.
+type Store[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+// NewStore returns an empty store.
+func NewStore[T any]() *Store[T] {
+	return &Store[T]{items: make(map[string]T)}
+}
+
+// Get returns the value of namespace.
+func (s *Store[T]) Get(namespace string) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.items[namespace]
+	return v, ok
+}
+
+// Set replaces the value of namespace.
+func (s *Store[T]) Set(namespace string, v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.items == nil {
+		s.items = make(map[string]T)
+	}
+	s.items[namespace] = v
+}
+
+// Delete removes namespace.
+func (s *Store[T]) Delete(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, namespace)
+}
+
+// Snapshot returns a copy of the store, limited to namespace when it is not empty.
+func (s *Store[T]) Snapshot(namespace string) map[string]T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]T)
+	for ns, v := range s.items {
+		if namespace == "" || ns == namespace {
+			out[ns] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+// Package policyfile reads ResourceQuotaPolicy manifests, for policies that apply without a
+// custom resource in the cluster.
+package policyfile
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// DefaultName names a policy whose manifest leaves metadata.name out.
+const DefaultName = "cluster-default"
+
+// Load reads the v1beta1 ResourceQuotaPolicy manifest at path, YAML or JSON, and applies the
+// same defaults the API server would. Unknown fields are an error, so a typo doesn't silently
+// leave a limit unset.
+func Load(path string) (*v1beta1.ResourceQuotaPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := Parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Name == "" {
+		policy.Name = DefaultName
+	}
+	return policy, nil
+}
+
+// Parse is Load for a manifest read from source, which errors are prefixed with. It leaves an
+// omitted metadata.name empty.
+func Parse(data []byte, source string) (*v1beta1.ResourceQuotaPolicy, error) {
+	var policy v1beta1.ResourceQuotaPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if gv := v1beta1.SchemeGroupVersion.String(); policy.APIVersion != gv || policy.Kind != "ResourceQuotaPolicy" {
+		return nil, fmt.Errorf("%s: want a %s ResourceQuotaPolicy, got %s %s", source, gv, policy.APIVersion, policy.Kind)
+	}
+	v1beta1.SetObjectDefaults_ResourceQuotaPolicy(&policy)
+	return &policy, nil
+}
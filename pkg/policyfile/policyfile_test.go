@@ -0,0 +1,45 @@
+package policyfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	policy, err := Load(write(`
+apiVersion: platform.example.com/v1beta1
+kind: ResourceQuotaPolicy
+spec:
+  maxPods: 20
+  maxCPU: "4"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Name != DefaultName || policy.Spec.MaxPods != 20 || policy.Spec.MaxCPU.String() != "4" {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+	// omitted limits get the API server's defaults
+	if policy.Spec.MaxMemory == nil || policy.Spec.MaxMemory.String() != "2Gi" {
+		t.Fatalf("maxMemory = %v, want the 2Gi default", policy.Spec.MaxMemory)
+	}
+
+	for content, want := range map[string]string{
+		"apiVersion: platform.example.com/v1beta1\nkind: ResourceQuotaPolicy\nspec:\n  maxPod: 20\n": "unknown field",
+		"apiVersion: platform.example.com/v1alpha1\nkind: ResourceQuotaPolicy\n":                     "want a platform.example.com/v1beta1 ResourceQuotaPolicy",
+	} {
+		if _, err := Load(write(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package policyreport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// ClusterReportName is the ClusterPolicyReport holding the state of every ResourceQuotaPolicy.
+const ClusterReportName = "resource-quota-enforcer"
+
+// ClusterReporter keeps the ClusterPolicyReport ClusterReportName in line with the status the
+// controller writes to each ResourceQuotaPolicy.
+type ClusterReporter struct {
+	Client   dynamic.Interface
+	Policies listers.ResourceQuotaPolicyLister
+	// HasSynced reports whether Policies holds every policy yet; nil assumes it does.
+	HasSynced cache.InformerSynced
+}
+
+// Run syncs the report every interval until ctx is done, starting once Policies has synced.
+func (r *ClusterReporter) Run(ctx context.Context, interval time.Duration) {
+	logger := logging.L().WithName("policyreport")
+	if r.HasSynced != nil && !cache.WaitForCacheSync(ctx.Done(), r.HasSynced) {
+		return
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Sync(ctx); err != nil {
+			logger.Error(err, "Failed to write cluster policy report", "name", ClusterReportName)
+		}
+	}, interval)
+}
+
+// Sync writes the report once.
+func (r *ClusterReporter) Sync(ctx context.Context) error {
+	policies, err := r.Policies.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return write(ctx, r.Client.Resource(ClusterPolicyReports), "ClusterPolicyReport", "", ClusterReportName, func(current []Result) []Result {
+		return policyResults(policies, current, now)
+	})
+}
+
+// policyResults has one result per policy: fail while it is violated, warn while usage is above
+// a soft limit and pass otherwise. A result with the outcome and message it has in previous keeps
+// its timestamp there, so the timestamp is when the policy entered its current state.
+func policyResults(policies []*v1beta1.ResourceQuotaPolicy, previous []Result, now time.Time) []Result {
+	since := make(map[string]Timestamp, len(previous))
+	for _, r := range previous {
+		since[stateKey(r)] = r.Timestamp
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Namespace != policies[j].Namespace {
+			return policies[i].Namespace < policies[j].Namespace
+		}
+		return policies[i].Name < policies[j].Name
+	})
+	results := make([]Result, 0, len(policies))
+	for _, p := range policies {
+		r := Result{
+			Source:    Source,
+			Policy:    p.Name,
+			Category:  category,
+			Result:    ResultPass,
+			Message:   "usage is within the policy's limits",
+			Resources: []ObjectReference{{APIVersion: "v1", Kind: "Namespace", Name: p.Namespace}},
+			Properties: map[string]string{
+				"namespace": p.Namespace,
+				"pods":      fmt.Sprint(p.Status.CurrentPods),
+				"cpu":       p.Status.CPUUsage,
+				"memory":    p.Status.MemoryUsage,
+			},
+		}
+		switch {
+		case p.Status.Violation:
+			r.Result, r.Severity, r.Message = ResultFail, "high", p.Status.Message
+			// messages start with the violated resource, e.g. "cpu:1500m>max:1"
+			r.Rule, _, _ = strings.Cut(p.Status.Message, ":")
+		case meta.IsStatusConditionTrue(p.Status.Conditions, v1beta1.ConditionWarning):
+			warning := meta.FindStatusCondition(p.Status.Conditions, v1beta1.ConditionWarning)
+			r.Result, r.Severity, r.Message = ResultWarn, "medium", warning.Message
+		}
+		r.Timestamp = timestamp(now)
+		if ts, ok := since[stateKey(r)]; ok {
+			r.Timestamp = ts
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// stateKey identifies the policy and state a cluster report result is about.
+func stateKey(r Result) string {
+	var ns string
+	if len(r.Resources) > 0 {
+		ns = r.Resources[0].Name
+	}
+	return ns + "/" + r.Policy + "/" + r.Result + "/" + r.Message
+}
@@ -0,0 +1,63 @@
+package policyreport
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+)
+
+func TestClusterReporterSync(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	policy := func(ns string, status v1beta1.ResourceQuotaPolicyStatus) *v1beta1.ResourceQuotaPolicy {
+		return &v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "quota"}, Status: status}
+	}
+	warning := v1beta1.ResourceQuotaPolicyStatus{}
+	meta.SetStatusCondition(&warning.Conditions, metav1.Condition{Type: v1beta1.ConditionWarning, Status: metav1.ConditionTrue, Reason: "SoftLimitExceeded", Message: "above soft limit: cpu"})
+	violating := policy("ns2", v1beta1.ResourceQuotaPolicyStatus{Violation: true, Message: "cpu:1500m>max:1"})
+	for _, p := range []*v1beta1.ResourceQuotaPolicy{policy("ns1", v1beta1.ResourceQuotaPolicyStatus{}), violating, policy("ns3", warning)} {
+		if err := indexer.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := newDynamicClient()
+	r := &ClusterReporter{Client: client, Policies: listers.NewResourceQuotaPolicyLister(indexer)}
+
+	if err := r.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	report := getReport(t, client, ClusterPolicyReports, "", ClusterReportName)
+	if report.Summary != (Summary{Pass: 1, Fail: 1, Warn: 1}) {
+		t.Fatalf("summary = %+v", report.Summary)
+	}
+	failed := report.Results[1]
+	if failed.Resources[0].Name != "ns2" || failed.Result != ResultFail || failed.Rule != "cpu" {
+		t.Fatalf("unexpected result for the violated policy: %+v", failed)
+	}
+
+	// a policy that stays violated keeps the time it was first reported
+	if err := r.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := getReport(t, client, ClusterPolicyReports, "", ClusterReportName).Results[1].Timestamp; got != failed.Timestamp {
+		t.Fatalf("timestamp changed from %+v to %+v", failed.Timestamp, got)
+	}
+
+	// and one that recovers passes
+	recovered := violating.DeepCopy()
+	recovered.Status = v1beta1.ResourceQuotaPolicyStatus{}
+	if err := indexer.Update(recovered); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := getReport(t, client, ClusterPolicyReports, "", ClusterReportName).Summary; got != (Summary{Pass: 2, Warn: 1}) {
+		t.Fatalf("summary after recovery = %+v", got)
+	}
+}
@@ -0,0 +1,126 @@
+package policyreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+)
+
+// NamespaceReportName is the PolicyReport in each namespace listing its recently denied and
+// evicted pods.
+const NamespaceReportName = "resource-quota-enforcer"
+
+// MaxPodResults is how many pods the report of a namespace lists, the most recent ones.
+const MaxPodResults = 50
+
+// Exporter is an audit.Exporter adding every denied and evicted pod to the PolicyReport of its
+// namespace. The webhook and the controller can both run one: each merges its results into
+// those the report already holds.
+type Exporter struct {
+	Client dynamic.Interface
+	// Cluster is the cluster Client writes to; events of other clusters are skipped.
+	Cluster string
+}
+
+// NewExporter returns an exporter writing reports with client.
+func NewExporter(client dynamic.Interface) *Exporter {
+	return &Exporter{Client: client}
+}
+
+func (e *Exporter) Name() string { return "policyreport" }
+
+func (e *Exporter) Export(ctx context.Context, events []audit.Event) error {
+	byNamespace := map[string][]Result{}
+	for _, ev := range events {
+		if ev.Cluster != e.Cluster {
+			continue
+		}
+		byNamespace[ev.Namespace] = append(byNamespace[ev.Namespace], podResult(ev))
+	}
+	namespaces := make([]string, 0, len(byNamespace))
+	for ns := range byNamespace {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	var errs []error
+	for _, ns := range namespaces {
+		added := byNamespace[ns]
+		err := write(ctx, e.Client.Resource(PolicyReports).Namespace(ns), "PolicyReport", ns, NamespaceReportName, func(current []Result) []Result {
+			return mergePodResults(current, added)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("write policy report in %s: %w", ns, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// podResult is the report result of the decision ev records.
+func podResult(ev audit.Event) Result {
+	r := Result{
+		Source:    Source,
+		Policy:    ev.Policy,
+		Category:  category,
+		Result:    ResultFail,
+		Message:   ev.Reason,
+		Timestamp: timestamp(ev.Timestamp),
+		Resources: []ObjectReference{{APIVersion: "v1", Kind: "Pod", Namespace: ev.Namespace, Name: ev.Pod}},
+		Properties: map[string]string{
+			"decision": string(ev.Decision),
+			"pods":     fmt.Sprint(ev.Usage.Pods),
+			"cpu":      ev.Usage.CPU,
+			"memory":   ev.Usage.Memory,
+		},
+	}
+	if r.Policy == "" {
+		// denied for want of a policy under -default-action=deny
+		r.Policy = "default-action"
+	}
+	switch ev.Decision {
+	case audit.DecisionDenied:
+		r.Rule, r.Severity = "admission", "medium"
+	case audit.DecisionEvicted:
+		r.Rule, r.Severity = "enforcement", "high"
+	case audit.DecisionWouldEvict:
+		r.Rule, r.Severity, r.Result = "enforcement", "high", ResultWarn
+	}
+	return r
+}
+
+// mergePodResults adds added to current, newest first, keeping MaxPodResults of them. A result
+// already in current, e.g. from a batch exported again after a failure, is not added twice.
+func mergePodResults(current, added []Result) []Result {
+	seen := make(map[string]bool, len(current)+len(added))
+	merged := make([]Result, 0, len(current)+len(added))
+	for _, r := range append(append([]Result{}, current...), added...) {
+		key := podKey(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, r)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i].Timestamp, merged[j].Timestamp
+		return a.Seconds > b.Seconds || a.Seconds == b.Seconds && a.Nanos > b.Nanos
+	})
+	if len(merged) > MaxPodResults {
+		merged = merged[:MaxPodResults]
+	}
+	return merged
+}
+
+// podKey identifies the decision a namespace report result records.
+func podKey(r Result) string {
+	var pod string
+	if len(r.Resources) > 0 {
+		pod = r.Resources[0].Name
+	}
+	return fmt.Sprintf("%s/%s/%s/%d.%d", pod, r.Policy, r.Rule, r.Timestamp.Seconds, r.Timestamp.Nanos)
+}
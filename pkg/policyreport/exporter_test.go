@@ -0,0 +1,104 @@
+package policyreport
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+)
+
+func newDynamicClient() *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		PolicyReports:        "PolicyReportList",
+		ClusterPolicyReports: "ClusterPolicyReportList",
+	})
+}
+
+func getReport(t *testing.T, client *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource, namespace, name string) *Report {
+	t.Helper()
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &Report{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestExporterMergesPodsIntoNamespaceReport(t *testing.T) {
+	client := newDynamicClient()
+	e := NewExporter(client)
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := func(i int, decision audit.Decision) audit.Event {
+		return audit.Event{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Namespace: "ns1",
+			Pod:       fmt.Sprintf("web-%d", i),
+			Policy:    "quota",
+			Reason:    "maxPods exceeded: 4 > 3",
+			Decision:  decision,
+		}
+	}
+
+	first := []audit.Event{event(0, audit.DecisionDenied), event(1, audit.DecisionEvicted)}
+	if err := e.Export(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	// a batch exported again after a failure must not list its pods twice
+	if err := e.Export(context.Background(), append(first, event(2, audit.DecisionWouldEvict))); err != nil {
+		t.Fatal(err)
+	}
+
+	report := getReport(t, client, PolicyReports, "ns1", NamespaceReportName)
+	if len(report.Results) != 3 || report.Summary.Fail != 2 || report.Summary.Warn != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := report.Results[0]; got.Resources[0].Name != "web-2" || got.Rule != "enforcement" || got.Result != ResultWarn {
+		t.Fatalf("newest result = %+v, want the dry run eviction of web-2", got)
+	}
+	if got := report.Results[2]; got.Resources[0].Name != "web-0" || got.Rule != "admission" || got.Source != Source {
+		t.Fatalf("oldest result = %+v, want the denial of web-0", got)
+	}
+
+	// only the most recent pods are kept
+	var many []audit.Event
+	for i := range MaxPodResults + 5 {
+		many = append(many, event(10+i, audit.DecisionDenied))
+	}
+	if err := e.Export(context.Background(), many); err != nil {
+		t.Fatal(err)
+	}
+	report = getReport(t, client, PolicyReports, "ns1", NamespaceReportName)
+	if len(report.Results) != MaxPodResults || report.Results[0].Resources[0].Name != fmt.Sprintf("web-%d", 10+MaxPodResults+4) {
+		t.Fatalf("expected the newest %d results, got %d starting with %+v", MaxPodResults, len(report.Results), report.Results[0])
+	}
+}
+
+func TestExporterSkipsOtherClusters(t *testing.T) {
+	client := newDynamicClient()
+	e := &Exporter{Client: client, Cluster: "eu-west"}
+	events := []audit.Event{
+		{Cluster: "eu-west", Namespace: "ns1", Pod: "web-0", Decision: audit.DecisionEvicted},
+		{Cluster: "us-east", Namespace: "ns1", Pod: "web-1", Decision: audit.DecisionEvicted},
+		{Cluster: "us-east", Namespace: "ns2", Pod: "web-2", Decision: audit.DecisionEvicted},
+	}
+	if err := e.Export(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	report := getReport(t, client, PolicyReports, "ns1", NamespaceReportName)
+	if len(report.Results) != 1 || report.Results[0].Resources[0].Name != "web-0" {
+		t.Fatalf("expected only web-0 of eu-west, got %+v", report.Results)
+	}
+	if _, err := client.Resource(PolicyReports).Namespace("ns2").Get(context.Background(), NamespaceReportName, metav1.GetOptions{}); err == nil {
+		t.Fatal("report written for a namespace of another cluster")
+	}
+}
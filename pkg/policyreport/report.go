@@ -0,0 +1,156 @@
+// Package policyreport publishes the enforcer's findings as wgpolicyk8s.io PolicyReport and
+// ClusterPolicyReport objects, which policy dashboards such as Policy Reporter already read.
+package policyreport
+
+import (
+	"context"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// The report resources, installed by Kyverno, Policy Reporter or the wg-policy-prototypes CRDs.
+var (
+	PolicyReports        = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	ClusterPolicyReports = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}
+)
+
+// Source names the enforcer in every result, so dashboards can filter on it.
+const Source = "resource-quota-enforcer"
+
+// category groups the results in dashboards.
+const category = "Resource Quota"
+
+// Result values.
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+	ResultWarn = "warn"
+)
+
+// managedBy labels the reports the enforcer writes.
+var managedBy = map[string]string{"app.kubernetes.io/managed-by": Source}
+
+// Report is the content shared by PolicyReport and ClusterPolicyReport.
+type Report struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Summary Summary  `json:"summary"`
+	Results []Result `json:"results,omitempty"`
+}
+
+// Summary counts the results of a report by outcome.
+type Summary struct {
+	Pass  int `json:"pass"`
+	Fail  int `json:"fail"`
+	Warn  int `json:"warn"`
+	Error int `json:"error"`
+	Skip  int `json:"skip"`
+}
+
+// Result is one finding about one or more resources.
+type Result struct {
+	Source     string            `json:"source"`
+	Policy     string            `json:"policy"`
+	Rule       string            `json:"rule,omitempty"`
+	Category   string            `json:"category,omitempty"`
+	Severity   string            `json:"severity,omitempty"`
+	Result     string            `json:"result"`
+	Message    string            `json:"message,omitempty"`
+	Timestamp  Timestamp         `json:"timestamp"`
+	Resources  []ObjectReference `json:"resources,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Timestamp is the protobuf style time of a result.
+type Timestamp struct {
+	Seconds int64 `json:"seconds"`
+	Nanos   int32 `json:"nanos"`
+}
+
+// ObjectReference names a resource a result is about.
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	UID        string `json:"uid,omitempty"`
+}
+
+func timestamp(t time.Time) Timestamp {
+	return Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// summarize counts results.
+func summarize(results []Result) Summary {
+	var s Summary
+	for _, r := range results {
+		switch r.Result {
+		case ResultPass:
+			s.Pass++
+		case ResultFail:
+			s.Fail++
+		case ResultWarn:
+			s.Warn++
+		case "error":
+			s.Error++
+		case "skip":
+			s.Skip++
+		}
+	}
+	return s
+}
+
+// write creates or updates the report name of kind in client, in namespace unless it is a
+// ClusterPolicyReport, with the results update returns for the current ones. Nothing is written when they don't change. Conflicts with a concurrent
+// writer are retried on a fresh copy, so update must only depend on what it is given.
+func write(ctx context.Context, client dynamic.ResourceInterface, kind, namespace, name string, update func(current []Result) []Result) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		found := err == nil
+		report := &Report{}
+		if found {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, report); err != nil {
+				return err
+			}
+		}
+		results := update(report.Results)
+		if found && apiequality.Semantic.DeepEqual(results, report.Results) {
+			return nil
+		}
+
+		report.APIVersion = PolicyReports.GroupVersion().String()
+		report.Kind = kind
+		report.Namespace, report.Name = namespace, name
+		if report.Labels == nil {
+			report.Labels = map[string]string{}
+		}
+		for k, v := range managedBy {
+			report.Labels[k] = v
+		}
+		report.Results = results
+		report.Summary = summarize(results)
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(report)
+		if err != nil {
+			return err
+		}
+		u := &unstructured.Unstructured{Object: content}
+		if !found {
+			_, err = client.Create(ctx, u, metav1.CreateOptions{})
+			return err
+		}
+		_, err = client.Update(ctx, u, metav1.UpdateOptions{})
+		return err
+	})
+}
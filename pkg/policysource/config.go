@@ -0,0 +1,42 @@
+package policysource
+
+import (
+	"flag"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Config selects where policies are read from besides the custom resources.
+type Config struct {
+	// Dir is a directory of policy manifests.
+	Dir string
+	// Interval is how often Dir is read again.
+	Interval time.Duration
+	// ConfigMapNamespace is the namespace of the ConfigMaps labeled ConfigMapLabel=true.
+	ConfigMapNamespace string
+}
+
+// AddFlags registers the policy source flags shared by the controller and the webhook.
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Dir, "policy-dir", "", "directory of ResourceQuotaPolicy manifests enforced in namespaces without a policy custom resource (disabled when empty)")
+	fs.DurationVar(&c.Interval, "policy-dir-interval", DefaultInterval, "how often -policy-dir is read again")
+	fs.StringVar(&c.ConfigMapNamespace, "policy-configmap-namespace", "", "namespace whose ConfigMaps labeled "+ConfigMapLabel+"=true hold ResourceQuotaPolicy manifests (disabled when empty)")
+}
+
+// NewFromConfig builds the Source described by c. It returns nil when nothing is configured.
+func NewFromConfig(c Config, client kubernetes.Interface, resync time.Duration) (*Source, error) {
+	if c.Dir == "" && c.ConfigMapNamespace == "" {
+		return nil, nil
+	}
+	s := New()
+	if c.Dir != "" {
+		s.AddDir(c.Dir, c.Interval)
+	}
+	if c.ConfigMapNamespace != "" {
+		if err := s.AddConfigMaps(client, c.ConfigMapNamespace, resync); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,100 @@
+package policysource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// ConfigMapLabel marks the ConfigMaps AddConfigMaps reads policies from; its value must be
+// "true".
+const ConfigMapLabel = "quota.platform/policy"
+
+// AddConfigMaps loads a policy from every .yaml, .yml and .json key of the ConfigMaps in
+// namespace labeled ConfigMapLabel=true, and watches them for changes. The policies name their
+// own namespaces, so whoever may write ConfigMaps in namespace sets the quota of every namespace:
+// pick one that tenants can't write to, such as the controller's own.
+func (s *Source) AddConfigMaps(client kubernetes.Interface, namespace string, resync time.Duration) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = ConfigMapLabel + "=true"
+		}))
+	l := &configMapLoader{factory: factory}
+	registration, err := factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { l.load(s, obj) },
+		UpdateFunc: func(_, newObj interface{}) { l.load(s, newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cm, ok := obj.(*corev1.ConfigMap); ok {
+				s.replace(configMapGroup(cm), nil)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("watch policy ConfigMaps: %w", err)
+	}
+	l.registration = registration
+	s.loaders = append(s.loaders, l)
+	return nil
+}
+
+type configMapLoader struct {
+	factory      informers.SharedInformerFactory
+	registration cache.ResourceEventHandlerRegistration
+}
+
+func (l *configMapLoader) run(ctx context.Context, _ *Source) {
+	l.factory.Start(ctx.Done())
+	<-ctx.Done()
+	l.factory.Shutdown()
+}
+
+// hasSynced waits for the handler, not just the informer, so every ConfigMap has been loaded.
+func (l *configMapLoader) hasSynced() bool {
+	return l.registration.HasSynced()
+}
+
+func configMapGroup(cm *corev1.ConfigMap) string {
+	return fmt.Sprintf("configmap %s/%s", cm.Namespace, cm.Name)
+}
+
+// load reads the policies of a ConfigMap into s. A key that can't be parsed keeps the policy it
+// last held, so a bad edit doesn't lift a namespace's limits.
+func (l *configMapLoader) load(s *Source, obj interface{}) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	group := configMapGroup(cm)
+	logger := logging.L().WithName("policysource").WithValues("configMap", cm.Namespace+"/"+cm.Name)
+	previous := s.group(group)
+	policies := make(map[string]*v1beta1.ResourceQuotaPolicy)
+	for key, data := range cm.Data {
+		if !isManifest(key) {
+			continue
+		}
+		origin := group + " key " + key
+		policy, err := parse([]byte(data), origin, key)
+		if err != nil {
+			logger.Error(err, "Failed to load policy, keeping the previous version", "key", key)
+			if p, ok := previous[origin]; ok {
+				policies[origin] = p
+			}
+			continue
+		}
+		policies[origin] = policy
+	}
+	s.replace(group, policies)
+}
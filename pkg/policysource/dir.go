@@ -0,0 +1,81 @@
+package policysource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// DefaultInterval is how often a directory is read again by default.
+const DefaultInterval = 30 * time.Second
+
+// AddDir loads a policy from every .yaml, .yml and .json file in dir, and reads dir again every
+// interval. Mounted ConfigMaps and Secrets work too, as their files are read through the
+// symlinks the kubelet swaps on updates.
+func (s *Source) AddDir(dir string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	s.loaders = append(s.loaders, &dirLoader{dir: dir, interval: interval})
+}
+
+type dirLoader struct {
+	dir      string
+	interval time.Duration
+	synced   atomic.Bool
+}
+
+func (l *dirLoader) run(ctx context.Context, s *Source) {
+	wait.UntilWithContext(ctx, func(context.Context) {
+		l.load(s)
+		l.synced.Store(true)
+	}, l.interval)
+}
+
+func (l *dirLoader) hasSynced() bool { return l.synced.Load() }
+
+// load reads the policies of the directory into s. A file that can't be read or parsed keeps the
+// policy it last held, so a bad edit doesn't lift a namespace's limits.
+func (l *dirLoader) load(s *Source) {
+	logger := logging.L().WithName("policysource").WithValues("dir", l.dir)
+	group := "dir " + l.dir
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		logger.Error(err, "Failed to read policy directory, keeping its policies")
+		return
+	}
+
+	previous := s.group(group)
+	policies := make(map[string]*v1beta1.ResourceQuotaPolicy)
+	for _, entry := range entries {
+		path := filepath.Join(l.dir, entry.Name())
+		if !isManifest(entry.Name()) {
+			continue
+		}
+		// the kubelet mounts ConfigMap keys as symlinks, which os.ReadDir doesn't follow
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		origin := "file " + path
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var policy *v1beta1.ResourceQuotaPolicy
+			if policy, err = parse(data, origin, entry.Name()); err == nil {
+				policies[origin] = policy
+				continue
+			}
+		}
+		logger.Error(err, "Failed to load policy, keeping the previous version", "file", path)
+		if p, ok := previous[origin]; ok {
+			policies[origin] = p
+		}
+	}
+	s.replace(group, policies)
+}
@@ -0,0 +1,168 @@
+// Package policysource loads ResourceQuotaPolicies from outside the API server's custom
+// resources: manifests in a mounted directory or in labeled ConfigMaps. It lets clusters that
+// can't install the CRD, or haven't yet, still get policies, which are reloaded as they change.
+package policysource
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policyfile"
+)
+
+// AnnotationOrigin is set on every loaded policy to where it was read from, e.g.
+// "file /etc/rqe/policies/team-a.yaml" or "configmap rqe-system/policies key team-a.yaml".
+const AnnotationOrigin = "quota.platform/policy-origin"
+
+// loader keeps a group of a Source's policies up to date.
+type loader interface {
+	run(ctx context.Context, s *Source)
+	hasSynced() bool
+}
+
+// Source holds the policies its loaders read. It is safe for concurrent use; the zero value has
+// no loaders and no policies.
+type Source struct {
+	mu       sync.RWMutex
+	groups   map[string]map[string]*v1beta1.ResourceQuotaPolicy // group → origin → policy
+	handlers []func(namespace string)
+	loaders  []loader
+}
+
+// New returns an empty source. Add loaders with AddDir and AddConfigMaps, then start them with
+// Run.
+func New() *Source {
+	return &Source{groups: make(map[string]map[string]*v1beta1.ResourceQuotaPolicy)}
+}
+
+// AddHandler calls handler with the namespace of every policy that is added, changed or removed.
+// It must be called before Run.
+func (s *Source) AddHandler(handler func(namespace string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Run starts the loaders and blocks until ctx is done.
+func (s *Source) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, l := range s.loaders {
+		wg.Add(1)
+		go func(l loader) {
+			defer wg.Done()
+			l.run(ctx, s)
+		}(l)
+	}
+	wg.Wait()
+}
+
+// HasSynced reports whether every loader has read its policies once.
+func (s *Source) HasSynced() bool {
+	for _, l := range s.loaders {
+		if !l.hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// List returns the policies of namespace, or of every namespace when it is empty, ordered by
+// origin. They are shared with the source and must not be modified.
+func (s *Source) List(namespace string) []*v1beta1.ResourceQuotaPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []*v1beta1.ResourceQuotaPolicy
+	for _, group := range s.groups {
+		for _, p := range group {
+			if namespace == "" || p.Namespace == namespace {
+				out = append(out, p)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Annotations[AnnotationOrigin] < out[j].Annotations[AnnotationOrigin]
+	})
+	return out
+}
+
+// group returns the policies of group by origin.
+func (s *Source) group(group string) map[string]*v1beta1.ResourceQuotaPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.groups[group]
+}
+
+// replace sets the policies of group, keyed by origin, and notifies the handlers of the
+// namespaces whose policies changed.
+func (s *Source) replace(group string, policies map[string]*v1beta1.ResourceQuotaPolicy) {
+	s.mu.Lock()
+	old := s.groups[group]
+	changed := sets.New[string]()
+	for origin, p := range policies {
+		if o, ok := old[origin]; !ok || !equality.Semantic.DeepEqual(o, p) {
+			changed.Insert(p.Namespace)
+			if ok {
+				changed.Insert(o.Namespace)
+			}
+		}
+	}
+	for origin, o := range old {
+		if _, ok := policies[origin]; !ok {
+			changed.Insert(o.Namespace)
+		}
+	}
+	if len(policies) == 0 {
+		delete(s.groups, group)
+	} else {
+		if s.groups == nil {
+			s.groups = make(map[string]map[string]*v1beta1.ResourceQuotaPolicy)
+		}
+		s.groups[group] = policies
+	}
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	for _, ns := range sets.List(changed) {
+		for _, handler := range handlers {
+			handler(ns)
+		}
+	}
+}
+
+// isManifest reports whether name, a file or ConfigMap key, holds a manifest.
+func isManifest(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return !strings.HasPrefix(name, ".")
+	}
+	return false
+}
+
+// parse reads the policy manifest in data, read from origin. A manifest without a name is named
+// after the file or key it was read from, without the extension. Unlike custom resources, policies
+// have no namespace to default to, so metadata.namespace is required.
+func parse(data []byte, origin, key string) (*v1beta1.ResourceQuotaPolicy, error) {
+	policy, err := policyfile.Parse(data, origin)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Namespace == "" {
+		return nil, fmt.Errorf("%s: metadata.namespace is required", origin)
+	}
+	if policy.Name == "" {
+		policy.Name = strings.TrimSuffix(key, filepath.Ext(key))
+	}
+	if policy.Annotations == nil {
+		policy.Annotations = make(map[string]string)
+	}
+	policy.Annotations[AnnotationOrigin] = origin
+	return policy, nil
+}
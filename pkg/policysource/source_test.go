@@ -0,0 +1,140 @@
+package policysource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+const teamA = `
+apiVersion: platform.example.com/v1beta1
+kind: ResourceQuotaPolicy
+metadata:
+  namespace: team-a
+spec:
+  maxPods: 20
+`
+
+// notified records the namespaces a Source's handler is called with.
+type notified struct {
+	mu  sync.Mutex
+	nss []string
+}
+
+func (n *notified) add(ns string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nss = append(n.nss, ns)
+}
+
+func (n *notified) take() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	out := n.nss
+	n.nss = nil
+	return out
+}
+
+func TestDirLoader(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("team-a.yaml", teamA)
+	write("README.md", "not a policy")
+
+	s := New()
+	var got notified
+	s.AddHandler(got.add)
+	s.AddDir(dir, time.Hour)
+	l := s.loaders[0].(*dirLoader)
+
+	l.load(s)
+	policies := s.List("team-a")
+	if len(policies) != 1 || policies[0].Name != "team-a" || policies[0].Spec.MaxPods != 20 {
+		t.Fatalf("List(team-a) = %+v, want policy team-a with maxPods 20", policies)
+	}
+	if origin := policies[0].Annotations[AnnotationOrigin]; origin != "file "+filepath.Join(dir, "team-a.yaml") {
+		t.Fatalf("origin = %q", origin)
+	}
+	if nss := got.take(); !slices.Equal(nss, []string{"team-a"}) {
+		t.Fatalf("notified %v, want [team-a]", nss)
+	}
+
+	// an unchanged directory notifies nobody
+	l.load(s)
+	if nss := got.take(); len(nss) != 0 {
+		t.Fatalf("notified %v for an unchanged directory", nss)
+	}
+
+	// a bad edit keeps the policy loaded before it
+	write("team-a.yaml", "apiVersion: platform.example.com/v1beta1\nkind: ResourceQuotaPolicy\nspec:\n  maxPods: 5\n")
+	l.load(s)
+	if policies := s.List("team-a"); len(policies) != 1 || policies[0].Spec.MaxPods != 20 {
+		t.Fatalf("List(team-a) after a bad edit = %+v, want the previous policy", policies)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "team-a.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	l.load(s)
+	if policies := s.List(""); len(policies) != 0 {
+		t.Fatalf("List after removing the file = %+v, want none", policies)
+	}
+	if nss := got.take(); !slices.Equal(nss, []string{"team-a"}) {
+		t.Fatalf("notified %v, want [team-a]", nss)
+	}
+}
+
+func TestConfigMapLoader(t *testing.T) {
+	labeled := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "rqe-system", Name: "quotas", Labels: map[string]string{ConfigMapLabel: "true"}},
+		Data:       map[string]string{"team-a.yaml": teamA, "notes.txt": "ignored"},
+	}
+	unlabeled := labeled.DeepCopy()
+	unlabeled.Name, unlabeled.Labels = "other", nil
+	client := fakeclient.NewSimpleClientset(labeled, unlabeled)
+
+	s := New()
+	var got notified
+	s.AddHandler(got.add)
+	if err := s.AddConfigMaps(client, "rqe-system", 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), s.HasSynced) {
+		t.Fatal("source did not sync")
+	}
+
+	policies := s.List("")
+	if len(policies) != 1 || policies[0].Namespace != "team-a" || policies[0].Name != "team-a" {
+		t.Fatalf("List = %+v, want only team-a/team-a", policies)
+	}
+	if origin := policies[0].Annotations[AnnotationOrigin]; origin != "configmap rqe-system/quotas key team-a.yaml" {
+		t.Fatalf("origin = %q", origin)
+	}
+
+	if err := client.CoreV1().ConfigMaps("rqe-system").Delete(ctx, "quotas", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.List("")) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("policies of the deleted ConfigMap were not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,160 @@
+// Package promusage reads what pods actually use from Prometheus, for policies with
+// usageSource prometheus.
+package promusage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// Default queries, over the cAdvisor metrics the kubelet exports.
+const (
+	DefaultCPUQuery    = `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="{{.Namespace}}",container!=""}[5m]))`
+	DefaultMemoryQuery = `sum by (pod) (container_memory_working_set_bytes{namespace="{{.Namespace}}",container!=""})`
+)
+
+// DefaultTimeout bounds each query when the client sets no timeout of its own.
+const DefaultTimeout = 10 * time.Second
+
+// Client runs usage queries against the Prometheus HTTP API.
+type Client struct {
+	// URL is the base URL of Prometheus, e.g. http://prometheus.monitoring.svc:9090.
+	URL string
+	// HTTP sends the queries; nil uses http.DefaultClient.
+	HTTP *http.Client
+}
+
+// Usage is the measured usage of each pod of a namespace, keyed by pod name. Pods without a
+// sample are absent.
+type Usage struct {
+	CPU    map[string]resource.Quantity
+	Memory map[string]resource.Quantity
+}
+
+// Usage runs queries, or the defaults for the ones left unset, for namespace.
+func (c *Client) Usage(ctx context.Context, namespace string, queries *v1beta1.UsageQueries) (Usage, error) {
+	cpuQuery, memoryQuery := DefaultCPUQuery, DefaultMemoryQuery
+	if queries != nil && queries.CPU != "" {
+		cpuQuery = queries.CPU
+	}
+	if queries != nil && queries.Memory != "" {
+		memoryQuery = queries.Memory
+	}
+
+	cpu, err := c.queryPods(ctx, cpuQuery, namespace)
+	if err != nil {
+		return Usage{}, fmt.Errorf("cpu query: %w", err)
+	}
+	memory, err := c.queryPods(ctx, memoryQuery, namespace)
+	if err != nil {
+		return Usage{}, fmt.Errorf("memory query: %w", err)
+	}
+	u := Usage{
+		CPU:    make(map[string]resource.Quantity, len(cpu)),
+		Memory: make(map[string]resource.Quantity, len(memory)),
+	}
+	for pod, cores := range cpu {
+		u.CPU[pod] = *resource.NewMilliQuantity(int64(math.Ceil(cores*1000)), resource.DecimalSI)
+	}
+	for pod, bytes := range memory {
+		u.Memory[pod] = *resource.NewQuantity(int64(math.Ceil(bytes)), resource.BinarySI)
+	}
+	return u, nil
+}
+
+// Render expands the query template tmpl for namespace.
+func Render(tmpl, namespace string) (string, error) {
+	t, err := template.New("query").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, struct{ Namespace string }{namespace}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// errNoPodLabel is returned for a query whose samples can't be told apart by pod.
+var errNoPodLabel = errors.New("result has a sample without a pod label, aggregate with sum by (pod)")
+
+// queryPods runs the instant query tmpl for namespace and returns its value per pod.
+func (c *Client) queryPods(ctx context.Context, tmpl, namespace string) (map[string]float64, error) {
+	query, err := Render(tmpl, namespace)
+	if err != nil {
+		return nil, err
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if client.Timeout == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.URL, "/")+"/api/v1/query?"+url.Values{"query": {query}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Metric map[string]string `json:"metric"`
+				Value  [2]any            `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	// Prometheus explains failed queries in the body of 4xx and 5xx responses too
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(&out); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if out.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s", out.Error)
+	}
+	if out.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("result is a %s, want a vector", out.Data.ResultType)
+	}
+
+	values := make(map[string]float64, len(out.Data.Result))
+	for _, sample := range out.Data.Result {
+		pod := sample.Metric["pod"]
+		if pod == "" {
+			return nil, errNoPodLabel
+		}
+		s, _ := sample.Value[1].(string)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			// a pod without a usable sample counts its requests instead
+			continue
+		}
+		values[pod] += v
+	}
+	return values, nil
+}
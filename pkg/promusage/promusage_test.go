@@ -0,0 +1,75 @@
+package promusage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+func TestUsage(t *testing.T) {
+	var queries []string
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query().Get("query")
+		queries = append(queries, q)
+		if strings.Contains(q, "cpu") {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"web-1"},"value":[1700000000,"0.2504"]},
+				{"metric":{"pod":"web-2"},"value":[1700000000,"NaN"]}]}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"web-1"},"value":[1700000000,"134217728"]}]}}`))
+	}))
+	defer prom.Close()
+
+	c := &Client{URL: prom.URL + "/"}
+	u, err := c.Usage(context.Background(), "team-a", &v1beta1.UsageQueries{Memory: `sum by (pod) (mem{namespace="{{.Namespace}}"})`})
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if cpu := u.CPU["web-1"]; cpu.String() != "251m" {
+		t.Errorf("cpu of web-1 = %s, want 251m", cpu.String())
+	}
+	if _, ok := u.CPU["web-2"]; ok {
+		t.Errorf("web-2 has cpu usage from a NaN sample")
+	}
+	if mem := u.Memory["web-1"]; mem.String() != "128Mi" {
+		t.Errorf("memory of web-1 = %s, want 128Mi", mem.String())
+	}
+	if len(queries) != 2 || !strings.Contains(queries[0], `namespace="team-a"`) || queries[1] != `sum by (pod) (mem{namespace="team-a"})` {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want string
+	}{
+		{"query fails", `{"status":"error","error":"parse error"}`, "parse error"},
+		{"no pod label", `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"1"]}]}}`, "pod label"},
+		{"not a vector", `{"status":"success","data":{"resultType":"scalar","result":[]}}`, "want a vector"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tc.body))
+			}))
+			defer prom.Close()
+
+			_, err := (&Client{URL: prom.URL}).Usage(context.Background(), "team-a", nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
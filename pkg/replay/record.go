@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Decisions of a record, as replayed or recorded.
+const (
+	Allowed = "allowed"
+	Denied  = "denied"
+)
+
+// Record is one recorded pod admission.
+type Record struct {
+	Request *admissionv1.AdmissionRequest
+	// Recorded is Allowed or Denied, what the webhook answered at the time, or empty when the
+	// recording doesn't tell.
+	Recorded string
+	// Decision is the webhook's "decision" audit annotation at the time, e.g. denied_no_policy,
+	// if it was recorded.
+	Decision string
+}
+
+// auditEvent is the part of an audit.k8s.io/v1 Event of the API server's audit log that a pod
+// admission can be rebuilt from.
+type auditEvent struct {
+	AuditID     types.UID                 `json:"auditID"`
+	Stage       string                    `json:"stage"`
+	Verb        string                    `json:"verb"`
+	User        authenticationv1.UserInfo `json:"user"`
+	ObjectRef   *auditObjectReference     `json:"objectRef"`
+	Status      *metav1.Status            `json:"responseStatus"`
+	Object      json.RawMessage           `json:"requestObject"`
+	Response    json.RawMessage           `json:"responseObject"`
+	Annotations map[string]string         `json:"annotations"`
+}
+
+type auditObjectReference struct {
+	Resource    string `json:"resource"`
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Subresource string `json:"subresource"`
+}
+
+// Read decodes the records in r, a stream of JSON objects such as JSON lines, in order. Each is
+// either an admission.k8s.io/v1 AdmissionReview, whose response, if any, is the recorded
+// decision, or an audit.k8s.io/v1 Event from the API server's audit log at level Request or
+// above. Of those, only the ResponseComplete stage of pod creations, updates and deletions is
+// read, and the decision comes from the webhook's audit annotations, which the API server
+// prefixes with the webhook's name, e.g. local-validator.example.com/decision. Anything else is
+// skipped.
+func Read(r io.Reader) ([]Record, error) {
+	dec := json.NewDecoder(r)
+	var records []Record
+	for i := 1; ; i++ {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		var rec *Record
+		switch {
+		case typeMeta.Kind == "AdmissionReview":
+			rec, err = fromReview(raw)
+		case typeMeta.Kind == "Event" && typeMeta.APIVersion == "audit.k8s.io/v1":
+			rec, err = fromAuditEvent(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		if rec != nil {
+			records = append(records, *rec)
+		}
+	}
+}
+
+func fromReview(raw []byte) (*Record, error) {
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(raw, &review); err != nil {
+		return nil, err
+	}
+	if review.Request == nil || review.Request.Kind.Kind != "Pod" {
+		return nil, nil
+	}
+	rec := &Record{Request: review.Request}
+	if resp := review.Response; resp != nil {
+		rec.Recorded = Denied
+		if resp.Allowed {
+			rec.Recorded = Allowed
+		}
+		rec.Decision = resp.AuditAnnotations["decision"]
+	}
+	return rec, nil
+}
+
+func fromAuditEvent(raw []byte) (*Record, error) {
+	var ev auditEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil, err
+	}
+	ref := ev.ObjectRef
+	if ev.Stage != "ResponseComplete" || ref == nil || ref.Resource != "pods" {
+		return nil, nil
+	}
+	req := &admissionv1.AdmissionRequest{
+		UID:         ev.AuditID,
+		Kind:        metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Resource:    metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+		SubResource: ref.Subresource,
+		Namespace:   ref.Namespace,
+		Name:        ref.Name,
+		UserInfo:    ev.User,
+	}
+	switch {
+	case ev.Verb == "create" && ref.Subresource == "":
+		req.Operation = admissionv1.Create
+	case ev.Verb == "update" && (ref.Subresource == "" || ref.Subresource == "resize" || ref.Subresource == "ephemeralcontainers"):
+		req.Operation = admissionv1.Update
+	case ev.Verb == "delete" && ref.Subresource == "":
+		// failed deletions left the pod in place
+		if ev.Status != nil && ev.Status.Code >= http.StatusBadRequest {
+			return nil, nil
+		}
+		req.Operation = admissionv1.Delete
+		return &Record{Request: req}, nil
+	default:
+		// patches only carry the patch, not the pod
+		return nil, nil
+	}
+	if len(ev.Object) == 0 {
+		return nil, fmt.Errorf("%s of pod %s/%s was logged without its requestObject, log pods at level Request or above", ev.Verb, ref.Namespace, ref.Name)
+	}
+	req.Object = runtime.RawExtension{Raw: ev.Object}
+	if req.Name == "" && len(ev.Response) > 0 {
+		// at level RequestResponse, the name the API server generated
+		var created metav1.PartialObjectMetadata
+		if err := json.Unmarshal(ev.Response, &created); err == nil {
+			req.Name = created.Name
+		}
+	}
+
+	rec := &Record{Request: req}
+	for key, value := range ev.Annotations {
+		if strings.HasSuffix(key, "/decision") {
+			rec.Decision = value
+		}
+	}
+	switch {
+	case rec.Decision == "":
+		// the webhook wasn't called, or the pod was rejected before it was
+	case strings.HasPrefix(rec.Decision, "denied"):
+		rec.Recorded = Denied
+	case (rec.Decision == "timeout" || rec.Decision == "overloaded") && ev.Status != nil &&
+		ev.Status.Code >= http.StatusBadRequest && strings.Contains(ev.Status.Message, "resource quota"):
+		// denied with -deny-on-timeout or -overload-policy=deny; a failed request is otherwise
+		// left to other admission plugins
+		rec.Recorded = Denied
+	default:
+		rec.Recorded = Allowed
+	}
+	return rec, nil
+}
@@ -0,0 +1,182 @@
+// Package replay re-runs recorded pod admissions through the webhook's evaluation against a set
+// of policies and reports the decisions that change, to try policy changes out before rolling
+// them out and to regression-test the evaluation itself.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	policyfake "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/webhook"
+)
+
+// Result is the replayed decision on one admission.
+type Result struct {
+	Namespace string                `json:"namespace"`
+	Pod       string                `json:"pod"`
+	Operation admissionv1.Operation `json:"operation"`
+	// Recorded and Replayed are Allowed or Denied; Recorded is empty when it wasn't recorded.
+	Recorded string `json:"recorded,omitempty"`
+	Replayed string `json:"replayed"`
+	// Decision is the webhook's "decision" audit annotation on the replay, e.g.
+	// allowed_no_policy.
+	Decision string `json:"decision"`
+	Policy   string `json:"policy,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Changed reports whether the replay decided differently than the recording.
+func (r Result) Changed() bool {
+	return r.Recorded != "" && r.Recorded != r.Replayed
+}
+
+// Replayer evaluates records against Policies. Namespaces start out without pods; the pods it
+// admits are added to them, and recorded deletions remove them again, so each admission sees
+// the usage left by the records before it, as decided by the replay rather than the recording.
+type Replayer struct {
+	// Policies are the ResourceQuotaPolicies to evaluate against, in their namespaces.
+	Policies []*v1beta1.ResourceQuotaPolicy
+	// Merge combines the policies of namespaces with more than one, as the webhook's
+	// -policy-merge.
+	Merge v1beta1.MergeStrategy
+	// DefaultAction is the webhook's -default-action for namespaces without a policy.
+	DefaultAction string
+}
+
+// Run replays records in order and returns the result of every creation and update it
+// evaluated. The cluster is never contacted.
+func (rp *Replayer) Run(ctx context.Context, records []Record) ([]Result, error) {
+	objs := make([]runtime.Object, 0, len(rp.Policies))
+	for _, p := range rp.Policies {
+		objs = append(objs, p)
+	}
+	policies := policycache.NewInformer(policyfake.NewSimpleClientset(objs...), 0)
+	policies.Merge = rp.Merge
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go policies.Run(stopCh)
+	if err := policies.WaitForReady(30 * time.Second); err != nil {
+		return nil, err
+	}
+
+	sandbox := fake.NewSimpleClientset()
+	server := webhook.NewWebhookServerWithInformer(sandbox, policies)
+	server.DefaultAction = rp.DefaultAction
+
+	var results []Result
+	for i, rec := range records {
+		req := rec.Request.DeepCopy()
+		pods := sandbox.CoreV1().Pods(req.Namespace)
+		if req.Operation == admissionv1.Delete {
+			if err := pods.Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			continue
+		}
+
+		var pod corev1.Pod
+		if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+			return nil, fmt.Errorf("record %d: decode pod: %w", i+1, err)
+		}
+		pod.Namespace = req.Namespace
+		if pod.Name == "" {
+			pod.Name = req.Name
+		}
+		if pod.Name == "" {
+			// the API server names pods with only a generateName after admission
+			pod.Name = fmt.Sprintf("%sreplayed-%d", pod.GenerateName, i+1)
+		}
+		var existing *corev1.Pod
+		if req.Operation == admissionv1.Update {
+			old, err := pods.Get(ctx, pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				// created before the recording started
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			existing = old
+			if len(req.OldObject.Raw) == 0 {
+				if req.OldObject.Raw, err = json.Marshal(old); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		resp, err := admit(server, req)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		res := Result{
+			Namespace: req.Namespace,
+			Pod:       pod.Name,
+			Operation: req.Operation,
+			Recorded:  rec.Recorded,
+			Replayed:  Denied,
+			Decision:  resp.AuditAnnotations["decision"],
+			Policy:    resp.AuditAnnotations["policy"],
+			Reason:    resp.AuditAnnotations["reason"],
+		}
+		if resp.Allowed {
+			res.Replayed = Allowed
+		}
+		results = append(results, res)
+		if !resp.Allowed {
+			continue
+		}
+
+		if existing != nil {
+			pod.ResourceVersion = existing.ResourceVersion
+			_, err = pods.Update(ctx, &pod, metav1.UpdateOptions{})
+		} else {
+			_, err = pods.Create(ctx, &pod, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// a name reused after a deletion the recording missed
+				_, err = pods.Update(ctx, &pod, metav1.UpdateOptions{})
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return results, nil
+}
+
+// admit sends req through the webhook's pod admission handler, as the API server would.
+func admit(server *webhook.WebhookServer, req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request:  req,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.HandleValidatePods(w, r)
+	if w.Code != http.StatusOK {
+		return nil, fmt.Errorf("webhook answered %d: %s", w.Code, bytes.TrimSpace(w.Body.Bytes()))
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(w.Body.Bytes(), &review); err != nil || review.Response == nil {
+		return nil, fmt.Errorf("webhook answered without a response: %s", bytes.TrimSpace(w.Body.Bytes()))
+	}
+	return review.Response, nil
+}
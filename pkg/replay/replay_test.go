@@ -0,0 +1,131 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func podJSON(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestRead(t *testing.T) {
+	review, _ := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "review",
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Namespace: "team",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: podJSON(t, "web-1")},
+		},
+		Response: &admissionv1.AdmissionResponse{UID: "review", Allowed: false, AuditAnnotations: map[string]string{"decision": "denied"}},
+	})
+	event := func(stage, verb, name string, object []byte, annotations string) string {
+		line := `{"kind":"Event","apiVersion":"audit.k8s.io/v1","auditID":"` + name + `","stage":"` + stage + `","verb":"` + verb +
+			`","objectRef":{"resource":"pods","namespace":"team","name":"` + name + `"},"annotations":{` + annotations + `}`
+		if object != nil {
+			line += `,"requestObject":` + string(object)
+		}
+		return line + "}"
+	}
+	input := strings.Join([]string{
+		string(review),
+		event("RequestReceived", "create", "web-2", podJSON(t, "web-2"), ""),
+		event("ResponseComplete", "create", "web-2", podJSON(t, "web-2"), `"local-validator.example.com/decision":"allowed"`),
+		event("ResponseComplete", "patch", "web-2", []byte(`{"metadata":{"labels":{"a":"b"}}}`), ""),
+		event("ResponseComplete", "delete", "web-2", nil, ""),
+		`{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"list","objectRef":{"resource":"pods"}}`,
+	}, "\n")
+
+	records, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		op       admissionv1.Operation
+		name     string
+		recorded string
+	}{
+		{admissionv1.Create, "", Denied},
+		{admissionv1.Create, "web-2", Allowed},
+		{admissionv1.Delete, "web-2", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		rec := records[i]
+		if rec.Request.Operation != w.op || rec.Request.Name != w.name || rec.Recorded != w.recorded {
+			t.Errorf("record %d: got %s %q recorded %q, want %s %q recorded %q", i+1, rec.Request.Operation, rec.Request.Name, rec.Recorded, w.op, w.name, w.recorded)
+		}
+	}
+
+	if _, err := Read(strings.NewReader(event("ResponseComplete", "create", "web-3", nil, ""))); err == nil {
+		t.Error("expected an error for a creation logged without its pod")
+	}
+}
+
+func TestReplayerRun(t *testing.T) {
+	create := func(name string) Record {
+		return Record{
+			Request: &admissionv1.AdmissionRequest{
+				UID:       types.UID("uid-" + name),
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				Namespace: "team",
+				Name:      name,
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: podJSON(t, name)},
+			},
+			Recorded: Allowed,
+		}
+	}
+	records := []Record{
+		create("web-1"),
+		create("web-2"),
+		{Request: &admissionv1.AdmissionRequest{Kind: metav1.GroupVersionKind{Version: "v1", Kind: "Pod"}, Namespace: "team", Name: "web-1", Operation: admissionv1.Delete}},
+		create("web-3"),
+	}
+	rp := &Replayer{Policies: []*v1beta1.ResourceQuotaPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "tight", Namespace: "team"},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 1},
+	}}}
+	results, err := rp.Run(context.Background(), records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{Allowed, Denied, Allowed}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Replayed != w {
+			t.Errorf("%s: replayed %s, want %s (%s)", results[i].Pod, results[i].Replayed, w, results[i].Reason)
+		}
+	}
+	if !results[1].Changed() || results[1].Policy != "tight" {
+		t.Errorf("web-2: got %+v, want a change denied by tight", results[1])
+	}
+	if results[0].Changed() || results[2].Changed() {
+		t.Errorf("unexpected changes: %+v", results)
+	}
+}
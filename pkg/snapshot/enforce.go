@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// Enforcement is what the controller would do in one namespace of a snapshot.
+type Enforcement struct {
+	Namespace string `json:"namespace"`
+	// Policy names the policies enforced, joined by "+" when the merge strategy combines several.
+	Policy string `json:"policy"`
+	// Pods, CPU and Memory are the namespace's usage in the snapshot.
+	Pods      int    `json:"pods"`
+	CPU       string `json:"cpu"`
+	Memory    string `json:"memory"`
+	Violation bool   `json:"violation"`
+	Message   string `json:"message,omitempty"`
+	// WouldDelete lists the pods the controller would delete, in the order it would pick them.
+	WouldDelete []string `json:"wouldDelete,omitempty"`
+	// DeletionCapReached is set when maxDeletionsPerSync leaves the violation in place.
+	DeletionCapReached bool `json:"deletionCapReached,omitempty"`
+}
+
+// Options are the controller flags enforcement depends on.
+type Options struct {
+	// Merge combines the policies of namespaces that have more than one, as -policy-merge.
+	Merge v1beta1.MergeStrategy
+	// Exclude holds the cluster-wide exclusion defaults, as -exclude-daemonset-pods and -exclude-mirror-pods.
+	Exclude handlers.Exclusions
+	// Now is when scheduled changes are resolved; zero means time.Now.
+	Now time.Time
+}
+
+// Enforce runs the controller's enforcement in dry-run mode on every namespace of s that has a
+// policy, as one sync would, and reports the pods it would delete. Suspended policies are left
+// out; policies from files or ConfigMaps, the default policy and QuotaExemptions aren't known
+// to a snapshot.
+func Enforce(ctx context.Context, s *Snapshot, opts Options) ([]Enforcement, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	kube, _ := s.Clients()
+	allocatable, err := capacity.List(ctx, kube)
+	if err != nil {
+		return nil, err
+	}
+	var mode handlers.EnforcementMode
+	if err := mode.Set(handlers.EnforcementDryRun); err != nil {
+		return nil, err
+	}
+	enforcer := &handlers.PodEnforcer{Client: kube, Mode: &mode, Exclude: opts.Exclude, MaxIterations: len(s.Pods) + 1}
+
+	byNamespace := map[string][]*v1beta1.ResourceQuotaPolicy{}
+	for i := range s.Policies {
+		p := &s.Policies[i]
+		if !p.Spec.Suspend {
+			byNamespace[p.Namespace] = append(byNamespace[p.Namespace], p)
+		}
+	}
+	namespaces := make([]string, 0, len(byNamespace))
+	for ns := range byNamespace {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	out := make([]Enforcement, 0, len(namespaces))
+	for _, ns := range namespaces {
+		items := byNamespace[ns]
+		v1beta1.SortByPriority(items)
+		if opts.Merge == v1beta1.MergeHighestPriorityOnly || opts.Merge == "" {
+			items = items[:1]
+		}
+		specs := make([]v1beta1.ResourceQuotaPolicySpec, len(items))
+		names := make([]string, len(items))
+		for i, item := range items {
+			specs[i], _ = item.Spec.EffectiveAt(now)
+			specs[i].ResolveClusterShare(allocatable)
+			names[i] = item.Name
+		}
+		spec := v1beta1.Merge(opts.Merge, specs)
+		policy := handlers.ParsePolicy(&spec)
+		policy.Exclude = opts.Exclude.With(spec.ExcludePods)
+		policy.Name = strings.Join(names, "+")
+
+		res, err := enforcer.EnforceUntilOK(ctx, ns, policy)
+		if err != nil {
+			return nil, fmt.Errorf("enforce %s in %s: %w", policy.Name, ns, err)
+		}
+		out = append(out, Enforcement{
+			Namespace:          ns,
+			Policy:             policy.Name,
+			Pods:               res.CurrentPods,
+			CPU:                res.CurrentCPU,
+			Memory:             res.CurrentMemory,
+			Violation:          res.Violation,
+			Message:            res.Message,
+			WouldDelete:        res.WouldEvict,
+			DeletionCapReached: res.DeletionCapReached,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestEnforce(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &Snapshot{}
+	for _, ns := range []string{"full", "over", "suspended"} {
+		s.Namespaces = append(s.Namespaces, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+		for i := range 3 {
+			s.Pods = append(s.Pods, corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              fmt.Sprintf("pod-%d", i),
+					Namespace:         ns,
+					UID:               types.UID(fmt.Sprintf("%s-%d", ns, i)),
+					CreationTimestamp: metav1.NewTime(created.Add(time.Duration(i) * time.Hour)),
+				},
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx"}}},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			})
+		}
+	}
+	policy := func(ns string, maxPods int32, suspend bool) v1beta1.ResourceQuotaPolicy {
+		return v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "quota"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: maxPods, Suspend: suspend},
+		}
+	}
+	s.Policies = []v1beta1.ResourceQuotaPolicy{policy("full", 3, false), policy("over", 1, false), policy("suspended", 1, true)}
+
+	got, err := Enforce(context.Background(), s, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("enforced %d namespaces, want full and over: %+v", len(got), got)
+	}
+	if full := got[0]; full.Namespace != "full" || full.Violation || len(full.WouldDelete) != 0 || full.Pods != 3 {
+		t.Errorf("full = %+v, want 3 pods and nothing to delete", full)
+	}
+	if over := got[1]; over.Namespace != "over" || !over.Violation || len(over.WouldDelete) != 2 || over.Pods != 3 {
+		t.Errorf("over = %+v, want 3 pods and 2 to delete", over)
+	}
+
+	// the snapshot itself is left as it was
+	if len(s.Pods) != 9 {
+		t.Errorf("snapshot has %d pods after Enforce, want 9", len(s.Pods))
+	}
+}
@@ -0,0 +1,219 @@
+// Package snapshot takes and reads dumps of a cluster's namespaces, nodes, pods and policies, and
+// runs the controller's enforcement against them without the cluster, for what-if analysis of
+// policy changes and for support tickets.
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	versioned "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	policyfake "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+)
+
+// Kind and APIVersion identify the document Take's snapshots are written as.
+const (
+	Kind       = "Snapshot"
+	APIVersion = "snapshot.platform.example.com/v1"
+)
+
+// Snapshot is the state of a cluster that enforcement depends on.
+type Snapshot struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	// TakenAt is when Take took the snapshot, and Version the version of rqe that took it.
+	TakenAt *metav1.Time `json:"takenAt,omitempty"`
+	Version string       `json:"version,omitempty"`
+
+	Namespaces []corev1.Namespace `json:"namespaces,omitempty"`
+	// Nodes give the cluster's allocatable resources, which clusterShare limits resolve against.
+	Nodes    []corev1.Node                 `json:"nodes,omitempty"`
+	Pods     []corev1.Pod                  `json:"pods,omitempty"`
+	Policies []v1beta1.ResourceQuotaPolicy `json:"policies,omitempty"`
+
+	// Usage sums up what each namespace runs, for whoever reads the snapshot. Enforce computes
+	// usage from the pods again, so it need not be kept up to date when editing a snapshot.
+	Usage []Usage `json:"usage,omitempty"`
+}
+
+// Read reads a snapshot from a stream of YAML or JSON documents: snapshots written by Take, or
+// objects such as the output of kubectl get namespaces,nodes,pods,resourcequotapolicies -A -o
+// yaml. Lists are read item by item; objects of other kinds are skipped. v1alpha1 policies are
+// converted to v1beta1.
+func Read(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) == "null" {
+			continue
+		}
+		if err := s.add(data, ""); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// object is what add needs to know of a document before decoding it.
+type object struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Items      []json.RawMessage `json:"items"`
+}
+
+// add adds the object in data to s. kind is the kind of a list's items, which typed lists
+// such as PodList leave out.
+func (s *Snapshot) add(data []byte, kind string) error {
+	var o object
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+	if o.Kind == "" {
+		o.Kind = kind
+	}
+	if strings.HasSuffix(o.Kind, "List") {
+		for _, item := range o.Items {
+			if err := s.add(item, strings.TrimSuffix(o.Kind, "List")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch o.Kind {
+	case Kind:
+		if o.APIVersion != APIVersion {
+			return fmt.Errorf("unknown snapshot version %s", o.APIVersion)
+		}
+		var other Snapshot
+		if err := json.Unmarshal(data, &other); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+		s.merge(&other)
+	case "Namespace":
+		var ns corev1.Namespace
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return fmt.Errorf("namespace: %w", err)
+		}
+		s.Namespaces = append(s.Namespaces, ns)
+	case "Node":
+		var node corev1.Node
+		if err := json.Unmarshal(data, &node); err != nil {
+			return fmt.Errorf("node: %w", err)
+		}
+		s.Nodes = append(s.Nodes, node)
+	case "Pod":
+		var pod corev1.Pod
+		if err := json.Unmarshal(data, &pod); err != nil {
+			return fmt.Errorf("pod: %w", err)
+		}
+		s.Pods = append(s.Pods, pod)
+	case "ResourceQuotaPolicy":
+		policy, err := decodePolicy(o.APIVersion, data)
+		if err != nil {
+			return err
+		}
+		s.Policies = append(s.Policies, *policy)
+	}
+	return nil
+}
+
+// merge adds the objects of other to s.
+func (s *Snapshot) merge(other *Snapshot) {
+	if s.TakenAt == nil {
+		s.APIVersion, s.Kind, s.TakenAt, s.Version = other.APIVersion, other.Kind, other.TakenAt, other.Version
+	}
+	s.Namespaces = append(s.Namespaces, other.Namespaces...)
+	s.Nodes = append(s.Nodes, other.Nodes...)
+	s.Pods = append(s.Pods, other.Pods...)
+	for i := range other.Policies {
+		v1beta1.SetObjectDefaults_ResourceQuotaPolicy(&other.Policies[i])
+	}
+	s.Policies = append(s.Policies, other.Policies...)
+	s.Usage = append(s.Usage, other.Usage...)
+}
+
+func decodePolicy(apiVersion string, data []byte) (*v1beta1.ResourceQuotaPolicy, error) {
+	var policy v1beta1.ResourceQuotaPolicy
+	switch apiVersion {
+	case v1beta1.SchemeGroupVersion.String(), "":
+		if err := json.Unmarshal(data, &policy); err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+	case v1alpha1.SchemeGroupVersion.String():
+		var old v1alpha1.ResourceQuotaPolicy
+		if err := json.Unmarshal(data, &old); err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+		if err := old.ConvertTo(&policy); err != nil {
+			return nil, fmt.Errorf("convert policy %s/%s: %w", old.Namespace, old.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown ResourceQuotaPolicy version %s", apiVersion)
+	}
+	v1beta1.SetObjectDefaults_ResourceQuotaPolicy(&policy)
+	return &policy, nil
+}
+
+// ReplacePolicies replaces the policies of each namespace policies name with those policies, to
+// see what a policy change would do.
+func (s *Snapshot) ReplacePolicies(policies []*v1beta1.ResourceQuotaPolicy) {
+	replaced := map[string]bool{}
+	for _, p := range policies {
+		replaced[p.Namespace] = true
+	}
+	kept := s.Policies[:0:0]
+	for _, p := range s.Policies {
+		if !replaced[p.Namespace] {
+			kept = append(kept, p)
+		}
+	}
+	for _, p := range policies {
+		kept = append(kept, *p)
+	}
+	s.Policies = kept
+}
+
+// Clients returns fake clientsets holding the objects of s. Changes made through them don't
+// touch s.
+func (s *Snapshot) Clients() (kubernetes.Interface, versioned.Interface) {
+	var objs []runtime.Object
+	for i := range s.Namespaces {
+		objs = append(objs, s.Namespaces[i].DeepCopy())
+	}
+	for i := range s.Nodes {
+		objs = append(objs, s.Nodes[i].DeepCopy())
+	}
+	for i := range s.Pods {
+		objs = append(objs, s.Pods[i].DeepCopy())
+	}
+	var policies []runtime.Object
+	for i := range s.Policies {
+		policies = append(policies, s.Policies[i].DeepCopy())
+	}
+	return fake.NewSimpleClientset(objs...), policyfake.NewSimpleClientset(policies...)
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const dump = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Namespace
+  metadata: {name: team-a}
+- apiVersion: v1
+  kind: ConfigMap
+  metadata: {name: skipped, namespace: team-a}
+- apiVersion: platform.example.com/v1alpha1
+  kind: ResourceQuotaPolicy
+  metadata: {name: old, namespace: team-a}
+  spec: {maxPods: 3, maxCPU: "2"}
+---
+{"apiVersion": "v1", "kind": "PodList", "items": [
+  {"metadata": {"name": "web-1", "namespace": "team-a"}, "spec": {"containers": [{"name": "app", "image": "nginx"}]}}
+]}
+---
+apiVersion: v1
+kind: Node
+metadata: {name: n1}
+`
+
+func TestRead(t *testing.T) {
+	s, err := Read(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Namespaces) != 1 || s.Namespaces[0].Name != "team-a" {
+		t.Errorf("namespaces = %+v", s.Namespaces)
+	}
+	if len(s.Nodes) != 1 || s.Nodes[0].Name != "n1" {
+		t.Errorf("nodes = %+v", s.Nodes)
+	}
+	if len(s.Pods) != 1 || s.Pods[0].Name != "web-1" {
+		t.Errorf("pods = %+v", s.Pods)
+	}
+	if len(s.Policies) != 1 {
+		t.Fatalf("policies = %+v", s.Policies)
+	}
+	p := s.Policies[0]
+	if p.Spec.MaxPods != 3 || p.Spec.MaxCPU == nil || p.Spec.MaxCPU.String() != "2" {
+		t.Errorf("v1alpha1 policy converted to %+v", p.Spec)
+	}
+}
+
+func TestReadUnknownPolicyVersion(t *testing.T) {
+	_, err := Read(strings.NewReader("apiVersion: platform.example.com/v2\nkind: ResourceQuotaPolicy\nmetadata: {name: p}\n"))
+	if err == nil {
+		t.Error("policy of an unknown version read")
+	}
+}
+
+func TestReplacePolicies(t *testing.T) {
+	policy := func(ns, name string) v1beta1.ResourceQuotaPolicy {
+		return v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	}
+	s := &Snapshot{Policies: []v1beta1.ResourceQuotaPolicy{policy("a", "one"), policy("a", "two"), policy("b", "three")}}
+	replacement := policy("a", "new")
+	s.ReplacePolicies([]*v1beta1.ResourceQuotaPolicy{&replacement})
+
+	var got []string
+	for _, p := range s.Policies {
+		got = append(got, p.Namespace+"/"+p.Name)
+	}
+	if want := "b/three a/new"; strings.Join(got, " ") != want {
+		t.Errorf("policies = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	versioned "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/version"
+)
+
+// Usage is what one namespace of a snapshot runs and requests.
+type Usage struct {
+	Namespace string `json:"namespace"`
+	// Pods, CPU and Memory total the pods that count toward a policy, whether or not the
+	// namespace has one.
+	Pods   int    `json:"pods"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	// Requests are what each of those pods requests.
+	Requests []PodRequests `json:"requests,omitempty"`
+	// Enforcement is the usage as the namespace's policies count it, with the pods the
+	// controller would delete; nil in a namespace without a policy.
+	Enforcement *Enforcement `json:"enforcement,omitempty"`
+}
+
+// PodRequests are the CPU and memory a pod counts against a policy.
+type PodRequests struct {
+	Pod    string `json:"pod"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// droppedAnnotations are left out of the objects of a snapshot, which is meant to be attached
+// to a support ticket.
+var droppedAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration"}
+
+// Take takes a snapshot of namespace, or of every namespace if it is empty, and sums up its
+// usage. Nodes are always included, for clusterShare limits. Completed pods are left out, as
+// they never count toward a policy, and so are the environment variables of pods, which may
+// hold credentials, and managedFields.
+func Take(ctx context.Context, kube kubernetes.Interface, policies versioned.Interface, namespace string, opts Options) (*Snapshot, error) {
+	now := metav1.Now()
+	s := &Snapshot{APIVersion: APIVersion, Kind: Kind, TakenAt: &now, Version: version.Get()}
+
+	if namespace == "" {
+		list, err := kube.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list namespaces: %w", err)
+		}
+		s.Namespaces = list.Items
+	} else {
+		ns, err := kube.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get namespace: %w", err)
+		}
+		s.Namespaces = []corev1.Namespace{*ns}
+	}
+	nodes, err := kube.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	s.Nodes = nodes.Items
+	err = handlers.EachPod(ctx, kube, namespace, func(pod *corev1.Pod) error {
+		s.Pods = append(s.Pods, *pod)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	list, err := policies.PlatformV1beta1().ResourceQuotaPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	s.Policies = list.Items
+
+	s.clean()
+	if s.Usage, err = Summarize(ctx, s, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// clean drops what a snapshot doesn't need and shouldn't carry from the objects of s.
+func (s *Snapshot) clean() {
+	for i := range s.Namespaces {
+		cleanMeta(&s.Namespaces[i].ObjectMeta)
+	}
+	for i := range s.Nodes {
+		cleanMeta(&s.Nodes[i].ObjectMeta)
+		// the images a node has pulled make up most of its status
+		s.Nodes[i].Status.Images = nil
+	}
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		cleanMeta(&pod.ObjectMeta)
+		for j := range pod.Spec.InitContainers {
+			pod.Spec.InitContainers[j].Env, pod.Spec.InitContainers[j].EnvFrom = nil, nil
+		}
+		for j := range pod.Spec.Containers {
+			pod.Spec.Containers[j].Env, pod.Spec.Containers[j].EnvFrom = nil, nil
+		}
+		for j := range pod.Spec.EphemeralContainers {
+			pod.Spec.EphemeralContainers[j].Env, pod.Spec.EphemeralContainers[j].EnvFrom = nil, nil
+		}
+	}
+	for i := range s.Policies {
+		cleanMeta(&s.Policies[i].ObjectMeta)
+	}
+}
+
+func cleanMeta(meta *metav1.ObjectMeta) {
+	meta.ManagedFields = nil
+	for _, a := range droppedAnnotations {
+		delete(meta.Annotations, a)
+	}
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// Summarize sums up the usage of every namespace of s, including those with pods but no
+// Namespace object, and what the controller would do in those with a policy.
+func Summarize(ctx context.Context, s *Snapshot, opts Options) ([]Usage, error) {
+	enforcement, err := Enforce(ctx, s, opts)
+	if err != nil {
+		return nil, err
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	byNamespace := map[string]*Usage{}
+	usage := func(ns string) *Usage {
+		u, ok := byNamespace[ns]
+		if !ok {
+			u = &Usage{Namespace: ns}
+			byNamespace[ns] = u
+		}
+		return u
+	}
+	cpuTotals, memoryTotals := map[string]resource.Quantity{}, map[string]resource.Quantity{}
+	for i := range s.Namespaces {
+		usage(s.Namespaces[i].Name)
+	}
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		if !handlers.CountsTowardUsage(pod, now) {
+			continue
+		}
+		cpu, memory := handlers.PodRequests(pod)
+		u := usage(pod.Namespace)
+		u.Pods++
+		u.Requests = append(u.Requests, PodRequests{Pod: pod.Name, CPU: cpu.String(), Memory: memory.String()})
+		cpuTotal, memoryTotal := cpuTotals[pod.Namespace], memoryTotals[pod.Namespace]
+		cpuTotal.Add(cpu)
+		memoryTotal.Add(memory)
+		cpuTotals[pod.Namespace], memoryTotals[pod.Namespace] = cpuTotal, memoryTotal
+	}
+	for i := range enforcement {
+		usage(enforcement[i].Namespace).Enforcement = &enforcement[i]
+	}
+
+	out := make([]Usage, 0, len(byNamespace))
+	for ns, u := range byNamespace {
+		cpu, memory := cpuTotals[ns], memoryTotals[ns]
+		u.CPU, u.Memory = cpu.String(), memory.String()
+		sort.Slice(u.Requests, func(i, j int) bool { return u.Requests[i].Pod < u.Requests[j].Pod })
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out, nil
+}
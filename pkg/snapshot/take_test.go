@@ -0,0 +1,104 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	policyfake "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestTake(t *testing.T) {
+	pod := func(ns, name, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ns,
+				Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "app",
+				Image:     "nginx",
+				Env:       []corev1.EnvVar{{Name: "PASSWORD", Value: "hunter2"}},
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	kube := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		pod("team-a", "web-1", "100m"),
+		pod("team-a", "web-2", "200m"),
+		pod("team-b", "api-1", "1"),
+	)
+	policies := policyfake.NewSimpleClientset(&v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 1},
+	})
+
+	s, err := Take(context.Background(), kube, policies, "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Namespaces) != 2 || len(s.Pods) != 3 || len(s.Policies) != 1 {
+		t.Fatalf("took %d namespaces, %d pods, %d policies", len(s.Namespaces), len(s.Pods), len(s.Policies))
+	}
+	for _, p := range s.Pods {
+		if p.Spec.Containers[0].Env != nil || p.Annotations != nil {
+			t.Errorf("pod %s keeps its env or annotations", p.Name)
+		}
+	}
+	if len(s.Usage) != 2 {
+		t.Fatalf("usage of %d namespaces, want 2", len(s.Usage))
+	}
+	a, b := s.Usage[0], s.Usage[1]
+	if a.Namespace != "team-a" || a.Pods != 2 || a.CPU != "300m" || len(a.Requests) != 2 {
+		t.Errorf("team-a usage = %+v", a)
+	}
+	if a.Enforcement == nil || len(a.Enforcement.WouldDelete) != 1 {
+		t.Errorf("team-a enforcement = %+v, want one pod to delete", a.Enforcement)
+	}
+	if b.Namespace != "team-b" || b.Pods != 1 || b.CPU != "1" || b.Enforcement != nil {
+		t.Errorf("team-b usage = %+v", b)
+	}
+
+	// what Take writes, Read reads back
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Namespaces) != 2 || len(read.Pods) != 3 || len(read.Policies) != 1 || len(read.Usage) != 2 {
+		t.Errorf("read back %d namespaces, %d pods, %d policies, %d usages", len(read.Namespaces), len(read.Pods), len(read.Policies), len(read.Usage))
+	}
+	if read.TakenAt == nil || read.Version == "" {
+		t.Errorf("read back without when and by what it was taken: %+v", read.TakenAt)
+	}
+}
+
+func TestTakeNamespace(t *testing.T) {
+	kube := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "team-a"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "team-b"}},
+	)
+	s, err := Take(context.Background(), kube, policyfake.NewSimpleClientset(), "team-a", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Namespaces) != 1 || len(s.Pods) != 1 || s.Pods[0].Name != "web-1" || len(s.Nodes) != 1 {
+		t.Errorf("took namespaces %v, pods %v, %d nodes", s.Namespaces, s.Pods, len(s.Nodes))
+	}
+}
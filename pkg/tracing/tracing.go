@@ -0,0 +1,86 @@
+// Package tracing exports OpenTelemetry spans over OTLP so admission decisions and
+// reconciles can be lined up with the API-server calls they make.
+package tracing
+
+import (
+	"context"
+	"flag"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+)
+
+const tracerName = "github.com/sri2103/resource-quota-enforcer"
+
+// Config selects where spans are exported.
+type Config struct {
+	// Endpoint is the OTLP gRPC collector address, e.g. otel-collector:4317.
+	Endpoint string
+	// Insecure disables TLS towards the collector.
+	Insecure bool
+	// SampleRatio is the fraction of new traces that are recorded.
+	SampleRatio float64
+}
+
+// AddFlags registers the tracing flags shared by the controller and the webhook.
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Endpoint, "otlp-endpoint", "", "OTLP gRPC endpoint to export traces to, e.g. otel-collector:4317 (disabled when empty)")
+	fs.BoolVar(&c.Insecure, "otlp-insecure", false, "connect to the OTLP endpoint without TLS")
+	fs.Float64Var(&c.SampleRatio, "otlp-sample-ratio", 1, "fraction of traces to record, between 0 and 1")
+}
+
+// Setup installs the global tracer provider for service. With no endpoint configured tracing
+// stays disabled and the returned shutdown does nothing.
+func Setup(ctx context.Context, service string, c Config) (func(context.Context) error, error) {
+	if c.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Endpoint)}
+	if c.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exp, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(service)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer every span in the enforcer is started from.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// WrapConfig makes every request sent with config a client span, so API-server calls show
+// up under the admission or reconcile that issued them.
+func WrapConfig(config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(rt)
+	})
+}
@@ -0,0 +1,106 @@
+package translate
+
+import (
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// ConstraintKind is the kind of the Gatekeeper constraints GatekeeperTemplate defines.
+const ConstraintKind = "RQEResourceQuota"
+
+// rego evaluates the limits of a constraint against the pods Gatekeeper replicates into
+// data.inventory, which its Config must sync.
+const rego = `package rqeresourcequota
+
+pods := [pod |
+  pod := data.inventory.namespace[input.review.object.metadata.namespace]["v1"]["Pod"][_]
+  not finished(pod)
+]
+
+finished(pod) { pod.status.phase == "Succeeded" }
+finished(pod) { pod.status.phase == "Failed" }
+
+requests(pod, resource) = total {
+  total := sum([units.parse(c.resources.requests[resource]) | c := pod.spec.containers[_]])
+}
+
+used(resource) = total {
+  total := sum([requests(pod, resource) | pod := pods[_]]) + requests(input.review.object, resource)
+}
+
+violation[{"msg": msg}] {
+  input.review.operation == "CREATE"
+  count(pods) + 1 > input.parameters.maxPods
+  msg := sprintf("maxPods exceeded: %v > %v", [count(pods) + 1, input.parameters.maxPods])
+}
+
+violation[{"msg": msg}] {
+  input.review.operation == "CREATE"
+  used("cpu") > units.parse(input.parameters.maxCPU)
+  msg := sprintf("maxCPU exceeded: %v > %v", [used("cpu"), input.parameters.maxCPU])
+}
+
+violation[{"msg": msg}] {
+  input.review.operation == "CREATE"
+  used("memory") > units.parse(input.parameters.maxMemory)
+  msg := sprintf("maxMemory exceeded: %v > %v", [used("memory"), input.parameters.maxMemory])
+}
+`
+
+// GatekeeperTemplate is the ConstraintTemplate defining ConstraintKind, shared by the
+// constraints of every policy.
+func GatekeeperTemplate() map[string]any {
+	return map[string]any{
+		"apiVersion": "templates.gatekeeper.sh/v1",
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]any{"name": "rqeresourcequota"},
+		"spec": map[string]any{
+			"crd": map[string]any{"spec": map[string]any{
+				"names": map[string]any{"kind": ConstraintKind},
+				"validation": map[string]any{"openAPIV3Schema": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"maxPods":   map[string]any{"type": "integer"},
+						"maxCPU":    map[string]any{"type": "string"},
+						"maxMemory": map[string]any{"type": "string"},
+					},
+				}},
+			}},
+			"targets": []any{map[string]any{
+				"target": "admission.k8s.gatekeeper.sh",
+				"rego":   rego,
+			}},
+		},
+	}
+}
+
+// GatekeeperConstraint renders policy, as in force at now, as a constraint of
+// GatekeeperTemplate. enforce denies the pods over a limit; otherwise they are only reported.
+func GatekeeperConstraint(policy *v1beta1.ResourceQuotaPolicy, now time.Time, enforce bool) map[string]any {
+	p := limits(policy, now)
+	action := "dryrun"
+	if enforce {
+		action = "deny"
+	}
+	return map[string]any{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       ConstraintKind,
+		"metadata": map[string]any{
+			"name":        Name(policy),
+			"annotations": map[string]any{sourceAnnotation: policy.Namespace + "/" + policy.Name},
+		},
+		"spec": map[string]any{
+			"enforcementAction": action,
+			"match": map[string]any{
+				"kinds":      []any{map[string]any{"apiGroups": []any{""}, "kinds": []any{"Pod"}}},
+				"namespaces": []any{policy.Namespace},
+			},
+			"parameters": map[string]any{
+				"maxPods":   int64(p.MaxPods),
+				"maxCPU":    p.MaxCPU.String(),
+				"maxMemory": p.MaxMemory.String(),
+			},
+		},
+	}
+}
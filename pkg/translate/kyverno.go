@@ -0,0 +1,80 @@
+package translate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// activePods filters the pods of an API list response down to those that count toward usage.
+const activePods = "items[?status.phase != 'Succeeded' && status.phase != 'Failed']"
+
+// Kyverno renders policy, as in force at now, as a Kyverno ClusterPolicy with a rule per limit.
+// Each rule lists the namespace's pods through the API server on admission. enforce denies the
+// pods over a limit; otherwise they are only reported.
+func Kyverno(policy *v1beta1.ResourceQuotaPolicy, now time.Time, enforce bool) map[string]any {
+	p := limits(policy, now)
+	action := "Audit"
+	if enforce {
+		action = "Enforce"
+	}
+
+	podsURL := "/api/v1/namespaces/{{request.namespace}}/pods"
+	rule := func(name, context, jmesPath, key string, limit any, message string) map[string]any {
+		return map[string]any{
+			"name": name,
+			"match": map[string]any{"any": []any{map[string]any{"resources": map[string]any{
+				"kinds":      []any{"Pod"},
+				"namespaces": []any{policy.Namespace},
+				"operations": []any{"CREATE"},
+			}}}},
+			"context": []any{map[string]any{
+				"name":    context,
+				"apiCall": map[string]any{"urlPath": podsURL, "jmesPath": jmesPath},
+			}},
+			"validate": map[string]any{
+				"message": message,
+				"deny": map[string]any{"conditions": map[string]any{"any": []any{map[string]any{
+					"key":      key,
+					"operator": "GreaterThan",
+					"value":    limit,
+				}}}},
+			},
+		}
+	}
+	// sum fails on an empty list, which a namespace or pod without requests gives
+	requests := func(resource string) (used, requested string) {
+		return fmt.Sprintf("sum(%s.spec.containers[].resources.requests.%s || ['0'])", activePods, resource),
+			fmt.Sprintf("sum(request.object.spec.containers[].resources.requests.%s || ['0'])", resource)
+	}
+	cpuUsed, cpuRequested := requests("cpu")
+	memoryUsed, memoryRequested := requests("memory")
+
+	return map[string]any{
+		"apiVersion": "kyverno.io/v1",
+		"kind":       "ClusterPolicy",
+		"metadata": map[string]any{
+			"name": Name(policy),
+			"annotations": map[string]any{
+				sourceAnnotation:            policy.Namespace + "/" + policy.Name,
+				"policies.kyverno.io/title": fmt.Sprintf("Resource quota of namespace %s", policy.Namespace),
+			},
+		},
+		"spec": map[string]any{
+			"validationFailureAction": action,
+			"background":              false,
+			"rules": []any{
+				rule("max-pods", "podCount", activePods+" | length(@)",
+					"{{ add(podCount, `1`) }}", int64(p.MaxPods),
+					fmt.Sprintf("maxPods exceeded: namespace %s may run %d pods", policy.Namespace, p.MaxPods)),
+				rule("max-cpu", "cpuUsed", cpuUsed,
+					"{{ add(cpuUsed, "+cpuRequested+") }}", p.MaxCPU.String(),
+					fmt.Sprintf("maxCPU exceeded: pods of namespace %s may request %s cpu", policy.Namespace, p.MaxCPU.String())),
+				rule("max-memory", "memoryUsed", memoryUsed,
+					"{{ add(memoryUsed, "+memoryRequested+") }}", p.MaxMemory.String(),
+					fmt.Sprintf("maxMemory exceeded: pods of namespace %s may request %s memory", policy.Namespace, p.MaxMemory.String())),
+			},
+		},
+	}
+}
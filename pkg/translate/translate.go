@@ -0,0 +1,64 @@
+// Package translate renders ResourceQuotaPolicies as the equivalent admission policies of other
+// policy engines, Kyverno and Gatekeeper, for teams evaluating or migrating between them.
+//
+// Only the namespace-wide maxPods, maxCPU and maxMemory limits translate: both engines deny new
+// pods that would take the namespace past them, counting the pods that have not completed and
+// the CPU and memory their containers request. Neither deletes running pods like the controller
+// does, and the other fields of a policy are reported by Unsupported.
+package translate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+)
+
+// Name is the name of the object translated from policy. Kyverno ClusterPolicies and Gatekeeper
+// constraints are cluster scoped, so it carries the policy's namespace.
+func Name(policy *v1beta1.ResourceQuotaPolicy) string {
+	return fmt.Sprintf("rqe-%s-%s", policy.Namespace, policy.Name)
+}
+
+// sourceAnnotation records the policy an object was translated from.
+const sourceAnnotation = "quota.platform/translated-from"
+
+// Unsupported lists the fields of policy's spec that are set but have no translation.
+func Unsupported(policy *v1beta1.ResourceQuotaPolicy) []string {
+	spec := policy.Spec
+	var fields []string
+	add := func(set bool, field string) {
+		if set {
+			fields = append(fields, field)
+		}
+	}
+	add(spec.ClusterShare != nil, "clusterShare")
+	add(len(spec.ScheduledChanges) > 0, "scheduledChanges")
+	add(spec.SoftLimits != nil, "softLimits")
+	add(len(spec.ValidationRules) > 0, "validationRules")
+	add(spec.ExternalPolicy != nil, "externalPolicy")
+	add(spec.ExcludePods != nil, "excludePods")
+	add(len(spec.MaxExtendedResources) > 0, "maxExtendedResources")
+	add(spec.MissingRequestsPolicy != "", "missingRequestsPolicy")
+	add(spec.DefaultRequests != nil, "defaultRequests")
+	add(spec.UsageSource != "" && spec.UsageSource != v1beta1.UsageSourceRequests, "usageSource")
+	add(len(spec.Subjects) > 0, "subjects")
+	add(spec.NodeScope != nil, "nodeScope")
+	add(spec.MaxReplicasPerWorkload > 0, "maxReplicasPerWorkload")
+	add(spec.AutoscalingCheck != "", "autoscalingCheck")
+	add(spec.MaxActiveJobs > 0, "maxActiveJobs")
+	add(spec.MaxCronJobs > 0, "maxCronJobs")
+	add(spec.MaxLoadBalancers > 0, "maxLoadBalancers")
+	add(spec.MaxNodePorts > 0, "maxNodePorts")
+	add(spec.Impersonate != nil, "impersonate")
+	add(spec.Suspend, "suspend")
+	return fields
+}
+
+// limits are the hard limits of policy at now, defaulted the way the controller and the webhook
+// default them.
+func limits(policy *v1beta1.ResourceQuotaPolicy, now time.Time) handlers.Policy {
+	spec, _ := policy.Spec.EffectiveAt(now)
+	return handlers.ParsePolicy(&spec)
+}
@@ -0,0 +1,86 @@
+package translate
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+func testPolicy() *v1beta1.ResourceQuotaPolicy {
+	cpu := resource.MustParse("4")
+	later := resource.MustParse("8")
+	return &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "quota"},
+		Spec: v1beta1.ResourceQuotaPolicySpec{
+			MaxPods:     20,
+			MaxCPU:      &cpu,
+			SoftLimits:  &v1beta1.SoftLimits{CPUPercent: 80},
+			MaxCronJobs: 2,
+			ScheduledChanges: []v1beta1.ScheduledChange{{
+				EffectiveFrom: metav1.NewTime(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)),
+				MaxCPU:        &later,
+			}},
+		},
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	want := []string{"scheduledChanges", "softLimits", "maxCronJobs"}
+	if got := Unsupported(testPolicy()); !slices.Equal(got, want) {
+		t.Fatalf("Unsupported = %v, want %v", got, want)
+	}
+}
+
+func TestKyvernoUsesLimitsInForce(t *testing.T) {
+	policy := testPolicy()
+	before := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	obj := Kyverno(policy, before, false)
+	spec := obj["spec"].(map[string]any)
+	if spec["validationFailureAction"] != "Audit" {
+		t.Fatalf("validationFailureAction = %v, want Audit", spec["validationFailureAction"])
+	}
+	limit := func(obj map[string]any, rule int) any {
+		r := obj["spec"].(map[string]any)["rules"].([]any)[rule].(map[string]any)
+		cond := r["validate"].(map[string]any)["deny"].(map[string]any)["conditions"].(map[string]any)["any"].([]any)[0]
+		return cond.(map[string]any)["value"]
+	}
+	if got := limit(obj, 0); got != int64(20) {
+		t.Fatalf("maxPods = %v, want 20", got)
+	}
+	if got := limit(obj, 1); got != "4" {
+		t.Fatalf("maxCPU = %v, want 4", got)
+	}
+	// an unset limit gets the API server's default
+	if got := limit(obj, 2); got == "" || got == "0" {
+		t.Fatalf("maxMemory = %v, want the default", got)
+	}
+
+	// the scheduled change is in force after its time
+	if got := limit(Kyverno(policy, before.AddDate(0, 2, 0), true), 1); got != "8" {
+		t.Fatalf("maxCPU after the scheduled change = %v, want 8", got)
+	}
+}
+
+func TestGatekeeperConstraint(t *testing.T) {
+	obj := GatekeeperConstraint(testPolicy(), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), true)
+	if obj["kind"] != ConstraintKind || obj["metadata"].(map[string]any)["name"] != "rqe-team-a-quota" {
+		t.Fatalf("unexpected constraint: %v", obj)
+	}
+	spec := obj["spec"].(map[string]any)
+	if spec["enforcementAction"] != "deny" {
+		t.Fatalf("enforcementAction = %v, want deny", spec["enforcementAction"])
+	}
+	params := spec["parameters"].(map[string]any)
+	if params["maxPods"] != int64(20) || params["maxCPU"] != "4" {
+		t.Fatalf("unexpected parameters: %v", params)
+	}
+	if ns := spec["match"].(map[string]any)["namespaces"].([]any); len(ns) != 1 || ns[0] != "team-a" {
+		t.Fatalf("constraint matches namespaces %v, want [team-a]", ns)
+	}
+}
@@ -0,0 +1,20 @@
+// Package version reports the version of the running binary.
+package version
+
+import "runtime/debug"
+
+// Version is set when building a release, with
+// -ldflags "-X github.com/sri2103/resource-quota-enforcer/pkg/version.Version=v1.2.3".
+var Version = ""
+
+// Get returns Version, or else the version of the module the binary was built from, or "dev"
+// for a build from a source tree.
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -3,13 +3,18 @@ package webhook
 import (
 	"context"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestEvaluatePodAgainstPolicy_PodsLimit(t *testing.T) {
@@ -28,7 +33,7 @@ func TestEvaluatePodAgainstPolicy_PodsLimit(t *testing.T) {
 	srv := &WebhookServer{Clientset: cs}
 	// policy: maxPods = 2
 	// spec := map[string]interface{}{"maxPods": int64(2)}
-	spec := v1alpha1.ResourceQuotaPolicySpec{
+	spec := v1beta1.ResourceQuotaPolicySpec{
 		MaxPods: 2,
 	}
 	pod := &corev1.Pod{
@@ -59,3 +64,222 @@ func TestEvaluatePodAgainstPolicy_PodsLimit(t *testing.T) {
 		t.Fatalf("expected reason message")
 	}
 }
+
+func TestEvaluatePodAgainstPolicy_ValidationRules(t *testing.T) {
+	cs := fakeclient.NewSimpleClientset()
+	srv := &WebhookServer{Clientset: cs}
+	spec := v1beta1.ResourceQuotaPolicySpec{
+		ValidationRules: []v1beta1.ValidationRule{{
+			Expression: "pod.spec.containers.all(c, has(c.resources.requests))",
+			Message:    "every container must set requests",
+		}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-pod", Namespace: "test-ns"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "c", Image: "busybox"}},
+		},
+	}
+
+	allowed, reason, err := srv.evaluatePodAgainstPolicy(context.TODO(), pod, "test-ns", &spec)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if allowed || reason != "every container must set requests" {
+		t.Fatalf("expected denial by rule, got allowed=%v reason=%q", allowed, reason)
+	}
+
+	pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+	allowed, _, err = srv.evaluatePodAgainstPolicy(context.TODO(), pod, "test-ns", &spec)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !allowed {
+		t.Fatalf("expected pod with requests to be allowed")
+	}
+}
+
+func TestEvaluatePodAgainstPolicy_ExcludesDaemonSetPods(t *testing.T) {
+	ns := "test-ns"
+	isController := true
+	dsPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "node-agent", Controller: &isController},
+				},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+		}
+	}
+	cs := fakeclient.NewSimpleClientset(dsPod("agent-1"))
+	plain := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+	}
+
+	exclude := true
+	spec := v1beta1.ResourceQuotaPolicySpec{
+		MaxPods:     1,
+		ExcludePods: &v1beta1.PodExclusions{DaemonSetPods: &exclude},
+	}
+	srv := &WebhookServer{Clientset: cs}
+	if allowed, reason, err := srv.evaluatePodAgainstPolicy(context.TODO(), plain, ns, &spec); err != nil || !allowed {
+		t.Fatalf("existing DaemonSet pod should not count: allowed=%v reason=%q err=%v", allowed, reason, err)
+	}
+
+	// the --exclude-daemonset-pods default applies unless the policy overrides it
+	srv.Exclude = handlers.Exclusions{DaemonSetPods: true}
+	exclude = false
+	if allowed, _, _ := srv.evaluatePodAgainstPolicy(context.TODO(), plain, ns, &spec); allowed {
+		t.Fatal("policy override of the exclusion default was ignored")
+	}
+}
+
+func TestEvaluatePodAgainstPolicy_QuotaExemptions(t *testing.T) {
+	ns := "test-ns"
+	pod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+		}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	exemption := &v1beta1.QuotaExemption{
+		ObjectMeta: metav1.ObjectMeta{Name: "load-test", Namespace: ns},
+		Spec: v1beta1.QuotaExemptionSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "loadgen"}},
+			ExpiresAt:   metav1.NewTime(time.Now().Add(time.Hour)),
+		},
+	}
+	if err := indexer.Add(exemption); err != nil {
+		t.Fatal(err)
+	}
+	srv := &WebhookServer{
+		Clientset:  fakeclient.NewSimpleClientset(pod("loadgen-1", "loadgen")),
+		Exemptions: listers.NewQuotaExemptionLister(indexer),
+	}
+	spec := v1beta1.ResourceQuotaPolicySpec{MaxPods: 1}
+
+	// the exempt pod neither counts nor is denied
+	if allowed, reason, err := srv.evaluatePodAgainstPolicy(context.TODO(), pod("web", "web"), ns, &spec); err != nil || !allowed {
+		t.Fatalf("exempt pod should not count: allowed=%v reason=%q err=%v", allowed, reason, err)
+	}
+
+	exemption.Spec.ExpiresAt = metav1.NewTime(time.Now().Add(-time.Minute))
+	if allowed, _, _ := srv.evaluatePodAgainstPolicy(context.TODO(), pod("web", "web"), ns, &spec); allowed {
+		t.Fatal("expired exemption still applies")
+	}
+}
+
+func TestEvaluatePodAgainstPolicy_MissingRequests(t *testing.T) {
+	ns := "test-ns"
+	bestEffort := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+		}
+	}
+	cs := fakeclient.NewSimpleClientset(bestEffort("p1"), bestEffort("p2"))
+	srv := &WebhookServer{Clientset: cs}
+
+	maxCPU := resource.MustParse("250m")
+	spec := v1beta1.ResourceQuotaPolicySpec{MaxCPU: &maxCPU}
+	if allowed, reason, err := srv.evaluatePodAgainstPolicy(context.TODO(), bestEffort("new"), ns, &spec); err != nil || !allowed {
+		t.Fatalf("without a policy missing requests count as zero: allowed=%v reason=%q err=%v", allowed, reason, err)
+	}
+
+	// three BestEffort pods at the 100m default exceed 250m
+	spec.MissingRequestsPolicy = v1beta1.MissingRequestsAssumeDefaults
+	if allowed, _, _ := srv.evaluatePodAgainstPolicy(context.TODO(), bestEffort("new"), ns, &spec); allowed {
+		t.Fatal("assumeDefaults should count each container at the default request")
+	}
+	small := resource.MustParse("50m")
+	spec.DefaultRequests = &v1beta1.DefaultRequests{CPU: &small}
+	if allowed, reason, _ := srv.evaluatePodAgainstPolicy(context.TODO(), bestEffort("new"), ns, &spec); !allowed {
+		t.Fatalf("spec.defaultRequests should override the default: %s", reason)
+	}
+
+	spec.MissingRequestsPolicy = v1beta1.MissingRequestsDeny
+	if allowed, reason, _ := srv.evaluatePodAgainstPolicy(context.TODO(), bestEffort("new"), ns, &spec); allowed || !strings.Contains(reason, "missing requests") {
+		t.Fatalf("deny should reject pods without requests: allowed=%v reason=%q", allowed, reason)
+	}
+}
+
+func TestEvaluateEphemeral(t *testing.T) {
+	ns := "test-ns"
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "c",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	srv := &WebhookServer{Clientset: fakeclient.NewSimpleClientset(running)}
+	debugged := running.DeepCopy()
+	debugged.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"},
+	}}
+
+	maxCPU := resource.MustParse("250m")
+	spec := v1beta1.ResourceQuotaPolicySpec{MaxPods: 1, MaxCPU: &maxCPU}
+	if v, err := srv.evaluateEphemeral(context.TODO(), running, debugged, ns, &spec); err != nil || !v.allowed {
+		t.Fatalf("a debug container without requests should fit and not count as a pod: allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+
+	// counted at the 100m default, the debug container takes the namespace to 300m
+	spec.MissingRequestsPolicy = v1beta1.MissingRequestsAssumeDefaults
+	v, err := srv.evaluateEphemeral(context.TODO(), running, debugged, ns, &spec)
+	if err != nil || v.allowed || !strings.Contains(v.reason, "cpu exceeded") {
+		t.Fatalf("expected cpu denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+
+	// containers that were already there are not evaluated again
+	if v, _ := srv.evaluateEphemeral(context.TODO(), debugged, debugged, ns, &spec); !v.allowed {
+		t.Fatal("update without new ephemeral containers was denied")
+	}
+}
+
+func TestEvaluateResize(t *testing.T) {
+	ns := "test-ns"
+	withCPU := func(name, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "c",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	srv := &WebhookServer{Clientset: fakeclient.NewSimpleClientset(withCPU("web", "200m"), withCPU("batch", "400m"))}
+	maxCPU := resource.MustParse("1")
+	spec := v1beta1.ResourceQuotaPolicySpec{MaxCPU: &maxCPU}
+
+	if v, err := srv.evaluateResize(context.TODO(), withCPU("web", "200m"), withCPU("web", "600m"), ns, &spec); err != nil || !v.allowed {
+		t.Fatalf("resize to exactly the limit denied: allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	v, err := srv.evaluateResize(context.TODO(), withCPU("web", "200m"), withCPU("web", "700m"), ns, &spec)
+	if err != nil || v.allowed || !strings.Contains(v.reason, "cpu exceeded: 1100m > 1") {
+		t.Fatalf("expected cpu denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	// the resize adds 500m to the 600m the namespace uses
+	if msg := v.status("denied").Message; !strings.Contains(msg, "(requested 500m, used 600m of 1, 400m remaining)") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+
+	// shrinking is allowed even when the namespace is already over quota
+	maxCPU = resource.MustParse("500m")
+	if v, _ := srv.evaluateResize(context.TODO(), withCPU("web", "200m"), withCPU("web", "100m"), ns, &spec); !v.allowed {
+		t.Fatal("shrinking resize was denied")
+	}
+}
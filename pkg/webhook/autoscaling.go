@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// autoscaler holds the fields of a HorizontalPodAutoscaler read here, which autoscaling/v1 and
+// v2 share.
+type autoscaler struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ScaleTargetRef struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Name       string `json:"name"`
+		} `json:"scaleTargetRef"`
+		MaxReplicas int32 `json:"maxReplicas"`
+	} `json:"spec"`
+}
+
+// targets reports whether a targets the apps/v1 workload kind/name.
+func (a *autoscaler) targets(kind, name string) bool {
+	ref := a.Spec.ScaleTargetRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	return err == nil && gv.Group == "apps" && ref.Kind == kind && ref.Name == name
+}
+
+// autoscalerFor returns the HorizontalPodAutoscaler of namespace targeting the workload
+// kind/name, or nil if there is none.
+func (s *WebhookServer) autoscalerFor(ctx context.Context, namespace, kind, name string) (*autoscaler, error) {
+	list, err := s.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, hpa := range list.Items {
+		a := &autoscaler{ObjectMeta: hpa.ObjectMeta}
+		a.Spec.ScaleTargetRef.APIVersion = hpa.Spec.ScaleTargetRef.APIVersion
+		a.Spec.ScaleTargetRef.Kind = hpa.Spec.ScaleTargetRef.Kind
+		a.Spec.ScaleTargetRef.Name = hpa.Spec.ScaleTargetRef.Name
+		a.Spec.MaxReplicas = hpa.Spec.MaxReplicas
+		if a.targets(kind, name) {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// autoscalingExceeded explains why hpa scaling current replicas of pod to its maxReplicas would
+// exceed spec, or returns "" when it wouldn't.
+func (s *WebhookServer) autoscalingExceeded(ctx context.Context, spec *platformv1beta1.ResourceQuotaPolicySpec, namespace string, hpa *autoscaler, pod *corev1.Pod, current int32) (string, error) {
+	if hpa.Spec.MaxReplicas <= current {
+		return "", nil
+	}
+	if reason := replicasExceeded(spec, hpa.Spec.MaxReplicas); reason != "" {
+		return reason, nil
+	}
+	v, err := s.evaluateScale(ctx, pod, int(hpa.Spec.MaxReplicas-current), namespace, spec)
+	if err != nil || v.allowed {
+		return "", err
+	}
+	return v.reason, nil
+}
+
+// checkAutoscaling runs the autoscaling check of spec on the workload kind/name, whose pods
+// look like pod and of which current replicas exist, and sets the response of review to deny
+// it or to warn.
+func (s *WebhookServer) checkAutoscaling(ctx context.Context, review *admissionv1.AdmissionReview, policy string, spec *platformv1beta1.ResourceQuotaPolicySpec, hpa *autoscaler, kind, name string, pod *corev1.Pod, current int32) error {
+	reason, err := s.autoscalingExceeded(ctx, spec, review.Request.Namespace, hpa, pod, current)
+	if err != nil || reason == "" {
+		return err
+	}
+	message := fmt.Sprintf("HorizontalPodAutoscaler %s can scale %s %s to %d replicas, which exceeds QuotaPolicy %s: %s",
+		hpa.Name, kind, name, hpa.Spec.MaxReplicas, policy, reason)
+	logger := logging.L().WithName("webhook").WithValues("namespace", review.Request.Namespace, "kind", kind, "name", name, "autoscaler", hpa.Name, "policy", policy)
+	if spec.AutoscalingCheck == platformv1beta1.AutoscalingCheckDeny {
+		logger.Info("Denied workload that can autoscale past quota", "reason", reason)
+		s.denyObject(review, policy, reason, message)
+		return nil
+	}
+	logger.Info("Workload can autoscale past quota", "reason", reason)
+	review.Response.Warnings = append(review.Response.Warnings, message)
+	return nil
+}
+
+// validateAutoscaler handles the CREATE and UPDATE of a HorizontalPodAutoscaler, checking the
+// workload it targets at its maxReplicas. Updates that neither raise maxReplicas nor change the
+// target pass.
+func (s *WebhookServer) validateAutoscaler(ctx context.Context, w http.ResponseWriter, review *admissionv1.AdmissionReview) {
+	req := review.Request
+	logger := logging.L().WithName("webhook").WithValues("namespace", req.Namespace, "autoscaler", req.Name)
+	var hpa autoscaler
+	if err := json.Unmarshal(req.Object.Raw, &hpa); err != nil {
+		logger.Error(err, "Failed to decode autoscaler, allowing")
+		writeAdmissionResponse(w, review)
+		return
+	}
+	if req.Operation == admissionv1.Update {
+		var old autoscaler
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err == nil &&
+			hpa.Spec.MaxReplicas <= old.Spec.MaxReplicas && hpa.Spec.ScaleTargetRef == old.Spec.ScaleTargetRef {
+			writeAdmissionResponse(w, review)
+			return
+		}
+	}
+
+	policy, effective := s.policyFor(ctx, req.Namespace)
+	if policy == nil || effective.AutoscalingCheck == "" {
+		writeAdmissionResponse(w, review)
+		return
+	}
+	ref := hpa.Spec.ScaleTargetRef
+	if !hpa.targets(ref.Kind, ref.Name) {
+		writeAdmissionResponse(w, review)
+		return
+	}
+	hpa.Name = req.Name
+	pod, current, err := s.scaleTarget(ctx, req.Namespace, ref.Kind, ref.Name)
+	if err == nil && pod != nil {
+		err = s.checkAutoscaling(ctx, review, policy.Name, effective, &hpa, ref.Kind, ref.Name, pod, current)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Failed to check autoscaler, allowing")
+	}
+	writeAdmissionResponse(w, review)
+}
+
+// workloadPod returns a pod as the serialized apps/v1 workload raw creates them.
+func workloadPod(raw []byte, namespace string) (*corev1.Pod, error) {
+	var obj struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	pod := &corev1.Pod{ObjectMeta: obj.Spec.Template.ObjectMeta, Spec: obj.Spec.Template.Spec}
+	pod.Namespace = namespace
+	return pod, nil
+}
+
+// templateChanged reports whether the pod template of an update of a serialized workload
+// changed.
+func templateChanged(oldRaw, raw []byte) bool {
+	oldPod, err := workloadPod(oldRaw, "")
+	if err != nil {
+		return true
+	}
+	pod, err := workloadPod(raw, "")
+	if err != nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldPod.Spec, pod.Spec)
+}
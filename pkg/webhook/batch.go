@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// HandleValidateBatch handles AdmissionReview v1 for Job and CronJob CREATE and UPDATE
+// operations. It denies Jobs that would take the namespace over maxActiveJobs, when created or
+// resumed, and CronJobs past maxCronJobs.
+func (s *WebhookServer) HandleValidateBatch(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.SubResource != "" || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ns := req.Namespace
+	logger := logging.L().WithName("webhook").WithValues("namespace", ns, "kind", req.Kind.Kind, "name", req.Name)
+	var check func(context.Context, *platformv1beta1.ResourceQuotaPolicySpec) (string, error)
+	switch req.Kind.Kind {
+	case "Job":
+		var job, oldJob batchv1.Job
+		if err := json.Unmarshal(req.Object.Raw, &job); err != nil {
+			logger.Error(err, "Failed to decode job, allowing")
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		if req.Operation == admissionv1.Update {
+			if err := json.Unmarshal(req.OldObject.Raw, &oldJob); err != nil || handlers.JobActive(&oldJob) {
+				// only resuming a suspended job makes it active
+				writeAdmissionResponse(w, &admissionReview)
+				return
+			}
+		}
+		if !handlers.JobActive(&job) {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		check = func(ctx context.Context, spec *platformv1beta1.ResourceQuotaPolicySpec) (string, error) {
+			return s.activeJobsExceeded(ctx, ns, spec)
+		}
+	case "CronJob":
+		if req.Operation != admissionv1.Create {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		check = func(ctx context.Context, spec *platformv1beta1.ResourceQuotaPolicySpec) (string, error) {
+			return s.cronJobsExceeded(ctx, ns, spec)
+		}
+	default:
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.admissionDeadline(r))
+	defer cancel()
+	policy, effective := s.policyFor(ctx, ns)
+	if policy == nil {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	reason, err := check(ctx, effective)
+	if err != nil {
+		logger.Error(err, "Failed to count batch workloads, allowing", "policy", policy.Name)
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	if reason == "" {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	logger.Info("Denied batch workload", "policy", policy.Name, "reason", reason)
+	s.denyObject(&admissionReview, policy.Name, reason, fmt.Sprintf("%s %s denied by QuotaPolicy: %s", req.Kind.Kind, req.Name, reason))
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+// activeJobsExceeded explains why one more active Job would take namespace over the
+// maxActiveJobs of spec, or returns "" when it fits.
+func (s *WebhookServer) activeJobsExceeded(ctx context.Context, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (string, error) {
+	if spec.MaxActiveJobs <= 0 {
+		return "", nil
+	}
+	jobs, err := s.Clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	active := int32(1)
+	for i := range jobs.Items {
+		if handlers.JobActive(&jobs.Items[i]) {
+			active++
+		}
+	}
+	if active <= spec.MaxActiveJobs {
+		return "", nil
+	}
+	return fmt.Sprintf("maxActiveJobs exceeded: %d > %d", active, spec.MaxActiveJobs), nil
+}
+
+// cronJobsExceeded explains why one more CronJob would take namespace over the maxCronJobs of
+// spec, or returns "" when it fits.
+func (s *WebhookServer) cronJobsExceeded(ctx context.Context, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (string, error) {
+	if spec.MaxCronJobs <= 0 {
+		return "", nil
+	}
+	cronJobs, err := s.Clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	if count := int32(len(cronJobs.Items)) + 1; count > spec.MaxCronJobs {
+		return fmt.Sprintf("maxCronJobs exceeded: %d > %d", count, spec.MaxCronJobs), nil
+	}
+	return "", nil
+}
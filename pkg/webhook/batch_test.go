@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleValidateBatch(t *testing.T) {
+	ns := "batch"
+	job := func(name string, suspend bool) *batchv1.Job {
+		return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}, Spec: batchv1.JobSpec{Suspend: &suspend}}
+	}
+	srv := &WebhookServer{
+		Clientset: fakeclient.NewSimpleClientset(job("running", false), job("parked", true), &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: ns}}),
+		Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxActiveJobs: 1, MaxCronJobs: 2},
+		}},
+	}
+	raw := func(obj any) runtime.RawExtension {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+
+	tests := []struct {
+		name    string
+		kind    string
+		op      admissionv1.Operation
+		obj     any
+		old     any
+		wantErr string
+	}{
+		{name: "job over maxActiveJobs", kind: "Job", op: admissionv1.Create, obj: job("new", false), wantErr: "maxActiveJobs exceeded: 2 > 1"},
+		{name: "suspended job", kind: "Job", op: admissionv1.Create, obj: job("new", true)},
+		{name: "resuming job", kind: "Job", op: admissionv1.Update, obj: job("parked", false), old: job("parked", true), wantErr: "maxActiveJobs exceeded"},
+		{name: "updating active job", kind: "Job", op: admissionv1.Update, obj: job("running", false), old: job("running", false)},
+		{name: "cronjob within limit", kind: "CronJob", op: admissionv1.Create, obj: &batchv1.CronJob{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: tt.kind},
+				Namespace: ns,
+				Name:      "new",
+				Operation: tt.op,
+				Object:    raw(tt.obj),
+			}
+			if tt.old != nil {
+				req.OldObject = raw(tt.old)
+			}
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: req})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			srv.HandleValidateBatch(rec, jsonRequest("/validate-batch", body))
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if allowed := tt.wantErr == ""; review.Response.Allowed != allowed {
+				t.Fatalf("allowed = %v, want %v (%+v)", review.Response.Allowed, allowed, review.Response.Result)
+			}
+			if tt.wantErr != "" && !strings.Contains(review.Response.Result.Message, tt.wantErr) {
+				t.Fatalf("message %q does not contain %q", review.Response.Result.Message, tt.wantErr)
+			}
+		})
+	}
+}
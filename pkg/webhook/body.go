@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// maxBodyBytes bounds request bodies. An AdmissionReview carries the object and, for updates,
+// the old object, each at most the API server's 3MiB object size limit.
+const maxBodyBytes = 7 << 20
+
+// decodeBody decodes the JSON body of r into v, describing it as what in errors. When it
+// returns false the request has been answered: 415 for a Content-Type other than
+// application/json, 413 for a body over maxBodyBytes and 400 for anything else that doesn't
+// decode.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any, what string) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, fmt.Sprintf("%s larger than %d bytes", what, tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return false
+	case err != nil:
+		http.Error(w, fmt.Sprintf("could not decode %s: %v", what, err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
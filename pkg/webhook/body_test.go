@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// jsonRequest builds a POST of body as the API server sends it.
+func jsonRequest(url string, body []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestDecodeBody(t *testing.T) {
+	srv := &WebhookServer{Cache: staticCache{}}
+	oversized := `{"namespace":"` + strings.Repeat("a", maxBodyBytes) + `"}`
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"valid", "application/json", `{"namespace":"ns1"}`, http.StatusOK},
+		{"charset parameter", "application/json; charset=utf-8", `{"namespace":"ns1"}`, http.StatusOK},
+		{"missing content type", "", `{"namespace":"ns1"}`, http.StatusUnsupportedMediaType},
+		{"wrong content type", "text/plain", `{"namespace":"ns1"}`, http.StatusUnsupportedMediaType},
+		{"malformed", "application/json", `{"namespace":`, http.StatusBadRequest},
+		{"oversized", "application/json", oversized, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mutate", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			srv.InvalidateHandler(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
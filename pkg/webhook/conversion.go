@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// HandleConvert serves ConversionReview requests for the ResourceQuotaPolicy CRD.
+func (s *WebhookServer) HandleConvert(w http.ResponseWriter, r *http.Request) {
+	var review apiextensionsv1.ConversionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, "could not decode conversion review", http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "no conversion request", http.StatusBadRequest)
+		return
+	}
+
+	resp := &apiextensionsv1.ConversionResponse{UID: review.Request.UID}
+	for _, obj := range review.Request.Objects {
+		converted, err := convertPolicy(obj.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	if resp.Result.Status == "" {
+		resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	}
+
+	review.Request = nil
+	review.Response = resp
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&review)
+}
+
+// convertPolicy converts a serialized ResourceQuotaPolicy to the desired apiVersion, going
+// through the v1beta1 hub.
+func convertPolicy(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var meta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("decode object: %w", err)
+	}
+	if meta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	hub := &platformv1beta1.ResourceQuotaPolicy{}
+	switch meta.APIVersion {
+	case platformv1beta1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, hub); err != nil {
+			return nil, err
+		}
+	case platformv1alpha1.SchemeGroupVersion.String():
+		src := &platformv1alpha1.ResourceQuotaPolicy{}
+		if err := json.Unmarshal(raw, src); err != nil {
+			return nil, err
+		}
+		if err := src.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported source version %q", meta.APIVersion)
+	}
+
+	switch desiredAPIVersion {
+	case platformv1beta1.SchemeGroupVersion.String():
+		hub.APIVersion = desiredAPIVersion
+		hub.Kind = "ResourceQuotaPolicy"
+		return json.Marshal(hub)
+	case platformv1alpha1.SchemeGroupVersion.String():
+		dst := &platformv1alpha1.ResourceQuotaPolicy{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		return json.Marshal(dst)
+	default:
+		return nil, fmt.Errorf("unsupported target version %q", desiredAPIVersion)
+	}
+}
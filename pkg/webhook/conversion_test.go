@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertPolicy_RoundTrip(t *testing.T) {
+	hub := v1beta1.ResourceQuotaPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "platform.example.com/v1beta1", Kind: "ResourceQuotaPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "ns1"},
+		Spec: v1beta1.ResourceQuotaPolicySpec{
+			MaxPods: 3,
+			MaxCPU:  resource.NewMilliQuantity(500, resource.DecimalSI),
+			SoftLimits: &v1beta1.SoftLimits{
+				CPUPercent: 80,
+			},
+		},
+	}
+	raw, _ := json.Marshal(hub)
+
+	alphaRaw, err := convertPolicy(raw, "platform.example.com/v1alpha1")
+	if err != nil {
+		t.Fatalf("convert to v1alpha1: %v", err)
+	}
+	var alpha v1alpha1.ResourceQuotaPolicy
+	if err := json.Unmarshal(alphaRaw, &alpha); err != nil {
+		t.Fatalf("decode v1alpha1: %v", err)
+	}
+	if alpha.APIVersion != "platform.example.com/v1alpha1" || alpha.Spec.MaxPods != 3 || alpha.Spec.MaxCPU != "500m" {
+		t.Fatalf("unexpected v1alpha1 object: %+v", alpha)
+	}
+
+	// a v1alpha1 client edits a shared field
+	alpha.Spec.MaxPods = 5
+	alphaRaw, _ = json.Marshal(alpha)
+
+	betaRaw, err := convertPolicy(alphaRaw, "platform.example.com/v1beta1")
+	if err != nil {
+		t.Fatalf("convert to v1beta1: %v", err)
+	}
+	var back v1beta1.ResourceQuotaPolicy
+	if err := json.Unmarshal(betaRaw, &back); err != nil {
+		t.Fatalf("decode v1beta1: %v", err)
+	}
+	if back.Spec.MaxPods != 5 {
+		t.Fatalf("expected edited maxPods to win, got %d", back.Spec.MaxPods)
+	}
+	if back.Spec.SoftLimits == nil || back.Spec.SoftLimits.CPUPercent != 80 {
+		t.Fatalf("expected soft limits to survive the round trip, got %+v", back.Spec.SoftLimits)
+	}
+	if _, ok := back.Annotations[v1alpha1.HubSpecAnnotation]; ok {
+		t.Fatalf("hub spec annotation must not leak into v1beta1 objects")
+	}
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// What happens to pods in namespaces without a ResourceQuotaPolicy.
+const (
+	DefaultActionAllow = "allow"
+	DefaultActionDeny  = "deny"
+)
+
+// ParseDefaultAction validates the value of the -default-action flag.
+func ParseDefaultAction(action string) (string, error) {
+	switch action {
+	case DefaultActionAllow, DefaultActionDeny:
+		return action, nil
+	case "":
+		return DefaultActionAllow, nil
+	}
+	return "", fmt.Errorf("unknown default action %q, must be %q or %q", action, DefaultActionAllow, DefaultActionDeny)
+}
+
+// withoutPolicy decides on an admission into namespace, which has no policy of its own. It
+// returns the cluster default policy to evaluate the admission against, or, when there is none,
+// whether new pods are denied. Namespaces annotated with v1beta1.AnnotationDefaultAction=allow get
+// neither.
+func (s *WebhookServer) withoutPolicy(ctx context.Context, namespace string) (*platformv1beta1.ResourceQuotaPolicy, bool, error) {
+	if s.DefaultPolicy == nil && s.DefaultAction != DefaultActionDeny {
+		return nil, false, nil
+	}
+	ns, err := s.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		// nothing can be created in a namespace that doesn't exist anyway
+	case err != nil:
+		return nil, false, err
+	case platformv1beta1.ExemptFromDefaults(ns.Annotations):
+		return nil, false, nil
+	}
+	if s.DefaultPolicy != nil {
+		return s.DefaultPolicy, false, nil
+	}
+	return nil, s.DefaultAction == DefaultActionDeny, nil
+}
+
+// noPolicyReason explains denials of admissions into namespaces without a policy.
+func noPolicyReason(namespace string) string {
+	return fmt.Sprintf("namespace %s has no ResourceQuotaPolicy and this cluster requires one (set the %s=%s annotation on the namespace to opt out)",
+		namespace, platformv1beta1.AnnotationDefaultAction, DefaultActionAllow)
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleValidatePodsWithoutPolicy(t *testing.T) {
+	cs := fakeclient.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "kube-system",
+			Annotations: map[string]string{v1beta1.AnnotationDefaultAction: DefaultActionAllow},
+		}},
+	)
+	// no pod fits the default policy
+	fallback := &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-default"},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{ValidationRules: []v1beta1.ValidationRule{{Expression: "false", Message: "default policy"}}},
+	}
+	tests := []struct {
+		action, namespace string
+		fallback          *v1beta1.ResourceQuotaPolicy
+		allowed, exempt   bool
+	}{
+		{DefaultActionAllow, "tenant", nil, true, false},
+		{DefaultActionDeny, "tenant", nil, false, false},
+		{DefaultActionDeny, "kube-system", nil, true, false},
+		{DefaultActionAllow, "tenant", fallback, false, false},
+		{DefaultActionAllow, "kube-system", fallback, true, false},
+		{DefaultActionDeny, "tenant", fallback, true, true},
+	}
+	for _, tt := range tests {
+		pod, _ := json.Marshal(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: tt.namespace}})
+		body, _ := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+			UID:       "uid",
+			Namespace: tt.namespace,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: pod},
+		}})
+		srv := &WebhookServer{Clientset: cs, Cache: staticCache{}, DefaultAction: tt.action, DefaultPolicy: tt.fallback}
+		if tt.exempt {
+			srv.Exempt = &handlers.ExemptNamespaces{}
+			if err := srv.Exempt.Set(tt.namespace); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rec := httptest.NewRecorder()
+		srv.HandleValidatePods(rec, jsonRequest("/validate", body))
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+			t.Fatal(err)
+		}
+		if review.Response.Allowed != tt.allowed {
+			t.Errorf("%s in %s: allowed = %v, want %v", tt.action, tt.namespace, review.Response.Allowed, tt.allowed)
+		}
+		want := "denied_no_policy"
+		if tt.fallback != nil {
+			want = "denied"
+		}
+		if !tt.allowed && review.Response.AuditAnnotations["decision"] != want {
+			t.Errorf("%s in %s: audit annotations = %v", tt.action, tt.namespace, review.Response.AuditAnnotations)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// exclusions returns the pods a policy of namespace with spec leaves out: the cluster-wide
+// defaults as overridden by spec, and the pods of the namespace's QuotaExemptions in force.
+func (s *WebhookServer) exclusions(namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) handlers.Exclusions {
+	x := s.Exclude.With(spec.ExcludePods)
+	if s.Exemptions == nil {
+		return x
+	}
+	items, err := s.Exemptions.QuotaExemptions(namespace).List(labels.Everything())
+	if err != nil {
+		logging.L().WithName("webhook").Error(err, "Failed to list QuotaExemptions, exempting no pods", "namespace", namespace)
+		return x
+	}
+	if x.Exemptions, err = handlers.NewExemptions(items, time.Now()); err != nil {
+		logging.L().WithName("webhook").Error(err, "Ignoring invalid QuotaExemptions", "namespace", namespace)
+	}
+	return x
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	corev1 "k8s.io/api/core/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+)
+
+var metricExternalDecisions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: NameExternalDecisions,
+		Help: "Admission decisions asked of external policy endpoints, by result",
+	}, []string{"result"},
+)
+
+// externalInput is the input document an ExternalPolicy gets.
+type externalInput struct {
+	Namespace string      `json:"namespace"`
+	Pod       *corev1.Pod `json:"pod"`
+	// Usage is the namespace's usage before the pod.
+	Usage  audit.Usage                              `json:"usage"`
+	Policy *platformv1beta1.ResourceQuotaPolicySpec `json:"policy"`
+	// Decision is what the webhook decides without the endpoint.
+	Decision externalDecision `json:"decision"`
+}
+
+// externalDecision is an allow or deny, with the reason for a denial.
+type externalDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON accepts a bare boolean as well as the object form.
+func (d *externalDecision) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Allow); err == nil {
+		return nil
+	}
+	type plain externalDecision
+	var p struct {
+		plain
+		Allow *bool `json:"allow"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Allow == nil {
+		return errors.New("decision has no boolean allow")
+	}
+	*d = externalDecision{Allow: *p.Allow, Reason: p.Reason}
+	return nil
+}
+
+// errNoDecision is returned for a response without a result, which OPA sends when the
+// decision is undefined, e.g. because the URL names no rule.
+var errNoDecision = errors.New("response has no result")
+
+// decideExternally returns the decision ext takes on pod, given v, the webhook's own verdict.
+// When ext can't be asked, its FailurePolicy decides.
+func (s *WebhookServer) decideExternally(ctx context.Context, ext *platformv1beta1.ExternalPolicy, namespace string, pod *corev1.Pod, spec *platformv1beta1.ResourceQuotaPolicySpec, v verdict) verdict {
+	ctx, span := tracing.Tracer().Start(ctx, "admission.external")
+	defer span.End()
+
+	decision, err := s.askExternal(ctx, ext.URL, externalInput{
+		Namespace: namespace,
+		Pod:       pod,
+		Usage:     v.usage,
+		Policy:    spec,
+		Decision:  externalDecision{Allow: v.allowed, Reason: v.reason},
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		metricExternalDecisions.WithLabelValues("error").Inc()
+		logging.L().WithName("webhook").Error(err, "External policy failed", "namespace", namespace, "url", ext.URL, "failurePolicy", ext.FailurePolicy)
+		if ext.FailurePolicy == platformv1beta1.ExternalFailureFail {
+			return deny(fmt.Sprintf("external policy unavailable: %v", err), v.usage)
+		}
+		return v
+	}
+	span.SetAttributes(attribute.Bool("rqe.external.allow", decision.Allow))
+
+	if decision.Allow {
+		metricExternalDecisions.WithLabelValues("allowed").Inc()
+		return allow(v.usage)
+	}
+	metricExternalDecisions.WithLabelValues("denied").Inc()
+	if decision.Reason == "" {
+		if !v.allowed {
+			// agreeing with the webhook keeps the details of the limit that was exceeded
+			return v
+		}
+		decision.Reason = "denied by external policy"
+	}
+	return deny(decision.Reason, v.usage)
+}
+
+// askExternal POSTs input to url the way OPA's data API expects it and returns the decision in
+// the result of the response.
+func (s *WebhookServer) askExternal(ctx context.Context, url string, input externalInput) (externalDecision, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return externalDecision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return externalDecision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.ExternalClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return externalDecision{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return externalDecision{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var out struct {
+		Result *externalDecision `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return externalDecision{}, fmt.Errorf("decode response: %w", err)
+	}
+	if out.Result == nil {
+		return externalDecision{}, errNoDecision
+	}
+	return *out.Result, nil
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEvaluateExternalPolicy(t *testing.T) {
+	ns := "test-ns"
+	cs := fakeclient.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: ns},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+	})
+	srv := &WebhookServer{Clientset: cs}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-pod", Namespace: ns},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+	}
+
+	tests := []struct {
+		name          string
+		maxPods       int32
+		status        int
+		response      string
+		failurePolicy v1beta1.ExternalFailurePolicy
+		wantAllowed   bool
+		wantReason    string
+	}{
+		{name: "allows over quota", maxPods: 1, response: `{"result": true}`, wantAllowed: true},
+		{name: "denies within quota", maxPods: 5, response: `{"result": {"allow": false, "reason": "no pods on fridays"}}`, wantReason: "no pods on fridays"},
+		{name: "denies without reason", maxPods: 5, response: `{"result": false}`, wantReason: "denied by external policy"},
+		{name: "undefined keeps own decision", maxPods: 1, response: `{}`, wantReason: "maxPods exceeded: 2 > 1"},
+		{name: "error ignored", maxPods: 5, status: http.StatusInternalServerError, wantAllowed: true},
+		{name: "error fails", maxPods: 5, status: http.StatusInternalServerError, failurePolicy: v1beta1.ExternalFailureFail, wantReason: "external policy unavailable: unexpected status 500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input externalInput
+			opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input externalInput `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				input = body.Input
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer opa.Close()
+
+			spec := v1beta1.ResourceQuotaPolicySpec{
+				MaxPods:        tt.maxPods,
+				ExternalPolicy: &v1beta1.ExternalPolicy{URL: opa.URL, FailurePolicy: tt.failurePolicy},
+			}
+			allowed, reason, err := srv.evaluatePodAgainstPolicy(context.TODO(), pod, ns, &spec)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			if allowed != tt.wantAllowed || (!allowed && reason != tt.wantReason) {
+				t.Fatalf("got allowed=%v reason=%q, want allowed=%v reason=%q", allowed, reason, tt.wantAllowed, tt.wantReason)
+			}
+			if input.Namespace != ns || input.Pod.Name != "new-pod" || input.Usage.Pods != 1 || input.Policy.MaxPods != tt.maxPods {
+				t.Fatalf("unexpected input: %+v", input)
+			}
+			if input.Decision.Allow != (tt.maxPods > 1) {
+				t.Fatalf("input decision allow = %v with maxPods %d", input.Decision.Allow, tt.maxPods)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// WarnDuringMaintenance wraps an admission handler so that, while s.Maintenance is on, requests it
+// denies are admitted with a warning that says why they would have been denied.
+func (s *WebhookServer) WarnDuringMaintenance(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.Maintenance.Active() {
+			next(w, r)
+			return
+		}
+		buf := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next(buf, r)
+
+		var review admissionv1.AdmissionReview
+		if buf.status != http.StatusOK || json.Unmarshal(buf.body.Bytes(), &review) != nil ||
+			review.Response == nil || review.Response.Allowed {
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(buf.body.Bytes())
+			return
+		}
+
+		resp := review.Response
+		message := "denied"
+		if resp.Result != nil && resp.Result.Message != "" {
+			message = resp.Result.Message
+		}
+		warning := "resource quota is not enforced during maintenance"
+		if reason := s.Maintenance.Reason(); reason != "" {
+			warning += " (" + reason + ")"
+		}
+		resp.Allowed = true
+		resp.Result = nil
+		resp.Warnings = append([]string{warning + "; would have been denied: " + message}, resp.Warnings...)
+		if resp.AuditAnnotations == nil {
+			resp.AuditAnnotations = map[string]string{}
+		}
+		resp.AuditAnnotations["decision"] = "warned_maintenance"
+		if review.Request != nil {
+			metricAdmissionRequests.WithLabelValues(review.Request.Namespace, "warned_maintenance").Inc()
+			logging.L().WithName("webhook").Info("Admitted during maintenance", "namespace", review.Request.Namespace, "kind", review.Request.Kind.Kind, "name", review.Request.Name, "message", message)
+		}
+		writeAdmissionResponse(w, &review)
+	}
+}
+
+// bufferedResponse holds what a handler writes, for WarnDuringMaintenance to rewrite.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
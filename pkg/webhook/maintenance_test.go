@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWarnDuringMaintenance(t *testing.T) {
+	cs := fakeclient.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}})
+	pod, _ := json.Marshal(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "tenant"}})
+	body, _ := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Namespace: "tenant",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: pod},
+	}})
+
+	for _, tt := range []struct {
+		name        string
+		maintenance *maintenance.Mode
+		allowed     bool
+	}{
+		{"no maintenance mode", nil, false},
+		{"maintenance off", maintenance.ForTesting(false, ""), false},
+		{"maintenance on", maintenance.ForTesting(true, "upgrade"), true},
+	} {
+		srv := &WebhookServer{Clientset: cs, Cache: staticCache{}, DefaultAction: DefaultActionDeny, Maintenance: tt.maintenance}
+		rec := httptest.NewRecorder()
+		srv.WarnDuringMaintenance(srv.HandleValidatePods)(rec, jsonRequest("/validate", body))
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if review.Response.Allowed != tt.allowed {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, review.Response.Allowed, tt.allowed)
+		}
+		if !tt.allowed {
+			continue
+		}
+		if len(review.Response.Warnings) == 0 || !strings.Contains(review.Response.Warnings[0], "(upgrade); would have been denied: Pod denied: ") {
+			t.Errorf("%s: warnings = %v", tt.name, review.Response.Warnings)
+		}
+		if review.Response.Result != nil || review.Response.AuditAnnotations["decision"] != "warned_maintenance" {
+			t.Errorf("%s: unexpected response %+v", tt.name, review.Response)
+		}
+	}
+}
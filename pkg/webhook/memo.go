@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// Memo remembers the denials of pods created from the same template, such as the replicas a
+// ReplicaSet keeps retrying during a rollout over quota, so that each retry doesn't list the
+// namespace's pods again. Each namespace has a usage epoch that moves on whenever one of its pods
+// is added, changed or deleted; a denial is only reused within the epoch it was made in, and for
+// at most TTL, which bounds how long a change to the namespace's QuotaExemptions goes unseen.
+// Admissions are not remembered: admitting a pod changes the usage the next one is evaluated
+// against. It is safe for concurrent use; a nil Memo remembers nothing.
+type Memo struct {
+	// TTL is how long a denial is reused at most.
+	TTL time.Duration
+
+	factory      metadatainformer.SharedInformerFactory
+	registration cache.ResourceEventHandlerRegistration
+
+	mu          sync.Mutex
+	byNamespace map[string]*memoNamespace
+}
+
+type memoNamespace struct {
+	epoch   uint64
+	denials map[string]memoDenial // by template and spec, see memoKey
+}
+
+type memoDenial struct {
+	v       verdict
+	expires time.Time
+}
+
+// NewMemo returns a Memo that keeps the usage epochs from a watch of the metadata of every pod.
+// Start it with Run.
+func NewMemo(client metadata.Interface, resync, ttl time.Duration) (*Memo, error) {
+	m := &Memo{
+		TTL:         ttl,
+		factory:     metadatainformer.NewSharedInformerFactory(client, resync),
+		byNamespace: make(map[string]*memoNamespace),
+	}
+	informer := m.factory.ForResource(corev1.SchemeGroupVersion.WithResource("pods")).Informer()
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { m.changed(obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// resyncs deliver the same object again
+			if oldObj.(*metav1.PartialObjectMetadata).ResourceVersion != newObj.(*metav1.PartialObjectMetadata).ResourceVersion {
+				m.changed(newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			m.changed(obj)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watch pods: %w", err)
+	}
+	m.registration = registration
+	return m, nil
+}
+
+// Run watches the pods until ctx is done.
+func (m *Memo) Run(ctx context.Context) {
+	m.factory.Start(ctx.Done())
+	<-ctx.Done()
+	m.factory.Shutdown()
+}
+
+// HasSynced reports whether every pod has been seen once.
+func (m *Memo) HasSynced() bool {
+	return m.registration.HasSynced()
+}
+
+// changed moves the usage epoch of the namespace of obj, a pod, on, dropping its denials.
+func (m *Memo) changed(obj interface{}) {
+	pod, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ns, ok := m.byNamespace[pod.Namespace]; ok {
+		ns.epoch++
+		ns.denials = nil
+	}
+}
+
+// epoch returns the current usage epoch of namespace.
+func (m *Memo) epoch(namespace string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ns, ok := m.byNamespace[namespace]
+	if !ok {
+		ns = &memoNamespace{}
+		m.byNamespace[namespace] = ns
+	}
+	return ns.epoch
+}
+
+// get returns the denial remembered under key in namespace, if it is still current at now.
+func (m *Memo) get(namespace, key string, now time.Time) (verdict, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ns, ok := m.byNamespace[namespace]
+	if !ok {
+		return verdict{}, false
+	}
+	d, ok := ns.denials[key]
+	if !ok || !now.Before(d.expires) {
+		return verdict{}, false
+	}
+	return d.v, true
+}
+
+// put remembers v, a denial made in epoch, under key in namespace, unless the epoch has moved on
+// since.
+func (m *Memo) put(namespace, key string, epoch uint64, v verdict, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ns, ok := m.byNamespace[namespace]
+	if !ok || ns.epoch != epoch {
+		return
+	}
+	if ns.denials == nil {
+		ns.denials = make(map[string]memoDenial)
+	}
+	for k, d := range ns.denials {
+		if !now.Before(d.expires) {
+			delete(ns.denials, k)
+		}
+	}
+	ns.denials[key] = memoDenial{v: v, expires: now.Add(m.TTL)}
+}
+
+// memoKey identifies what the evaluation of pod against spec depends on, leaving out what differs
+// between pods of one template, like their names. It returns false for pods not created by a
+// controller, which are rarely created alike, and for specs whose validation rules or external
+// policy may decide on anything about the pod.
+func memoKey(pod *corev1.Pod, spec *platformv1beta1.ResourceQuotaPolicySpec) (string, bool) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || len(spec.ValidationRules) > 0 || spec.ExternalPolicy != nil {
+		return "", false
+	}
+	// the service account admission plugin names each pod's token volume differently
+	podSpec := pod.Spec.DeepCopy()
+	podSpec.Volumes = nil
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].VolumeMounts = nil
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = nil
+	}
+	h := fnv.New64a()
+	enc := json.NewEncoder(h)
+	for _, v := range []any{owner.UID, pod.Labels, pod.Annotations, podSpec, spec} {
+		if err := enc.Encode(v); err != nil {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum64()), true
+}
+
+// evaluateMemoized is evaluate for the creation of pod, reusing the denial of an identical pod
+// made while the usage of namespace is unchanged.
+func (s *WebhookServer) evaluateMemoized(ctx context.Context, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	if s.Memo == nil || !s.Memo.HasSynced() {
+		// until the watch has caught up, a pod deleted since could go unseen
+		return s.evaluate(ctx, pod, namespace, spec)
+	}
+	key, ok := memoKey(pod, spec)
+	if !ok {
+		return s.evaluate(ctx, pod, namespace, spec)
+	}
+	now := time.Now()
+	if v, ok := s.Memo.get(namespace, key, now); ok {
+		metricMemoHits.Inc()
+		return v, nil
+	}
+	epoch := s.Memo.epoch(namespace)
+	v, err := s.evaluate(ctx, pod, namespace, spec)
+	if err == nil && !v.allowed {
+		s.Memo.put(namespace, key, epoch, v, now)
+	}
+	return v, err
+}
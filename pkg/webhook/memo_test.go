@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func templatePod(name, token string) *corev1.Pod {
+	isController := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "test-ns",
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", UID: "rs-uid", Controller: &isController}},
+		},
+		Spec: corev1.PodSpec{
+			Volumes:    []corev1.Volume{{Name: token}},
+			Containers: []corev1.Container{{Name: "app", VolumeMounts: []corev1.VolumeMount{{Name: token}}}},
+		},
+	}
+}
+
+func TestMemoKey(t *testing.T) {
+	spec := &v1beta1.ResourceQuotaPolicySpec{MaxPods: 1}
+	a, ok := memoKey(templatePod("web-1", "kube-api-access-abcde"), spec)
+	if !ok {
+		t.Fatal("no key for a pod of a ReplicaSet")
+	}
+	if b, _ := memoKey(templatePod("web-2", "kube-api-access-fghij"), spec); a != b {
+		t.Fatal("pods of one template differ")
+	}
+
+	other := templatePod("web-3", "kube-api-access-abcde")
+	other.Labels["app"] = "api"
+	if b, _ := memoKey(other, spec); a == b {
+		t.Fatal("pods with different labels share a key")
+	}
+	if b, _ := memoKey(templatePod("web-1", "kube-api-access-abcde"), &v1beta1.ResourceQuotaPolicySpec{MaxPods: 2}); a == b {
+		t.Fatal("different specs share a key")
+	}
+
+	bare := templatePod("bare", "token")
+	bare.OwnerReferences = nil
+	if _, ok := memoKey(bare, spec); ok {
+		t.Fatal("key for a pod without a controller")
+	}
+	if _, ok := memoKey(templatePod("web-1", "token"), &v1beta1.ResourceQuotaPolicySpec{
+		ValidationRules: []v1beta1.ValidationRule{{Expression: "true"}},
+	}); ok {
+		t.Fatal("key for a spec with validation rules")
+	}
+}
+
+func TestEvaluateMemoized(t *testing.T) {
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-ns"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	cs := fakeclient.NewSimpleClientset(running)
+	scheme := runtime.NewScheme()
+	metav1.AddMetaToScheme(scheme)
+	metaClient := metadatafake.NewSimpleMetadataClient(scheme)
+	memo, err := NewMemo(metaClient, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go memo.Run(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), memo.HasSynced) {
+		t.Fatal("memo not synced")
+	}
+
+	srv := &WebhookServer{Clientset: cs, Memo: memo}
+	spec := &v1beta1.ResourceQuotaPolicySpec{MaxPods: 1}
+	lists := func() int {
+		n := 0
+		for _, a := range cs.Actions() {
+			if a.GetVerb() == "list" {
+				n++
+			}
+		}
+		return n
+	}
+
+	for i, name := range []string{"web-1", "web-2"} {
+		v, err := srv.evaluateMemoized(ctx, templatePod(name, name+"-token"), "test-ns", spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.allowed {
+			t.Fatalf("%s allowed over maxPods", name)
+		}
+		if got := lists(); got != 1 {
+			t.Fatalf("after %d evaluations, pods listed %d times, want once", i+1, got)
+		}
+	}
+
+	// a pod change moves the epoch on, so the next pod is evaluated afresh
+	epoch := memo.epoch("test-ns")
+	if err := metaClient.Tracker().Add(&metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test-ns"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for memo.epoch("test-ns") == epoch {
+		if time.Now().After(deadline) {
+			t.Fatal("epoch unchanged after a pod was added")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := srv.evaluateMemoized(ctx, templatePod("web-3", "web-3-token"), "test-ns", spec); err != nil {
+		t.Fatal(err)
+	}
+	if got := lists(); got != 2 {
+		t.Fatalf("pods listed %d times after the epoch moved on, want twice", got)
+	}
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// What happens to admissions shed while the webhook is overloaded.
+const (
+	OverloadAllow = "allow"
+	OverloadDeny  = "deny"
+)
+
+// ParseOverloadPolicy validates the value of the -overload-policy flag.
+func ParseOverloadPolicy(policy string) (string, error) {
+	switch policy {
+	case OverloadAllow, OverloadDeny:
+		return policy, nil
+	case "":
+		return OverloadAllow, nil
+	}
+	return "", fmt.Errorf("unknown overload policy %q, must be %q or %q", policy, OverloadAllow, OverloadDeny)
+}
+
+// Limiter bounds how many admissions are evaluated at once, each of which lists the pods of its
+// namespace, and how many wait for their turn. It is safe for concurrent use.
+type Limiter struct {
+	slots chan struct{}
+	queue chan struct{}
+	wait  time.Duration
+}
+
+// NewLimiter returns a Limiter evaluating at most maxInFlight admissions at once, with at most
+// maxQueued more waiting up to wait each for one of them to finish.
+func NewLimiter(maxInFlight, maxQueued int, wait time.Duration) *Limiter {
+	return &Limiter{
+		slots: make(chan struct{}, maxInFlight),
+		queue: make(chan struct{}, max(maxQueued, 0)),
+		wait:  wait,
+	}
+}
+
+// acquire takes a slot, queueing for at most l.wait or until ctx is done. It returns false when
+// the queue is full or no slot freed up in time; otherwise the slot must be given back with
+// release.
+func (l *Limiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return false
+	}
+	metricAdmissionQueued.Inc()
+	defer func() {
+		<-l.queue
+		metricAdmissionQueued.Dec()
+	}()
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+func (l *Limiter) release() {
+	<-l.slots
+}
+
+// LimitConcurrency evaluates admissions with next while s.Limiter has a slot for them. Once it
+// has none, they are answered straight away according to s.OverloadPolicy, without evaluating
+// them, so a storm of pod creations can't pile up goroutines and pod lists in memory. A nil
+// Limiter evaluates every admission.
+func (s *WebhookServer) LimitConcurrency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Limiter == nil {
+			next(w, r)
+			return
+		}
+		// don't queue for longer than half of what the API server waits for the answer
+		ctx, cancel := context.WithTimeout(r.Context(), s.admissionDeadline(r)/2)
+		ok := s.Limiter.acquire(ctx)
+		cancel()
+		if ok {
+			defer s.Limiter.release()
+			metricAdmissionInFlight.Inc()
+			defer metricAdmissionInFlight.Dec()
+			next(w, r)
+			return
+		}
+
+		start := time.Now()
+		defer func() {
+			metricAdmissionDuration.WithLabelValues("overloaded").Observe(time.Since(start).Seconds())
+		}()
+		// the response has to carry the request's UID
+		var review admissionv1.AdmissionReview
+		if !decodeBody(w, r, &review, "admission review") {
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "no admission request", http.StatusBadRequest)
+			return
+		}
+		req := review.Request
+		metricAdmissionRequests.WithLabelValues(req.Namespace, "overloaded").Inc()
+		logging.L().WithName("webhook").V(logging.Debug).Info("Shed admission, webhook overloaded",
+			"namespace", req.Namespace, "kind", req.Kind.Kind, "name", req.Name, "overloadPolicy", s.OverloadPolicy)
+		review.Response = s.overloadResponse(req.UID)
+		review.Response.AuditAnnotations = map[string]string{"decision": "overloaded"}
+		writeAdmissionResponse(w, &review)
+	}
+}
+
+// overloadResponse answers an admission shed by LimitConcurrency: it is allowed with a warning,
+// or denied when s.OverloadPolicy is OverloadDeny, so the outcome doesn't depend on the API
+// server's failurePolicy.
+func (s *WebhookServer) overloadResponse(uid types.UID) *admissionv1.AdmissionResponse {
+	if s.OverloadPolicy == OverloadDeny {
+		return &admissionv1.AdmissionResponse{
+			UID:     uid,
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: "resource quota webhook overloaded, try again",
+				Reason:  metav1.StatusReasonTooManyRequests,
+				Code:    http.StatusTooManyRequests,
+			},
+		}
+	}
+	return &admissionv1.AdmissionResponse{
+		UID:      uid,
+		Allowed:  true,
+		Warnings: []string{"resource quota was not checked: webhook overloaded"},
+	}
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestLimitConcurrency(t *testing.T) {
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	s := &WebhookServer{Limiter: NewLimiter(1, 1, time.Minute)}
+	h := s.LimitConcurrency(func(w http.ResponseWriter, _ *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(uid types.UID) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{UID: uid, Namespace: "test-ns"}})
+		r := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h(rec, r)
+		return rec
+	}
+	shed := func(uid types.UID) *admissionv1.AdmissionResponse {
+		rec := serve(uid)
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil || review.Response == nil {
+			t.Fatalf("%s: not an admission review: %q", uid, rec.Body.String())
+		}
+		if review.Response.UID != uid {
+			t.Fatalf("%s: answered for %s", uid, review.Response.UID)
+		}
+		return review.Response
+	}
+
+	done := make(chan int, 2)
+	go func() { done <- serve("in-flight").Code }()
+	<-started
+	go func() { done <- serve("queued").Code }()
+	for deadline := time.Now().Add(2 * time.Second); len(s.Limiter.queue) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("second admission not queued")
+		}
+	}
+
+	if resp := shed("allowed"); !resp.Allowed || len(resp.Warnings) == 0 {
+		t.Fatalf("shed admission not allowed with a warning: %+v", resp)
+	}
+	s.OverloadPolicy = OverloadDeny
+	if resp := shed("denied"); resp.Allowed || resp.Result == nil || resp.Result.Code != http.StatusTooManyRequests {
+		t.Fatalf("shed admission not denied with 429: %+v", resp)
+	}
+
+	// the queued admission is evaluated once the first one finishes
+	close(unblock)
+	for range 2 {
+		if code := <-done; code != http.StatusOK {
+			t.Fatalf("evaluated admission answered %d", code)
+		}
+	}
+	if len(started) != 1 {
+		t.Fatalf("%d admissions evaluated after the first, want 1", len(started))
+	}
+}
+
+func TestLimiterQueueWait(t *testing.T) {
+	l := NewLimiter(1, 1, 10*time.Millisecond)
+	if !l.acquire(t.Context()) {
+		t.Fatal("free slot not acquired")
+	}
+	if l.acquire(t.Context()) {
+		t.Fatal("slot acquired while taken")
+	}
+	l.release()
+	if !l.acquire(t.Context()) {
+		t.Fatal("released slot not acquired")
+	}
+}
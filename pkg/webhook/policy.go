@@ -0,0 +1,439 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/promusage"
+)
+
+// HandleValidatePolicy handles AdmissionReview v1 for ResourceQuotaPolicy CREATE and UPDATE
+// operations, rejecting specs the enforcer can't act on.
+func (s *WebhookServer) HandleValidatePolicy(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Kind.Kind != "ResourceQuotaPolicy" || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	errs, err := validatePolicyObject(req.Object.Raw)
+	if err != nil {
+		admissionReview.Response = &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &metav1.Status{Message: fmt.Sprintf("could not decode ResourceQuotaPolicy: %v", err)},
+			UID:     req.UID,
+		}
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	if len(errs) > 0 {
+		admissionReview.Response = &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: fmt.Sprintf("invalid ResourceQuotaPolicy: %s", errs.ToAggregate().Error()),
+			},
+			UID: req.UID,
+		}
+	} else {
+		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	}
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+// validatePolicyObject decodes a serialized policy of either served version and validates it.
+func validatePolicyObject(raw []byte) (field.ErrorList, error) {
+	var meta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, err
+	}
+
+	var errs field.ErrorList
+	if meta.APIVersion == platformv1alpha1.SchemeGroupVersion.String() {
+		// v1alpha1 quantities are strings; conversion silently drops the bad ones so catch them here
+		var alpha platformv1alpha1.ResourceQuotaPolicy
+		if err := json.Unmarshal(raw, &alpha); err != nil {
+			return nil, err
+		}
+		errs = append(errs, validateV1alpha1Quantities(&alpha.Spec, field.NewPath("spec"))...)
+	}
+
+	converted, err := convertPolicy(raw, platformv1beta1.SchemeGroupVersion.String())
+	if err != nil {
+		return nil, err
+	}
+	var policy platformv1beta1.ResourceQuotaPolicy
+	if err := json.Unmarshal(converted, &policy); err != nil {
+		return nil, err
+	}
+	return append(errs, validatePolicySpec(&policy.Spec, field.NewPath("spec"))...), nil
+}
+
+// validatePolicySpec returns every problem found in spec.
+func validatePolicySpec(spec *platformv1beta1.ResourceQuotaPolicySpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	errs = append(errs, validateLimits(spec.MaxPods, spec.MaxCPU, spec.MaxMemory, path)...)
+
+	seen := map[string]int{}
+	for i, c := range spec.ScheduledChanges {
+		p := path.Child("scheduledChanges").Index(i)
+		if c.EffectiveFrom.IsZero() {
+			errs = append(errs, field.Required(p.Child("effectiveFrom"), "effectiveFrom must be set"))
+		} else {
+			key := c.EffectiveFrom.UTC().String()
+			if j, dup := seen[key]; dup {
+				errs = append(errs, field.Duplicate(p.Child("effectiveFrom"), fmt.Sprintf("same time as scheduledChanges[%d]", j)))
+			}
+			seen[key] = i
+		}
+		if c.MaxPods == 0 && c.MaxCPU == nil && c.MaxMemory == nil {
+			errs = append(errs, field.Required(p, "a scheduled change must set at least one limit"))
+		}
+		errs = append(errs, validateLimits(c.MaxPods, c.MaxCPU, c.MaxMemory, p)...)
+	}
+
+	if share := spec.ClusterShare; share != nil {
+		p := path.Child("clusterShare")
+		for _, pct := range []struct {
+			name  string
+			value int32
+		}{
+			{"podsPercent", share.PodsPercent},
+			{"cpuPercent", share.CPUPercent},
+			{"memoryPercent", share.MemoryPercent},
+		} {
+			if pct.value < 0 || pct.value > 100 {
+				errs = append(errs, field.Invalid(p.Child(pct.name), pct.value, "must be between 0 and 100"))
+			}
+		}
+	}
+
+	if soft := spec.SoftLimits; soft != nil {
+		p := path.Child("softLimits")
+		for _, pct := range []struct {
+			name  string
+			value int32
+		}{
+			{"podsPercent", soft.PodsPercent},
+			{"cpuPercent", soft.CPUPercent},
+			{"memoryPercent", soft.MemoryPercent},
+		} {
+			if pct.value < 0 || pct.value > 100 {
+				errs = append(errs, field.Invalid(p.Child(pct.name), pct.value, "must be between 0 and 100"))
+			}
+		}
+	}
+
+	for i, rule := range spec.ValidationRules {
+		p := path.Child("validationRules").Index(i).Child("expression")
+		if rule.Expression == "" {
+			errs = append(errs, field.Required(p, "expression must be set"))
+			continue
+		}
+		if _, err := rules.check(rule.Expression); err != nil {
+			errs = append(errs, field.Invalid(p, rule.Expression, err.Error()))
+		}
+	}
+
+	if ext := spec.ExternalPolicy; ext != nil {
+		p := path.Child("externalPolicy")
+		if u, err := url.Parse(ext.URL); ext.URL == "" {
+			errs = append(errs, field.Required(p.Child("url"), "url must be set"))
+		} else if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, field.Invalid(p.Child("url"), ext.URL, "must be an absolute http or https URL"))
+		}
+		switch ext.FailurePolicy {
+		case "", platformv1beta1.ExternalFailureIgnore, platformv1beta1.ExternalFailureFail:
+		default:
+			errs = append(errs, field.NotSupported(p.Child("failurePolicy"), ext.FailurePolicy,
+				[]platformv1beta1.ExternalFailurePolicy{platformv1beta1.ExternalFailureIgnore, platformv1beta1.ExternalFailureFail}))
+		}
+	}
+
+	if pricing := spec.Pricing; pricing != nil {
+		p := path.Child("pricing")
+		for _, price := range []struct {
+			name  string
+			value string
+		}{
+			{"cpuHour", pricing.CPUHour},
+			{"memoryGiBHour", pricing.MemoryGiBHour},
+		} {
+			if price.value == "" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(price.value, 64); err != nil || v < 0 {
+				errs = append(errs, field.Invalid(p.Child(price.name), price.value, "must be a non-negative decimal number"))
+			}
+		}
+	}
+
+	switch spec.MissingRequestsPolicy {
+	case "", platformv1beta1.MissingRequestsAssumeDefaults, platformv1beta1.MissingRequestsDeny:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("missingRequestsPolicy"), spec.MissingRequestsPolicy,
+			[]platformv1beta1.MissingRequestsPolicy{platformv1beta1.MissingRequestsAssumeDefaults, platformv1beta1.MissingRequestsDeny}))
+	}
+	if dr := spec.DefaultRequests; dr != nil {
+		p := path.Child("defaultRequests")
+		if dr.CPU != nil && dr.CPU.Sign() < 0 {
+			errs = append(errs, field.Invalid(p.Child("cpu"), dr.CPU.String(), "must not be negative"))
+		}
+		if dr.Memory != nil && dr.Memory.Sign() < 0 {
+			errs = append(errs, field.Invalid(p.Child("memory"), dr.Memory.String(), "must not be negative"))
+		}
+	}
+
+	switch spec.AutoscalingCheck {
+	case "", platformv1beta1.AutoscalingCheckWarn, platformv1beta1.AutoscalingCheckDeny:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("autoscalingCheck"), spec.AutoscalingCheck,
+			[]platformv1beta1.AutoscalingCheck{platformv1beta1.AutoscalingCheckWarn, platformv1beta1.AutoscalingCheckDeny}))
+	}
+	switch spec.UsageSource {
+	case "", platformv1beta1.UsageSourceRequests, platformv1beta1.UsageSourcePrometheus, platformv1beta1.UsageSourceVPARecommendation:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("usageSource"), spec.UsageSource,
+			[]platformv1beta1.UsageSource{platformv1beta1.UsageSourceRequests, platformv1beta1.UsageSourcePrometheus, platformv1beta1.UsageSourceVPARecommendation}))
+	}
+	if q := spec.UsageQueries; q != nil {
+		p := path.Child("usageQueries")
+		for _, query := range []struct {
+			name  string
+			value string
+		}{
+			{"cpu", q.CPU},
+			{"memory", q.Memory},
+		} {
+			if query.value == "" {
+				continue
+			}
+			if _, err := promusage.Render(query.value, "default"); err != nil {
+				errs = append(errs, field.Invalid(p.Child(query.name), query.value, err.Error()))
+			}
+		}
+	}
+
+	for _, name := range handlers.ExtendedNames(spec.MaxExtendedResources) {
+		p := path.Child("maxExtendedResources").Key(name)
+		// extended resources always carry a domain, e.g. example.com/gpu
+		if msgs := validation.IsQualifiedName(name); len(msgs) > 0 || !strings.Contains(name, "/") {
+			errs = append(errs, field.Invalid(p, name, "must be a domain-prefixed resource name such as example.com/gpu"))
+		}
+		if q := spec.MaxExtendedResources[name]; q.Sign() < 0 {
+			errs = append(errs, field.Invalid(p, q.String(), "must not be negative"))
+		}
+	}
+
+	for _, count := range []struct {
+		name  string
+		value int32
+	}{
+		{"maxReplicasPerWorkload", spec.MaxReplicasPerWorkload},
+		{"maxActiveJobs", spec.MaxActiveJobs},
+		{"maxCronJobs", spec.MaxCronJobs},
+		{"maxLoadBalancers", spec.MaxLoadBalancers},
+		{"maxNodePorts", spec.MaxNodePorts},
+		{"maxDeletionsPerSync", spec.MaxDeletionsPerSync},
+	} {
+		if count.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(count.name), count.value, "must not be negative"))
+		}
+	}
+
+	subjects := map[platformv1beta1.SubjectQuota]int{}
+	for i, subject := range spec.Subjects {
+		p := path.Child("subjects").Index(i)
+		switch subject.Kind {
+		case platformv1beta1.SubjectServiceAccount, platformv1beta1.SubjectUser:
+		default:
+			errs = append(errs, field.NotSupported(p.Child("kind"), subject.Kind,
+				[]string{platformv1beta1.SubjectServiceAccount, platformv1beta1.SubjectUser}))
+		}
+		if subject.Name == "" {
+			errs = append(errs, field.Required(p.Child("name"), "name must be set"))
+		}
+		key := platformv1beta1.SubjectQuota{Kind: subject.Kind, Name: subject.Name}
+		if j, dup := subjects[key]; dup {
+			errs = append(errs, field.Duplicate(p, fmt.Sprintf("same subject as subjects[%d]", j)))
+		}
+		subjects[key] = i
+		errs = append(errs, validateLimits(subject.MaxPods, subject.MaxCPU, subject.MaxMemory, p)...)
+	}
+
+	if scope := spec.NodeScope; scope != nil {
+		p := path.Child("nodeScope")
+		names := map[string]int{}
+		for i, quota := range scope.Scopes {
+			sp := p.Child("scopes").Index(i)
+			if quota.Name == "" {
+				errs = append(errs, field.Required(sp.Child("name"), "name must be set"))
+			} else if j, dup := names[quota.Name]; dup {
+				errs = append(errs, field.Duplicate(sp.Child("name"), fmt.Sprintf("same name as scopes[%d]", j)))
+			} else {
+				names[quota.Name] = i
+			}
+			if len(quota.NodeSelector) == 0 {
+				errs = append(errs, field.Required(sp.Child("nodeSelector"), "nodeSelector must select nodes by at least one label"))
+			} else if _, err := labels.ValidatedSelectorFromSet(quota.NodeSelector); err != nil {
+				errs = append(errs, field.Invalid(sp.Child("nodeSelector"), quota.NodeSelector, err.Error()))
+			}
+			errs = append(errs, validateLimits(quota.MaxPods, quota.MaxCPU, quota.MaxMemory, sp)...)
+		}
+		if _, ok := names[scope.DefaultScope]; scope.DefaultScope != "" && !ok {
+			errs = append(errs, field.Invalid(p.Child("defaultScope"), scope.DefaultScope, "must name one of the scopes"))
+		}
+	}
+
+	if spec.Impersonate != nil && spec.Impersonate.User == "" {
+		// the API server refuses to impersonate groups without a user
+		errs = append(errs, field.Required(path.Child("impersonate", "user"), "user must be set"))
+	}
+
+	return errs
+}
+
+func validateLimits(maxPods int32, maxCPU, maxMemory *resource.Quantity, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if maxPods < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxPods"), maxPods, "must not be negative"))
+	}
+	if maxCPU != nil && maxCPU.Sign() < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxCPU"), maxCPU.String(), "must not be negative"))
+	}
+	if maxMemory != nil && maxMemory.Sign() < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxMemory"), maxMemory.String(), "must not be negative"))
+	}
+	return errs
+}
+
+func validateV1alpha1Quantities(spec *platformv1alpha1.ResourceQuotaPolicySpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	check := func(p *field.Path, v string) {
+		if v == "" {
+			return
+		}
+		if _, err := resource.ParseQuantity(v); err != nil {
+			errs = append(errs, field.Invalid(p, v, err.Error()))
+		}
+	}
+	check(path.Child("maxCPU"), spec.MaxCPU)
+	check(path.Child("maxMemory"), spec.MaxMemory)
+	for i, c := range spec.ScheduledChanges {
+		p := path.Child("scheduledChanges").Index(i)
+		check(p.Child("maxCPU"), c.MaxCPU)
+		check(p.Child("maxMemory"), c.MaxMemory)
+	}
+	return errs
+}
+
+// HandleMutatePolicy handles AdmissionReview v1 for ResourceQuotaPolicy CREATE and UPDATE
+// operations, filling omitted limits with the v1beta1 defaults so they are visible on the
+// stored object.
+func (s *WebhookServer) HandleMutatePolicy(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.Kind.Kind != "ResourceQuotaPolicy" || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	patch, err := defaultPolicyPatch(req.Object.Raw)
+	if err != nil {
+		// leave the object alone; the validating webhook reports decode problems
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	if len(patch) > 0 {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		pt := admissionv1.PatchTypeJSONPatch
+		admissionReview.Response.Patch = raw
+		admissionReview.Response.PatchType = &pt
+	}
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// defaultPolicyPatch returns the JSON patch adding the default limits missing from a policy.
+// Both served versions use the same field names and accept quantity strings, so the patch is
+// computed on the raw object.
+func defaultPolicyPatch(raw []byte) ([]patchOperation, error) {
+	var obj struct {
+		Spec map[string]interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+
+	var patch []patchOperation
+	if obj.Spec == nil {
+		patch = append(patch, patchOperation{Op: "add", Path: "/spec", Value: map[string]interface{}{}})
+		obj.Spec = map[string]interface{}{}
+	}
+
+	defaults := []struct {
+		field string
+		value interface{}
+	}{
+		{"maxPods", platformv1beta1.DefaultMaxPods},
+		{"maxCPU", platformv1beta1.DefaultMaxCPU},
+		{"maxMemory", platformv1beta1.DefaultMaxMemory},
+	}
+	for _, d := range defaults {
+		v, ok := obj.Spec[d.field]
+		if ok && v != nil && v != "" && v != float64(0) {
+			continue
+		}
+		op := "add"
+		if ok {
+			op = "replace"
+		}
+		patch = append(patch, patchOperation{Op: op, Path: "/spec/" + d.field, Value: d.value})
+	}
+	return patch, nil
+}
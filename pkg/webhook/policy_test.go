@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+func TestValidatePolicyObject(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{
+			name: "valid v1beta1",
+			raw:  `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"maxPods":3,"maxCPU":"500m"}}`,
+		},
+		{
+			name:    "malformed v1alpha1 quantity",
+			raw:     `{"apiVersion":"platform.example.com/v1alpha1","kind":"ResourceQuotaPolicy","spec":{"maxCPU":"lots"}}`,
+			wantErr: "spec.maxCPU",
+		},
+		{
+			name:    "negative maxPods",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"maxPods":-1}}`,
+			wantErr: "spec.maxPods",
+		},
+		{
+			name:    "duplicate scheduled change",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"scheduledChanges":[{"effectiveFrom":"2026-01-01T00:00:00Z","maxPods":2},{"effectiveFrom":"2026-01-01T00:00:00Z","maxPods":4}]}}`,
+			wantErr: "spec.scheduledChanges[1].effectiveFrom",
+		},
+		{
+			name:    "rule does not compile",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"validationRules":[{"expression":"pod.spec.containers.size() +"}]}}`,
+			wantErr: "spec.validationRules[0].expression",
+		},
+		{
+			name:    "rule too expensive",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"validationRules":[{"expression":"pod.spec.containers.all(a, pod.spec.containers.all(b, pod.spec.containers.all(c, a.name != c.name || b.name == c.name)))"}]}}`,
+			wantErr: "exceeds the limit",
+		},
+		{
+			name:    "unparseable price",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"pricing":{"cpuHour":"0.03","memoryGiBHour":"cheap"}}}`,
+			wantErr: "spec.pricing.memoryGiBHour",
+		},
+		{
+			name:    "extended resource without domain",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"maxExtendedResources":{"example.com/gpu":2,"gpu":1}}}`,
+			wantErr: "spec.maxExtendedResources[gpu]",
+		},
+		{
+			name:    "unknown missing requests policy",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"missingRequestsPolicy":"ignore"}}`,
+			wantErr: "spec.missingRequestsPolicy",
+		},
+		{
+			name:    "usage query does not parse",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"usageSource":"prometheus","usageQueries":{"cpu":"sum by (pod) (x{namespace=\"{{.Namespace\"})"}}}`,
+			wantErr: "spec.usageQueries.cpu",
+		},
+		{
+			name:    "cluster share over 100",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"clusterShare":{"cpuPercent":150}}}`,
+			wantErr: "spec.clusterShare.cpuPercent",
+		},
+		{
+			name:    "default scope names no scope",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"nodeScope":{"scopes":[{"name":"a","nodeSelector":{"topology.kubernetes.io/zone":"a"},"maxPods":5}],"defaultScope":"b"}}}`,
+			wantErr: "spec.nodeScope.defaultScope",
+		},
+		{
+			name:    "unknown subject kind",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"subjects":[{"kind":"ServiceAccount","name":"ci","maxPods":2},{"kind":"Group","name":"devs"}]}}`,
+			wantErr: "spec.subjects[1].kind",
+		},
+		{
+			name:    "impersonated groups without a user",
+			raw:     `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"impersonate":{"groups":["quota-enforcers"]}}}`,
+			wantErr: "spec.impersonate.user",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := validatePolicyObject([]byte(tc.raw))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if tc.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs.ToAggregate().Error(), tc.wantErr) {
+				t.Fatalf("expected error on %s, got %v", tc.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyCachesNoRules(t *testing.T) {
+	const expr = "pod.metadata.name != 'only-validated'"
+	raw := `{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"validationRules":[{"expression":"` + expr + `"}]}}`
+	if errs, err := validatePolicyObject([]byte(raw)); err != nil || len(errs) > 0 {
+		t.Fatalf("validate: %v %v", err, errs)
+	}
+	if _, ok := rules.programs.Get(expr); ok {
+		t.Fatal("validating a policy cached its rule")
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if ok, _, err := rules.evaluate([]platformv1beta1.ValidationRule{{Expression: expr}}, pod, nil); err != nil || !ok {
+		t.Fatalf("evaluate: %v %v", ok, err)
+	}
+	if _, ok := rules.programs.Get(expr); !ok {
+		t.Fatal("admission didn't cache the rule")
+	}
+}
+
+func TestDefaultPolicyPatch(t *testing.T) {
+	patch, err := defaultPolicyPatch([]byte(`{"apiVersion":"platform.example.com/v1beta1","kind":"ResourceQuotaPolicy","spec":{"maxCPU":"500m"}}`))
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	got := map[string]interface{}{}
+	for _, op := range patch {
+		got[op.Path] = op.Value
+	}
+	if len(got) != 2 || got["/spec/maxPods"] != 10 || got["/spec/maxMemory"] != "2Gi" {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/lru"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+const (
+	// ruleCostLimit caps the CEL cost of evaluating one rule against one pod, the per-call limit
+	// Kubernetes applies to its own CEL expressions. Rules estimated to exceed it are rejected at
+	// write time, and evaluation stops once it is reached.
+	ruleCostLimit = 1_000_000
+	// ruleMaxSize is the length assumed for every list, map and string in a pod when estimating
+	// a rule's cost.
+	ruleMaxSize = 1024
+	// maxCachedRules bounds the programs kept for admission, least recently used first out.
+	maxCachedRules = 1024
+)
+
+// ruleEvaluator compiles and caches CEL programs for policy validation rules.
+type ruleEvaluator struct {
+	once     sync.Once
+	env      *cel.Env
+	envErr   error
+	programs *lru.Cache // of cel.Program, by expression
+}
+
+var rules = &ruleEvaluator{programs: lru.New(maxCachedRules)}
+
+func (e *ruleEvaluator) init() error {
+	e.once.Do(func() {
+		e.env, e.envErr = cel.NewEnv(
+			cel.Variable("pod", cel.DynType),
+			cel.Variable("usage", cel.MapType(cel.StringType, cel.IntType)),
+		)
+	})
+	return e.envErr
+}
+
+// check compiles expr and reports why it can't be a rule: it doesn't evaluate to bool, or it
+// may cost more than ruleCostLimit for a large pod. Nothing is cached, so validating policies
+// that are then rejected or only dry-run leaves nothing behind.
+func (e *ruleEvaluator) check(expr string) (*cel.Ast, error) {
+	if err := e.init(); err != nil {
+		return nil, err
+	}
+	ast, iss := e.env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to bool, got %s", ast.OutputType())
+	}
+	cost, err := e.env.EstimateCost(ast, ruleSizes{})
+	if err != nil {
+		return nil, fmt.Errorf("estimate cost: %w", err)
+	}
+	if cost.Max > ruleCostLimit {
+		return nil, fmt.Errorf("estimated cost %d exceeds the limit of %d", cost.Max, ruleCostLimit)
+	}
+	return ast, nil
+}
+
+// program returns the cached program for expr, compiling it on first use. Only admission uses
+// it, so the cache holds the rules of policies in force rather than every expression validated.
+func (e *ruleEvaluator) program(expr string) (cel.Program, error) {
+	if prg, ok := e.programs.Get(expr); ok {
+		return prg.(cel.Program), nil
+	}
+	ast, err := e.check(expr)
+	if err != nil {
+		return nil, err
+	}
+	prg, err := e.env.Program(ast, cel.CostLimit(ruleCostLimit))
+	if err != nil {
+		return nil, err
+	}
+	e.programs.Add(expr, prg)
+	return prg, nil
+}
+
+// ruleSizes bounds the size of everything in a pod, which is dynamically typed, at ruleMaxSize
+// so that a rule's cost can be estimated at all.
+type ruleSizes struct{}
+
+func (ruleSizes) EstimateSize(checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: ruleMaxSize}
+}
+
+func (ruleSizes) EstimateCallCost(string, string, *checker.AstNode, []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// evaluate runs every rule against the pod and usage. It returns false and the rule's message
+// for the first rule that doesn't hold.
+func (e *ruleEvaluator) evaluate(rs []platformv1beta1.ValidationRule, pod *corev1.Pod, usage map[string]int64) (bool, string, error) {
+	if len(rs) == 0 {
+		return true, "", nil
+	}
+
+	podObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return true, "", fmt.Errorf("convert pod: %w", err)
+	}
+	vars := map[string]interface{}{"pod": podObj, "usage": usage}
+
+	for _, r := range rs {
+		prg, err := e.program(r.Expression)
+		if err != nil {
+			return true, "", fmt.Errorf("compile rule %q: %w", r.Expression, err)
+		}
+		out, _, err := prg.Eval(vars)
+		if err != nil {
+			return true, "", fmt.Errorf("evaluate rule %q: %w", r.Expression, err)
+		}
+		if ok, _ := out.Value().(bool); !ok {
+			if r.Message != "" {
+				return false, r.Message, nil
+			}
+			return false, fmt.Sprintf("validation rule failed: %s", r.Expression), nil
+		}
+	}
+	return true, "", nil
+}
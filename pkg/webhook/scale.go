@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+)
+
+// HandleValidateScale handles AdmissionReview v1 for updates of the scale subresource of
+// Deployments, StatefulSets and ReplicaSets. It denies scale-ups past maxReplicasPerWorkload or
+// whose extra replicas would exceed the namespace's policy, so `kubectl scale` fails right away
+// instead of leaving pods that the pod webhook rejects one by one.
+func (s *WebhookServer) HandleValidateScale(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "admission.validate_scale")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, s.admissionDeadline(r))
+	defer cancel()
+
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.SubResource != "scale" || req.Operation != admissionv1.Update {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ns := req.Namespace
+	logger := logging.L().WithName("webhook").WithValues("namespace", ns, "resource", req.Resource.Resource, "name", req.Name)
+	var scale, oldScale autoscalingv1.Scale
+	if err := json.Unmarshal(req.Object.Raw, &scale); err != nil {
+		logger.Error(err, "Failed to decode scale, allowing")
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, &oldScale); err != nil {
+		logger.Error(err, "Failed to decode old scale, allowing")
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	added := scale.Spec.Replicas - oldScale.Spec.Replicas
+	if added <= 0 || s.Exempt.Has(ns) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	policy, found := s.Cache.Get(ns)
+	if !found || policy == nil {
+		fallback, deny, err := s.withoutPolicy(ctx, ns)
+		if err != nil {
+			logger.Error(err, "Failed to read namespace, allowing")
+		} else if deny {
+			reason := noPolicyReason(ns)
+			metricAdmissionRequests.WithLabelValues(ns, "denied_no_policy").Inc()
+			logger.Info("Denied scale, no policy in namespace")
+			admissionReview.Response = &admissionv1.AdmissionResponse{
+				Allowed:          false,
+				Result:           &metav1.Status{Message: fmt.Sprintf("Scaling %s %s denied: %s", req.Resource.Resource, req.Name, reason)},
+				UID:              req.UID,
+				AuditAnnotations: auditAnnotations("denied_no_policy", "", reason, audit.Usage{}),
+			}
+		}
+		if fallback == nil {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		policy = fallback
+	}
+	if policy.Spec.Suspend {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	metricAdmissionRequests.WithLabelValues(ns, "received").Inc()
+	logger = logger.WithValues("policy", policy.Name, "replicas", scale.Spec.Replicas)
+
+	effective := s.effectiveSpec(policy)
+	var v verdict
+	var err error
+	if reason := replicasExceeded(&effective, scale.Spec.Replicas); reason != "" {
+		v = deny(reason, audit.Usage{})
+	} else {
+		var pod *corev1.Pod
+		pod, err = s.podTemplate(ctx, req.Resource, ns, req.Name)
+		if err == nil && pod == nil {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		if err == nil {
+			v, err = s.evaluateScale(ctx, pod, int(added), ns, &effective)
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			metricAdmissionRequests.WithLabelValues(ns, "timeout").Inc()
+			logger.Error(err, "Timed out evaluating scale", "denyOnTimeout", s.DenyOnTimeout)
+			admissionReview.Response = s.timeoutResponse(req.UID)
+			admissionReview.Response.AuditAnnotations = auditAnnotations("timeout", policy.Name, err.Error(), audit.Usage{})
+		} else {
+			logger.Error(err, "Failed to evaluate scale, allowing")
+			admissionReview.Response.AuditAnnotations = auditAnnotations("error", policy.Name, err.Error(), audit.Usage{})
+		}
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	reason, usage := v.reason, v.usage
+	if v.allowed {
+		metricAdmissionRequests.WithLabelValues(ns, "allowed").Inc()
+		logger.V(logging.Debug).Info("Allowed scale")
+		admissionReview.Response.AuditAnnotations = auditAnnotations("allowed", policy.Name, "", usage)
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	metricAdmissionViolations.WithLabelValues(ns, reason).Inc()
+	metricAdmissionRequests.WithLabelValues(ns, "denied").Inc()
+	logger.Info("Denied scale", "reason", reason, "usagePods", usage.Pods, "usageCPU", usage.CPU, "usageMemory", usage.Memory)
+	// no pod exists yet, so the record names the workload
+	s.Audit.Record(audit.Event{
+		Source:    audit.SourceWebhook,
+		Namespace: ns,
+		Pod:       req.Resource.Resource + "/" + req.Name,
+		Policy:    policy.Name,
+		Reason:    reason,
+		Usage:     usage,
+		Decision:  audit.DecisionDenied,
+	})
+	admissionReview.Response = &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: v.status(fmt.Sprintf("Scaling %s %s from %d to %d replicas denied by QuotaPolicy",
+			req.Resource.Resource, req.Name, oldScale.Spec.Replicas, scale.Spec.Replicas)),
+		UID:              req.UID,
+		Warnings:         v.warnings(),
+		AuditAnnotations: auditAnnotations("denied", policy.Name, reason, usage),
+	}
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+// podTemplate returns a pod as the workload name creates them, or nil for resources that are
+// not scaled through a pod template.
+func (s *WebhookServer) podTemplate(ctx context.Context, res metav1.GroupVersionResource, namespace, name string) (*corev1.Pod, error) {
+	if res.Group != "apps" {
+		return nil, nil
+	}
+	kind := map[string]string{"deployments": "Deployment", "statefulsets": "StatefulSet", "replicasets": "ReplicaSet"}[res.Resource]
+	pod, _, err := s.scaleTarget(ctx, namespace, kind, name)
+	return pod, err
+}
+
+// scaleTarget returns a pod as the apps/v1 workload kind/name creates them and its replicas, or
+// nil for kinds other than Deployment, StatefulSet and ReplicaSet.
+func (s *WebhookServer) scaleTarget(ctx context.Context, namespace, kind, name string) (*corev1.Pod, int32, error) {
+	var tmpl corev1.PodTemplateSpec
+	var replicas *int32
+	switch kind {
+	case "Deployment":
+		d, err := s.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, 0, err
+		}
+		tmpl, replicas = d.Spec.Template, d.Spec.Replicas
+	case "StatefulSet":
+		ss, err := s.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, 0, err
+		}
+		tmpl, replicas = ss.Spec.Template, ss.Spec.Replicas
+	case "ReplicaSet":
+		rs, err := s.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, 0, err
+		}
+		tmpl, replicas = rs.Spec.Template, rs.Spec.Replicas
+	default:
+		return nil, 0, nil
+	}
+	pod := &corev1.Pod{ObjectMeta: tmpl.ObjectMeta, Spec: tmpl.Spec}
+	pod.Namespace = namespace
+	if replicas == nil {
+		return pod, 1, nil
+	}
+	return pod, *replicas, nil
+}
+
+// evaluateScale reports whether replicas more pods like pod fit in namespace under spec. Only
+// the limits apply; validation rules are left to the admission of each pod.
+func (s *WebhookServer) evaluateScale(ctx context.Context, pod *corev1.Pod, replicas int, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	exclude := s.exclusions(namespace, spec)
+	if exclude.Excludes(pod) {
+		return allow(audit.Usage{}), nil
+	}
+	if spec.MissingRequestsPolicy == platformv1beta1.MissingRequestsDeny {
+		if missing := handlers.MissingRequests(pod); len(missing) > 0 {
+			return deny(fmt.Sprintf("missing requests: containers %s must request cpu and memory", strings.Join(missing, ", ")), audit.Usage{}), nil
+		}
+	}
+
+	u, err := s.listUsage(ctx, namespace, spec, exclude)
+	if err != nil {
+		return allow(audit.Usage{}), err
+	}
+
+	ctx, decisionSpan := tracing.Tracer().Start(ctx, "admission.decision")
+	defer decisionSpan.End()
+
+	if err := u.addReplicas(ctx, pod, replicas); err != nil {
+		return allow(u.base.audit()), err
+	}
+	if v := u.exceeded(spec); v != nil {
+		return u.denyOver(v, spec), nil
+	}
+	return allow(u.base.audit()), nil
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+// staticCache serves one policy for every namespace.
+type staticCache struct{ policy *v1beta1.ResourceQuotaPolicy }
+
+func (c staticCache) Get(string) (*v1beta1.ResourceQuotaPolicy, bool) {
+	return c.policy, c.policy != nil
+}
+func (staticCache) Invalidate(string)                {}
+func (staticCache) Run(<-chan struct{})              {}
+func (staticCache) WaitForReady(time.Duration) error { return nil }
+
+func scaleReview(t *testing.T, from, to int32) []byte {
+	t.Helper()
+	raw := func(n int32) runtime.RawExtension {
+		b, err := json.Marshal(autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: n}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+	body, err := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:         "uid",
+		Namespace:   "test-ns",
+		Name:        "web",
+		Resource:    metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		SubResource: "scale",
+		Operation:   admissionv1.Update,
+		Object:      raw(to),
+		OldObject:   raw(from),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestHandleValidateScale(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("200m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "c", Resources: corev1.ResourceRequirements{Requests: requests}}},
+		}}},
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "test-ns"},
+		Spec:       deploy.Spec.Template.Spec,
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	maxCPU := resource.MustParse("1")
+	srv := &WebhookServer{
+		Clientset: fakeclient.NewSimpleClientset(deploy, running),
+		Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 10, MaxCPU: &maxCPU},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		from, to int32
+		allowed  bool
+	}{
+		{"scale down", 1, 0, true},
+		{"fits", 1, 5, true},
+		// 6 replicas at 200m need 1200m
+		{"exceeds cpu", 1, 6, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.HandleValidateScale(rec, jsonRequest("/validate-scale", scaleReview(t, tt.from, tt.to)))
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if review.Response.Allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v (%+v)", review.Response.Allowed, tt.allowed, review.Response.Result)
+			}
+			if !tt.allowed && !strings.Contains(review.Response.Result.Message, "cpu exceeded: 1200m > 1") {
+				t.Fatalf("unexpected message %q", review.Response.Result.Message)
+			}
+			if !tt.allowed {
+				want := map[string]string{"decision": "denied", "policy": "quota", "reason": "cpu exceeded: 1200m > 1", "usage": "pods=1,cpu=200m,memory=128Mi"}
+				if !reflect.DeepEqual(review.Response.AuditAnnotations, want) {
+					t.Fatalf("audit annotations = %v, want %v", review.Response.AuditAnnotations, want)
+				}
+				causes := review.Response.Result.Details.Causes
+				wantCauses := []metav1.StatusCause{
+					{Type: CauseTypeRequested, Field: "cpu", Message: "1"},
+					{Type: CauseTypeUsed, Field: "cpu", Message: "200m"},
+					{Type: CauseTypeLimit, Field: "cpu", Message: "1"},
+					{Type: CauseTypeRemaining, Field: "cpu", Message: "800m"},
+				}
+				if !reflect.DeepEqual(causes, wantCauses) {
+					t.Fatalf("causes = %v, want %v", causes, wantCauses)
+				}
+				if want := []string{"remaining quota: pods 9, cpu 800m"}; !reflect.DeepEqual(review.Response.Warnings, want) {
+					t.Fatalf("warnings = %v, want %v", review.Response.Warnings, want)
+				}
+			}
+		})
+	}
+}
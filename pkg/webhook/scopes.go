@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// newScopeUsage returns the usage of the node scope quota. nodes holds the labels of every node
+// by name; pending pods belong to the scope when it is the policy's default scope.
+func newScopeUsage(quota platformv1beta1.NodeScopeQuota, isDefault bool, nodes map[string]labels.Set, u *namespaceUsage) *subjectUsage {
+	selector := labels.SelectorFromSet(quota.NodeSelector)
+	return &subjectUsage{
+		namespaceUsage: &namespaceUsage{defaults: u.defaults, extended: map[string]resource.Quantity{}},
+		label:          "node scope " + quota.Name,
+		matches: func(pod *corev1.Pod) bool {
+			if pod.Spec.NodeName == "" {
+				return isDefault
+			}
+			node, ok := nodes[pod.Spec.NodeName]
+			return ok && selector.Matches(node)
+		},
+		nodeScope: true,
+		limits: platformv1beta1.ResourceQuotaPolicySpec{
+			MaxPods:   quota.MaxPods,
+			MaxCPU:    quota.MaxCPU,
+			MaxMemory: quota.MaxMemory,
+		},
+	}
+}
+
+// nodeLabels returns the labels of every node by name, from the capacity tracker's cache when
+// there is one.
+func (s *WebhookServer) nodeLabels(ctx context.Context) (map[string]labels.Set, error) {
+	var nodes []*corev1.Node
+	if s.Capacity != nil {
+		var err error
+		if nodes, err = s.Capacity.Nodes(); err != nil {
+			return nil, err
+		}
+	} else {
+		list, err := s.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list nodes: %w", err)
+		}
+		for i := range list.Items {
+			nodes = append(nodes, &list.Items[i])
+		}
+	}
+	out := make(map[string]labels.Set, len(nodes))
+	for _, node := range nodes {
+		out[node.Name] = node.Labels
+	}
+	return out, nil
+}
+
+// evaluateBinding decides on binding pod, which counts toward the policy's default node scope
+// until then, to node. The pod moves to the scopes of the node, so only those it wasn't in
+// already are checked.
+func (s *WebhookServer) evaluateBinding(ctx context.Context, pod *corev1.Pod, node, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	exclude := s.exclusions(namespace, spec)
+	if exclude.Excludes(pod) {
+		return allow(audit.Usage{}), nil
+	}
+	u, err := s.listUsage(ctx, namespace, spec, exclude)
+	if err != nil {
+		return allow(audit.Usage{}), err
+	}
+	bound := pod.DeepCopy()
+	bound.Spec.NodeName = node
+	for _, scope := range u.subjects {
+		if !scope.nodeScope || !scope.matches(bound) || scope.matches(pod) {
+			continue
+		}
+		scope.pods++
+		scope.addRequests(bound)
+		if v := scope.exceeded(&scope.limits); v != nil {
+			v.subject = scope
+			return u.denyOver(v, spec), nil
+		}
+	}
+	return allow(u.base.audit()), nil
+}
+
+// validateBinding handles the CREATE of the binding subresource of a pod, through which the
+// scheduler assigns the pod to a node, for policies with node scopes. A denied binding leaves the
+// pod pending, and the scheduler tries again later.
+func (s *WebhookServer) validateBinding(ctx context.Context, w http.ResponseWriter, review *admissionv1.AdmissionReview) string {
+	req := review.Request
+	logger := logging.L().WithName("webhook").WithValues("namespace", req.Namespace, "pod", req.Name)
+	var binding corev1.Binding
+	if err := json.Unmarshal(req.Object.Raw, &binding); err != nil {
+		logger.Error(err, "Failed to decode binding, allowing")
+		writeAdmissionResponse(w, review)
+		return "error"
+	}
+	policy, effective := s.policyFor(ctx, req.Namespace)
+	if policy == nil || effective.NodeScope == nil || binding.Target.Kind != "Node" {
+		writeAdmissionResponse(w, review)
+		return "skipped"
+	}
+	pod, err := s.Clientset.CoreV1().Pods(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+	if err != nil {
+		logger.Error(err, "Failed to read pod, allowing")
+		writeAdmissionResponse(w, review)
+		return "error"
+	}
+	v, err := s.evaluateBinding(ctx, pod, binding.Target.Name, req.Namespace, effective)
+	if err != nil {
+		logger.Error(err, "Failed to evaluate binding, allowing")
+		writeAdmissionResponse(w, review)
+		return "error"
+	}
+	if v.allowed {
+		metricAdmissionRequests.WithLabelValues(req.Namespace, "allowed").Inc()
+		writeAdmissionResponse(w, review)
+		return "allowed"
+	}
+	logger.Info("Denied binding", "node", binding.Target.Name, "policy", policy.Name, "reason", v.reason)
+	message := fmt.Sprintf("binding pod %s to node %s denied by QuotaPolicy %s", req.Name, binding.Target.Name, policy.Name)
+	s.denyObject(review, policy.Name, v.reason, v.status(message).Message)
+	review.Response.Warnings = v.warnings()
+	writeAdmissionResponse(w, review)
+	return "denied"
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEvaluateNodeScopes(t *testing.T) {
+	ns := "team-a"
+	zone := "topology.kubernetes.io/zone"
+	node := func(name, z string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{zone: z}}}
+	}
+	pod := func(name, nodeName, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{
+				Name:      "c",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pending := pod("pending", "", "500m")
+	srv := &WebhookServer{Clientset: fakeclient.NewSimpleClientset(
+		node("a-1", "a"), node("b-1", "b"),
+		pod("web-1", "a-1", "600m"),
+		pod("web-2", "b-1", "200m"),
+		pending,
+	)}
+	zoneCPU := resource.MustParse("1")
+	spec := v1beta1.ResourceQuotaPolicySpec{NodeScope: &v1beta1.NodeScope{
+		Scopes: []v1beta1.NodeScopeQuota{
+			{Name: "a", NodeSelector: map[string]string{zone: "a"}, MaxCPU: &zoneCPU},
+			{Name: "b", NodeSelector: map[string]string{zone: "b"}, MaxCPU: &zoneCPU},
+		},
+		DefaultScope: "b",
+	}}
+
+	// new pods are pending, so they count toward zone b with web-2 and the pending pod
+	if v, err := srv.evaluate(context.TODO(), pod("new", "", "300m"), ns, &spec); err != nil || !v.allowed {
+		t.Fatalf("pod within the default scope denied: allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	v, err := srv.evaluate(context.TODO(), pod("new", "", "400m"), ns, &spec)
+	if err != nil || v.allowed || v.reason != "node scope b: cpu exceeded: 1100m > 1" {
+		t.Fatalf("expected default scope denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+
+	v, err = srv.evaluateBinding(context.TODO(), pending, "a-1", ns, &spec)
+	if err != nil || v.allowed || v.reason != "node scope a: cpu exceeded: 1100m > 1" {
+		t.Fatalf("expected binding denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	// the pending pod already counts toward zone b
+	if v, err := srv.evaluateBinding(context.TODO(), pending, "b-1", ns, &spec); err != nil || !v.allowed {
+		t.Fatalf("binding within the default scope denied: allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+}
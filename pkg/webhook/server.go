@@ -2,71 +2,172 @@ package webhook
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 
-	platformv1alpha1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1alpha1"
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/capacity"
+	listers "github.com/sri2103/resource-quota-enforcer/pkg/generated/listers/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+	"github.com/sri2103/resource-quota-enforcer/pkg/maintenance"
+	"github.com/sri2103/resource-quota-enforcer/pkg/metrics"
+	"github.com/sri2103/resource-quota-enforcer/pkg/policycache"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+)
+
+// Names of the webhook's metrics, for dashboards and alerts generated from code.
+const (
+	NameAdmissionRequests   = "rqe_admission_requests_total"
+	NameAdmissionViolations = "rqe_admission_violations_total"
+	NameCacheHits           = "rqe_policy_cache_hits_total"
+	NameCacheMisses         = "rqe_policy_cache_misses_total"
+	NameAdmissionDuration   = "rqe_admission_duration_seconds"
+	NameExternalDecisions   = "rqe_external_decisions_total"
+	NameServingCertExpiry   = "rqe_webhook_serving_cert_expiry_timestamp_seconds"
+	NameClientCertRejected  = "rqe_webhook_client_cert_rejections_total"
+	NameMemoHits            = "rqe_admission_memo_hits_total"
+	NameAdmissionInFlight   = "rqe_admission_in_flight"
+	NameAdmissionQueued     = "rqe_admission_queued"
 )
 
 var (
 	metricAdmissionRequests = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "rqe_admission_requests_total",
+			Name: NameAdmissionRequests,
 			Help: "Total number of admission requests received",
 		}, []string{"namespace", "result"},
 	)
 
 	metricAdmissionViolations = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "rqe_admission_violations_total",
+			Name: NameAdmissionViolations,
 			Help: "Total number of admission rejections by reason",
 		}, []string{"namespace", "reason"},
 	)
 
 	metricCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "rqe_policy_cache_hits_total",
+		Name: NameCacheHits,
 		Help: "Policy cache hits",
 	})
 
 	metricCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "rqe_policy_cache_misses_total",
+		Name: NameCacheMisses,
 		Help: "Policy cache misses",
 	})
+
+	metricAdmissionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    NameAdmissionDuration,
+			Help:    "Time spent deciding pod admission requests",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"result"},
+	)
+
+	metricServingCertExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: NameServingCertExpiry,
+		Help: "Unix time at which the webhook's serving certificate expires",
+	})
+
+	metricClientCertRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: NameClientCertRejected,
+		Help: "Requests rejected for lacking a client certificate verified against -client-ca-file",
+	})
+
+	metricMemoHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: NameMemoHits,
+		Help: "Pod admissions denied by reusing the denial of an identical pod under unchanged usage",
+	})
+
+	metricAdmissionInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: NameAdmissionInFlight,
+		Help: "Admissions being evaluated under -max-in-flight",
+	})
+
+	metricAdmissionQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: NameAdmissionQueued,
+		Help: "Admissions waiting for one of -max-in-flight to finish",
+	})
 )
 
-// func init() {
-// 	prometheus.MustRegister(metricAdmissionRequests, metricAdmissionViolations, metricCacheHits, metricCacheMisses)
-// }
+// SetServingCert records when cert, the certificate the webhook serves, expires.
+func SetServingCert(cert *x509.Certificate) {
+	metricServingCertExpiry.Set(float64(cert.NotAfter.Unix()))
+}
 
+// InitMetrics registers the webhook metrics in the shared registry.
 func InitMetrics() {
-	prometheus.MustRegister(metricAdmissionRequests, metricAdmissionViolations, metricCacheHits, metricCacheMisses)
-	go func() {
-		http.Handle("/metrics", promhttp.Handler())
-		http.ListenAndServe(":2113", nil)
-	}()
+	metrics.Registry.MustRegister(metricAdmissionRequests, metricAdmissionViolations, metricCacheHits, metricCacheMisses, metricAdmissionDuration, metricExternalDecisions, metricServingCertExpiry, metricClientCertRejections, metricMemoHits, metricAdmissionInFlight, metricAdmissionQueued)
+	metrics.InitClientMetrics()
 }
 
 // WebhookServer provides handlers for admission requests.
 type WebhookServer struct {
 	Clientset kubernetes.Interface
 	Decoder   runtime.Decoder
-	Cache     PolicyCacheIF
+	Cache     policycache.Interface
+	// Audit receives a record of every denied pod; nil disables auditing.
+	Audit *audit.Logger
+	// Exclude holds the cluster-wide defaults for pods that are never counted or denied.
+	Exclude handlers.Exclusions
+	// Devices resolves DRA resource claims for extended resource limits; nil counts container
+	// requests only.
+	Devices *handlers.DeviceResolver
+
+	// Timeout is the webhook's timeoutSeconds, used when the API server doesn't pass it; zero
+	// means DefaultTimeout.
+	Timeout time.Duration
+	// DenyOnTimeout denies pods whose evaluation runs out of time instead of allowing them.
+	DenyOnTimeout bool
+	// DefaultAction is DefaultActionDeny to deny new pods in namespaces without a policy, unless
+	// the namespace opts out through v1beta1.AnnotationDefaultAction. Anything else allows them.
+	DefaultAction string
+	// DefaultPolicy applies to namespaces without a policy of their own, unless they opt out
+	// through v1beta1.AnnotationDefaultAction; nil leaves them to DefaultAction.
+	DefaultPolicy *platformv1beta1.ResourceQuotaPolicy
+	// Capacity resolves the clusterShare of policies; nil leaves them to their maxPods, maxCPU
+	// and maxMemory.
+	Capacity *capacity.Tracker
+	// ExternalClient calls the OPA endpoints of policies with an ExternalPolicy; nil uses
+	// http.DefaultClient.
+	ExternalClient *http.Client
+	// Exempt are namespaces whose admissions are always allowed; nil exempts none.
+	Exempt *handlers.ExemptNamespaces
+	// Exemptions lists the QuotaExemptions whose pods are neither counted nor denied until they
+	// expire; nil exempts no pods.
+	Exemptions listers.QuotaExemptionLister
+	// Maintenance admits what would be denied, with a warning, while maintenance mode is on, for
+	// the handlers wrapped with WarnDuringMaintenance; nil never does.
+	Maintenance *maintenance.Mode
+	// Limiter bounds the admissions evaluated at once by the handlers wrapped with
+	// LimitConcurrency; nil evaluates every admission.
+	Limiter *Limiter
+	// OverloadPolicy is OverloadDeny to deny the admissions Limiter sheds. Anything else allows
+	// them with a warning.
+	OverloadPolicy string
+	// Memo reuses the denials of pods created alike while their namespace's usage is unchanged;
+	// nil evaluates every pod afresh.
+	Memo *Memo
 }
 
 // NewWebhookServerWithInformer creates a new webhook server.
-func NewWebhookServerWithInformer(cs kubernetes.Interface, cache PolicyCacheIF) *WebhookServer {
+func NewWebhookServerWithInformer(cs kubernetes.Interface, cache policycache.Interface) *WebhookServer {
 	scheme := serializer.NewCodecFactory(nil).UniversalDeserializer()
 	return &WebhookServer{
 		Clientset: cs,
@@ -77,9 +178,23 @@ func NewWebhookServerWithInformer(cs kubernetes.Interface, cache PolicyCacheIF)
 
 // HandleValidatePods handles AdmissionReview v1 for Pod CREATE operations.
 func (s *WebhookServer) HandleValidatePods(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	result := "error"
+	ctx, span := tracing.Tracer().Start(r.Context(), "admission.validate")
+	defer func() {
+		span.SetAttributes(attribute.String("rqe.result", result))
+		span.End()
+		metricAdmissionDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	}()
+	ctx, cancel := context.WithTimeout(ctx, s.admissionDeadline(r))
+	defer cancel()
+
 	var admissionReview admissionv1.AdmissionReview
-	if err := json.NewDecoder(r.Body).Decode(&admissionReview); err != nil {
-		http.Error(w, "could not decode admission review", http.StatusBadRequest)
+	_, decodeSpan := tracing.Tracer().Start(ctx, "admission.decode")
+	ok := decodeBody(w, r, &admissionReview, "admission review")
+	decodeSpan.End()
+	if !ok {
+		span.SetStatus(codes.Error, "could not decode admission review")
 		return
 	}
 
@@ -90,9 +205,35 @@ func (s *WebhookServer) HandleValidatePods(w http.ResponseWriter, r *http.Reques
 	}
 
 	ns := req.Namespace
+	span.SetAttributes(
+		attribute.String("k8s.namespace.name", ns),
+		attribute.String("rqe.admission.uid", string(req.UID)),
+	)
 	metricAdmissionRequests.WithLabelValues(ns, "received").Inc()
 
-	if req.Kind.Kind != "Pod" || req.Operation != admissionv1.Create {
+	if s.Exempt.Has(ns) {
+		result = "exempt"
+		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	// the scheduler assigns pods to nodes through the binding subresource, which moves them
+	// between node scopes
+	if req.SubResource == "binding" && req.Operation == admissionv1.Create {
+		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+		result = s.validateBinding(ctx, w, &admissionReview)
+		return
+	}
+
+	// kubectl debug adds containers to a running pod through the ephemeralcontainers subresource;
+	// in-place resizes go through the resize subresource, or a plain update on older clusters
+	update := req.Operation == admissionv1.Update
+	ephemeral := update && req.SubResource == "ephemeralcontainers"
+	resize := update && (req.SubResource == "resize" || req.SubResource == "")
+	create := req.SubResource == "" && req.Operation == admissionv1.Create
+	if req.Kind.Kind != "Pod" || (!create && !ephemeral && !resize) {
+		result = "skipped"
 		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
 		writeAdmissionResponse(w, &admissionReview)
 		return
@@ -100,57 +241,160 @@ func (s *WebhookServer) HandleValidatePods(w http.ResponseWriter, r *http.Reques
 
 	var pod corev1.Pod
 	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		logging.L().WithName("webhook").Error(err, "Failed to decode pod, allowing", "namespace", ns, "uid", req.UID)
 		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
 		writeAdmissionResponse(w, &admissionReview)
 		return
 	}
+	if create && req.UserInfo.Username != "" {
+		// what HandleMutatePod stamps on the pod, in case it isn't registered
+		setRequestedBy(&pod, req.UserInfo.Username)
+	}
 
-	spec, found := s.Cache.Get(ns)
+	_, lookupSpan := tracing.Tracer().Start(ctx, "admission.cache_lookup")
+	policy, found := s.Cache.Get(ns)
+	lookupSpan.SetAttributes(attribute.Bool("rqe.cache.hit", found))
+	lookupSpan.End()
 	if found {
 		metricCacheHits.Inc()
 	} else {
 		metricCacheMisses.Inc()
 	}
 
-	if !found || spec == nil {
-		metricAdmissionRequests.WithLabelValues(ns, "allowed_no_policy").Inc()
-		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	podName := pod.Name
+	if podName == "" {
+		podName = pod.GenerateName
+	}
+	logger := logging.L().WithName("webhook").WithValues("namespace", ns, "pod", podName)
+
+	if !found || policy == nil {
+		fallback, deny, err := s.withoutPolicy(ctx, ns)
+		if err != nil {
+			// like any other failure to evaluate, this is left to allow
+			logger.Error(err, "Failed to read namespace, allowing")
+			metricAdmissionRequests.WithLabelValues(ns, result).Inc()
+			admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID, AuditAnnotations: auditAnnotations(result, "", err.Error(), audit.Usage{})}
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		policy = fallback
+		if deny && create {
+			result = "denied_no_policy"
+			reason := noPolicyReason(ns)
+			logger.Info("Denied pod, no policy in namespace")
+			metricAdmissionRequests.WithLabelValues(ns, result).Inc()
+			s.Audit.Record(audit.Event{
+				Source:    audit.SourceWebhook,
+				Namespace: ns,
+				Pod:       podName,
+				Reason:    reason,
+				Decision:  audit.DecisionDenied,
+			})
+			admissionReview.Response = &admissionv1.AdmissionResponse{
+				Allowed:          false,
+				Result:           &metav1.Status{Message: "Pod denied: " + reason},
+				UID:              req.UID,
+				AuditAnnotations: auditAnnotations(result, "", reason, audit.Usage{}),
+			}
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		if policy == nil {
+			result = "allowed_no_policy"
+			logger.V(logging.Debug).Info("Allowed pod, no policy in namespace")
+			metricAdmissionRequests.WithLabelValues(ns, "allowed_no_policy").Inc()
+			admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID, AuditAnnotations: auditAnnotations(result, "", "", audit.Usage{})}
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+	}
+
+	if policy.Spec.Suspend {
+		result = "suspended"
+		logger.V(logging.Debug).Info("Allowed pod, policy suspended", "policy", policy.Name)
+		metricAdmissionRequests.WithLabelValues(ns, result).Inc()
+		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID, AuditAnnotations: auditAnnotations(result, policy.Name, "", audit.Usage{})}
 		writeAdmissionResponse(w, &admissionReview)
 		return
 	}
 
-	allowed, reason, err := s.evaluatePodAgainstPolicy(r.Context(), &pod, ns, spec)
+	effective := s.effectiveSpec(policy)
+	span.SetAttributes(attribute.String("rqe.policy", policy.Name))
+	logger = logger.WithValues("policy", policy.Name)
+	var v verdict
+	var err error
+	if create {
+		v, err = s.evaluateMemoized(ctx, &pod, ns, &effective)
+	} else {
+		var oldPod corev1.Pod
+		if err = json.Unmarshal(req.OldObject.Raw, &oldPod); err == nil {
+			if ephemeral {
+				v, err = s.evaluateEphemeral(ctx, &oldPod, &pod, ns, &effective)
+			} else {
+				v, err = s.evaluateResize(ctx, &oldPod, &pod, ns, &effective)
+			}
+		}
+	}
+	reason, usage := v.reason, v.usage
 	if err != nil {
-		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+		span.SetStatus(codes.Error, err.Error())
+		if ctx.Err() != nil {
+			result = "timeout"
+			metricAdmissionRequests.WithLabelValues(ns, "timeout").Inc()
+			logger.Error(err, "Timed out evaluating pod", "denyOnTimeout", s.DenyOnTimeout)
+			admissionReview.Response = s.timeoutResponse(req.UID)
+		} else {
+			logger.Error(err, "Failed to evaluate pod, allowing")
+			admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+		}
+		admissionReview.Response.AuditAnnotations = auditAnnotations(result, policy.Name, err.Error(), audit.Usage{})
 		writeAdmissionResponse(w, &admissionReview)
 		return
 	}
 
-	if !allowed {
+	if !v.allowed {
+		result = "denied"
 		metricAdmissionViolations.WithLabelValues(ns, reason).Inc()
 		metricAdmissionRequests.WithLabelValues(ns, "denied").Inc()
+		logger.Info("Denied pod", "reason", reason, "usagePods", usage.Pods, "usageCPU", usage.CPU, "usageMemory", usage.Memory)
+		s.Audit.Record(audit.Event{
+			Source:    audit.SourceWebhook,
+			Namespace: ns,
+			Pod:       podName,
+			Policy:    policy.Name,
+			Reason:    reason,
+			Usage:     usage,
+			Decision:  audit.DecisionDenied,
+		})
 		admissionReview.Response = &admissionv1.AdmissionResponse{
-			Allowed: false,
-			Result: &metav1.Status{
-				Message: fmt.Sprintf("Pod denied by QuotaPolicy: %s", reason),
-			},
-			UID: req.UID,
+			Allowed:  false,
+			Result:   v.status("Pod denied by QuotaPolicy"),
+			UID:      req.UID,
+			Warnings: v.warnings(),
 		}
 	} else {
+		result = "allowed"
 		metricAdmissionRequests.WithLabelValues(ns, "allowed").Inc()
+		logger.V(logging.Debug).Info("Allowed pod")
 		admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
 	}
+	admissionReview.Response.AuditAnnotations = auditAnnotations(result, policy.Name, reason, usage)
 
 	writeAdmissionResponse(w, &admissionReview)
 }
 
-// InvalidateHandler invalidates cache for a namespace.
+// InvalidateHandler invalidates cache for a namespace. It acts on whoever asks, so it must be
+// mounted behind authentication, such as health.RequireAPIServerAuth.
 func (s *WebhookServer) InvalidateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 	var payload struct {
 		Namespace string `json:"namespace"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
+	if !decodeBody(w, r, &payload, "invalidation request") {
 		return
 	}
 	if payload.Namespace == "" {
@@ -158,60 +402,147 @@ func (s *WebhookServer) InvalidateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 	s.Cache.Invalidate(payload.Namespace)
+	logging.L().WithName("webhook").Info("Invalidated policy cache", "namespace", payload.Namespace)
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`{"status":"invalidated"}`))
 }
 
+// EvaluatePod reports whether pod would be admitted into namespace under spec, which must
+// already have scheduled changes applied. It only reads from the API server.
+func (s *WebhookServer) EvaluatePod(ctx context.Context, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (bool, string, error) {
+	return s.evaluatePodAgainstPolicy(ctx, pod, namespace, spec)
+}
+
 // evaluatePodAgainstPolicy compares pod requests to policy limits.
-func (s *WebhookServer) evaluatePodAgainstPolicy(ctx context.Context, pod *corev1.Pod, namespace string, spec *platformv1alpha1.ResourceQuotaPolicySpec) (bool, string, error) {
-	maxPods := int64(spec.MaxPods)
-	maxCPU := resource.MustParse(spec.MaxCPU)
-	maxMem := resource.MustParse(spec.MaxMemory)
+func (s *WebhookServer) evaluatePodAgainstPolicy(ctx context.Context, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (bool, string, error) {
+	v, err := s.evaluate(ctx, pod, namespace, spec)
+	return v.allowed, v.reason, err
+}
 
-	pods, err := s.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+// evaluate is evaluatePodAgainstPolicy that also returns the namespace usage, before the pod,
+// that the decision was based on.
+func (s *WebhookServer) evaluate(ctx context.Context, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	exclude := s.exclusions(namespace, spec)
+	if exclude.Excludes(pod) {
+		return allow(audit.Usage{}), nil
+	}
+	if spec.MissingRequestsPolicy == platformv1beta1.MissingRequestsDeny {
+		if missing := handlers.MissingRequests(pod); len(missing) > 0 {
+			return deny(fmt.Sprintf("missing requests: containers %s must request cpu and memory", strings.Join(missing, ", ")), audit.Usage{}), nil
+		}
+	}
+	u, err := s.listUsage(ctx, namespace, spec, exclude)
 	if err != nil {
-		return true, "", err
+		return allow(audit.Usage{}), err
 	}
 
-	totalPods := int64(0)
-	totalCPU := resource.MustParse("0")
-	totalMem := resource.MustParse("0")
-	for _, p := range pods.Items {
-		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
-			continue
-		}
-		totalPods++
-		for _, c := range p.Spec.Containers {
-			if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
-				totalCPU.Add(q)
-			}
-			if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
-				totalMem.Add(q)
-			}
-		}
+	// usage before this pod, exposed to validation rules
+	usage := map[string]int64{
+		"pods":   u.pods,
+		"cpu":    u.cpu.MilliValue(),
+		"memory": u.memory.Value(),
 	}
+	before := u.audit()
 
-	totalPods++
-	for _, c := range pod.Spec.Containers {
-		if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
-			totalCPU.Add(q)
-		}
-		if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
-			totalMem.Add(q)
+	ctx, decisionSpan := tracing.Tracer().Start(ctx, "admission.decision")
+	defer decisionSpan.End()
+
+	if err := u.add(ctx, pod); err != nil {
+		return allow(before), err
+	}
+	v := allow(before)
+	if exceeded := u.exceeded(spec); exceeded != nil {
+		v = u.denyOver(exceeded, spec)
+	} else if ok, msg, err := rules.evaluate(spec.ValidationRules, pod, usage); err != nil {
+		return allow(before), err
+	} else if !ok {
+		v = deny(msg, before)
+	}
+
+	if spec.ExternalPolicy != nil {
+		return s.decideExternally(ctx, spec.ExternalPolicy, namespace, pod, spec, v), nil
+	}
+	return v, nil
+}
+
+// evaluateEphemeral decides on the ephemeral containers pod gained over oldPod. They run next to
+// the pod's containers without adding a pod, so only the resource limits apply.
+func (s *WebhookServer) evaluateEphemeral(ctx context.Context, oldPod, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	exclude := s.exclusions(namespace, spec)
+	if exclude.Excludes(pod) || !handlers.CountsTowardUsage(pod, time.Now()) {
+		return allow(audit.Usage{}), nil
+	}
+	added := handlers.AddedEphemeralContainers(oldPod, pod)
+	if added == nil {
+		return allow(audit.Usage{}), nil
+	}
+	if spec.MissingRequestsPolicy == platformv1beta1.MissingRequestsDeny {
+		if missing := handlers.MissingRequests(added); len(missing) > 0 {
+			return deny(fmt.Sprintf("missing requests: ephemeral containers %s must request cpu and memory", strings.Join(missing, ", ")), audit.Usage{}), nil
 		}
 	}
 
-	if maxPods > 0 && totalPods > maxPods {
-		return false, fmt.Sprintf("maxPods exceeded: %d > %d", totalPods, maxPods), nil
+	u, err := s.listUsage(ctx, namespace, spec, exclude)
+	if err != nil {
+		return allow(audit.Usage{}), err
+	}
+
+	_, decisionSpan := tracing.Tracer().Start(ctx, "admission.decision")
+	defer decisionSpan.End()
+
+	u.addRequestsOf(pod, added)
+	if v := u.exceeded(spec); v != nil {
+		return u.denyOver(v, spec), nil
+	}
+	return allow(u.base.audit()), nil
+}
+
+// evaluateResize decides on an in-place resize of pod from oldPod. Only a resource whose requests
+// grow is checked, so shrinking a pod in a namespace already over its quota is never denied.
+func (s *WebhookServer) evaluateResize(ctx context.Context, oldPod, pod *corev1.Pod, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec) (verdict, error) {
+	exclude := s.exclusions(namespace, spec)
+	if exclude.Excludes(pod) || !handlers.CountsTowardUsage(pod, time.Now()) {
+		return allow(audit.Usage{}), nil
 	}
-	if maxCPU.Cmp(resource.MustParse("0")) > 0 && totalCPU.Cmp(maxCPU) > 0 {
-		return false, fmt.Sprintf("cpu exceeded: %s > %s", totalCPU.String(), maxCPU.String()), nil
+	defaults := handlers.RequestDefaultsFor(spec)
+	oldCPU, oldMem := defaults.PodRequests(oldPod)
+	newCPU, newMem := defaults.PodRequests(pod)
+	cpuGrows, memGrows := newCPU.Cmp(oldCPU) > 0, newMem.Cmp(oldMem) > 0
+	if !cpuGrows && !memGrows {
+		return allow(audit.Usage{}), nil
 	}
-	if maxMem.Cmp(resource.MustParse("0")) > 0 && totalMem.Cmp(maxMem) > 0 {
-		return false, fmt.Sprintf("memory exceeded: %s > %s", totalMem.String(), maxMem.String()), nil
+
+	// the listed pod still has its old requests
+	u, err := s.listUsage(ctx, namespace, spec, exclude)
+	if err != nil {
+		return allow(audit.Usage{}), err
+	}
+
+	_, decisionSpan := tracing.Tracer().Start(ctx, "admission.decision")
+	defer decisionSpan.End()
+
+	u.resize(pod, oldCPU, oldMem, newCPU, newMem)
+	if v := u.resizeExceeded(pod, spec, cpuGrows, memGrows); v != nil {
+		return u.denyOver(v, spec), nil
 	}
+	return allow(u.base.audit()), nil
+}
 
-	return true, "", nil
+// auditAnnotations explain an admission decision in the cluster audit log. The API server
+// prefixes each key with the webhook's name, e.g. local-validator.example.com/decision.
+func auditAnnotations(decision, policy, reason string, usage audit.Usage) map[string]string {
+	annotations := map[string]string{"decision": decision}
+	if policy != "" {
+		annotations["policy"] = policy
+	}
+	if reason != "" {
+		annotations["reason"] = reason
+	}
+	// usage is unset when the decision didn't need it, e.g. for an excluded pod
+	if usage.CPU != "" {
+		annotations["usage"] = fmt.Sprintf("pods=%d,cpu=%s,memory=%s", usage.Pods, usage.CPU, usage.Memory)
+	}
+	return annotations
 }
 
 // writeAdmissionResponse encodes response.
@@ -222,5 +553,5 @@ func writeAdmissionResponse(w http.ResponseWriter, review *admissionv1.Admission
 
 // MetricsHandler exposes Prometheus metrics.
 func MetricsHandler() http.Handler {
-	return promhttp.Handler()
+	return metrics.Handler()
 }
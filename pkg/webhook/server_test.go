@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleValidatePodsSuspended(t *testing.T) {
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-ns"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	pod, _ := json.Marshal(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"}})
+	body, _ := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Namespace: "test-ns",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: pod},
+	}})
+
+	for _, suspend := range []bool{false, true} {
+		srv := &WebhookServer{
+			Clientset: fakeclient.NewSimpleClientset(running),
+			Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-ns"},
+				Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 1, Suspend: suspend},
+			}},
+		}
+		rec := httptest.NewRecorder()
+		srv.HandleValidatePods(rec, jsonRequest("/validate", body))
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+			t.Fatal(err)
+		}
+		if review.Response.Allowed != suspend {
+			t.Fatalf("suspend=%v: allowed = %v", suspend, review.Response.Allowed)
+		}
+		if suspend {
+			if got := review.Response.AuditAnnotations["decision"]; got != "suspended" {
+				t.Fatalf("decision audit annotation = %q, want suspended", got)
+			}
+		}
+	}
+}
+
+func TestInvalidateHandler(t *testing.T) {
+	srv := &WebhookServer{Cache: staticCache{}}
+	tests := []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, `{}`, http.StatusBadRequest},
+		{http.MethodPost, `{"namespace":"ns1"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/invalidate", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.InvalidateHandler(rec, r)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.body, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// serviceUsage is what Services count against maxLoadBalancers and maxNodePorts.
+type serviceUsage struct {
+	loadBalancers int32
+	nodePorts     int32
+}
+
+// usageOf returns what svc counts: each of its ports takes a node port on NodePort Services and
+// on LoadBalancer Services that don't turn allocateLoadBalancerNodePorts off.
+func usageOf(svc *corev1.Service) serviceUsage {
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeNodePort:
+		return serviceUsage{nodePorts: int32(len(svc.Spec.Ports))}
+	case corev1.ServiceTypeLoadBalancer:
+		u := serviceUsage{loadBalancers: 1}
+		if alloc := svc.Spec.AllocateLoadBalancerNodePorts; alloc == nil || *alloc {
+			u.nodePorts = int32(len(svc.Spec.Ports))
+		}
+		return u
+	}
+	return serviceUsage{}
+}
+
+// exceeded explains which limit of spec u is over, or returns "" when it is within them.
+func (u serviceUsage) exceeded(spec *platformv1beta1.ResourceQuotaPolicySpec, grown serviceUsage) string {
+	if spec.MaxLoadBalancers > 0 && grown.loadBalancers > 0 && u.loadBalancers > spec.MaxLoadBalancers {
+		return fmt.Sprintf("maxLoadBalancers exceeded: %d > %d", u.loadBalancers, spec.MaxLoadBalancers)
+	}
+	if spec.MaxNodePorts > 0 && grown.nodePorts > 0 && u.nodePorts > spec.MaxNodePorts {
+		return fmt.Sprintf("maxNodePorts exceeded: %d > %d", u.nodePorts, spec.MaxNodePorts)
+	}
+	return ""
+}
+
+// HandleValidateService handles AdmissionReview v1 for Service CREATE and UPDATE operations,
+// denying those that take the namespace over maxLoadBalancers or maxNodePorts. Load balancers
+// cost money and node ports are shared by the whole cluster, so neither is left to pod quotas.
+// Updates that don't add either are allowed, even in a namespace already over its limits.
+func (s *WebhookServer) HandleValidateService(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.Kind.Kind != "Service" || req.SubResource != "" || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ns := req.Namespace
+	logger := logging.L().WithName("webhook").WithValues("namespace", ns, "service", req.Name)
+	var svc, oldSvc corev1.Service
+	if err := json.Unmarshal(req.Object.Raw, &svc); err != nil {
+		logger.Error(err, "Failed to decode service, allowing")
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	// what the request adds to the namespace
+	grown := usageOf(&svc)
+	if req.Operation == admissionv1.Update {
+		if err := json.Unmarshal(req.OldObject.Raw, &oldSvc); err != nil {
+			logger.Error(err, "Failed to decode old service, allowing")
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		old := usageOf(&oldSvc)
+		grown.loadBalancers -= old.loadBalancers
+		grown.nodePorts -= old.nodePorts
+	}
+	if grown.loadBalancers <= 0 && grown.nodePorts <= 0 {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.admissionDeadline(r))
+	defer cancel()
+	policy, effective := s.policyFor(ctx, ns)
+	if policy == nil || (effective.MaxLoadBalancers <= 0 && effective.MaxNodePorts <= 0) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	services, err := s.Clientset.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Error(err, "Failed to list services, allowing", "policy", policy.Name)
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	total := usageOf(&svc)
+	for i := range services.Items {
+		// an updated service is counted as it will be
+		if existing := &services.Items[i]; existing.Name != svc.Name {
+			u := usageOf(existing)
+			total.loadBalancers += u.loadBalancers
+			total.nodePorts += u.nodePorts
+		}
+	}
+	reason := total.exceeded(effective, grown)
+	if reason == "" {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	logger.Info("Denied service", "policy", policy.Name, "reason", reason)
+	s.denyObject(&admissionReview, policy.Name, reason, fmt.Sprintf("Service %s of type %s denied by QuotaPolicy: %s", svc.Name, svc.Spec.Type, reason))
+	writeAdmissionResponse(w, &admissionReview)
+}
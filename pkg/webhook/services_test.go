@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleValidateService(t *testing.T) {
+	ns := "web"
+	service := func(name string, typ corev1.ServiceType, ports int) *corev1.Service {
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}, Spec: corev1.ServiceSpec{Type: typ}}
+		for i := range ports {
+			svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: int32(8080 + i)})
+		}
+		return svc
+	}
+	noNodePorts := service("internal-lb", corev1.ServiceTypeLoadBalancer, 2)
+	noNodePorts.Spec.AllocateLoadBalancerNodePorts = new(bool)
+	srv := &WebhookServer{
+		Clientset: fakeclient.NewSimpleClientset(service("frontend", corev1.ServiceTypeLoadBalancer, 2), service("debug", corev1.ServiceTypeNodePort, 1)),
+		Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+			Spec:       v1beta1.ResourceQuotaPolicySpec{MaxLoadBalancers: 1, MaxNodePorts: 4},
+		}},
+	}
+	raw := func(svc *corev1.Service) runtime.RawExtension {
+		b, err := json.Marshal(svc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+
+	tests := []struct {
+		name     string
+		svc, old *corev1.Service
+		wantErr  string
+	}{
+		{name: "cluster IP", svc: service("api", corev1.ServiceTypeClusterIP, 3)},
+		{name: "node port within limit", svc: service("api", corev1.ServiceTypeNodePort, 1)},
+		{name: "node ports over limit", svc: service("api", corev1.ServiceTypeNodePort, 2), wantErr: "maxNodePorts exceeded: 5 > 4"},
+		{name: "second load balancer", svc: noNodePorts, wantErr: "maxLoadBalancers exceeded: 2 > 1"},
+		{name: "load balancer gains a port", svc: service("frontend", corev1.ServiceTypeLoadBalancer, 3), old: service("frontend", corev1.ServiceTypeLoadBalancer, 2)},
+		{name: "node port becomes load balancer", svc: service("debug", corev1.ServiceTypeLoadBalancer, 1), old: service("debug", corev1.ServiceTypeNodePort, 1), wantErr: "maxLoadBalancers exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Service"},
+				Namespace: ns,
+				Name:      tt.svc.Name,
+				Operation: admissionv1.Create,
+				Object:    raw(tt.svc),
+			}
+			if tt.old != nil {
+				req.Operation, req.OldObject = admissionv1.Update, raw(tt.old)
+			}
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: req})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			srv.HandleValidateService(rec, jsonRequest("/validate-service", body))
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if allowed := tt.wantErr == ""; review.Response.Allowed != allowed {
+				t.Fatalf("allowed = %v, want %v (%+v)", review.Response.Allowed, allowed, review.Response.Result)
+			}
+			if tt.wantErr != "" && !strings.Contains(review.Response.Result.Message, tt.wantErr) {
+				t.Fatalf("message %q does not contain %q", review.Response.Result.Message, tt.wantErr)
+			}
+		})
+	}
+}
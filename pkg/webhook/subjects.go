@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// subjectUsage is what the pods of one of a policy's subjects or node scopes use.
+type subjectUsage struct {
+	*namespaceUsage
+	// label is how denials refer to the subject, e.g. "serviceAccount ci-runner"
+	label string
+	// matches reports whether a pod belongs to the subject
+	matches func(pod *corev1.Pod) bool
+	// nodeScope is set for the usage of a node scope
+	nodeScope bool
+	// limits are the limits of the subject, so namespaceUsage.exceeded can check them
+	limits platformv1beta1.ResourceQuotaPolicySpec
+}
+
+func newSubjectUsage(quota platformv1beta1.SubjectQuota, u *namespaceUsage) *subjectUsage {
+	label := "serviceAccount " + quota.Name
+	if quota.Kind == platformv1beta1.SubjectUser {
+		label = "user " + quota.Name
+	}
+	return &subjectUsage{
+		namespaceUsage: &namespaceUsage{defaults: u.defaults, extended: map[string]resource.Quantity{}},
+		label:          label,
+		matches:        func(pod *corev1.Pod) bool { return subjectMatches(quota, pod) },
+		limits: platformv1beta1.ResourceQuotaPolicySpec{
+			MaxPods:   quota.MaxPods,
+			MaxCPU:    quota.MaxCPU,
+			MaxMemory: quota.MaxMemory,
+		},
+	}
+}
+
+// name is how denials refer to the subject.
+func (s *subjectUsage) name() string {
+	return s.label
+}
+
+// subjectMatches reports whether pod belongs to the subject of quota.
+func subjectMatches(quota platformv1beta1.SubjectQuota, pod *corev1.Pod) bool {
+	switch quota.Kind {
+	case platformv1beta1.SubjectServiceAccount:
+		sa := pod.Spec.ServiceAccountName
+		if sa == "" {
+			sa = "default"
+		}
+		return sa == quota.Name
+	case platformv1beta1.SubjectUser:
+		return pod.Annotations[platformv1beta1.AnnotationRequestedBy] == quota.Name
+	}
+	return false
+}
+
+// grew reports whether the request being evaluated added to the subject's usage, so a subject
+// that was already over its limits doesn't get requests of other subjects denied.
+func (s *subjectUsage) grew() bool {
+	return s.pods > s.base.pods || s.cpu.Cmp(s.base.cpu) > 0 || s.memory.Cmp(s.base.memory) > 0
+}
+
+// subjectsOf returns the subjects pod belongs to.
+func (u *namespaceUsage) subjectsOf(pod *corev1.Pod) []*subjectUsage {
+	var out []*subjectUsage
+	for _, s := range u.subjects {
+		if s.matches(pod) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// setRequestedBy records user as the creator of pod, see v1beta1.AnnotationRequestedBy.
+func setRequestedBy(pod *corev1.Pod, user string) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[platformv1beta1.AnnotationRequestedBy] = user
+}
+
+// HandleMutatePod handles AdmissionReview v1 for pod CREATE and UPDATE operations. It sets the
+// quota.platform/requested-by annotation User subjects are counted by to the requesting user on
+// creation, and keeps updates from changing it.
+func (s *WebhookServer) HandleMutatePod(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.Kind.Kind != "Pod" || req.SubResource != "" {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	var pod, oldPod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	want, keep := req.UserInfo.Username, true
+	switch req.Operation {
+	case admissionv1.Create:
+	case admissionv1.Update:
+		if err := json.Unmarshal(req.OldObject.Raw, &oldPod); err != nil {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		want, keep = oldPod.Annotations[platformv1beta1.AnnotationRequestedBy]
+	default:
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	if patch := requestedByPatch(pod.Annotations, want, keep); len(patch) > 0 {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			writeAdmissionResponse(w, &admissionReview)
+			return
+		}
+		pt := admissionv1.PatchTypeJSONPatch
+		admissionReview.Response.Patch = raw
+		admissionReview.Response.PatchType = &pt
+	}
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+// requestedByPatch returns the JSON patch that sets AnnotationRequestedBy in annotations to want,
+// or removes it when keep is false.
+func requestedByPatch(annotations map[string]string, want string, keep bool) []patchOperation {
+	have, had := annotations[platformv1beta1.AnnotationRequestedBy]
+	// "/" in a key is escaped as "~1" in a JSON pointer
+	path := "/metadata/annotations/" + strings.ReplaceAll(platformv1beta1.AnnotationRequestedBy, "/", "~1")
+	switch {
+	case !keep && had:
+		return []patchOperation{{Op: "remove", Path: path}}
+	case !keep || (had && have == want):
+		return nil
+	case annotations == nil:
+		return []patchOperation{{Op: "add", Path: "/metadata/annotations", Value: map[string]string{platformv1beta1.AnnotationRequestedBy: want}}}
+	}
+	return []patchOperation{{Op: "add", Path: path, Value: want}}
+}
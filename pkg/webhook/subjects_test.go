@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEvaluateSubjects(t *testing.T) {
+	ns := "ci"
+	pod := func(name, sa, user, cpu string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: corev1.PodSpec{ServiceAccountName: sa, Containers: []corev1.Container{{
+				Name:      "c",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if user != "" {
+			setRequestedBy(p, user)
+		}
+		return p
+	}
+	srv := &WebhookServer{Clientset: fakeclient.NewSimpleClientset(
+		pod("build-1", "pipeline-a", "", "600m"),
+		pod("build-2", "pipeline-b", "", "900m"),
+		pod("debug", "", "alice", "100m"),
+	)}
+	pipelineCPU := resource.MustParse("1")
+	spec := v1beta1.ResourceQuotaPolicySpec{Subjects: []v1beta1.SubjectQuota{
+		{Kind: v1beta1.SubjectServiceAccount, Name: "pipeline-a", MaxCPU: &pipelineCPU},
+		// already over its limit, which must not affect the other subjects
+		{Kind: v1beta1.SubjectServiceAccount, Name: "pipeline-b", MaxCPU: &pipelineCPU, MaxPods: 1},
+		{Kind: v1beta1.SubjectUser, Name: "alice", MaxPods: 1},
+	}}
+
+	if v, err := srv.evaluate(context.TODO(), pod("build-3", "pipeline-a", "", "400m"), ns, &spec); err != nil || !v.allowed {
+		t.Fatalf("pod within its service account's limit denied: allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	v, err := srv.evaluate(context.TODO(), pod("build-3", "pipeline-a", "", "500m"), ns, &spec)
+	if err != nil || v.allowed || v.reason != "serviceAccount pipeline-a: cpu exceeded: 1100m > 1" {
+		t.Fatalf("expected service account cpu denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	if w := v.warnings(); len(w) != 1 || w[0] != "remaining quota: serviceAccount pipeline-a: cpu 400m" {
+		t.Fatalf("unexpected warnings %q", w)
+	}
+
+	v, err = srv.evaluate(context.TODO(), pod("shell", "", "alice", "10m"), ns, &spec)
+	if err != nil || v.allowed || !strings.HasPrefix(v.reason, "user alice: maxPods exceeded") {
+		t.Fatalf("expected user pod denial, got allowed=%v reason=%q err=%v", v.allowed, v.reason, err)
+	}
+	if v, _ := srv.evaluate(context.TODO(), pod("shell", "", "bob", "10m"), ns, &spec); !v.allowed {
+		t.Fatalf("pod of a user without a subject denied: %q", v.reason)
+	}
+}
+
+func TestRequestedByPatch(t *testing.T) {
+	key := v1beta1.AnnotationRequestedBy
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		keep        bool
+		wantOps     string
+	}{
+		{name: "no annotations", want: "alice", keep: true, wantOps: "add /metadata/annotations"},
+		{name: "other annotations", annotations: map[string]string{"a": "b"}, want: "alice", keep: true, wantOps: "add /metadata/annotations/quota.platform~1requested-by"},
+		{name: "forged", annotations: map[string]string{key: "bob"}, want: "alice", keep: true, wantOps: "add /metadata/annotations/quota.platform~1requested-by"},
+		{name: "unchanged", annotations: map[string]string{key: "alice"}, want: "alice", keep: true},
+		{name: "added on update", annotations: map[string]string{key: "bob"}, wantOps: "remove /metadata/annotations/quota.platform~1requested-by"},
+		{name: "never set", annotations: map[string]string{"a": "b"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ops []string
+			for _, op := range requestedByPatch(tc.annotations, tc.want, tc.keep) {
+				ops = append(ops, op.Op+" "+op.Path)
+			}
+			if got := strings.Join(ops, "; "); got != tc.wantOps {
+				t.Fatalf("got patch %q, want %q", got, tc.wantOps)
+			}
+		})
+	}
+}
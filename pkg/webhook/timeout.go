@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultTimeout matches the API server's default webhook timeoutSeconds.
+	DefaultTimeout = 10 * time.Second
+	// timeoutMargin is left of the API server's timeout to write the response in.
+	timeoutMargin = 500 * time.Millisecond
+)
+
+// admissionDeadline returns how long handling r may take: slightly under the timeout the API
+// server passes in the "timeout" query parameter, or under s.Timeout when there is none.
+func (s *WebhookServer) admissionDeadline(r *http.Request) time.Duration {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+	if timeout > 2*timeoutMargin {
+		return timeout - timeoutMargin
+	}
+	return timeout / 2
+}
+
+// timeoutResponse answers an admission that could not be evaluated within its deadline. Rather
+// than leaving the outcome to the API server's failurePolicy, it allows with a warning, or
+// denies when s.DenyOnTimeout is set.
+func (s *WebhookServer) timeoutResponse(uid types.UID) *admissionv1.AdmissionResponse {
+	if s.DenyOnTimeout {
+		return &admissionv1.AdmissionResponse{
+			UID:     uid,
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: "resource quota check timed out, try again",
+				Reason:  metav1.StatusReasonTimeout,
+				Code:    http.StatusGatewayTimeout,
+			},
+		}
+	}
+	return &admissionv1.AdmissionResponse{
+		UID:      uid,
+		Allowed:  true,
+		Warnings: []string{"resource quota was not checked: evaluation timed out"},
+	}
+}
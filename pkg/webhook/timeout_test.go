@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAdmissionDeadline(t *testing.T) {
+	tests := []struct {
+		url     string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{"/validate", 0, DefaultTimeout - timeoutMargin},
+		{"/validate", 5 * time.Second, 5*time.Second - timeoutMargin},
+		{"/validate?timeout=3s", 5 * time.Second, 3*time.Second - timeoutMargin},
+		{"/validate?timeout=600ms", 0, 300 * time.Millisecond},
+		{"/validate?timeout=bogus", 0, DefaultTimeout - timeoutMargin},
+	}
+	for _, tt := range tests {
+		s := &WebhookServer{Timeout: tt.timeout}
+		if got := s.admissionDeadline(httptest.NewRequest(http.MethodPost, tt.url, nil)); got != tt.want {
+			t.Errorf("%s with timeout %s: got %s, want %s", tt.url, tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestHandleValidatePodsTimeout(t *testing.T) {
+	cs := fakeclient.NewSimpleClientset()
+	// a list that outlives the deadline fails the way a real client does
+	cs.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		time.Sleep(100 * time.Millisecond)
+		return true, nil, context.DeadlineExceeded
+	})
+	pod, _ := json.Marshal(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"}})
+	body, _ := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Namespace: "test-ns",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: pod},
+	}})
+
+	for _, deny := range []bool{false, true} {
+		srv := &WebhookServer{
+			Clientset:     cs,
+			Cache:         staticCache{&v1beta1.ResourceQuotaPolicy{ObjectMeta: metav1.ObjectMeta{Name: "quota"}}},
+			DenyOnTimeout: deny,
+		}
+		rec := httptest.NewRecorder()
+		srv.HandleValidatePods(rec, jsonRequest("/validate?timeout=100ms", body))
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+			t.Fatal(err)
+		}
+		if review.Response.Allowed == deny {
+			t.Fatalf("denyOnTimeout=%v: allowed = %v", deny, review.Response.Allowed)
+		}
+		if got := review.Response.AuditAnnotations["decision"]; got != "timeout" {
+			t.Fatalf("decision audit annotation = %q, want timeout", got)
+		}
+		if !deny && len(review.Response.Warnings) == 0 {
+			t.Fatal("pod allowed after a timeout without a warning")
+		}
+	}
+}
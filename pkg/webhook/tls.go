@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tlsVersions are the values -tls-min-version accepts.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOptions hardens the webhook's TLS listener beyond serving its certificate.
+type TLSOptions struct {
+	// MinVersion is the lowest TLS version accepted, 1.2 or 1.3.
+	MinVersion string
+	// CipherSuites are the IANA names of the TLS 1.2 cipher suites accepted, comma separated;
+	// empty leaves Go's defaults. TLS 1.3 suites are not configurable.
+	CipherSuites string
+	// ClientCAFile is a PEM bundle of the CAs a client certificate must be signed by; empty
+	// accepts admissions from any client.
+	ClientCAFile string
+}
+
+// AddFlags registers -tls-min-version, -tls-cipher-suites and -client-ca-file.
+func (o *TLSOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.MinVersion, "tls-min-version", "1.2", "Lowest TLS version the webhook accepts: 1.2 or 1.3")
+	fs.StringVar(&o.CipherSuites, "tls-cipher-suites", "", "Comma-separated IANA names of the TLS 1.2 cipher suites the webhook accepts, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256; empty uses Go's defaults")
+	fs.StringVar(&o.ClientCAFile, "client-ca-file", "", "PEM bundle of the CAs that sign the API server's client certificate; when set, admission requests without a certificate verified against it are rejected")
+}
+
+// Apply sets the options on cfg, reading the client CA bundle.
+func (o TLSOptions) Apply(cfg *tls.Config) error {
+	version, ok := tlsVersions[o.MinVersion]
+	if !ok {
+		return fmt.Errorf("-tls-min-version %q must be 1.2 or 1.3", o.MinVersion)
+	}
+	cfg.MinVersion = version
+
+	if o.CipherSuites != "" {
+		suites, err := parseCipherSuites(o.CipherSuites)
+		if err != nil {
+			return err
+		}
+		cfg.CipherSuites = suites
+	}
+
+	if o.ClientCAFile != "" {
+		pem, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read -client-ca-file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("-client-ca-file %s holds no PEM certificate", o.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		// a certificate is only verified if given, so kubelet probes and metrics scrapes without
+		// one still reach the endpoints RequireClientCert leaves open
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return nil
+}
+
+// parseCipherSuites returns the IDs of the comma-separated suite names in s. Suites Go deems
+// insecure are refused.
+func parseCipherSuites(s string) ([]uint16, error) {
+	byName := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := byName[name]
+		switch {
+		case insecure[name]:
+			return nil, fmt.Errorf("-tls-cipher-suites: %s is insecure", name)
+		case !ok:
+			names := make([]string, 0, len(byName))
+			for n := range byName {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("-tls-cipher-suites: unknown cipher suite %q, must be one of %s", name, strings.Join(names, ", "))
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// RequireClientCert rejects requests to next that didn't present a client certificate verified
+// against the TLS config's ClientCAs, except to the open paths, e.g. the probes. Paths match
+// exactly, so opening one never opens what is mounted below it.
+func RequireClientCert(next http.Handler, open ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(open, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			metricClientCertRejections.Inc()
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
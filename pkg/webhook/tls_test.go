@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSOptionsApply(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	garbage := filepath.Join(dir, "garbage.crt")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg tls.Config
+	opts := TLSOptions{
+		MinVersion:   "1.3",
+		CipherSuites: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		ClientCAFile: caFile,
+	}
+	if err := opts.Apply(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 || len(cfg.CipherSuites) != 2 || cfg.ClientCAs == nil || cfg.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Fatalf("unexpected config: min %x, suites %v, client auth %v", cfg.MinVersion, cfg.CipherSuites, cfg.ClientAuth)
+	}
+
+	for name, bad := range map[string]TLSOptions{
+		"old version":     {MinVersion: "1.0"},
+		"unknown suite":   {MinVersion: "1.2", CipherSuites: "TLS_NOPE"},
+		"insecure suite":  {MinVersion: "1.2", CipherSuites: "TLS_RSA_WITH_RC4_128_SHA"},
+		"missing CA file": {MinVersion: "1.2", ClientCAFile: filepath.Join(dir, "missing.crt")},
+		"no certificate":  {MinVersion: "1.2", ClientCAFile: garbage},
+	} {
+		if err := bad.Apply(&tls.Config{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	h := RequireClientCert(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "/readyz", "/debug/cache")
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+
+	tests := []struct {
+		path  string
+		state *tls.ConnectionState
+		want  int
+	}{
+		{"/validate", nil, http.StatusUnauthorized},
+		{"/validate", &tls.ConnectionState{}, http.StatusUnauthorized},
+		{"/validate", verified, http.StatusOK},
+		{"/readyz", &tls.ConnectionState{}, http.StatusOK},
+		{"/debug/cache", &tls.ConnectionState{}, http.StatusOK},
+		// pprof doesn't authorize its callers, so it isn't open with the debug endpoints
+		{"/debug/pprof/heap", &tls.ConnectionState{}, http.StatusUnauthorized},
+		{"/debug/pprof/heap", verified, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		r.TLS = tt.state
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tt.want {
+			t.Errorf("%s with %v: got %d, want %d", tt.path, tt.state, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/handlers"
+	"github.com/sri2103/resource-quota-enforcer/pkg/tracing"
+)
+
+// namespaceUsage is what pods count against one policy.
+type namespaceUsage struct {
+	pods     int64
+	cpu      resource.Quantity
+	memory   resource.Quantity
+	extended map[string]resource.Quantity
+	// base is the usage before the request being evaluated, see checkpoint
+	base *namespaceUsage
+
+	defaults      handlers.RequestDefaults
+	devices       *handlers.DeviceResolver
+	extendedNames []string
+	// claims already counted, so a claim shared by several pods counts once
+	claimsSeen map[string]bool
+	// subjects is the usage of each of the policy's subjects and node scopes
+	subjects []*subjectUsage
+}
+
+// newUsage returns an empty usage of spec. nodes holds the labels of every node by name, for the
+// node scopes of spec.
+func (s *WebhookServer) newUsage(spec *platformv1beta1.ResourceQuotaPolicySpec, nodes map[string]labels.Set) *namespaceUsage {
+	names := handlers.ExtendedNames(spec.MaxExtendedResources)
+	u := &namespaceUsage{
+		extended:      make(map[string]resource.Quantity, len(names)),
+		defaults:      handlers.RequestDefaultsFor(spec),
+		devices:       s.Devices,
+		extendedNames: names,
+		claimsSeen:    map[string]bool{},
+	}
+	for _, quota := range spec.Subjects {
+		u.subjects = append(u.subjects, newSubjectUsage(quota, u))
+	}
+	if scope := spec.NodeScope; scope != nil {
+		for _, quota := range scope.Scopes {
+			u.subjects = append(u.subjects, newScopeUsage(quota, quota.Name == scope.DefaultScope, nodes, u))
+		}
+	}
+	return u
+}
+
+// listUsage sums the pods of namespace that count toward spec.
+func (s *WebhookServer) listUsage(ctx context.Context, namespace string, spec *platformv1beta1.ResourceQuotaPolicySpec, exclude handlers.Exclusions) (*namespaceUsage, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "admission.usage_compute")
+	defer span.End()
+
+	var nodes map[string]labels.Set
+	if spec.NodeScope != nil {
+		var err error
+		if nodes, err = s.nodeLabels(ctx); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+	u := s.newUsage(spec, nodes)
+	now := time.Now()
+	err := handlers.EachPod(ctx, s.Clientset, namespace, func(p *corev1.Pod) error {
+		if !handlers.CountsTowardUsage(p, now) || exclude.Excludes(p) {
+			return nil
+		}
+		return u.add(ctx, p)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int64("rqe.usage.pods", u.pods))
+	u.checkpoint()
+	return u, nil
+}
+
+// checkpoint records the current totals as the usage before the request, so violations can
+// tell what the request adds.
+func (u *namespaceUsage) checkpoint() {
+	base := &namespaceUsage{
+		pods:     u.pods,
+		cpu:      u.cpu.DeepCopy(),
+		memory:   u.memory.DeepCopy(),
+		extended: make(map[string]resource.Quantity, len(u.extended)),
+	}
+	for name, q := range u.extended {
+		base.extended[name] = q.DeepCopy()
+	}
+	u.base = base
+	for _, s := range u.subjects {
+		s.checkpoint()
+	}
+}
+
+// add counts pod, for the namespace and the subjects it belongs to.
+func (u *namespaceUsage) add(ctx context.Context, pod *corev1.Pod) error {
+	u.pods++
+	u.addRequests(pod)
+	for _, s := range u.subjectsOf(pod) {
+		s.pods++
+		s.addRequests(pod)
+	}
+	if len(u.extendedNames) == 0 {
+		return nil
+	}
+	extended, err := u.devices.ExtendedRequests(ctx, pod, u.extendedNames, u.claimsSeen)
+	if err != nil {
+		return err
+	}
+	u.addExtended(extended)
+	return nil
+}
+
+// addReplicas counts n pods created from the template pod.
+func (u *namespaceUsage) addReplicas(ctx context.Context, pod *corev1.Pod, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	if err := u.add(ctx, pod); err != nil {
+		return err
+	}
+	var extended map[string]resource.Quantity
+	if len(u.extendedNames) > 0 {
+		// the first replica marked the claims the replicas share as seen, so this is what
+		// each further replica adds
+		var err error
+		if extended, err = u.devices.ExtendedRequests(ctx, pod, u.extendedNames, u.claimsSeen); err != nil {
+			return err
+		}
+	}
+	cpu, mem := u.defaults.PodRequests(pod)
+	subjects := u.subjectsOf(pod)
+	for range n - 1 {
+		u.pods++
+		u.cpu.Add(cpu)
+		u.memory.Add(mem)
+		u.addExtended(extended)
+		for _, s := range subjects {
+			s.pods++
+			s.cpu.Add(cpu)
+			s.memory.Add(mem)
+		}
+	}
+	return nil
+}
+
+func (u *namespaceUsage) addExtended(extended map[string]resource.Quantity) {
+	for name, q := range extended {
+		total := u.extended[name]
+		total.Add(q)
+		u.extended[name] = total
+	}
+}
+
+// addRequests counts the CPU and memory pod requests, without counting it as a pod.
+func (u *namespaceUsage) addRequests(pod *corev1.Pod) {
+	cpu, mem := u.defaults.PodRequests(pod)
+	u.cpu.Add(cpu)
+	u.memory.Add(mem)
+}
+
+// addRequestsOf is addRequests for requests that pod, e.g. the ephemeral containers added to
+// it, adds to owner, which also counts them for the subjects owner belongs to.
+func (u *namespaceUsage) addRequestsOf(owner, pod *corev1.Pod) {
+	u.addRequests(pod)
+	for _, s := range u.subjectsOf(owner) {
+		s.addRequests(pod)
+	}
+}
+
+// resize replaces the CPU and memory requests of pod, counted as oldCPU and oldMem, by
+// newCPU and newMem.
+func (u *namespaceUsage) resize(pod *corev1.Pod, oldCPU, oldMem, newCPU, newMem resource.Quantity) {
+	usages := []*namespaceUsage{u}
+	for _, s := range u.subjectsOf(pod) {
+		usages = append(usages, s.namespaceUsage)
+	}
+	for _, usage := range usages {
+		usage.cpu.Sub(oldCPU)
+		usage.cpu.Add(newCPU)
+		usage.memory.Sub(oldMem)
+		usage.memory.Add(newMem)
+	}
+}
+
+// audit returns the usage as recorded in audit events.
+func (u *namespaceUsage) audit() audit.Usage {
+	return audit.Usage{Pods: u.pods, CPU: u.cpu.String(), Memory: u.memory.String()}
+}
+
+// exceeded returns the first limit of spec that u is over, or nil when it is within them.
+func (u *namespaceUsage) exceeded(spec *platformv1beta1.ResourceQuotaPolicySpec) *quotaViolation {
+	// unset and zero limits are treated as "no limit"
+	if maxPods := int64(spec.MaxPods); maxPods > 0 && u.pods > maxPods {
+		return &quotaViolation{
+			resource:  "pods",
+			used:      *resource.NewQuantity(u.base.pods, resource.DecimalSI),
+			requested: *resource.NewQuantity(u.pods-u.base.pods, resource.DecimalSI),
+			limit:     *resource.NewQuantity(maxPods, resource.DecimalSI),
+		}
+	}
+	if v := u.cpuExceeded(spec); v != nil {
+		return v
+	}
+	if v := u.memoryExceeded(spec); v != nil {
+		return v
+	}
+	for _, name := range u.extendedNames {
+		// unlike maxCPU and maxMemory, a zero limit forbids the resource
+		used, limit := u.extended[name], spec.MaxExtendedResources[name]
+		if used.Cmp(limit) > 0 {
+			return newViolation(name, u.base.extended[name], used, limit)
+		}
+	}
+	for _, s := range u.subjects {
+		if !s.grew() {
+			continue
+		}
+		if v := s.exceeded(&s.limits); v != nil {
+			v.subject = s
+			return v
+		}
+	}
+	return nil
+}
+
+// resizeExceeded returns the first CPU or memory limit, of spec or of a subject of pod, that a
+// resize of pod takes u over. Only a resource that grows is checked.
+func (u *namespaceUsage) resizeExceeded(pod *corev1.Pod, spec *platformv1beta1.ResourceQuotaPolicySpec, cpuGrows, memGrows bool) *quotaViolation {
+	if v := u.cpuExceeded(spec); cpuGrows && v != nil {
+		return v
+	}
+	if v := u.memoryExceeded(spec); memGrows && v != nil {
+		return v
+	}
+	for _, s := range u.subjectsOf(pod) {
+		if v := s.cpuExceeded(&s.limits); cpuGrows && v != nil {
+			v.subject = s
+			return v
+		}
+		if v := s.memoryExceeded(&s.limits); memGrows && v != nil {
+			v.subject = s
+			return v
+		}
+	}
+	return nil
+}
+
+func (u *namespaceUsage) cpuExceeded(spec *platformv1beta1.ResourceQuotaPolicySpec) *quotaViolation {
+	if spec.MaxCPU != nil && spec.MaxCPU.Sign() > 0 && u.cpu.Cmp(*spec.MaxCPU) > 0 {
+		return newViolation("cpu", u.base.cpu, u.cpu, *spec.MaxCPU)
+	}
+	return nil
+}
+
+func (u *namespaceUsage) memoryExceeded(spec *platformv1beta1.ResourceQuotaPolicySpec) *quotaViolation {
+	if spec.MaxMemory != nil && spec.MaxMemory.Sign() > 0 && u.memory.Cmp(*spec.MaxMemory) > 0 {
+		return newViolation("memory", u.base.memory, u.memory, *spec.MaxMemory)
+	}
+	return nil
+}
+
+// headroom lists what is left of each limit of spec before the request, e.g.
+// "pods 9, cpu 800m, memory 1Gi".
+func (u *namespaceUsage) headroom(spec *platformv1beta1.ResourceQuotaPolicySpec) string {
+	var parts []string
+	if maxPods := int64(spec.MaxPods); maxPods > 0 {
+		parts = append(parts, fmt.Sprintf("pods %d", max(maxPods-u.base.pods, 0)))
+	}
+	if spec.MaxCPU != nil && spec.MaxCPU.Sign() > 0 {
+		left := remaining(*spec.MaxCPU, u.base.cpu)
+		parts = append(parts, "cpu "+left.String())
+	}
+	if spec.MaxMemory != nil && spec.MaxMemory.Sign() > 0 {
+		left := remaining(*spec.MaxMemory, u.base.memory)
+		parts = append(parts, "memory "+left.String())
+	}
+	for _, name := range u.extendedNames {
+		left := remaining(spec.MaxExtendedResources[name], u.base.extended[name])
+		parts = append(parts, name+" "+left.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+)
+
+// Cause types of the details of a quota denial. Each cause's field is the resource and its
+// message the quantity, so clients can read the numbers without parsing the message.
+const (
+	CauseTypeRequested metav1.CauseType = "QuotaRequested"
+	CauseTypeUsed      metav1.CauseType = "QuotaUsed"
+	CauseTypeLimit     metav1.CauseType = "QuotaLimit"
+	CauseTypeRemaining metav1.CauseType = "QuotaRemaining"
+)
+
+// verdict is the outcome of evaluating an admission against a policy.
+type verdict struct {
+	allowed bool
+	reason  string
+	// usage is the namespace's usage before the request
+	usage audit.Usage
+	// violation is the limit the request would exceed, nil for other denials
+	violation *quotaViolation
+	// headroom is what was left of the limits before the request, see namespaceUsage.headroom
+	headroom string
+}
+
+func allow(usage audit.Usage) verdict {
+	return verdict{allowed: true, usage: usage}
+}
+
+func deny(reason string, usage audit.Usage) verdict {
+	return verdict{reason: reason, usage: usage}
+}
+
+// denyOver denies the request for taking u over a limit of spec, or of one of its subjects.
+func (u *namespaceUsage) denyOver(v *quotaViolation, spec *platformv1beta1.ResourceQuotaPolicySpec) verdict {
+	headroom := u.headroom(spec)
+	if v.subject != nil {
+		headroom = v.subject.name() + ": " + v.subject.headroom(&v.subject.limits)
+	}
+	return verdict{reason: v.reason(), usage: u.base.audit(), violation: v, headroom: headroom}
+}
+
+// status explains the denial to the client, starting with message.
+func (v verdict) status(message string) *metav1.Status {
+	if v.violation == nil {
+		return &metav1.Status{Message: fmt.Sprintf("%s: %s", message, v.reason)}
+	}
+	return v.violation.status(message)
+}
+
+// warnings returns the admission warnings of a denial.
+func (v verdict) warnings() []string {
+	if v.headroom == "" {
+		return nil
+	}
+	return []string{"remaining quota: " + v.headroom}
+}
+
+// quotaViolation is a limit a request would take the namespace over.
+type quotaViolation struct {
+	// resource is "pods", "cpu", "memory" or an extended resource name
+	resource string
+	// used is the usage before the request and requested what the request adds to it
+	used, requested, limit resource.Quantity
+	// subject is the subject whose limit it is, nil for the namespace's
+	subject *subjectUsage
+}
+
+// newViolation returns the violation of limit by total, up from used.
+func newViolation(name string, used, total, limit resource.Quantity) *quotaViolation {
+	requested := total.DeepCopy()
+	requested.Sub(used)
+	return &quotaViolation{resource: name, used: used.DeepCopy(), requested: requested, limit: limit.DeepCopy()}
+}
+
+// reason is the short form used in metrics, events and audit records, e.g.
+// "cpu exceeded: 1200m > 1", or "serviceAccount ci: cpu exceeded: 1200m > 1" for a subject's
+// limit.
+func (v *quotaViolation) reason() string {
+	total := v.used.DeepCopy()
+	total.Add(v.requested)
+	name := v.resource
+	if name == "pods" {
+		name = "maxPods"
+	}
+	reason := fmt.Sprintf("%s exceeded: %s > %s", name, total.String(), v.limit.String())
+	if v.subject != nil {
+		return v.subject.name() + ": " + reason
+	}
+	return reason
+}
+
+func (v *quotaViolation) status(message string) *metav1.Status {
+	left := remaining(v.limit, v.used)
+	return &metav1.Status{
+		Message: fmt.Sprintf("%s: %s (requested %s, used %s of %s, %s remaining)",
+			message, v.reason(), v.requested.String(), v.used.String(), v.limit.String(), left.String()),
+		Details: &metav1.StatusDetails{
+			Causes: []metav1.StatusCause{
+				{Type: CauseTypeRequested, Field: v.resource, Message: v.requested.String()},
+				{Type: CauseTypeUsed, Field: v.resource, Message: v.used.String()},
+				{Type: CauseTypeLimit, Field: v.resource, Message: v.limit.String()},
+				{Type: CauseTypeRemaining, Field: v.resource, Message: left.String()},
+			},
+		},
+	}
+}
+
+// remaining returns what used leaves of limit, never below zero.
+func remaining(limit, used resource.Quantity) resource.Quantity {
+	left := limit.DeepCopy()
+	left.Sub(used)
+	if left.Sign() < 0 {
+		return *resource.NewQuantity(0, limit.Format)
+	}
+	return left
+}
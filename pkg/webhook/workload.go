@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1beta1 "github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/audit"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
+	"github.com/sri2103/resource-quota-enforcer/pkg/logging"
+)
+
+// replicasExceeded explains why replicas is over the maxReplicasPerWorkload of spec, or returns
+// "" when it isn't.
+func replicasExceeded(spec *platformv1beta1.ResourceQuotaPolicySpec, replicas int32) string {
+	if spec.MaxReplicasPerWorkload <= 0 || replicas <= spec.MaxReplicasPerWorkload {
+		return ""
+	}
+	return fmt.Sprintf("maxReplicasPerWorkload exceeded: %d > %d", replicas, spec.MaxReplicasPerWorkload)
+}
+
+// HandleValidateWorkload handles AdmissionReview v1 for Deployment, StatefulSet and ReplicaSet
+// CREATE and UPDATE operations, denying those that set more replicas than the namespace's
+// maxReplicasPerWorkload. Updates that don't add replicas are allowed, so workloads created
+// before the limit can still be changed. Under an autoscalingCheck, workloads that a
+// HorizontalPodAutoscaler could scale past the policy, and HorizontalPodAutoscalers that could
+// scale their workload past it, are denied or warned about.
+func (s *WebhookServer) HandleValidateWorkload(w http.ResponseWriter, r *http.Request) {
+	var admissionReview admissionv1.AdmissionReview
+	if !decodeBody(w, r, &admissionReview, "admission review") {
+		return
+	}
+	req := admissionReview.Request
+	if req == nil {
+		http.Error(w, "no admission request", http.StatusBadRequest)
+		return
+	}
+	admissionReview.Response = &admissionv1.AdmissionResponse{Allowed: true, UID: req.UID}
+	if req.SubResource != "" || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) || !features.Enabled(features.WorkloadAdmission) {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	switch req.Kind.Kind {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+	case "HorizontalPodAutoscaler":
+		s.validateAutoscaler(r.Context(), w, &admissionReview)
+		return
+	default:
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	ns := req.Namespace
+	logger := logging.L().WithName("webhook").WithValues("namespace", ns, "kind", req.Kind.Kind, "name", req.Name)
+	replicas, err := workloadReplicas(req.Object.Raw)
+	if err != nil {
+		logger.Error(err, "Failed to decode workload, allowing")
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	// replicas that already run
+	var current int32
+	grew := true
+	if req.Operation == admissionv1.Update {
+		if old, err := workloadReplicas(req.OldObject.Raw); err == nil {
+			current, grew = old, replicas > old
+		}
+	}
+
+	policy, effective := s.policyFor(r.Context(), ns)
+	if policy == nil {
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+	if reason := replicasExceeded(effective, replicas); grew && reason != "" {
+		logger.Info("Denied workload", "policy", policy.Name, "reason", reason)
+		s.denyObject(&admissionReview, policy.Name, reason, fmt.Sprintf("%s %s with %d replicas denied by QuotaPolicy: %s", req.Kind.Kind, req.Name, replicas, reason))
+		writeAdmissionResponse(w, &admissionReview)
+		return
+	}
+
+	if effective.AutoscalingCheck != "" && (req.Operation == admissionv1.Create || grew || templateChanged(req.OldObject.Raw, req.Object.Raw)) {
+		if err := s.checkWorkloadAutoscaling(r.Context(), &admissionReview, policy.Name, effective, current); err != nil {
+			logger.Error(err, "Failed to check autoscaling, allowing")
+		}
+	}
+	writeAdmissionResponse(w, &admissionReview)
+}
+
+// checkWorkloadAutoscaling runs the autoscaling check of spec on the workload of review, of
+// which current replicas exist, if a HorizontalPodAutoscaler targets it.
+func (s *WebhookServer) checkWorkloadAutoscaling(ctx context.Context, review *admissionv1.AdmissionReview, policy string, spec *platformv1beta1.ResourceQuotaPolicySpec, current int32) error {
+	req := review.Request
+	hpa, err := s.autoscalerFor(ctx, req.Namespace, req.Kind.Kind, req.Name)
+	if err != nil || hpa == nil {
+		return err
+	}
+	pod, err := workloadPod(req.Object.Raw, req.Namespace)
+	if err != nil {
+		return err
+	}
+	return s.checkAutoscaling(ctx, review, policy, spec, hpa, req.Kind.Kind, req.Name, pod, current)
+}
+
+// denyObject sets the response of review to deny the request, for an object other than a pod,
+// with message, and records the denial in the metrics and the audit log.
+func (s *WebhookServer) denyObject(review *admissionv1.AdmissionReview, policy, reason, message string) {
+	req := review.Request
+	metricAdmissionViolations.WithLabelValues(req.Namespace, reason).Inc()
+	metricAdmissionRequests.WithLabelValues(req.Namespace, "denied").Inc()
+	// no pod exists yet, so the record names the object
+	s.Audit.Record(audit.Event{
+		Source:    audit.SourceWebhook,
+		Namespace: req.Namespace,
+		Pod:       req.Resource.Resource + "/" + req.Name,
+		Policy:    policy,
+		Reason:    reason,
+		Decision:  audit.DecisionDenied,
+	})
+	review.Response = &admissionv1.AdmissionResponse{
+		Allowed:          false,
+		Result:           &metav1.Status{Message: message},
+		UID:              req.UID,
+		AuditAnnotations: auditAnnotations("denied", policy, reason, audit.Usage{}),
+	}
+}
+
+// policyFor returns the policy of namespace, or the cluster default policy for a namespace
+// without one, and its spec in force now. It returns nil when neither applies, the policy is
+// suspended, the namespace is exempt or it can't be read. A namespace denying pods without a policy denies them when they are created, so
+// admissions of the objects that create them are left alone.
+func (s *WebhookServer) policyFor(ctx context.Context, namespace string) (*platformv1beta1.ResourceQuotaPolicy, *platformv1beta1.ResourceQuotaPolicySpec) {
+	if s.Exempt.Has(namespace) {
+		return nil, nil
+	}
+	policy, found := s.Cache.Get(namespace)
+	if !found || policy == nil {
+		var err error
+		if policy, _, err = s.withoutPolicy(ctx, namespace); err != nil || policy == nil {
+			return nil, nil
+		}
+	}
+	if policy.Spec.Suspend {
+		return nil, nil
+	}
+	effective := s.effectiveSpec(policy)
+	return policy, &effective
+}
+
+// effectiveSpec returns the spec of policy in force now, with its clusterShare resolved.
+func (s *WebhookServer) effectiveSpec(policy *platformv1beta1.ResourceQuotaPolicy) platformv1beta1.ResourceQuotaPolicySpec {
+	effective, _ := policy.Spec.EffectiveAt(time.Now())
+	effective.ResolveClusterShare(s.Capacity.Allocatable())
+	return effective
+}
+
+// workloadReplicas returns spec.replicas of a serialized apps/v1 workload, which defaults to 1.
+func workloadReplicas(raw []byte) (int32, error) {
+	var obj struct {
+		Spec struct {
+			Replicas *int32 `json:"replicas"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return 0, err
+	}
+	if obj.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *obj.Spec.Replicas, nil
+}
@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+	"github.com/sri2103/resource-quota-enforcer/pkg/features"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleValidateWorkload(t *testing.T) {
+	srv := &WebhookServer{Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxReplicasPerWorkload: 5},
+	}}}
+	raw := func(replicas *int32) runtime.RawExtension {
+		b, err := json.Marshal(appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+	replicas := func(n int32) *int32 { return &n }
+
+	tests := []struct {
+		name     string
+		op       admissionv1.Operation
+		old, new *int32
+		allowed  bool
+		disabled bool
+	}{
+		{name: "create within limit", op: admissionv1.Create, new: replicas(5), allowed: true},
+		{name: "create without replicas", op: admissionv1.Create, allowed: true},
+		{name: "create over limit", op: admissionv1.Create, new: replicas(6), allowed: false},
+		{name: "update past limit", op: admissionv1.Update, old: replicas(5), new: replicas(8), allowed: false},
+		// workloads that were over before the limit can still be changed
+		{name: "update already over", op: admissionv1.Update, old: replicas(8), new: replicas(8), allowed: true},
+		{name: "create over limit without WorkloadAdmission", op: admissionv1.Create, new: replicas(6), allowed: true, disabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.disabled {
+				features.SetForTesting(t, features.WorkloadAdmission, false)
+			}
+			req := &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Resource:  metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+				Namespace: "test-ns",
+				Name:      "web",
+				Operation: tt.op,
+				Object:    raw(tt.new),
+			}
+			if tt.op == admissionv1.Update {
+				req.OldObject = raw(tt.old)
+			}
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: req})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			srv.HandleValidateWorkload(rec, jsonRequest("/validate-workload", body))
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if review.Response.Allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v (%+v)", review.Response.Allowed, tt.allowed, review.Response.Result)
+			}
+			if !tt.allowed && !strings.Contains(review.Response.Result.Message, "maxReplicasPerWorkload exceeded") {
+				t.Fatalf("unexpected message %q", review.Response.Result.Message)
+			}
+		})
+	}
+}
+
+func TestHandleValidateWorkloadAutoscaling(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name:      "c",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}},
+	}}}}
+	one := int32(1)
+	deploy := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &one, Template: template},
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test-ns"},
+		Spec:       template.Spec,
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	hpa := func(max int32) *autoscalingv2.HorizontalPodAutoscaler {
+		return &autoscalingv2.HorizontalPodAutoscaler{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				MaxReplicas:    max,
+			},
+		}
+	}
+	raw := func(obj any) runtime.RawExtension {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+	maxCPU := resource.MustParse("1")
+
+	tests := []struct {
+		name    string
+		check   v1beta1.AutoscalingCheck
+		max     int32
+		kind    string // the kind admitted, the other one exists
+		allowed bool
+		warned  bool
+	}{
+		// 4 replicas at 200m and the running pod need 1
+		{name: "fits at maxReplicas", check: v1beta1.AutoscalingCheckDeny, max: 4, kind: "Deployment", allowed: true},
+		{name: "warn", check: v1beta1.AutoscalingCheckWarn, max: 10, kind: "Deployment", allowed: true, warned: true},
+		{name: "deny", check: v1beta1.AutoscalingCheckDeny, max: 10, kind: "Deployment", allowed: false},
+		{name: "no check", max: 10, kind: "Deployment", allowed: true},
+		{name: "deny autoscaler", check: v1beta1.AutoscalingCheckDeny, max: 10, kind: "HorizontalPodAutoscaler", allowed: false},
+		{name: "autoscaler fits", check: v1beta1.AutoscalingCheckDeny, max: 4, kind: "HorizontalPodAutoscaler", allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Namespace: "test-ns",
+				Name:      "web",
+				Operation: admissionv1.Create,
+			}
+			var existing runtime.Object
+			if tt.kind == "Deployment" {
+				req.Kind = metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+				req.Resource = metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+				req.Object, existing = raw(deploy), hpa(tt.max)
+			} else {
+				req.Kind = metav1.GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}
+				req.Resource = metav1.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+				req.Object, existing = raw(hpa(tt.max)), deploy
+			}
+			srv := &WebhookServer{
+				Clientset: fakeclient.NewSimpleClientset(existing, running),
+				Cache: staticCache{&v1beta1.ResourceQuotaPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+					Spec:       v1beta1.ResourceQuotaPolicySpec{MaxCPU: &maxCPU, AutoscalingCheck: tt.check},
+				}},
+			}
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: req})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			srv.HandleValidateWorkload(rec, jsonRequest("/validate-workload", body))
+			var review admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if review.Response.Allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v (%+v)", review.Response.Allowed, tt.allowed, review.Response.Result)
+			}
+			if warned := len(review.Response.Warnings) > 0; warned != tt.warned {
+				t.Fatalf("warnings = %v, want warned %v", review.Response.Warnings, tt.warned)
+			}
+			message := strings.Join(review.Response.Warnings, "")
+			if !tt.allowed {
+				message = review.Response.Result.Message
+			}
+			if (!tt.allowed || tt.warned) && !strings.Contains(message, "HorizontalPodAutoscaler web can scale Deployment web to 10 replicas") {
+				t.Fatalf("unexpected message %q", message)
+			}
+		})
+	}
+}
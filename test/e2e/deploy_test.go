@@ -0,0 +1,375 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// systemNamespace is where config/ deploys the controller, next to which the webhook goes.
+	systemNamespace = "kube-system"
+	webhookName     = "rqe-webhook"
+	fieldManager    = "rqe-e2e"
+)
+
+// deployer applies the manifests of config/ and the webhook's objects, and deletes them again.
+type deployer struct {
+	mapper  *restmapper.DeferredDiscoveryRESTMapper
+	applied []*unstructured.Unstructured
+}
+
+func newDeployer() *deployer {
+	return &deployer{mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(env.kube.Discovery()))}
+}
+
+// repoRoot is the root of the repository, for reading config/.
+func repoRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// deploy installs the CRDs, RBAC and both binaries and waits for them to become available.
+func (d *deployer) deploy(ctx context.Context, controllerImage, webhookImage string) error {
+	ca, cert, key, err := servingCert(webhookName + "." + systemNamespace + ".svc")
+	if err != nil {
+		return err
+	}
+	service := func(path string) *admissionregistrationv1.ServiceReference {
+		port := int32(443)
+		return &admissionregistrationv1.ServiceReference{Namespace: systemNamespace, Name: webhookName, Path: &path, Port: &port}
+	}
+
+	// the CRD converts between versions through the webhook, which has to be reachable in the
+	// cluster rather than on the host config/crd.yaml points at
+	err = d.applyFile(ctx, "config/crd.yaml", func(u *unstructured.Unstructured) error {
+		if _, found, _ := unstructured.NestedMap(u.Object, "spec", "conversion", "webhook", "clientConfig"); !found {
+			return nil
+		}
+		return unstructured.SetNestedMap(u.Object, map[string]interface{}{
+			"service": map[string]interface{}{
+				"namespace": systemNamespace,
+				"name":      webhookName,
+				"path":      "/convert",
+				"port":      int64(443),
+			},
+			"caBundle": base64.StdEncoding.EncodeToString(ca),
+		}, "spec", "conversion", "webhook", "clientConfig")
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range []string{"config/serviceaccount.yaml", "config/clusterrole.yaml", "config/clusterrolebinding.yaml"} {
+		if err := d.applyFile(ctx, file, nil); err != nil {
+			return err
+		}
+	}
+
+	labels := map[string]string{"app": webhookName}
+	webhookObjects := []k8sruntime.Object{
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: webhookName + "-tls", Namespace: systemNamespace},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: webhookName, Namespace: systemNamespace},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    []corev1.ServicePort{{Port: 443, TargetPort: intstr.FromInt32(8443)}},
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: webhookName, Namespace: systemNamespace},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						ServiceAccountName: "rqe-controller",
+						Containers: []corev1.Container{{
+							Name:            "webhook",
+							Image:           webhookImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args: []string{
+								"-kubeconfig=",
+								"-listen=:8443",
+								"-tls-cert-file=/certs/tls.crt",
+								"-tls-key-file=/certs/tls.key",
+								"-log-level=debug",
+							},
+							Ports: []corev1.ContainerPort{{ContainerPort: 8443}},
+							ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+								Path: "/readyz", Port: intstr.FromInt32(8443), Scheme: corev1.URISchemeHTTPS,
+							}}},
+							VolumeMounts: []corev1.VolumeMount{{Name: "certs", MountPath: "/certs", ReadOnly: true}},
+						}},
+						Volumes: []corev1.Volume{{Name: "certs", VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{SecretName: webhookName + "-tls"},
+						}}},
+					},
+				},
+			},
+		},
+	}
+	for _, obj := range webhookObjects {
+		if err := d.apply(ctx, obj); err != nil {
+			return err
+		}
+	}
+	err = d.applyFile(ctx, "config/deployment.yaml", func(u *unstructured.Unstructured) error {
+		var deployment appsv1.Deployment
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &deployment); err != nil {
+			return err
+		}
+		for i := range deployment.Spec.Template.Spec.Containers {
+			deployment.Spec.Template.Spec.Containers[i].Image = controllerImage
+			deployment.Spec.Template.Spec.Containers[i].ImagePullPolicy = corev1.PullIfNotPresent
+		}
+		obj, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+		u.Object = obj
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{webhookName, "rqe-controller"} {
+		if err := waitAvailable(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	// registered last, so that pods aren't rejected while the webhook isn't serving yet
+	fail := admissionregistrationv1.Fail
+	none := admissionregistrationv1.SideEffectClassNone
+	return d.apply(ctx, &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "ValidatingWebhookConfiguration"},
+		ObjectMeta: metav1.ObjectMeta{Name: fieldManager},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:                    "local-validator.example.com",
+				AdmissionReviewVersions: []string{"v1"},
+				SideEffects:             &none,
+				FailurePolicy:           &fail,
+				ClientConfig:            admissionregistrationv1.WebhookClientConfig{Service: service("/validate"), CABundle: ca},
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{""},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"pods", "pods/ephemeralcontainers", "pods/resize"},
+					},
+				}},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"webhook": "enabled"}},
+			},
+			{
+				Name:                    "policy-validator.example.com",
+				AdmissionReviewVersions: []string{"v1"},
+				SideEffects:             &none,
+				FailurePolicy:           &fail,
+				ClientConfig:            admissionregistrationv1.WebhookClientConfig{Service: service("/validate-policy"), CABundle: ca},
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"platform.example.com"},
+						APIVersions: []string{"v1beta1", "v1alpha1"},
+						Resources:   []string{"resourcequotapolicies"},
+					},
+				}},
+			},
+		},
+	})
+}
+
+// teardown deletes what deploy applied, the webhook configuration first.
+func (d *deployer) teardown(ctx context.Context) {
+	for i := len(d.applied) - 1; i >= 0; i-- {
+		u := d.applied[i]
+		mapping, err := d.mapping(u)
+		if err == nil {
+			err = d.resource(mapping, u).Delete(ctx, u.GetName(), metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			fmt.Fprintf(os.Stderr, "e2e: delete %s %s: %v\n", u.GetKind(), u.GetName(), err)
+		}
+	}
+}
+
+// applyFile applies every object of the manifest at path, relative to the repository, after
+// passing it to mutate, if any.
+func (d *deployer) applyFile(ctx context.Context, path string, mutate func(*unstructured.Unstructured) error) error {
+	data, err := os.ReadFile(filepath.Join(repoRoot(), path))
+	if err != nil {
+		return err
+	}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc, &u.Object); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		if mutate != nil {
+			if err := mutate(u); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		if err := d.applyUnstructured(ctx, u); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+}
+
+func (d *deployer) apply(ctx context.Context, obj k8sruntime.Object) error {
+	content, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	return d.applyUnstructured(ctx, &unstructured.Unstructured{Object: content})
+}
+
+// applyUnstructured server-side applies u, retrying while its kind isn't served yet, e.g. right
+// after its CRD was applied.
+func (d *deployer) applyUnstructured(ctx context.Context, u *unstructured.Unstructured) error {
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+	err := wait.PollUntilContextTimeout(ctx, time.Second, time.Minute, true, func(ctx context.Context) (bool, error) {
+		mapping, err := d.mapping(u)
+		if meta.IsNoMatchError(err) {
+			d.mapper.Reset()
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		_, err = d.resource(mapping, u).Apply(ctx, u.GetName(), u, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+		return err == nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("apply %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	d.applied = append(d.applied, u)
+	return nil
+}
+
+func (d *deployer) mapping(u *unstructured.Unstructured) (*meta.RESTMapping, error) {
+	gvk := u.GroupVersionKind()
+	return d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func (d *deployer) resource(mapping *meta.RESTMapping, u *unstructured.Unstructured) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return env.dynamic.Resource(mapping.Resource).Namespace(u.GetNamespace())
+	}
+	return env.dynamic.Resource(mapping.Resource)
+}
+
+// waitAvailable waits for the deployment name in systemNamespace to roll out.
+func waitAvailable(ctx context.Context, name string) error {
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, 3*time.Minute, true, func(ctx context.Context) (bool, error) {
+		deployment, err := env.kube.AppsV1().Deployments(systemNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		s := deployment.Status
+		return s.ObservedGeneration >= deployment.Generation && s.UpdatedReplicas == s.Replicas && s.AvailableReplicas == s.Replicas && s.Replicas > 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("deployment %s/%s not available: %w", systemNamespace, name, err)
+	}
+	return nil
+}
+
+// servingCert returns a self-signed CA and a certificate it signed for host, PEM encoded, with
+// the certificate's key.
+func servingCert(host string) (ca, cert, key []byte, err error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rqe-e2e-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	servingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &servingKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(servingKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
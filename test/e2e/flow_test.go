@@ -0,0 +1,150 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/apis/platform/v1beta1"
+)
+
+// pauseImage is preloaded on kind nodes, as the pod sandbox image.
+const pauseImage = "registry.k8s.io/pause:3.10"
+
+func TestPolicyFlow(t *testing.T) {
+	ctx := context.Background()
+	ns := newNamespace(t)
+
+	policies := env.policies.PlatformV1beta1().ResourceQuotaPolicies(ns)
+	if _, err := policies.Create(ctx, &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e", Namespace: ns},
+		Spec:       v1beta1.ResourceQuotaPolicySpec{MaxPods: 2},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+
+	pods := env.kube.CoreV1().Pods(ns)
+	for _, name := range []string{"web-1", "web-2"} {
+		if _, err := pods.Create(ctx, pod(name), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("create %s within maxPods: %v", name, err)
+		}
+	}
+
+	// the webhook learns of the policy through its informer, so the third pod is tried as a dry
+	// run until it is denied
+	var denial error
+	eventually(t, "webhook to deny a pod over maxPods", func(ctx context.Context) (bool, error) {
+		_, err := pods.Create(ctx, pod("web-3"), metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		if err == nil {
+			return false, nil
+		}
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) || strings.Contains(err.Error(), "denied the request") {
+			denial = err
+			return true, nil
+		}
+		return false, err
+	})
+	if !strings.Contains(denial.Error(), "maxPods") {
+		t.Errorf("denial doesn't name maxPods: %v", denial)
+	}
+
+	// a pod created while the webhook doesn't see the namespace is over the policy, which the
+	// controller enforces by deleting a pod
+	setWebhookLabel(t, ns, false)
+	if _, err := pods.Create(ctx, pod("web-3"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create web-3 past the webhook: %v", err)
+	}
+	setWebhookLabel(t, ns, true)
+	eventually(t, "controller to delete a pod over maxPods", func(ctx context.Context) (bool, error) {
+		list, err := pods.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		live := 0
+		for _, p := range list.Items {
+			if p.DeletionTimestamp == nil {
+				live++
+			}
+		}
+		return live == 2, nil
+	})
+
+	eventually(t, "policy status to report the usage", func(ctx context.Context) (bool, error) {
+		policy, err := policies.Get(ctx, "e2e", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return policy.Status.CurrentPods == 2 && len(policy.Status.Conditions) > 0, nil
+	})
+}
+
+func TestPolicyValidation(t *testing.T) {
+	ns := newNamespace(t)
+	_, err := env.policies.PlatformV1beta1().ResourceQuotaPolicies(ns).Create(context.Background(), &v1beta1.ResourceQuotaPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: ns},
+		Spec: v1beta1.ResourceQuotaPolicySpec{
+			MaxPods:         1,
+			ValidationRules: []v1beta1.ValidationRule{{Expression: "pod.spec.("}},
+		},
+	}, metav1.CreateOptions{})
+	if err == nil {
+		t.Fatal("policy with a validation rule that doesn't compile was accepted")
+	}
+}
+
+// newNamespace creates a namespace the webhook validates pods in, deleted after the test.
+func newNamespace(t *testing.T) string {
+	t.Helper()
+	ns, err := env.kube.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "rqe-e2e-", Labels: map[string]string{"webhook": "enabled"}},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = env.kube.CoreV1().Namespaces().Delete(context.Background(), ns.Name, metav1.DeleteOptions{})
+	})
+	return ns.Name
+}
+
+// setWebhookLabel adds or removes the label the webhook selects namespaces by.
+func setWebhookLabel(t *testing.T, ns string, enabled bool) {
+	t.Helper()
+	value := "null"
+	if enabled {
+		value = `"enabled"`
+	}
+	patch := fmt.Sprintf(`{"metadata":{"labels":{"webhook":%s}}}`, value)
+	if _, err := env.kube.CoreV1().Namespaces().Patch(context.Background(), ns, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		t.Fatalf("label namespace %s: %v", ns, err)
+	}
+}
+
+func pod(name string) *corev1.Pod {
+	grace := int64(0)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{
+			TerminationGracePeriodSeconds: &grace,
+			Containers:                    []corev1.Container{{Name: "pause", Image: pauseImage}},
+		},
+	}
+}
+
+// eventually polls condition for up to two minutes, failing the test if it doesn't hold by then.
+func eventually(t *testing.T, what string, condition wait.ConditionWithContextFunc) {
+	t.Helper()
+	if err := wait.PollUntilContextTimeout(context.Background(), time.Second, 2*time.Minute, true, condition); err != nil {
+		t.Fatalf("waiting for %s: %v", what, err)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build e2e
+
+// Package e2e deploys the controller and the webhook into a cluster and checks the whole flow,
+// from creating a policy to the webhook denying pods over it and the controller deleting pods
+// that got past it. hack/scripts/e2e.sh runs it against a kind cluster; against any other, set
+// RQE_E2E_KUBECONFIG and the images in RQE_E2E_CONTROLLER_IMAGE and RQE_E2E_WEBHOOK_IMAGE, which
+// the cluster must be able to pull.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/sri2103/resource-quota-enforcer/pkg/client"
+	versioned "github.com/sri2103/resource-quota-enforcer/pkg/generated/clientset/versioned"
+)
+
+// env is the cluster the suite runs against, set up by TestMain.
+var env struct {
+	config   *rest.Config
+	kube     kubernetes.Interface
+	policies versioned.Interface
+	dynamic  dynamic.Interface
+}
+
+func TestMain(m *testing.M) {
+	kubeconfig := os.Getenv("RQE_E2E_KUBECONFIG")
+	controllerImage := os.Getenv("RQE_E2E_CONTROLLER_IMAGE")
+	webhookImage := os.Getenv("RQE_E2E_WEBHOOK_IMAGE")
+	if kubeconfig == "" || controllerImage == "" || webhookImage == "" {
+		fmt.Fprintln(os.Stderr, "RQE_E2E_KUBECONFIG, RQE_E2E_CONTROLLER_IMAGE and RQE_E2E_WEBHOOK_IMAGE must be set, see hack/scripts/e2e.sh")
+		os.Exit(1)
+	}
+
+	code, err := run(m, kubeconfig, controllerImage, webhookImage)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e:", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func run(m *testing.M, kubeconfig, controllerImage, webhookImage string) (int, error) {
+	var err error
+	if env.config, err = client.BuildConfig(kubeconfig); err != nil {
+		return 0, err
+	}
+	if env.kube, err = kubernetes.NewForConfig(env.config); err != nil {
+		return 0, err
+	}
+	if env.policies, err = versioned.NewForConfig(env.config); err != nil {
+		return 0, err
+	}
+	if env.dynamic, err = dynamic.NewForConfig(env.config); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	d := newDeployer()
+	// torn down even when deploying fails half way, so a failing webhook doesn't keep rejecting
+	// pods in a cluster that is kept around
+	defer func() {
+		if os.Getenv("RQE_E2E_KEEP") == "" {
+			d.teardown(context.Background())
+		}
+	}()
+	if err := d.deploy(ctx, controllerImage, webhookImage); err != nil {
+		return 0, err
+	}
+	return m.Run(), nil
+}